@@ -27,6 +27,18 @@ type SecretClient interface {
 	DeleteSecret(ctx context.Context, in *DeleteSecretRequest, opts ...grpc.CallOption) (*DeleteSecretResponse, error)
 	EditSecret(ctx context.Context, in *EditSecretRequest, opts ...grpc.CallOption) (*EditSecretResponse, error)
 	GetListOfSecretsByType(ctx context.Context, in *GetListOfSecretsByTypeRequest, opts ...grpc.CallOption) (*GetListOfSecretsByTypeResponse, error)
+	ListSecretVersions(ctx context.Context, in *ListSecretVersionsRequest, opts ...grpc.CallOption) (*ListSecretVersionsResponse, error)
+	GetSecretVersion(ctx context.Context, in *GetSecretVersionRequest, opts ...grpc.CallOption) (*GetSecretVersionResponse, error)
+	RestoreSecretVersion(ctx context.Context, in *RestoreSecretVersionRequest, opts ...grpc.CallOption) (*RestoreSecretVersionResponse, error)
+	ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error)
+	RestoreSecret(ctx context.Context, in *RestoreSecretRequest, opts ...grpc.CallOption) (*RestoreSecretResponse, error)
+	PurgeSecret(ctx context.Context, in *PurgeSecretRequest, opts ...grpc.CallOption) (*PurgeSecretResponse, error)
+	ListSecrets(ctx context.Context, in *ListSecretsRequest, opts ...grpc.CallOption) (*ListSecretsResponse, error)
+	MoveSecret(ctx context.Context, in *MoveSecretRequest, opts ...grpc.CallOption) (*MoveSecretResponse, error)
+	SetSecretTags(ctx context.Context, in *SetSecretTagsRequest, opts ...grpc.CallOption) (*SetSecretTagsResponse, error)
+	BatchCreateSecrets(ctx context.Context, in *BatchCreateSecretsRequest, opts ...grpc.CallOption) (*BatchCreateSecretsResponse, error)
+	BatchGetSecrets(ctx context.Context, in *BatchGetSecretsRequest, opts ...grpc.CallOption) (*BatchGetSecretsResponse, error)
+	BatchDeleteSecrets(ctx context.Context, in *BatchDeleteSecretsRequest, opts ...grpc.CallOption) (*BatchDeleteSecretsResponse, error)
 }
 
 type secretClient struct {
@@ -82,6 +94,114 @@ func (c *secretClient) GetListOfSecretsByType(ctx context.Context, in *GetListOf
 	return out, nil
 }
 
+func (c *secretClient) ListSecretVersions(ctx context.Context, in *ListSecretVersionsRequest, opts ...grpc.CallOption) (*ListSecretVersionsResponse, error) {
+	out := new(ListSecretVersionsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/ListSecretVersions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) GetSecretVersion(ctx context.Context, in *GetSecretVersionRequest, opts ...grpc.CallOption) (*GetSecretVersionResponse, error) {
+	out := new(GetSecretVersionResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/GetSecretVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) RestoreSecretVersion(ctx context.Context, in *RestoreSecretVersionRequest, opts ...grpc.CallOption) (*RestoreSecretVersionResponse, error) {
+	out := new(RestoreSecretVersionResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/RestoreSecretVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error) {
+	out := new(ListTrashResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/ListTrash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) RestoreSecret(ctx context.Context, in *RestoreSecretRequest, opts ...grpc.CallOption) (*RestoreSecretResponse, error) {
+	out := new(RestoreSecretResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/RestoreSecret", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) PurgeSecret(ctx context.Context, in *PurgeSecretRequest, opts ...grpc.CallOption) (*PurgeSecretResponse, error) {
+	out := new(PurgeSecretResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/PurgeSecret", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) ListSecrets(ctx context.Context, in *ListSecretsRequest, opts ...grpc.CallOption) (*ListSecretsResponse, error) {
+	out := new(ListSecretsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/ListSecrets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) MoveSecret(ctx context.Context, in *MoveSecretRequest, opts ...grpc.CallOption) (*MoveSecretResponse, error) {
+	out := new(MoveSecretResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/MoveSecret", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) SetSecretTags(ctx context.Context, in *SetSecretTagsRequest, opts ...grpc.CallOption) (*SetSecretTagsResponse, error) {
+	out := new(SetSecretTagsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/SetSecretTags", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) BatchCreateSecrets(ctx context.Context, in *BatchCreateSecretsRequest, opts ...grpc.CallOption) (*BatchCreateSecretsResponse, error) {
+	out := new(BatchCreateSecretsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/BatchCreateSecrets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) BatchGetSecrets(ctx context.Context, in *BatchGetSecretsRequest, opts ...grpc.CallOption) (*BatchGetSecretsResponse, error) {
+	out := new(BatchGetSecretsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/BatchGetSecrets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretClient) BatchDeleteSecrets(ctx context.Context, in *BatchDeleteSecretsRequest, opts ...grpc.CallOption) (*BatchDeleteSecretsResponse, error) {
+	out := new(BatchDeleteSecretsResponse)
+	err := c.cc.Invoke(ctx, "/proto.Secret/BatchDeleteSecrets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SecretServer is the server API for Secret service.
 // All implementations must embed UnimplementedSecretServer
 // for forward compatibility
@@ -91,6 +211,18 @@ type SecretServer interface {
 	DeleteSecret(context.Context, *DeleteSecretRequest) (*DeleteSecretResponse, error)
 	EditSecret(context.Context, *EditSecretRequest) (*EditSecretResponse, error)
 	GetListOfSecretsByType(context.Context, *GetListOfSecretsByTypeRequest) (*GetListOfSecretsByTypeResponse, error)
+	ListSecretVersions(context.Context, *ListSecretVersionsRequest) (*ListSecretVersionsResponse, error)
+	GetSecretVersion(context.Context, *GetSecretVersionRequest) (*GetSecretVersionResponse, error)
+	RestoreSecretVersion(context.Context, *RestoreSecretVersionRequest) (*RestoreSecretVersionResponse, error)
+	ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error)
+	RestoreSecret(context.Context, *RestoreSecretRequest) (*RestoreSecretResponse, error)
+	PurgeSecret(context.Context, *PurgeSecretRequest) (*PurgeSecretResponse, error)
+	ListSecrets(context.Context, *ListSecretsRequest) (*ListSecretsResponse, error)
+	MoveSecret(context.Context, *MoveSecretRequest) (*MoveSecretResponse, error)
+	SetSecretTags(context.Context, *SetSecretTagsRequest) (*SetSecretTagsResponse, error)
+	BatchCreateSecrets(context.Context, *BatchCreateSecretsRequest) (*BatchCreateSecretsResponse, error)
+	BatchGetSecrets(context.Context, *BatchGetSecretsRequest) (*BatchGetSecretsResponse, error)
+	BatchDeleteSecrets(context.Context, *BatchDeleteSecretsRequest) (*BatchDeleteSecretsResponse, error)
 	mustEmbedUnimplementedSecretServer()
 }
 
@@ -113,6 +245,42 @@ func (UnimplementedSecretServer) EditSecret(context.Context, *EditSecretRequest)
 func (UnimplementedSecretServer) GetListOfSecretsByType(context.Context, *GetListOfSecretsByTypeRequest) (*GetListOfSecretsByTypeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetListOfSecretsByType not implemented")
 }
+func (UnimplementedSecretServer) ListSecretVersions(context.Context, *ListSecretVersionsRequest) (*ListSecretVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSecretVersions not implemented")
+}
+func (UnimplementedSecretServer) GetSecretVersion(context.Context, *GetSecretVersionRequest) (*GetSecretVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSecretVersion not implemented")
+}
+func (UnimplementedSecretServer) RestoreSecretVersion(context.Context, *RestoreSecretVersionRequest) (*RestoreSecretVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecretVersion not implemented")
+}
+func (UnimplementedSecretServer) ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedSecretServer) RestoreSecret(context.Context, *RestoreSecretRequest) (*RestoreSecretResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecret not implemented")
+}
+func (UnimplementedSecretServer) PurgeSecret(context.Context, *PurgeSecretRequest) (*PurgeSecretResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSecret not implemented")
+}
+func (UnimplementedSecretServer) ListSecrets(context.Context, *ListSecretsRequest) (*ListSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSecrets not implemented")
+}
+func (UnimplementedSecretServer) MoveSecret(context.Context, *MoveSecretRequest) (*MoveSecretResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveSecret not implemented")
+}
+func (UnimplementedSecretServer) SetSecretTags(context.Context, *SetSecretTagsRequest) (*SetSecretTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSecretTags not implemented")
+}
+func (UnimplementedSecretServer) BatchCreateSecrets(context.Context, *BatchCreateSecretsRequest) (*BatchCreateSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateSecrets not implemented")
+}
+func (UnimplementedSecretServer) BatchGetSecrets(context.Context, *BatchGetSecretsRequest) (*BatchGetSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetSecrets not implemented")
+}
+func (UnimplementedSecretServer) BatchDeleteSecrets(context.Context, *BatchDeleteSecretsRequest) (*BatchDeleteSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeleteSecrets not implemented")
+}
 func (UnimplementedSecretServer) mustEmbedUnimplementedSecretServer() {}
 
 // UnsafeSecretServer may be embedded to opt out of forward compatibility for this service.
@@ -216,6 +384,222 @@ func _Secret_GetListOfSecretsByType_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Secret_ListSecretVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSecretVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).ListSecretVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/ListSecretVersions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).ListSecretVersions(ctx, req.(*ListSecretVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_GetSecretVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSecretVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).GetSecretVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/GetSecretVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).GetSecretVersion(ctx, req.(*GetSecretVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_RestoreSecretVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSecretVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).RestoreSecretVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/RestoreSecretVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).RestoreSecretVersion(ctx, req.(*RestoreSecretVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/ListTrash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).ListTrash(ctx, req.(*ListTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_RestoreSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).RestoreSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/RestoreSecret",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).RestoreSecret(ctx, req.(*RestoreSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_PurgeSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).PurgeSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/PurgeSecret",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).PurgeSecret(ctx, req.(*PurgeSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_ListSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).ListSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/ListSecrets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).ListSecrets(ctx, req.(*ListSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_MoveSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).MoveSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/MoveSecret",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).MoveSecret(ctx, req.(*MoveSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_SetSecretTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSecretTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).SetSecretTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/SetSecretTags",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).SetSecretTags(ctx, req.(*SetSecretTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_BatchCreateSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).BatchCreateSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/BatchCreateSecrets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).BatchCreateSecrets(ctx, req.(*BatchCreateSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_BatchGetSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).BatchGetSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/BatchGetSecrets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).BatchGetSecrets(ctx, req.(*BatchGetSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secret_BatchDeleteSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretServer).BatchDeleteSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Secret/BatchDeleteSecrets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretServer).BatchDeleteSecrets(ctx, req.(*BatchDeleteSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Secret_ServiceDesc is the grpc.ServiceDesc for Secret service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -243,6 +627,54 @@ var Secret_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetListOfSecretsByType",
 			Handler:    _Secret_GetListOfSecretsByType_Handler,
 		},
+		{
+			MethodName: "ListSecretVersions",
+			Handler:    _Secret_ListSecretVersions_Handler,
+		},
+		{
+			MethodName: "GetSecretVersion",
+			Handler:    _Secret_GetSecretVersion_Handler,
+		},
+		{
+			MethodName: "RestoreSecretVersion",
+			Handler:    _Secret_RestoreSecretVersion_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _Secret_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreSecret",
+			Handler:    _Secret_RestoreSecret_Handler,
+		},
+		{
+			MethodName: "PurgeSecret",
+			Handler:    _Secret_PurgeSecret_Handler,
+		},
+		{
+			MethodName: "ListSecrets",
+			Handler:    _Secret_ListSecrets_Handler,
+		},
+		{
+			MethodName: "MoveSecret",
+			Handler:    _Secret_MoveSecret_Handler,
+		},
+		{
+			MethodName: "SetSecretTags",
+			Handler:    _Secret_SetSecretTags_Handler,
+		},
+		{
+			MethodName: "BatchCreateSecrets",
+			Handler:    _Secret_BatchCreateSecrets_Handler,
+		},
+		{
+			MethodName: "BatchGetSecrets",
+			Handler:    _Secret_BatchGetSecrets_Handler,
+		},
+		{
+			MethodName: "BatchDeleteSecrets",
+			Handler:    _Secret_BatchDeleteSecrets_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/secret.proto",
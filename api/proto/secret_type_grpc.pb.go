@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.2.0
-// - protoc             v3.21.5
+// - protoc             v3.21.6
 // source: api/proto/secret_type.proto
 
 package proto
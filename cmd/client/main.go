@@ -16,9 +16,11 @@ var (
 func main() {
 	fmt.Printf("Build version: %s\nBuild date: %s\n", buildVersion, buildDate)
 
+	executor := customPrompt.NewExecutor()
+
 	p := prompt.New(
-		customPrompt.NewExecutor().Execute,
-		customPrompt.NewCompleter().Complete,
+		executor.Execute,
+		customPrompt.NewCompleter(executor.FolderPaths).Complete,
 		prompt.OptionTitle("Gophkeeper"),
 		prompt.OptionPrefix(">>>"),
 		prompt.OptionInputTextColor(prompt.Yellow),
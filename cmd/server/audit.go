@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergalkin/gophkeeper/internal/server/config"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/audit"
+	"github.com/sergalkin/gophkeeper/pkg/signer"
+)
+
+// runVerifyAuditCommand - walks audit chain and prints the first broken link, checkpoints are verified with
+// certificate of the server.
+//
+// Usage: verify-audit
+func runVerifyAuditCommand(ctx context.Context) error {
+	cfg := config.NewConfig()
+
+	verifier, err := signer.NewKeyPairSigner(cfg.SSLCertPath, cfg.SSLKeyPath)
+	if err != nil {
+		return err
+	}
+
+	audits, closeStorage, err := openAuditStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	report, err := audit.Verify(ctx, audits, verifier)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("verified events: %d, checkpoints: %d\n", report.Events, report.Checkpoints)
+
+	if report.LegacyEvents > 0 {
+		fmt.Printf("events recorded before chaining, which can't be verified: %d\n", report.LegacyEvents)
+	}
+
+	if report.Broken != nil {
+		return errors.New("audit chain is broken at " + report.Broken.String())
+	}
+
+	if report.Unsigned > 0 {
+		fmt.Printf("events after the last checkpoint, which are protected by chain only: %d\n", report.Unsigned)
+	}
+
+	fmt.Println("audit chain is intact")
+
+	return nil
+}
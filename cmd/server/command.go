@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/config"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/postgres"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/sqlite"
+)
+
+// runCommand - runs administrative command of the server instead of serving requests.
+func runCommand(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "jobs":
+		return runJobsCommand(ctx, args[1:])
+	case "migrate":
+		return runMigrateCommand(args[1:])
+	case "users":
+		return runUsersCommand(ctx, args[1:])
+	case "verify-audit":
+		return runVerifyAuditCommand(ctx)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// connect - opens connection to database of the server.
+func connect(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, config.NewConfig().DSN)
+	if err != nil {
+		return nil, fmt.Errorf("db open: %w", err)
+	}
+
+	return conn, nil
+}
+
+// openJobStorage - opens storage of job runs in database selected via STORAGE_DRIVER, returned function closes it.
+func openJobStorage(ctx context.Context) (storage.JobServerStorage, func(), error) {
+	cfg := config.NewConfig()
+
+	if cfg.StorageDriver == config.StorageDriverMemory {
+		return nil, nil, errors.New("data of memory storage is available to running server only")
+	}
+
+	if cfg.StorageDriver == config.StorageDriverSQLite {
+		db, err := sqlite.NewDB(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sqlite.NewJobSQLiteStorage(db), func() { db.Close() }, nil
+	}
+
+	conn, err := connect(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return postgres.NewJobPostgresStorage(conn), func() { conn.Close(ctx) }, nil
+}
+
+// openUserStorage - opens storage of users in database selected via STORAGE_DRIVER, returned function closes it.
+func openUserStorage(ctx context.Context) (storage.UserServerStorage, func(), error) {
+	cfg := config.NewConfig()
+
+	if cfg.StorageDriver == config.StorageDriverMemory {
+		return nil, nil, errors.New("data of memory storage is available to running server only")
+	}
+
+	if cfg.StorageDriver == config.StorageDriverSQLite {
+		db, err := sqlite.NewDB(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sqlite.NewUserSQLiteStorage(db), func() { db.Close() }, nil
+	}
+
+	pool, err := postgres.NewPool(ctx, cfg.DSN, postgres.PoolLimits{MaxConns: 1})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return postgres.NewPostgresUserStorage(pool), pool.Close, nil
+}
+
+// openAuditStorage - opens storage of audit events in database selected via STORAGE_DRIVER, returned function closes
+// it.
+func openAuditStorage(ctx context.Context) (storage.AuditServerStorage, func(), error) {
+	cfg := config.NewConfig()
+
+	if cfg.StorageDriver == config.StorageDriverMemory {
+		return nil, nil, errors.New("data of memory storage is available to running server only")
+	}
+
+	if cfg.StorageDriver == config.StorageDriverSQLite {
+		db, err := sqlite.NewDB(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sqlite.NewAuditSQLiteStorage(db), func() { db.Close() }, nil
+	}
+
+	pool, err := postgres.NewPool(ctx, cfg.DSN, postgres.PoolLimits{MaxConns: 1})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return postgres.NewAuditPostgresStorage(pool), pool.Close, nil
+}
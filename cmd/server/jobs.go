@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// runJobsCommand - prints history of maintenance job runs, latest go first.
+//
+// Usage: jobs [-name=purge-trash] [-limit=20]
+func runJobsCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobs", flag.ContinueOnError)
+	name := fs.String("name", "", "show runs of the job only")
+	limit := fs.Int("limit", 20, "number of runs to show")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	jobs, closeStorage, err := openJobStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	runs, err := jobs.ListJobRuns(ctx, *name, *limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tJOB\tINSTANCE\tSTATUS\tSTARTED\tDURATION\tMESSAGE")
+
+	for _, run := range runs {
+		duration := "-"
+		if run.FinishedAt != nil {
+			duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			run.ID, run.Name, run.Instance, run.Status, run.StartedAt.Format(time.RFC3339), duration, run.Message,
+		)
+	}
+
+	return w.Flush()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
 
@@ -14,6 +15,15 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 	defer cancel()
 
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, os.Args[1:]); err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	application, err := app.NewApp(ctx)
 	if err != nil {
 		log.Println(fmt.Errorf("error in creating app: %w", err))
@@ -22,10 +32,13 @@ func main() {
 	}
 
 	application.Server.Start(cancel)
+	application.Scheduler.Start()
 
 	<-ctx.Done()
 
+	<-application.Scheduler.Stop().Done()
 	application.Server.Stop()
+	application.Close()
 
 	application.Logger.Info("Application stopped")
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/sergalkin/gophkeeper/internal/server/config"
+	"github.com/sergalkin/gophkeeper/pkg/migrations"
+)
+
+// runMigrateCommand - manages schema of database selected via STORAGE_DRIVER.
+//
+// Usage: migrate up | down [-steps=N] | status | force <version>
+func runMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [-steps=N] | status | force <version>")
+	}
+
+	manager := migrations.NewMigrationManager(config.NewConfig())
+
+	switch args[0] {
+	case "up":
+		return manager.Up()
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back, 0 rolls back all of them")
+
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if *steps < 0 {
+			return errors.New("steps must not be negative")
+		}
+
+		return manager.Down(*steps)
+	case "status":
+		status, err := manager.Status()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", status.Version, status.Dirty, status.Latest)
+
+		return nil
+	case "force":
+		if len(args) != 2 {
+			return errors.New("usage: migrate force <version>")
+		}
+
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+
+		return manager.Force(version)
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
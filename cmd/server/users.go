@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+// runUsersCommand - manages users of the server.
+//
+// Usage: users list | disable <user> | enable <user> | delete <user> | role <user> <admin|user> |
+// reset-password <user> --confirm-vault-loss | stats, where user is a login or an id.
+func runUsersCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: users list | disable <user> | enable <user> | delete <user> | role <user> <role> | " +
+			"reset-password <user> --confirm-vault-loss | stats")
+	}
+
+	users, closeStorage, err := openUserStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	switch args[0] {
+	case "list":
+		return listUsers(ctx, users)
+	case "stats":
+		return printUsersStats(ctx, users)
+	case "disable", "enable", "delete":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: users %s <login or id>", args[0])
+		}
+
+		user, errFind := findUser(ctx, users, args[1])
+		if errFind != nil {
+			return errFind
+		}
+
+		return changeUser(ctx, users, args[0], user)
+	case "role":
+		if len(args) != 3 || (args[2] != model.RoleAdmin && args[2] != model.RoleUser) {
+			return fmt.Errorf("usage: users role <login or id> <%s|%s>", model.RoleAdmin, model.RoleUser)
+		}
+
+		user, errFind := findUser(ctx, users, args[1])
+		if errFind != nil {
+			return errFind
+		}
+
+		if _, err = users.SetUserRole(ctx, user, args[2]); err != nil {
+			return err
+		}
+
+		fmt.Printf("user %s (%s) has %s role now\n", user.Login, user.ID, args[2])
+
+		return nil
+	case "reset-password":
+		if len(args) != 3 || args[2] != confirmVaultLoss {
+			return fmt.Errorf("usage: users reset-password <login or id> %s, reset deletes secrets, folders and tags "+
+				"of the user, which can't be decrypted without the previous password", confirmVaultLoss)
+		}
+
+		user, errFind := findUser(ctx, users, args[1])
+		if errFind != nil {
+			return errFind
+		}
+
+		return resetPassword(ctx, users, user)
+	default:
+		return fmt.Errorf("unknown users command %q", args[0])
+	}
+}
+
+// listUsers - prints users with amount of their data.
+func listUsers(ctx context.Context, users storage.UserServerStorage) error {
+	list, err := users.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLOGIN\tROLE\tCREATED\tDISABLED\tSECRETS\tTRASHED\tSIZE")
+
+	for _, usage := range list {
+		disabled := "-"
+		if usage.User.DisabledAt != nil {
+			disabled = usage.User.DisabledAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\n",
+			usage.User.ID, usage.User.Login, usage.User.Role, usage.User.CreatedAt.Format(time.RFC3339), disabled,
+			usage.Secrets, usage.TrashedSecrets, usage.Size,
+		)
+	}
+
+	return w.Flush()
+}
+
+// printUsersStats - prints summary of users and their data.
+func printUsersStats(ctx context.Context, users storage.UserServerStorage) error {
+	stats, err := users.GetUsersStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "users:\t%d\n", stats.Users)
+	fmt.Fprintf(w, "disabled users:\t%d\n", stats.DisabledUsers)
+	fmt.Fprintf(w, "secrets:\t%d\n", stats.Secrets)
+	fmt.Fprintf(w, "secrets in trash:\t%d\n", stats.TrashedSecrets)
+	fmt.Fprintf(w, "secret versions:\t%d\n", stats.SecretVersions)
+	fmt.Fprintf(w, "size, bytes:\t%d\n", stats.Size)
+
+	return w.Flush()
+}
+
+// findUser - finds a user by id, or by login if provided value is not an id.
+func findUser(ctx context.Context, users storage.UserServerStorage, loginOrID string) (model.User, error) {
+	user := model.User{Login: loginOrID}
+	if id, err := uuid.Parse(loginOrID); err == nil {
+		user = model.User{ID: &id}
+	}
+
+	found, err := users.GetUser(ctx, user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return found, fmt.Errorf("user %q is not found", loginOrID)
+	}
+
+	return found, err
+}
+
+// confirmVaultLoss - is a flag, which confirms that password reset deletes the vault of a user.
+const confirmVaultLoss = "--confirm-vault-loss"
+
+// resetPassword - replaces password of a user with generated temporary one and prints it. Vault of the user is
+// deleted, see storage.UserServerStorage.
+func resetPassword(ctx context.Context, users storage.UserServerStorage, user model.User) error {
+	password, err := model.TemporaryPassword()
+	if err != nil {
+		return err
+	}
+
+	if _, err = users.ResetPassword(ctx, user, password); err != nil {
+		return err
+	}
+
+	fmt.Printf("password of user %s (%s) is reset, its vault is deleted, temporary password: %s\n",
+		user.Login, user.ID, password)
+
+	return nil
+}
+
+// changeUser - disables, enables or deletes a user.
+func changeUser(ctx context.Context, users storage.UserServerStorage, action string, user model.User) error {
+	var err error
+
+	switch action {
+	case "disable":
+		_, err = users.SetUserDisabled(ctx, user, true)
+	case "enable":
+		_, err = users.SetUserDisabled(ctx, user, false)
+	case "delete":
+		_, err = users.DeleteUser(ctx, user)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("user %s (%s) is %sd\n", user.Login, user.ID, action)
+
+	return nil
+}
@@ -17,12 +17,17 @@ import (
 	"github.com/sergalkin/gophkeeper/pkg/crypt"
 )
 
+// userAgent - is a user agent of the client, which is recorded by server in audit log.
+const userAgent = "gophkeeper-client"
+
 type App struct {
 	Cancel context.CancelFunc
 
 	SecretService     *service.SecretClientService
 	SecretTypeService *service.SecretTypeClientService
 	UserService       *service.UserClientService
+	AuditService      *service.AuditClientService
+	FolderService     *service.FolderClientService
 
 	Storage storage.Memorier
 	Syncer  storage.Syncer
@@ -48,13 +53,49 @@ func NewApp() (*App, error) {
 		"/proto.Secret/CreateSecret":           true,
 		"/proto.Secret/GetSecret":              true,
 		"/proto.Secret/DeleteSecret":           true,
-		"/proto.Secret/Edit":                   true,
+		"/proto.Secret/EditSecret":             true,
+		"/proto.Secret/ListSecretVersions":     true,
+		"/proto.Secret/GetSecretVersion":       true,
+		"/proto.Secret/RestoreSecretVersion":   true,
+		"/proto.Secret/ListTrash":              true,
+		"/proto.Secret/RestoreSecret":          true,
+		"/proto.Secret/PurgeSecret":            true,
+		"/proto.Secret/ListSecrets":            true,
+		"/proto.Secret/MoveSecret":             true,
+		"/proto.Secret/SetSecretTags":          true,
+		"/proto.Secret/BatchCreateSecrets":     true,
+		"/proto.Secret/BatchGetSecrets":        true,
+		"/proto.Secret/BatchDeleteSecrets":     true,
+		"/proto.Folder/CreateFolder":           true,
+		"/proto.Folder/ListFolders":            true,
+		"/proto.Folder/CreateTag":              true,
+		"/proto.Folder/ListTags":               true,
+		"/proto.Audit/ListAuditEvents":         true,
 	}
 	intercept := interceptor.NewAuthInterceptor(protectedRoutes)
 
+	idempotentRoutes := map[string]bool{
+		"/proto.User/Delete":                 true,
+		"/proto.User/ChangePassword":         true,
+		"/proto.Secret/CreateSecret":         true,
+		"/proto.Secret/DeleteSecret":         true,
+		"/proto.Secret/EditSecret":           true,
+		"/proto.Secret/RestoreSecretVersion": true,
+		"/proto.Secret/RestoreSecret":        true,
+		"/proto.Secret/PurgeSecret":          true,
+		"/proto.Secret/MoveSecret":           true,
+		"/proto.Secret/SetSecretTags":        true,
+		"/proto.Secret/BatchCreateSecrets":   true,
+		"/proto.Secret/BatchDeleteSecrets":   true,
+		"/proto.Folder/CreateFolder":         true,
+		"/proto.Folder/CreateTag":            true,
+	}
+	idempotencyIntercept := interceptor.NewIdempotencyInterceptor(idempotentRoutes, cfg.RetryAttempts, cfg.RetryBackoff)
+
 	conn, errConn := grpc.Dial(":"+cfg.Port,
 		grpc.WithTransportCredentials(tlsCredential),
-		grpc.WithUnaryInterceptor(intercept.Unary()),
+		grpc.WithUserAgent(userAgent),
+		grpc.WithChainUnaryInterceptor(intercept.Unary(), idempotencyIntercept.Unary()),
 	)
 	if errConn != nil {
 		return nil, fmt.Errorf("error in creating grpc con:%w", errConn)
@@ -63,18 +104,20 @@ func NewApp() (*App, error) {
 	secretClient := pb.NewSecretClient(conn)
 	userClient := pb.NewUserClient(conn)
 	secretTypeClient := pb.NewSecretTypeClient(conn)
+	auditClient := pb.NewAuditClient(conn)
+	folderClient := pb.NewFolderClient(conn)
 
-	cr, errCr := crypt.NewCrypt()
-	if errCr != nil {
-		return nil, fmt.Errorf("could create crypt")
-	}
+	// keys of the crypt are derived from password of a user on login
+	cr := crypt.NewUserCrypt()
 
 	memoryStorage := storage.NewMemoryStorage()
-	syn := storage.NewSync(memoryStorage, secretClient, &glCtx, cr)
+	syn := storage.NewSync(memoryStorage, secretClient, secretTypeClient, folderClient, &glCtx, cr)
 
 	secretClientService := service.NewSecretClientService(&glCtx, secretClient, memoryStorage, cr, syn)
-	userClientService := service.NewUserClientService(&glCtx, userClient)
+	userClientService := service.NewUserClientService(&glCtx, userClient, cr)
 	secretTypeClientService := service.NewSecretTypeClientService(&glCtx, secretTypeClient)
+	auditClientService := service.NewAuditClientService(&glCtx, auditClient)
+	folderClientService := service.NewFolderClientService(&glCtx, folderClient, memoryStorage, cr, syn)
 
 	c := cron.New()
 	c.AddFunc("* * * * *", syn.SyncAll)
@@ -83,6 +126,8 @@ func NewApp() (*App, error) {
 		SecretService:     secretClientService,
 		SecretTypeService: secretTypeClientService,
 		UserService:       userClientService,
+		AuditService:      auditClientService,
+		FolderService:     folderClientService,
 		Storage:           memoryStorage,
 		Syncer:            syn,
 		Cron:              c,
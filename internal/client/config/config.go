@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v6"
 
 	"github.com/sergalkin/gophkeeper/pkg/logger"
@@ -15,6 +17,9 @@ type Config struct {
 
 	JWTSecret string `env:"JWT_SECRET" envDefault:"supa_secret_key"`
 	JWTExp    string `env:"JWT_EXP" envDefault:"14"`
+
+	RetryAttempts int           `env:"RETRY_ATTEMPTS" envDefault:"3"`
+	RetryBackoff  time.Duration `env:"RETRY_BACKOFF" envDefault:"500ms"`
 }
 
 var cfg Config
@@ -0,0 +1,78 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// idempotencyHeader - name of metadata header that carries idempotency key of a request.
+const idempotencyHeader = "idempotency-key"
+
+// IdempotencyInterceptor is a client interceptor that attaches idempotency keys to mutating requests and retries them
+type IdempotencyInterceptor struct {
+	idempotentMethods map[string]bool
+	attempts          int
+	backoff           time.Duration
+}
+
+// NewIdempotencyInterceptor - returns an Idempotency interceptor
+func NewIdempotencyInterceptor(methods map[string]bool, attempts int, backoff time.Duration) *IdempotencyInterceptor {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return &IdempotencyInterceptor{idempotentMethods: methods, attempts: attempts, backoff: backoff}
+}
+
+// Unary returns a client interceptor that generates idempotency key for unary RPC and retries it with the same key
+// on transient errors
+func (i *IdempotencyInterceptor) Unary() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if !i.idempotentMethods[method] {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		if md, ok := metadata.FromOutgoingContext(ctx); !ok || len(md.Get(idempotencyHeader)) == 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, idempotencyHeader, uuid.NewString())
+		}
+
+		var err error
+		for attempt := 1; attempt <= i.attempts; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !isRetryable(err) || attempt == i.attempts {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(i.backoff * time.Duration(attempt)):
+			}
+		}
+
+		return err
+	}
+}
+
+// isRetryable - reports whether request could be safely retried with the same idempotency key.
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
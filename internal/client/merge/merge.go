@@ -0,0 +1,260 @@
+// Package merge is a package that provides three-way merge of secrets, which were changed concurrently.
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+)
+
+// ErrAborted - is returned when user aborted resolving of conflicts.
+var ErrAborted = errors.New("merge aborted")
+
+// customFieldPrefix - prefixes names of custom fields, so they don't clash with fields of secret type.
+const customFieldPrefix = "custom:"
+
+// urisField - is a name of a field, which holds URIs of a login secret as they are formatted by model.FormatURIs.
+const urisField = "URIs"
+
+// Field - represents a single field of a secret in base, local and server versions.
+type Field struct {
+	Name     string
+	Base     string
+	Local    string
+	Server   string
+	Merged   string
+	Conflict bool
+}
+
+// Resolver - resolves a conflicting Field by returning value, which has to be stored.
+type Resolver interface {
+	Resolve(f Field) (string, error)
+}
+
+// Diff - returns field-by-field comparison of base, local and server versions of a secret of type t.
+//
+// Field changed only on one side is merged automatically, field changed on both sides differently is marked as
+// conflicting and has empty Merged value. If base version is unknown, then nil could be passed, in that case every
+// different field is conflicting.
+func Diff(t model.SecretType, base *model.Secret, local, server model.Secret) ([]Field, error) {
+	if t.IsBinary() {
+		return nil, fmt.Errorf("merge is not supported for %s secrets", t.Title)
+	}
+
+	baseValues := map[string]string{}
+	secrets := []model.Secret{local, server}
+	if base != nil {
+		baseValues = Values(t, *base)
+		secrets = append(secrets, *base)
+	}
+
+	localValues := Values(t, local)
+	serverValues := Values(t, server)
+
+	names := fieldNames(t, secrets...)
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		f := Field{
+			Name:   name,
+			Base:   baseValues[name],
+			Local:  localValues[name],
+			Server: serverValues[name],
+		}
+
+		switch {
+		case f.Local == f.Server:
+			f.Merged = f.Local
+		case f.Local == f.Base:
+			f.Merged = f.Server
+		case f.Server == f.Base:
+			f.Merged = f.Local
+		default:
+			f.Conflict = true
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// ThreeWay - merges local and server versions of a secret of type t using base version as a common ancestor.
+//
+// Conflicting fields are resolved via provided Resolver. Returned secret keeps identity of local one.
+func ThreeWay(t model.SecretType, base *model.Secret, local, server model.Secret, r Resolver) (model.Secret, error) {
+	fields, err := Diff(t, base, local, server)
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	secrets := []model.Secret{local, server}
+	if base != nil {
+		secrets = append(secrets, *base)
+	}
+
+	names := make([]string, 0, len(fields))
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		if f.Conflict {
+			if f.Merged, err = r.Resolve(f); err != nil {
+				return model.Secret{}, err
+			}
+		}
+
+		names = append(names, f.Name)
+		values[f.Name] = f.Merged
+	}
+
+	return withValues(t, names, values, secrets...), nil
+}
+
+// Format - returns printable table of fields with marked conflicts.
+func Format(fields []Field) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%-12s| %-20s| %-20s| %-20s|\n", "field", "base", "local", "server")
+	for _, f := range fields {
+		mark := ""
+		if f.Conflict {
+			mark = " CONFLICT"
+		}
+
+		fmt.Fprintf(&b, "%-12s| %-20s| %-20s| %-20s|%s\n", f.Name, f.Base, f.Local, f.Server, mark)
+	}
+
+	return b.String()
+}
+
+// Compare - returns field-by-field comparison of two versions of a secret, which could be of different types.
+//
+// Older version is placed into Base and newer into Server, field is marked as Conflict if its values differ.
+func Compare(olderType model.SecretType, older model.Secret, newerType model.SecretType, newer model.Secret) (
+	[]Field, error,
+) {
+	if olderType.IsBinary() || newerType.IsBinary() {
+		return nil, errors.New("compare is not supported for binary secrets")
+	}
+
+	olderValues := Values(olderType, older)
+	newerValues := Values(newerType, newer)
+
+	names := fieldNames(newerType, newer, older)
+	if olderType.Id != newerType.Id {
+		for _, name := range fieldNames(olderType, older) {
+			if _, ok := newerValues[name]; !ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		f := Field{Name: name, Base: olderValues[name], Server: newerValues[name]}
+		f.Conflict = f.Base != f.Server
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// FormatChanges - returns printable table of fields of two versions with marked changes.
+func FormatChanges(fields []Field, from, to string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%-12s| %-20s| %-20s|\n", "field", from, to)
+	for _, f := range fields {
+		mark := ""
+		if f.Conflict {
+			mark = " CHANGED"
+		}
+
+		fmt.Fprintf(&b, "%-12s| %-20s| %-20s|%s\n", f.Name, f.Base, f.Server, mark)
+	}
+
+	return b.String()
+}
+
+// fieldNames - returns ordered list of editable field names of secrets of type t, Title goes first, URIs, if any of
+// provided secrets has them, and custom fields of provided secrets go last.
+func fieldNames(t model.SecretType, secrets ...model.Secret) []string {
+	names := make([]string, 0, len(t.Fields)+2)
+	names = append(names, "Title")
+	for _, f := range t.Fields {
+		names = append(names, f.Name)
+	}
+
+	for _, secret := range secrets {
+		if len(secret.URIs) > 0 {
+			names = append(names, urisField)
+			break
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, secret := range secrets {
+		for _, f := range secret.CustomFields {
+			if name := customFieldPrefix + f.Name; !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Values - returns editable field values of a secret of type t by their names, including URIs and custom fields.
+func Values(t model.SecretType, secret model.Secret) map[string]string {
+	values := make(map[string]string, len(t.Fields)+len(secret.CustomFields)+2)
+	values["Title"] = secret.Title
+	for _, f := range t.Fields {
+		values[f.Name] = secret.Fields[f.Name]
+	}
+	if len(secret.URIs) > 0 {
+		values[urisField] = model.FormatURIs(secret.URIs)
+	}
+	for _, f := range secret.CustomFields {
+		values[customFieldPrefix+f.Name] = f.Value
+	}
+
+	return values
+}
+
+// withValues - returns copy of the first of secrets of type t with editable fields set from values.
+//
+// Custom fields are ordered by names and have kinds they have in the first of secrets, which has them, custom fields
+// with empty values are removed.
+func withValues(t model.SecretType, names []string, values map[string]string, secrets ...model.Secret) model.Secret {
+	secret := secrets[0]
+	secret.Title = values["Title"]
+	secret.Fields = make(map[string]string, len(t.Fields))
+	for _, f := range t.Fields {
+		secret.Fields[f.Name] = values[f.Name]
+	}
+	secret.URIs = model.ParseURIs(values[urisField])
+
+	kinds := map[string]string{}
+	for i := len(secrets) - 1; i >= 0; i-- {
+		for _, f := range secrets[i].CustomFields {
+			kinds[f.Name] = f.Kind
+		}
+	}
+
+	secret.CustomFields = nil
+	for _, name := range names {
+		if !strings.HasPrefix(name, customFieldPrefix) || values[name] == "" {
+			continue
+		}
+
+		name = strings.TrimPrefix(name, customFieldPrefix)
+		secret.CustomFields = append(secret.CustomFields, model.CustomField{
+			Name: name, Kind: kinds[name], Value: values[customFieldPrefix+name],
+		})
+	}
+
+	return secret
+}
@@ -0,0 +1,225 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+)
+
+var (
+	loginPassType = model.SecretType{Id: 1, Title: "login/pass", Fields: []model.SecretTypeField{
+		{Name: "Login", Label: "Login", Kind: model.SecretFieldKindText},
+		{Name: "Password", Label: "Password", Kind: model.SecretFieldKindText, Sensitive: true},
+	}}
+	textType = model.SecretType{Id: 2, Title: "text", Fields: []model.SecretTypeField{
+		{Name: "Text", Label: "Text", Kind: model.SecretFieldKindMultiline},
+	}}
+	binaryType = model.SecretType{Id: 3, Title: "binary", Fields: []model.SecretTypeField{
+		{Name: "File", Label: "File", Kind: model.SecretFieldKindFile},
+	}}
+)
+
+type staticResolver struct {
+	value string
+	err   error
+	calls int
+}
+
+func (r *staticResolver) Resolve(f Field) (string, error) {
+	r.calls++
+
+	return r.value, r.err
+}
+
+func loginPass(login, password string) model.Secret {
+	return model.Secret{Title: "mail", RecordType: 1, Fields: map[string]string{"Login": login, "Password": password}}
+}
+
+func text(value string) model.Secret {
+	return model.Secret{Title: "note", RecordType: 2, Fields: map[string]string{"Text": value}}
+}
+
+func withCustomFields(secret model.Secret, fields ...model.CustomField) model.Secret {
+	secret.CustomFields = fields
+
+	return secret
+}
+
+func withURIs(secret model.Secret, uris ...model.LoginURI) model.Secret {
+	secret.URIs = uris
+
+	return secret
+}
+
+func TestThreeWay(t *testing.T) {
+	base := loginPass("alice", "old")
+	textBase := text("base")
+
+	tests := []struct {
+		name       string
+		secretType model.SecretType
+		base       *model.Secret
+		local      model.Secret
+		server     model.Secret
+		resolver   *staticResolver
+		want       model.Secret
+		wantCalls  int
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "Changes of different fields are merged automatically",
+			secretType: loginPassType,
+			base:       &base,
+			local:      loginPass("alice", "new"),
+			server:     loginPass("bob", "old"),
+			resolver:   &staticResolver{},
+			want:       loginPass("bob", "new"),
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Conflicting field is resolved by resolver",
+			secretType: loginPassType,
+			base:       &base,
+			local:      loginPass("alice", "local"),
+			server:     loginPass("alice", "server"),
+			resolver:   &staticResolver{value: "server"},
+			want:       loginPass("alice", "server"),
+			wantCalls:  1,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Same change on both sides is not a conflict",
+			secretType: loginPassType,
+			base:       &base,
+			local:      loginPass("alice", "same"),
+			server:     loginPass("alice", "same"),
+			resolver:   &staticResolver{},
+			want:       loginPass("alice", "same"),
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Every different field is a conflict without base",
+			secretType: textType,
+			base:       nil,
+			local:      text("local"),
+			server:     text("server"),
+			resolver:   &staticResolver{value: "merged"},
+			want:       text("merged"),
+			wantCalls:  1,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Aborted resolving returns error",
+			secretType: textType,
+			base:       &textBase,
+			local:      text("local"),
+			server:     text("server"),
+			resolver:   &staticResolver{err: ErrAborted},
+			want:       model.Secret{},
+			wantCalls:  1,
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "Custom fields are merged in order",
+			secretType: loginPassType,
+			base:       &base,
+			local: withCustomFields(loginPass("alice", "old"),
+				model.CustomField{Name: "PIN", Kind: model.CustomFieldKindHidden, Value: "1234"}),
+			server: withCustomFields(loginPass("bob", "old"),
+				model.CustomField{Name: "Site", Kind: model.CustomFieldKindURL, Value: "https://mail.example"}),
+			resolver: &staticResolver{},
+			want: withCustomFields(loginPass("bob", "old"),
+				model.CustomField{Name: "PIN", Kind: model.CustomFieldKindHidden, Value: "1234"},
+				model.CustomField{Name: "Site", Kind: model.CustomFieldKindURL, Value: "https://mail.example"}),
+			wantErr: assert.NoError,
+		},
+		{
+			name:       "URIs changed on one side are merged",
+			secretType: loginPassType,
+			base:       &base,
+			local:      loginPass("alice", "new"),
+			server: withURIs(loginPass("alice", "old"),
+				model.LoginURI{URI: "mail.example", Match: model.URIMatchDomain},
+				model.LoginURI{URI: "https://mail.example/?login=1", Match: model.URIMatchPrefix}),
+			resolver: &staticResolver{},
+			want: withURIs(loginPass("alice", "new"),
+				model.LoginURI{URI: "mail.example", Match: model.URIMatchDomain},
+				model.LoginURI{URI: "https://mail.example/?login=1", Match: model.URIMatchPrefix}),
+			wantErr: assert.NoError,
+		},
+		{
+			name:       "Binary secrets are not supported",
+			secretType: binaryType,
+			local:      model.Secret{},
+			server:     model.Secret{},
+			resolver:   &staticResolver{},
+			want:       model.Secret{},
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ThreeWay(tt.secretType, tt.base, tt.local, tt.server, tt.resolver)
+
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantCalls, tt.resolver.calls)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		olderType model.SecretType
+		older     model.Secret
+		newerType model.SecretType
+		newer     model.Secret
+		want      []Field
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "Changed fields are marked",
+			olderType: textType,
+			older:     text("old"),
+			newerType: textType,
+			newer:     text("new"),
+			want: []Field{
+				{Name: "Title", Base: "note", Server: "note"},
+				{Name: "Text", Base: "old", Server: "new", Conflict: true},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "Fields of both types are compared when type was changed",
+			olderType: textType,
+			older:     text("old"),
+			newerType: loginPassType,
+			newer:     model.Secret{Title: "note", RecordType: 1, Fields: map[string]string{"Login": "alice"}},
+			want: []Field{
+				{Name: "Title", Base: "note", Server: "note"},
+				{Name: "Login", Server: "alice", Conflict: true},
+				{Name: "Password"},
+				{Name: "Text", Base: "old", Conflict: true},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:      "Binary secrets are not supported",
+			olderType: binaryType,
+			newerType: binaryType,
+			want:      nil,
+			wantErr:   assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compare(tt.olderType, tt.older, tt.newerType, tt.newer)
+
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
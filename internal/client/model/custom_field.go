@@ -0,0 +1,131 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Kinds of custom fields of secrets.
+const (
+	CustomFieldKindText   = "text"
+	CustomFieldKindHidden = "hidden"
+	CustomFieldKindURL    = "url"
+	CustomFieldKindDate   = "date"
+	CustomFieldKindTOTP   = "totp"
+)
+
+// CustomFieldDateLayout - is a layout of values of date custom fields.
+const CustomFieldDateLayout = "2006-01-02"
+
+// totpPeriod - is a period of time, during which TOTP code is valid.
+const totpPeriod = 30 * time.Second
+
+// CustomField - is a field added by user to a secret of any type, it is stored encrypted in content of the secret.
+type CustomField struct {
+	Name  string
+	Kind  string
+	Value string
+}
+
+// IsSensitive - reports whether value of the field has to be hidden on display.
+func (f CustomField) IsSensitive() bool {
+	return f.Kind == CustomFieldKindHidden || f.Kind == CustomFieldKindTOTP
+}
+
+// Validate - checks that field has a name and its value fits its kind.
+func (f CustomField) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("validation error: name of custom field is missing")
+	}
+
+	switch f.Kind {
+	case CustomFieldKindText, CustomFieldKindHidden:
+		return nil
+	case CustomFieldKindURL:
+		u, err := url.Parse(f.Value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("validation error: %s is not a valid URL", f.Name)
+		}
+	case CustomFieldKindDate:
+		if _, err := time.Parse(CustomFieldDateLayout, f.Value); err != nil {
+			return fmt.Errorf("validation error: %s is not a date in %s format", f.Name, CustomFieldDateLayout)
+		}
+	case CustomFieldKindTOTP:
+		if _, err := f.TOTPCode(time.Now()); err != nil {
+			return fmt.Errorf("validation error: %s is not a valid TOTP secret: %w", f.Name, err)
+		}
+	default:
+		return fmt.Errorf("validation error: unknown kind %q of custom field %s", f.Kind, f.Name)
+	}
+
+	return nil
+}
+
+// TOTPCode - returns 6 digits RFC 6238 code of TOTP field for provided time.
+//
+// Value of the field is either base32 encoded secret or otpauth:// URI with secret parameter.
+func (f CustomField) TOTPCode(at time.Time) (string, error) {
+	secret := f.Value
+	if strings.HasPrefix(secret, "otpauth://") {
+		u, err := url.Parse(secret)
+		if err != nil {
+			return "", err
+		}
+
+		secret = u.Query().Get("secret")
+	}
+
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return "", err
+	}
+	if len(key) == 0 {
+		return "", fmt.Errorf("secret is empty")
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(at.Unix()/int64(totpPeriod/time.Second)))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", code%1000000), nil
+}
+
+// WithCustomFields - returns fields with changes applied: field is replaced by a change with the same name or
+// appended if there is no such field, change with empty value removes a field. Order of fields is kept.
+func WithCustomFields(fields, changes []CustomField) []CustomField {
+	result := append([]CustomField(nil), fields...)
+
+	for _, change := range changes {
+		i := 0
+		for ; i < len(result); i++ {
+			if result[i].Name == change.Name {
+				break
+			}
+		}
+
+		switch {
+		case change.Value == "" && i < len(result):
+			result = append(result[:i], result[i+1:]...)
+		case change.Value == "":
+		case i < len(result):
+			result[i] = change
+		default:
+			result = append(result, change)
+		}
+	}
+
+	return result
+}
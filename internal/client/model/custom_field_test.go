@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomField_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   CustomField
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "Text field is valid", field: CustomField{Name: "Q", Kind: CustomFieldKindText}, wantErr: assert.NoError},
+		{
+			name:    "URL field has to be absolute URL",
+			field:   CustomField{Name: "Site", Kind: CustomFieldKindURL, Value: "example.com"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "Date field has to be a date",
+			field:   CustomField{Name: "Since", Kind: CustomFieldKindDate, Value: "2024-02-30"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "TOTP field has to be base32 secret",
+			field:   CustomField{Name: "2FA", Kind: CustomFieldKindTOTP, Value: "not base32!"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "Unknown kind is refused",
+			field:   CustomField{Name: "X", Kind: "color", Value: "red"},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.wantErr(t, tt.field.Validate())
+		})
+	}
+}
+
+func TestCustomField_TOTPCode(t *testing.T) {
+	// test vector of RFC 6238 for SHA1, truncated to 6 digits
+	for _, value := range []string{
+		"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"otpauth://totp/example?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+	} {
+		code, err := CustomField{Kind: CustomFieldKindTOTP, Value: value}.TOTPCode(time.Unix(59, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, "287082", code)
+	}
+}
+
+func TestWithCustomFields(t *testing.T) {
+	fields := []CustomField{
+		{Name: "PIN", Kind: CustomFieldKindHidden, Value: "1234"},
+		{Name: "Question", Kind: CustomFieldKindText, Value: "pet"},
+	}
+
+	got := WithCustomFields(fields, []CustomField{
+		{Name: "PIN", Kind: CustomFieldKindHidden},
+		{Name: "Question", Kind: CustomFieldKindText, Value: "city"},
+		{Name: "Since", Kind: CustomFieldKindDate, Value: "2020-01-01"},
+	})
+
+	assert.Equal(t, []CustomField{
+		{Name: "Question", Kind: CustomFieldKindText, Value: "city"},
+		{Name: "Since", Kind: CustomFieldKindDate, Value: "2020-01-01"},
+	}, got)
+	assert.Len(t, fields, 2, "original fields are not changed")
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// FolderPathSeparator - separates names of folders in a path of a folder, path "/" is a root.
+const FolderPathSeparator = "/"
+
+// Folder - is a decrypted folder of secrets, ParentId is 0 for folders in root.
+type Folder struct {
+	Id       int
+	ParentId int
+	Name     string
+}
+
+// Tag - is a decrypted tag of secrets.
+type Tag struct {
+	Id   int
+	Name string
+}
+
+// Folders - is a tree of folders of a user kept as a flat list.
+type Folders []Folder
+
+// SplitFolderPath - splits path of a folder into names of folders from root, leading and trailing separators are
+// ignored, so root is represented by an empty list.
+func SplitFolderPath(path string) ([]string, error) {
+	path = strings.Trim(strings.TrimSpace(path), FolderPathSeparator)
+	if path == "" {
+		return nil, nil
+	}
+
+	names := strings.Split(path, FolderPathSeparator)
+	for _, name := range names {
+		if name == "" {
+			return nil, errors.New("validation error: folder path can't contain empty names")
+		}
+	}
+
+	return names, nil
+}
+
+// Child - returns a folder with provided name in a folder with parentId.
+func (f Folders) Child(parentId int, name string) (Folder, bool) {
+	for _, folder := range f {
+		if folder.ParentId == parentId && folder.Name == name {
+			return folder, true
+		}
+	}
+
+	return Folder{}, false
+}
+
+// Find - returns a folder by its path, root is returned as a Folder with zero Id.
+func (f Folders) Find(path string) (Folder, bool) {
+	names, err := SplitFolderPath(path)
+	if err != nil {
+		return Folder{}, false
+	}
+
+	var found Folder
+	for _, name := range names {
+		child, ok := f.Child(found.Id, name)
+		if !ok {
+			return Folder{}, false
+		}
+
+		found = child
+	}
+
+	return found, true
+}
+
+// Path - returns path of a folder by its id, path of root and of unknown folders is "/".
+func (f Folders) Path(id int) string {
+	byId := make(map[int]Folder, len(f))
+	for _, folder := range f {
+		byId[folder.Id] = folder
+	}
+
+	var names []string
+	for folder, ok := byId[id]; ok && len(names) <= len(f); folder, ok = byId[folder.ParentId] {
+		names = append([]string{folder.Name}, names...)
+	}
+
+	return FolderPathSeparator + strings.Join(names, FolderPathSeparator)
+}
+
+// Paths - returns sorted paths of all folders.
+func (f Folders) Paths() []string {
+	paths := make([]string, 0, len(f))
+	for _, folder := range f {
+		paths = append(paths, f.Path(folder.Id))
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// FindTag - returns a tag by its name.
+func FindTag(tags []Tag, name string) (Tag, bool) {
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+
+	return Tag{}, false
+}
+
+// NewTagIds - casts ids of tags of a secret from pb.
+func NewTagIds(ids []uint32) []int {
+	var tagIds []int
+	for _, id := range ids {
+		tagIds = append(tagIds, int(id))
+	}
+
+	return tagIds
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFolderPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Root", path: "/"},
+		{name: "Empty path is a root", path: ""},
+		{name: "Separators around path are ignored", path: "/work/db/", want: []string{"work", "db"}},
+		{name: "Path without leading separator", path: "work", want: []string{"work"}},
+		{name: "Empty names are not allowed", path: "work//db", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitFolderPath(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFolders(t *testing.T) {
+	folders := Folders{
+		{Id: 1, Name: "work"},
+		{Id: 2, ParentId: 1, Name: "db"},
+		{Id: 3, Name: "home"},
+		{Id: 4, ParentId: 3, Name: "db"},
+	}
+
+	assert.Equal(t, "/work/db", folders.Path(2))
+	assert.Equal(t, "/", folders.Path(0))
+	assert.Equal(t, []string{"/home", "/home/db", "/work", "/work/db"}, folders.Paths())
+
+	found, ok := folders.Find("home/db")
+	assert.True(t, ok)
+	assert.Equal(t, 4, found.Id)
+
+	root, ok := folders.Find("/")
+	assert.True(t, ok)
+	assert.Zero(t, root.Id)
+
+	_, ok = folders.Find("work/unknown")
+	assert.False(t, ok)
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ImportEntry - is a secret in a file of "import" command, Type is an ID or a title of a secret type.
+type ImportEntry struct {
+	Type         string            `json:"type"`
+	Title        string            `json:"title"`
+	Fields       map[string]string `json:"fields"`
+	CustomFields []CustomField     `json:"custom_fields"`
+	URIs         []LoginURI        `json:"uris"`
+}
+
+// SecretDraft - is a new secret prepared to be sent to the server, Content is not encrypted yet.
+type SecretDraft struct {
+	Title      string
+	RecordType int
+	Content    string
+}
+
+// ParseImport - decodes entries of a file of "import" command, which is a JSON array of ImportEntry.
+func ParseImport(r io.Reader) ([]ImportEntry, error) {
+	var entries []ImportEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid import file: %w", err)
+	}
+
+	for i := range entries {
+		for j, f := range entries[i].CustomFields {
+			if f.Kind == "" {
+				entries[i].CustomFields[j].Kind = CustomFieldKindText
+			}
+		}
+
+		for j, u := range entries[i].URIs {
+			if u.Match == "" {
+				entries[i].URIs[j].Match = URIMatchDomain
+			}
+		}
+	}
+
+	return entries, nil
+}
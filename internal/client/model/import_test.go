@@ -0,0 +1,35 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImport(t *testing.T) {
+	entries, err := ParseImport(strings.NewReader(`[
+		{"type": "login/pass", "title": "github", "fields": {"login": "alice", "password": "secret"},
+		 "custom_fields": [{"name": "pin", "value": "1234"}, {"name": "otp", "kind": "totp", "value": "JBSWY3DP"}],
+		 "uris": [{"uri": "github.com"}, {"uri": "https://gist.github.com", "match": "host"}]},
+		{"type": "2", "title": "note", "fields": {"text": "hello"}}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "github", entries[0].Title)
+	assert.Equal(t, "alice", entries[0].Fields["login"])
+	assert.Equal(t, []CustomField{
+		{Name: "pin", Kind: CustomFieldKindText, Value: "1234"},
+		{Name: "otp", Kind: CustomFieldKindTOTP, Value: "JBSWY3DP"},
+	}, entries[0].CustomFields, "kind of custom field is text by default")
+	assert.Equal(t, []LoginURI{
+		{URI: "github.com", Match: URIMatchDomain},
+		{URI: "https://gist.github.com", Match: URIMatchHost},
+	}, entries[0].URIs, "URI is matched by domain by default")
+	assert.Equal(t, "2", entries[1].Type)
+
+	_, err = ParseImport(strings.NewReader(`{"type": "text"}`))
+	assert.Error(t, err)
+}
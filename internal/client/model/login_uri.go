@@ -0,0 +1,219 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Strategies of matching URIs of login secrets with URLs.
+const (
+	URIMatchDomain = "domain"
+	URIMatchHost   = "host"
+	URIMatchRegex  = "regex"
+	URIMatchPrefix = "prefix"
+)
+
+// uriMatchRanks - are specificity of strategies, a match by more specific strategy is ranked higher.
+var uriMatchRanks = map[string]int{URIMatchDomain: 1, URIMatchRegex: 2, URIMatchHost: 3, URIMatchPrefix: 4}
+
+// LoginURI - is a URI of a site, which credentials are kept by a login secret, with a strategy of matching it with
+// URLs. It is stored encrypted in content of the secret.
+type LoginURI struct {
+	URI   string
+	Match string
+}
+
+// Validate - checks that match strategy is known and URI fits it: domain and host URIs have to have a host, regex
+// has to compile.
+func (u LoginURI) Validate() error {
+	switch u.Match {
+	case URIMatchDomain, URIMatchHost:
+		if _, err := parseURI(u.URI); err != nil {
+			return fmt.Errorf("validation error: %s is not a valid URI", u.URI)
+		}
+	case URIMatchPrefix:
+		if u.URI == "" {
+			return fmt.Errorf("validation error: prefix URI is empty")
+		}
+	case URIMatchRegex:
+		if _, err := regexp.Compile(u.URI); err != nil {
+			return fmt.Errorf("validation error: %s is not a valid regex: %w", u.URI, err)
+		}
+	default:
+		return fmt.Errorf("validation error: unknown match %q of URI %s", u.Match, u.URI)
+	}
+
+	return nil
+}
+
+// Matches - reports whether URL matches the URI:
+//   - domain: URL has the same base domain, which is a registrable domain by the public suffix list, e.g.
+//     "login.github.com" matches "github.com", but "alice.github.io" doesn't match "bob.github.io";
+//   - host: URL has the same host, and the same port if URI has it;
+//   - regex: URL matches regex;
+//   - prefix: URL starts with URI.
+//
+// URL and URIs without scheme are taken as https ones.
+func (u LoginURI) Matches(rawURL string) bool {
+	target, err := parseURI(rawURL)
+	if err != nil {
+		return false
+	}
+
+	switch u.Match {
+	case URIMatchDomain, URIMatchHost:
+		uri, errURI := parseURI(u.URI)
+		if errURI != nil {
+			return false
+		}
+
+		if u.Match == URIMatchDomain {
+			return baseDomain(uri.Hostname()) == baseDomain(target.Hostname())
+		}
+
+		sameHost := strings.EqualFold(uri.Hostname(), target.Hostname())
+
+		return sameHost && (uri.Port() == "" || uri.Port() == target.Port())
+	case URIMatchRegex:
+		re, errRe := regexp.Compile(u.URI)
+
+		return errRe == nil && re.MatchString(withScheme(rawURL))
+	case URIMatchPrefix:
+		return strings.HasPrefix(withScheme(rawURL), withScheme(u.URI))
+	}
+
+	return false
+}
+
+// String - returns URI with its match strategy as "match=uri".
+func (u LoginURI) String() string {
+	return u.Match + "=" + u.URI
+}
+
+// FormatURIs - returns URIs one per line, see ParseURIs.
+func FormatURIs(uris []LoginURI) string {
+	list := make([]string, 0, len(uris))
+	for _, u := range uris {
+		list = append(list, u.String())
+	}
+
+	return strings.Join(list, "\n")
+}
+
+// ParseURIs - parses URIs one per line, each of them is either "match=uri" or a URI matched by domain. URIs are
+// separated by newlines, which can't be a part of a URI, so a regex could contain spaces. Empty lines are skipped.
+func ParseURIs(s string) []LoginURI {
+	var uris []LoginURI
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		uri := LoginURI{URI: line, Match: URIMatchDomain}
+		if match, value, ok := strings.Cut(line, "="); ok && uriMatchRanks[match] > 0 {
+			uri = LoginURI{URI: value, Match: match}
+		}
+
+		uris = append(uris, uri)
+	}
+
+	return uris
+}
+
+// LoginMatch - is a login secret, which URI matches a URL.
+type LoginMatch struct {
+	Secret Secret
+	URI    LoginURI
+}
+
+// FindLogins - returns secrets, which URIs match URL, ranked by specificity of the best matching URI of each secret:
+// prefix matches go first, then host, regex and domain ones. Matches by the same strategy are ordered by length of
+// URI, so longer prefixes and subdomains go first, and then by title.
+func FindLogins(secrets []Secret, rawURL string) ([]LoginMatch, error) {
+	if _, err := parseURI(rawURL); err != nil {
+		return nil, fmt.Errorf("validation error: %s is not a valid URL", rawURL)
+	}
+
+	var matches []LoginMatch
+	for _, secret := range secrets {
+		var best *LoginURI
+		for i, u := range secret.URIs {
+			if u.Matches(rawURL) && (best == nil || moreSpecific(u, *best)) {
+				best = &secret.URIs[i]
+			}
+		}
+
+		if best != nil {
+			matches = append(matches, LoginMatch{Secret: secret, URI: *best})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if aFirst, bFirst := moreSpecific(a.URI, b.URI), moreSpecific(b.URI, a.URI); aFirst != bFirst {
+			return aFirst
+		}
+		if a.Secret.Title != b.Secret.Title {
+			return a.Secret.Title < b.Secret.Title
+		}
+
+		return a.Secret.Id < b.Secret.Id
+	})
+
+	return matches, nil
+}
+
+// moreSpecific - reports whether URI a is more specific than URI b.
+func moreSpecific(a, b LoginURI) bool {
+	if uriMatchRanks[a.Match] != uriMatchRanks[b.Match] {
+		return uriMatchRanks[a.Match] > uriMatchRanks[b.Match]
+	}
+
+	return len(a.URI) > len(b.URI)
+}
+
+// parseURI - parses URI, which has to have a host, URI without scheme is taken as https one.
+func parseURI(raw string) (*url.URL, error) {
+	u, err := url.Parse(withScheme(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("host of %s is missing", raw)
+	}
+
+	return u, nil
+}
+
+// withScheme - prefixes URI without scheme by https one.
+func withScheme(raw string) string {
+	if strings.Contains(raw, "://") {
+		return raw
+	}
+
+	return "https://" + raw
+}
+
+// baseDomain - returns registrable domain of host by the public suffix list, hosts, which are IP addresses or have no
+// registrable domain, such as "localhost", are returned as is.
+func baseDomain(host string) string {
+	host = strings.ToLower(host)
+	if net.ParseIP(host) != nil {
+		return host
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+
+	return domain
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginURI_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     LoginURI
+		wantErr string
+	}{
+		{name: "Domain without scheme", uri: LoginURI{URI: "github.com", Match: URIMatchDomain}},
+		{name: "Host with port", uri: LoginURI{URI: "http://localhost:8080", Match: URIMatchHost}},
+		{name: "Regex", uri: LoginURI{URI: `^https://[a-z]+\.example\.com/`, Match: URIMatchRegex}},
+		{
+			name:    "Host is required",
+			uri:     LoginURI{URI: "https://", Match: URIMatchHost},
+			wantErr: "validation error: https:// is not a valid URI",
+		},
+		{
+			name:    "Invalid regex",
+			uri:     LoginURI{URI: "(", Match: URIMatchRegex},
+			wantErr: "validation error: ( is not a valid regex: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:    "Unknown match",
+			uri:     LoginURI{URI: "github.com", Match: "path"},
+			wantErr: `validation error: unknown match "path" of URI github.com`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.uri.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoginURI_Matches(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  LoginURI
+		url  string
+		want bool
+	}{
+		{name: "Domain matches subdomain", uri: LoginURI{URI: "github.com", Match: URIMatchDomain},
+			url: "https://gist.github.com/alice", want: true},
+		{name: "Domain respects public suffixes", uri: LoginURI{URI: "alice.github.io", Match: URIMatchDomain},
+			url: "https://bob.github.io", want: false},
+		{name: "Domain of country code suffix", uri: LoginURI{URI: "https://shop.example.co.uk", Match: URIMatchDomain},
+			url: "example.co.uk/login", want: true},
+		{name: "Host differs by subdomain", uri: LoginURI{URI: "https://github.com", Match: URIMatchHost},
+			url: "https://gist.github.com", want: false},
+		{name: "Host ignores case and path", uri: LoginURI{URI: "GitHub.com", Match: URIMatchHost},
+			url: "https://github.com/login", want: true},
+		{name: "Host with port", uri: LoginURI{URI: "http://localhost:8080", Match: URIMatchHost},
+			url: "http://localhost:9090", want: false},
+		{name: "Regex", uri: LoginURI{URI: `^https://[a-z]+\.example\.com/admin`, Match: URIMatchRegex},
+			url: "https://eu.example.com/admin/users", want: true},
+		{name: "Prefix", uri: LoginURI{URI: "https://example.com/admin", Match: URIMatchPrefix},
+			url: "example.com/admin/users", want: true},
+		{name: "Prefix differs by path", uri: LoginURI{URI: "https://example.com/admin", Match: URIMatchPrefix},
+			url: "https://example.com/shop", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.uri.Matches(tt.url))
+		})
+	}
+}
+
+func TestParseURIs(t *testing.T) {
+	uris := []LoginURI{
+		{URI: "github.com", Match: URIMatchDomain},
+		{URI: "https://example.com/?a=b", Match: URIMatchPrefix},
+		{URI: `^https://example\.com/(sign in|login)`, Match: URIMatchRegex},
+	}
+
+	assert.Equal(t, uris, ParseURIs(FormatURIs(uris)), "regex with spaces is kept")
+	assert.Equal(t, uris[:2], ParseURIs("\n github.com \n\nprefix=https://example.com/?a=b\n"),
+		"empty lines are skipped")
+	assert.Equal(t, []LoginURI{{URI: "https://example.com/?a=b", Match: URIMatchDomain}},
+		ParseURIs("https://example.com/?a=b"), "URI without match is matched by domain")
+	assert.Nil(t, ParseURIs(""))
+}
+
+func TestFindLogins(t *testing.T) {
+	secrets := []Secret{
+		{Id: 1, Title: "github", URIs: []LoginURI{{URI: "github.com", Match: URIMatchDomain}}},
+		{Id: 2, Title: "gist", URIs: []LoginURI{
+			{URI: "github.com", Match: URIMatchDomain},
+			{URI: "gist.github.com", Match: URIMatchHost},
+		}},
+		{Id: 3, Title: "github org", URIs: []LoginURI{{URI: "https://github.com/org", Match: URIMatchPrefix}}},
+		{Id: 4, Title: "work", URIs: []LoginURI{{URI: `^https://github\.com/`, Match: URIMatchRegex}}},
+		{Id: 5, Title: "github enterprise", URIs: []LoginURI{{URI: "github.com", Match: URIMatchDomain}}},
+		{Id: 6, Title: "mail", URIs: []LoginURI{{URI: "mail.example", Match: URIMatchDomain}}},
+		{Id: 7, Title: "note"},
+	}
+
+	matches, err := FindLogins(secrets, "https://github.com/org/repo")
+	require.NoError(t, err)
+
+	ids := make([]int, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.Secret.Id)
+	}
+	assert.Equal(t, []int{3, 4, 2, 1, 5}, ids, "equally specific matches are ordered by title")
+
+	matches, err = FindLogins(secrets, "gist.github.com")
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	assert.Equal(t, 2, matches[0].Secret.Id)
+	assert.Equal(t, LoginURI{URI: "gist.github.com", Match: URIMatchHost}, matches[0].URI, "the best URI is returned")
+
+	_, err = FindLogins(secrets, "https://")
+	assert.Error(t, err)
+}
@@ -1,44 +1,99 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// SecretRef - references a secret either by its numeric server ID or by its client generated public ID.
+type SecretRef struct {
+	Id       int
+	PublicId string
+}
+
+// IndexedTitle - returns title, which blind index is computed of. Title is trimmed and lowercased, so titles differing
+// only by case have the same index.
+func IndexedTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
 
 type SecretList struct {
-	Id    int
-	Title string
+	Id       int
+	PublicId string
+	Title    string
 }
 
-type LoginPassSecret struct {
-	Id         int
-	Title      string
-	RecordType int
-	Login      string
-	Password   string
-	UpdatedAt  time.Time
+// Secret - is a decrypted secret, which Fields are described by SecretType of its RecordType and CustomFields are
+// added by user. URIs are sites of a login secret, see SecretType.IsLogin.
+//
+// FolderId and TagIds are kept by server aside from encrypted content, FolderId is 0 for secrets in root.
+type Secret struct {
+	Id           int
+	PublicId     string
+	Title        string
+	RecordType   int
+	Fields       map[string]string
+	CustomFields []CustomField
+	URIs         []LoginURI
+	UpdatedAt    time.Time
+	Version      int64
+	FolderId     int
+	TagIds       []int
 }
 
-type TextSecret struct {
-	Id         int `json:"id"`
-	Title      string
-	RecordType int
-	Text       string
-	UpdatedAt  time.Time
+// contentMetaKeys - are keys of content, which were written by previous versions of the client alongside the fields
+// and are not fields of a secret.
+var contentMetaKeys = map[string]bool{
+	"Id": true, "id": true, "PublicId": true, "Title": true, "RecordType": true, "UpdatedAt": true, "Version": true,
 }
 
-type FileSecret struct {
-	Id         int
-	Title      string
-	RecordType int
-	Path       string
-	Binary     []byte
-	UpdatedAt  time.Time
+// MarshalJSON - encodes Title, RecordType and Fields of a secret into one flat object, which is stored encrypted
+// as content of a secret on the server. CustomFields and URIs are kept in order under their own keys.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	content := make(map[string]interface{}, len(s.Fields)+2)
+	for name, value := range s.Fields {
+		content[name] = value
+	}
+	content["Title"] = s.Title
+	content["RecordType"] = s.RecordType
+	if len(s.CustomFields) > 0 {
+		content["CustomFields"] = s.CustomFields
+	}
+	if len(s.URIs) > 0 {
+		content["URIs"] = s.URIs
+	}
+
+	return json.Marshal(content)
 }
 
-type CardSecret struct {
-	Id         int
-	Title      string
-	RecordType int
-	CardNumber string
-	CVV        string
-	Due        string
-	UpdatedAt  time.Time
+// UnmarshalJSON - decodes content of a secret, every string value except of Title is a field.
+func (s *Secret) UnmarshalJSON(b []byte) error {
+	var content map[string]interface{}
+	if err := json.Unmarshal(b, &content); err != nil {
+		return err
+	}
+
+	var custom struct {
+		CustomFields []CustomField
+		URIs         []LoginURI
+	}
+	if err := json.Unmarshal(b, &custom); err != nil {
+		return err
+	}
+	s.CustomFields, s.URIs = custom.CustomFields, custom.URIs
+
+	s.Fields = make(map[string]string, len(content))
+	for name, value := range content {
+		if str, ok := value.(string); ok && !contentMetaKeys[name] {
+			s.Fields[name] = str
+		}
+	}
+
+	s.Title, _ = content["Title"].(string)
+	if recordType, ok := content["RecordType"].(float64); ok {
+		s.RecordType = int(recordType)
+	}
+
+	return nil
 }
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// SecretPath - returns path of a secret, which is a path of its folder followed by its title.
+func (f Folders) SecretPath(secret Secret) string {
+	folderPath := f.Path(secret.FolderId)
+	if folderPath == FolderPathSeparator {
+		return folderPath + secret.Title
+	}
+
+	return folderPath + FolderPathSeparator + secret.Title
+}
+
+// FindSecrets - returns secrets matching query, which is either "folder/title" path or a title of a secret in any
+// folder. Titles are compared case-insensitively and could be shortened to their prefix, but exact matches are
+// preferred to prefix ones.
+//
+// If query is not a path of an existing folder followed by a title, then it is matched as a title as a whole, so
+// titles with a separator in them are found too. Found secrets are ordered by Id.
+func (f Folders) FindSecrets(secrets []Secret, query string) []Secret {
+	sorted := append([]Secret(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	var found []Secret
+	if i := strings.LastIndex(query, FolderPathSeparator); i >= 0 {
+		if folder, ok := f.Find(query[:i]); ok {
+			found = matchTitle(sorted, &folder.Id, query[i+1:])
+		}
+	}
+
+	if len(found) == 0 {
+		found = matchTitle(sorted, nil, query)
+	}
+
+	return found
+}
+
+// matchTitle - returns secrets of a folder with folderId, or of any folder if it's nil, which titles are equal to
+// title, or have it as a prefix if there are no equal ones.
+func matchTitle(secrets []Secret, folderId *int, title string) []Secret {
+	title = strings.ToLower(title)
+
+	var exact, prefix []Secret
+	for _, secret := range secrets {
+		if folderId != nil && secret.FolderId != *folderId {
+			continue
+		}
+
+		secretTitle := strings.ToLower(secret.Title)
+		switch {
+		case secretTitle == title:
+			exact = append(exact, secret)
+		case strings.HasPrefix(secretTitle, title):
+			prefix = append(prefix, secret)
+		}
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+
+	return prefix
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolders_FindSecrets(t *testing.T) {
+	folders := Folders{{Id: 1, Name: "work"}, {Id: 2, Name: "home"}}
+	secrets := []Secret{
+		{Id: 4, Title: "github", FolderId: 2},
+		{Id: 1, Title: "github", FolderId: 1},
+		{Id: 2, Title: "gitlab", FolderId: 1},
+		{Id: 3, Title: "Bank"},
+		{Id: 5, Title: "notes/2023"},
+		{Id: 6, Title: "github-backup", FolderId: 1},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{name: "Path of a secret", query: "work/github", want: []int{1}},
+		{name: "Path from root", query: "/home/github", want: []int{4}},
+		{name: "Secret in root", query: "/bank", want: []int{3}},
+		{name: "Exact title is ambiguous", query: "github", want: []int{1, 4}},
+		{name: "Prefix of a title in a folder", query: "work/gitl", want: []int{2}},
+		{name: "Prefix of titles in a folder", query: "work/git", want: []int{1, 2, 6}},
+		{name: "Title is compared case-insensitively", query: "BANK", want: []int{3}},
+		{name: "Title with separator", query: "notes/2023", want: []int{5}},
+		{name: "Unknown secret", query: "work/unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, secret := range folders.FindSecrets(secrets, tt.query) {
+				got = append(got, secret.Id)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, "/work/github", folders.SecretPath(secrets[1]))
+	assert.Equal(t, "/Bank", folders.SecretPath(secrets[3]))
+}
@@ -1,6 +1,141 @@
 package model
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+)
+
+// Kinds of fields of secrets.
+const (
+	SecretFieldKindText      = "text"
+	SecretFieldKindMultiline = "multiline"
+	SecretFieldKindFile      = "file"
+)
+
+// LoginField - is a name of a field of secret types, which secrets are credentials of sites.
+const LoginField = "Login"
+
+// SecretType - is a type of secrets with a schema of their fields.
 type SecretType struct {
-	Id    int
-	Title string
+	Id     int
+	Title  string
+	Fields []SecretTypeField
+}
+
+// SecretTypeField - describes a field of secrets of a type.
+type SecretTypeField struct {
+	Name      string
+	Label     string
+	Kind      string
+	Required  bool
+	Sensitive bool
+	Pattern   string
+}
+
+// NewSecretType - creates SecretType from the one returned by server.
+func NewSecretType(t *pb.Type) SecretType {
+	m := SecretType{Id: int(t.Id), Title: t.Title}
+	for _, f := range t.Fields {
+		m.Fields = append(m.Fields, SecretTypeField{
+			Name:      f.Name,
+			Label:     f.Label,
+			Kind:      f.Kind,
+			Required:  f.Required,
+			Sensitive: f.Sensitive,
+			Pattern:   f.Pattern,
+		})
+	}
+
+	return m
+}
+
+// Matches - reports whether type is referenced by key, which is either its ID or its title.
+func (t SecretType) Matches(key string) bool {
+	return key == strconv.Itoa(t.Id) || strings.EqualFold(key, t.Title)
+}
+
+// IsBinary - reports whether secrets of the type are files, content of such secret is a file itself.
+func (t SecretType) IsBinary() bool {
+	for _, f := range t.Fields {
+		if f.Kind == SecretFieldKindFile {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsLogin - reports whether secrets of the type are credentials of sites, which is whether it has Login field, only
+// such secrets could have URIs.
+func (t SecretType) IsLogin() bool {
+	for _, f := range t.Fields {
+		if strings.EqualFold(f.Name, LoginField) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate - checks that every required field of a secret is filled, fields match their patterns, custom fields
+// fit their kinds and URIs fit their match strategies.
+func (t SecretType) Validate(s Secret) error {
+	var missing []string
+	if s.Title == "" {
+		missing = append(missing, "Title")
+	}
+
+	for _, f := range t.Fields {
+		if f.Required && s.Fields[f.Name] == "" {
+			missing = append(missing, f.Label)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("validation error: %s is missing", strings.Join(missing, ", "))
+	}
+
+	for _, f := range t.Fields {
+		value := s.Fields[f.Name]
+		if f.Pattern == "" || value == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern of %s: %w", f.Label, err)
+		}
+
+		if !re.MatchString(value) {
+			return fmt.Errorf("validation error: %s has invalid format", f.Label)
+		}
+	}
+
+	names := make(map[string]bool, len(s.CustomFields))
+	for _, f := range s.CustomFields {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+
+		if names[f.Name] {
+			return fmt.Errorf("validation error: custom field %s is duplicated", f.Name)
+		}
+		names[f.Name] = true
+	}
+
+	if len(s.URIs) > 0 && !t.IsLogin() {
+		return fmt.Errorf("validation error: URIs are supported by login secrets only")
+	}
+
+	for _, u := range s.URIs {
+		if err := u.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var cardType = SecretType{Id: 4, Title: "card", Fields: []SecretTypeField{
+	{Name: "CardNumber", Label: "Card number", Kind: SecretFieldKindText, Required: true, Pattern: "^[0-9]{12,19}$"},
+	{Name: "CVV", Label: "CVV", Kind: SecretFieldKindText, Required: true, Pattern: "^[0-9]{3,4}$"},
+	{Name: "Note", Label: "Note", Kind: SecretFieldKindMultiline},
+}}
+
+func TestSecretType_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  Secret
+		wantErr string
+	}{
+		{
+			name:   "Valid secret passes",
+			secret: Secret{Title: "visa", Fields: map[string]string{"CardNumber": "4111111111111111", "CVV": "123"}},
+		},
+		{
+			name:    "Missing required fields are listed",
+			secret:  Secret{Fields: map[string]string{"CardNumber": "4111111111111111"}},
+			wantErr: "validation error: Title, CVV is missing",
+		},
+		{
+			name:    "Field not matching its pattern is refused",
+			secret:  Secret{Title: "visa", Fields: map[string]string{"CardNumber": "4111111111111111", "CVV": "12a"}},
+			wantErr: "validation error: CVV has invalid format",
+		},
+		{
+			name: "URIs are refused for secrets without login",
+			secret: Secret{
+				Title: "visa", Fields: map[string]string{"CardNumber": "4111111111111111", "CVV": "123"},
+				URIs: []LoginURI{{URI: "bank.example", Match: URIMatchDomain}},
+			},
+			wantErr: "validation error: URIs are supported by login secrets only",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cardType.Validate(tt.secret)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSecretType_Matches(t *testing.T) {
+	assert.True(t, cardType.Matches("4"))
+	assert.True(t, cardType.Matches("Card"))
+	assert.False(t, cardType.Matches("text"))
+}
+
+func TestSecret_UnmarshalJSON(t *testing.T) {
+	// content written by previous versions of the client carries metadata alongside fields
+	content := `{"Id":0,"PublicId":"","Title":"mail","RecordType":1,"Login":"alice","Password":"secret",` +
+		`"UpdatedAt":"0001-01-01T00:00:00Z","Version":0}`
+
+	var secret Secret
+	assert.NoError(t, json.Unmarshal([]byte(content), &secret))
+	assert.Equal(t, Secret{
+		Title:      "mail",
+		RecordType: 1,
+		Fields:     map[string]string{"Login": "alice", "Password": "secret"},
+	}, secret)
+
+	encoded, err := json.Marshal(secret)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Title":"mail","RecordType":1,"Login":"alice","Password":"secret"}`, string(encoded))
+}
@@ -1,12 +1,28 @@
 package prompt
 
-import "github.com/c-bata/go-prompt"
+import (
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+)
+
+// folderArgs - are positions of arguments of commands, which are paths of folders.
+var folderArgs = map[string]int{
+	"mkdir": 1,
+	"mv":    2,
+	"ls":    1,
+}
 
 type Completer struct {
+	folderPaths func() []string
 }
 
-func NewCompleter() *Completer {
-	return &Completer{}
+// NewCompleter - creates new Completer, folderPaths returns paths of folders, which are suggested for arguments of
+// commands working with folders.
+func NewCompleter(folderPaths func() []string) *Completer {
+	return &Completer{folderPaths: folderPaths}
 }
 
 // Complete - a list of suggestions.
@@ -19,19 +35,69 @@ func (c *Completer) Complete(d prompt.Document) []prompt.Suggest {
 			{Text: "logout", Description: "Logout authenticated user"},
 			{Text: "register", Description: "Register new user"},
 			{Text: "delete-user", Description: "Delete logged user"},
+			{Text: "change-password", Description: "Change password of logged user"},
 			{Text: "types", Description: "Get list of secret types available to be stored"},
+			{Text: "create", Description: "Create new secret of provided type"},
 			{Text: "create-auth", Description: "Create new login/pass secret"},
 			{Text: "create-text", Description: "Create new text secret"},
 			{Text: "create-binary", Description: "Create new binary secret"},
 			{Text: "create-card", Description: "Create new card secret"},
 			{Text: "get-secret", Description: "Retrieve stored secret"},
+			{Text: "search", Description: "Search stored secrets by their titles and fields"},
+			{Text: "find-login", Description: "Find login secrets by URL of a site"},
 			{Text: "get-secret-binary", Description: "Retrieve stored binary secret"},
-			{Text: "delete-secret", Description: "Retrieve stored secret"},
+			{Text: "delete-secret", Description: "Move stored secrets to trash"},
+			{Text: "import", Description: "Create secrets from JSON file"},
+			{Text: "trash", Description: "Retrieves list of secrets in trash"},
+			{Text: "restore-secret", Description: "Restore secret from trash"},
+			{Text: "purge-secret", Description: "Permanently delete secret from trash"},
 			{Text: "edit-secret", Description: "Edit stored secret"},
+			{Text: "rename", Description: "Change title of stored secret"},
+			{Text: "history", Description: "Retrieves list of versions of stored secret"},
+			{Text: "show-version", Description: "Retrieve version of stored secret"},
+			{Text: "restore", Description: "Restore stored secret to its version"},
+			{Text: "diff-versions", Description: "Compare two versions of stored secret"},
 			{Text: "get-secrets-by-type", Description: "Retrieves list of secretes by their type"},
+			{Text: "mkdir", Description: "Create folder with its missing parent folders"},
+			{Text: "mv", Description: "Move stored secret to folder"},
+			{Text: "tag", Description: "Add tags to stored secret or remove them"},
+			{Text: "ls", Description: "Retrieves list of folders and secrets of folder"},
+			{Text: "audit", Description: "Retrieves log of security-relevant events of logged user"},
 			{Text: "exit", Description: "Exit program"},
 		}
+
+		return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+	}
+
+	return c.completeFolder(d)
+}
+
+// completeFolder - suggests paths of folders, if word before cursor is an argument of a command, which is a path of
+// a folder.
+func (c *Completer) completeFolder(d prompt.Document) []prompt.Suggest {
+	args := strings.Fields(d.TextBeforeCursor())
+	if len(args) == 0 {
+		return nil
+	}
+
+	position := len(args)
+	if !strings.HasSuffix(d.TextBeforeCursor(), " ") {
+		position--
+	}
+
+	if pos, ok := folderArgs[args[0]]; !ok || pos != position || c.folderPaths == nil {
+		return nil
+	}
+
+	s := []prompt.Suggest{{Text: model.FolderPathSeparator, Description: "Root folder"}}
+	for _, path := range c.folderPaths() {
+		s = append(s, prompt.Suggest{Text: path})
+	}
+
+	word := d.GetWordBeforeCursor()
+	if !strings.HasPrefix(word, model.FolderPathSeparator) {
+		word = model.FolderPathSeparator + word
 	}
 
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+	return prompt.FilterHasPrefix(s, word, true)
 }
@@ -7,14 +7,33 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	pb "github.com/sergalkin/gophkeeper/api/proto"
 	"github.com/sergalkin/gophkeeper/internal/client/app"
+	"github.com/sergalkin/gophkeeper/internal/client/merge"
 	"github.com/sergalkin/gophkeeper/internal/client/model"
+	"github.com/sergalkin/gophkeeper/internal/client/search"
 )
 
+// maxMergeAttempts - is a number of times edited secret is merged with the server version on repeated conflicts.
+const maxMergeAttempts = 3
+
+// sensitiveMask - is displayed instead of values of sensitive fields, unless they are revealed.
+const sensitiveMask = "********"
+
+// createAliases - are titles of secret types created by "create-*" commands.
+var createAliases = map[string]string{
+	"create-auth":   "login/pass",
+	"create-text":   "text",
+	"create-binary": "binary",
+	"create-card":   "card",
+}
+
 type Executor struct {
 	app *app.App
 }
@@ -32,9 +51,17 @@ func (e *Executor) Execute(s string) {
 	var isForce bool
 
 	setCommand, options := getCommandArgsAndOptions(s)
-	if options["force"] || options["f"] {
+	if options.Has("force") || options.Has("f") {
 		isForce = true
 	}
+	reveal := options.Has("reveal")
+
+	customFields, errFields := parseCustomFields(options)
+	if errFields != nil {
+		fmt.Println(errFields)
+		return
+	}
+	uris := parseURIs(options)
 
 	switch setCommand[0] {
 	case "login":
@@ -62,6 +89,15 @@ func (e *Executor) Execute(s string) {
 
 		fmt.Println("you successfully deleted account and logged out")
 
+		return
+	case "change-password":
+		if err := e.changePassword(setCommand); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Println("password successfully changed")
+
 		return
 	case "logout":
 		if err := e.logout(); err != nil {
@@ -79,53 +115,77 @@ func (e *Executor) Execute(s string) {
 		}
 
 		for _, t := range types {
-			fmt.Printf("%+v\n", t)
+			fmt.Print(formatSecretType(t))
 		}
 
 		return
-	case "create-auth":
-		if err := e.createAuth(setCommand); err != nil {
+	case "create":
+		if err := e.create(setCommand, customFields, uris); err != nil {
 			fmt.Println(err)
 			return
 		}
+
 		return
-	case "create-text":
-		if err := e.createText(setCommand); err != nil {
+	case "create-auth", "create-text", "create-binary", "create-card":
+		if err := e.createOfType(createAliases[setCommand[0]], setCommand, customFields, uris); err != nil {
 			fmt.Println(err)
 			return
 		}
 
 		return
-	case "create-binary":
-		if err := e.createBinary(setCommand); err != nil {
+	case "delete-secret":
+		if err := e.deleteSecret(setCommand); err != nil {
 			fmt.Println(err)
 			return
 		}
 
 		return
-	case "create-card":
-		if err := e.createCard(setCommand); err != nil {
+	case "import":
+		if err := e.importSecrets(setCommand); err != nil {
 			fmt.Println(err)
 			return
 		}
 
 		return
-	case "delete-secret":
-		if err := e.deleteSecret(setCommand); err != nil {
+	case "get-secrets-by-type":
+		list, err := e.getSecretsByTypeId(setCommand)
+		if err != nil {
 			fmt.Println(err)
 			return
 		}
 
+		for _, secret := range list {
+			fmt.Printf("ID:%v PublicID: %v Title: %v\n", secret.Id, secret.PublicId, secret.Title)
+		}
+
 		return
-	case "get-secrets-by-type":
-		list, err := e.getSecretsByTypeId(setCommand)
+	case "search":
+		results, err := e.search(setCommand)
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
 
-		for _, secret := range list {
-			fmt.Printf("ID:%v Title: %v\n", secret.Id, secret.Title)
+		for _, r := range results {
+			fmt.Printf(
+				"ID:%v PublicID: %v Title: %v Matched: %v\n",
+				r.Secret.Id, r.Secret.PublicId, r.Secret.Title, strings.Join(r.Scopes, ", "),
+			)
+		}
+
+		return
+	case "find-login":
+		matches, err := e.findLogin(setCommand)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		for _, m := range matches {
+			fmt.Printf(
+				"ID:%v PublicID: %v Title: %v Login: %v URI: %v\n",
+				m.Secret.Id, m.Secret.PublicId, m.Secret.Title, loginOf(m.Secret), m.URI,
+			)
 		}
 
 		return
@@ -136,7 +196,9 @@ func (e *Executor) Execute(s string) {
 			return
 		}
 
-		fmt.Printf("Content:%+v\n", secret)
+		if err = e.printSecret(secret, reveal); err != nil {
+			fmt.Println(err)
+		}
 
 		return
 	case "get-secret-binary":
@@ -146,11 +208,149 @@ func (e *Executor) Execute(s string) {
 		}
 		return
 	case "edit-secret":
-		if err := e.editSecret(setCommand, isForce); err != nil {
+		if err := e.editSecret(setCommand, customFields, uris, isForce); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "rename":
+		if err := e.rename(setCommand, isForce); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "trash":
+		list, err := e.trash()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		for _, secret := range list {
+			fmt.Printf(
+				"ID:%v PublicID: %v Title: %v DeletedAt: %v\n",
+				secret.Id, secret.PublicId, secret.Title, secret.DeletedAt.GetData().AsTime().Format(time.RFC3339),
+			)
+		}
+
+		return
+	case "restore-secret":
+		if err := e.restoreSecret(setCommand); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "purge-secret":
+		if err := e.purgeSecret(setCommand); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "history":
+		versions, err := e.history(setCommand)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		for _, v := range versions {
+			fmt.Printf(
+				"Version: %v Title: %v Type: %v ChangedBy: %v ChangedAt: %v\n",
+				v.Version, v.Title, v.Type, v.ChangedBy, v.ChangedAt.AsTime().Format(time.RFC3339),
+			)
+		}
+
+		return
+	case "show-version":
+		secret, err := e.showVersion(setCommand)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err = e.printSecret(secret, reveal); err != nil {
+			fmt.Println(err)
+		}
+
+		return
+	case "restore":
+		if err := e.restore(setCommand); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "diff-versions":
+		changes, err := e.diffVersions(setCommand)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Print(changes)
+
+		return
+	case "mkdir":
+		if err := e.mkdir(setCommand); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "mv":
+		if err := e.move(setCommand); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "tag":
+		tags, err := e.tag(setCommand, options)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if tags != nil {
+			fmt.Printf("Tags: %v\n", strings.Join(tags, ", "))
+		}
+
+		return
+	case "ls":
+		if err := e.ls(setCommand, options); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		return
+	case "audit":
+		events, err := e.audit(setCommand)
+		if err != nil {
 			fmt.Println(err)
 			return
 		}
 
+		for _, event := range events {
+			result := "ok"
+			if !event.Success {
+				result = "failed"
+			}
+
+			fmt.Printf(
+				"%v %v %v Target: %v IP: %v UserAgent: %v RequestID: %v\n",
+				event.CreatedAt.AsTime().Format(time.RFC3339), event.Event, result,
+				event.Target, event.Ip, event.UserAgent, event.RequestId,
+			)
+		}
+
+		if e.app.AuditService.HasNext() {
+			fmt.Println("more events: audit next")
+		}
+
 		return
 	case "exit":
 		fmt.Println("bye bye...application is closing")
@@ -220,6 +420,29 @@ func (e *Executor) deleteUser() error {
 	return e.app.UserService.Delete()
 }
 
+// changePassword - is executor for "change-password" case in Execute method.
+func (e *Executor) changePassword(args []string) error {
+	switch len(args) - 1 {
+	case 1:
+		return fmt.Errorf("validation error: New password is missing")
+	case 0:
+		return fmt.Errorf("validation error: Password and New password is missing")
+	}
+
+	if err := e.app.UserService.ChangePassword(args[1], args[2]); err != nil {
+		switch status.Code(err) {
+		case codes.PermissionDenied:
+			return fmt.Errorf("error: password is wrong")
+		case codes.InvalidArgument:
+			return fmt.Errorf("error: new password is invalid")
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
 // logout - is executor for "types" case in Execute method.
 func (e *Executor) logout() error {
 	e.app.UserService.Logout()
@@ -233,164 +456,181 @@ func (e *Executor) logout() error {
 
 // types - is executor for "types" case in Execute method.
 func (e *Executor) types() ([]model.SecretType, error) {
-	secrets, err := e.app.SecretTypeService.List()
-	if err != nil {
-		return nil, err
-	}
+	return e.app.SecretTypeService.List()
+}
 
-	var models []model.SecretType
-	for _, secret := range secrets.Secrets {
+// create - is executor for "create" case in Execute method, secret type is passed by its ID or title and is followed
+// by title of a secret and values of fields of the type in their order.
+func (e *Executor) create(args []string, customFields []model.CustomField, uris []model.LoginURI) error {
+	if len(args)-1 < 1 {
+		return fmt.Errorf("validation error: Secret Type is missing")
+	}
 
-		models = append(models, model.SecretType{
-			Id:    int(secret.Id),
-			Title: secret.Title,
-		})
+	t, err := e.app.SecretService.SecretType(args[1])
+	if err != nil {
+		return err
 	}
 
-	return models, nil
+	return e.createSecret(t, args[2:], customFields, uris)
 }
 
-// createAuth - is executor for "create-auth" case in Execute method.
-func (e *Executor) createAuth(args []string) error {
-	switch len(args) - 1 {
-	case 2:
-		return fmt.Errorf("validation error: Password is missing")
-	case 1:
-		return fmt.Errorf("validation error: Login and Password is missing")
-	case 0:
-		return fmt.Errorf("validation error: Title, Login, Password is missing")
+// createOfType - is executor for "create-auth", "create-text", "create-card" and "create-binary" cases in Execute
+// method, which are shortcuts of "create" for secret types created by default.
+func (e *Executor) createOfType(
+	typeTitle string, args []string, customFields []model.CustomField, uris []model.LoginURI,
+) error {
+	t, err := e.app.SecretService.SecretType(typeTitle)
+	if err != nil {
+		return err
 	}
 
-	m := model.LoginPassSecret{
-		Title:      args[1],
-		RecordType: 1,
-		Login:      args[2],
-		Password:   args[3],
+	return e.createSecret(t, args[1:], customFields, uris)
+}
+
+// createSecret - builds a secret of type t from title, values of fields, custom fields and URIs and creates it on the
+// server.
+func (e *Executor) createSecret(
+	t model.SecretType, args []string, customFields []model.CustomField, uris []model.LoginURI,
+) error {
+	var title string
+	if len(args) > 0 {
+		title, args = args[0], args[1:]
 	}
 
-	cont, errMarshal := json.Marshal(m)
-	if errMarshal != nil {
-		return errMarshal
+	draft, errDraft := buildDraft(t, model.SecretRef{}, title, args, model.WithCustomFields(nil, customFields), uris)
+	if errDraft != nil {
+		return errDraft
 	}
 
-	if err := e.app.SecretService.CreateSecret(m.Title, 1, string(cont)); err != nil {
-		return err
+	content, errContent := secretContent(t, draft)
+	if errContent != nil {
+		return errContent
 	}
 
-	return nil
+	return e.app.SecretService.CreateSecret(draft.Title, t.Id, content)
 }
 
-// createText - is executor for "create-text" case in Execute method.
-func (e *Executor) createText(args []string) error {
+// deleteSecret - is executor for "delete-secret" case in Execute method, several secrets are moved to trash with
+// one request.
+func (e *Executor) deleteSecret(args []string) error {
 	switch len(args) - 1 {
-	case 1:
-		return fmt.Errorf("validation error: Text is missing")
 	case 0:
-		return fmt.Errorf("validation error: Title and Text is missing")
+		return fmt.Errorf("validation error: Secret ID is missing")
 	}
 
-	m := model.TextSecret{
-		Title:      args[1],
-		RecordType: 2,
-		Text:       strings.Join(args[2:], " "),
+	refs := make([]model.SecretRef, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		ref, convErr := e.secretRef(arg)
+		if convErr != nil {
+			return convErr
+		}
+
+		refs = append(refs, ref)
 	}
 
-	marshal, errMarshal := json.Marshal(m)
-	if errMarshal != nil {
-		return errMarshal
+	if len(refs) == 1 {
+		return e.app.SecretService.DeleteSecret(refs[0])
 	}
 
-	if err := e.app.SecretService.CreateSecret(m.Title, 2, string(marshal)); err != nil {
-		return err
+	errs, err := e.app.SecretService.DeleteSecrets(refs)
+
+	var deleted int
+	for i, errItem := range errs {
+		if errItem != nil {
+			fmt.Printf("%s: %s\n", args[i+1], status.Convert(errItem).Message())
+			continue
+		}
+
+		deleted++
 	}
+	fmt.Printf("moved %d of %d secrets to trash\n", deleted, len(refs))
 
-	return nil
+	return err
 }
 
-// createBinary - is executor for "create-binary" case in Execute method.
-func (e *Executor) createBinary(args []string) error {
+// importSecrets - is executor for "import" case in Execute method, secrets are read from a JSON file, see
+// model.ImportEntry, and are created on the server with batch requests.
+func (e *Executor) importSecrets(args []string) error {
 	switch len(args) - 1 {
-	case 1:
-		return fmt.Errorf("validation error: Filepath is missing")
 	case 0:
-		return fmt.Errorf("validation error: Title and Filepath is missing")
+		return fmt.Errorf("validation error: path of a file is missing")
 	}
 
-	m := model.FileSecret{
-		Title:      args[1],
-		RecordType: 3,
-		Path:       args[2],
-	}
-
-	f, err := os.Open(m.Path)
+	f, err := os.Open(args[1])
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	data, errData := ioutil.ReadAll(f)
-	if errData != nil {
-		return errData
-	}
-
-	errCreate := e.app.SecretService.CreateSecret(m.Title, m.RecordType, string(data))
-	if errCreate != nil {
-		return errCreate
+	entries, err := model.ParseImport(f)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
+	drafts := make([]model.SecretDraft, 0, len(entries))
+	titles := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		draft, errDraft := e.importDraft(entry)
+		if errDraft != nil {
+			fmt.Printf("#%d %s: %v\n", i+1, entry.Title, errDraft)
+			continue
+		}
 
-// createCard - is executor for "create-card" case in Execute method.
-func (e *Executor) createCard(args []string) error {
-	switch len(args) - 1 {
-	case 3:
-		return fmt.Errorf("validation error: Due date is missing")
-	case 2:
-		return fmt.Errorf("validation error: CVV and Due date is missing")
-	case 1:
-		return fmt.Errorf("validation error: Card number, CVV, Due date is missing")
-	case 0:
-		return fmt.Errorf("validation error: Title, Card number, CVV, Due date is missing")
+		drafts = append(drafts, draft)
+		titles = append(titles, fmt.Sprintf("#%d %s", i+1, entry.Title))
 	}
 
-	cardModel := model.CardSecret{
-		Title:      args[1],
-		RecordType: 4,
-		CardNumber: args[2],
-		CVV:        args[3],
-		Due:        args[4],
+	if len(drafts) == 0 {
+		return fmt.Errorf("nothing to import")
 	}
 
-	cont, er := json.Marshal(cardModel)
-	if er != nil {
-		return er
-	}
+	errs, err := e.app.SecretService.CreateSecrets(drafts)
 
-	if err := e.app.SecretService.CreateSecret(cardModel.Title, cardModel.RecordType, string(cont)); err != nil {
-		return err
+	var created int
+	for i, errItem := range errs {
+		if errItem != nil {
+			fmt.Printf("%s: %s\n", titles[i], status.Convert(errItem).Message())
+			continue
+		}
+
+		created++
 	}
+	fmt.Printf("imported %d of %d secrets\n", created, len(entries))
 
-	return nil
+	return err
 }
 
-// deleteSecret - is executor for "delete-secret" case in Execute method.
-func (e *Executor) deleteSecret(args []string) error {
-	switch len(args) - 1 {
-	case 0:
-		return fmt.Errorf("validation error: Secret ID is missing")
+// importDraft - validates an entry of a file of "import" command against schema of its type and returns a draft of
+// a secret.
+func (e *Executor) importDraft(entry model.ImportEntry) (model.SecretDraft, error) {
+	t, err := e.app.SecretService.SecretType(entry.Type)
+	if err != nil {
+		return model.SecretDraft{}, err
 	}
 
-	id, convErr := strconv.Atoi(args[1])
-	if convErr != nil {
-		return convErr
+	secret := model.Secret{
+		Title:        entry.Title,
+		RecordType:   t.Id,
+		Fields:       make(map[string]string, len(entry.Fields)),
+		CustomFields: entry.CustomFields,
+		URIs:         entry.URIs,
+	}
+	for _, f := range t.Fields {
+		if value, ok := entry.Fields[f.Name]; ok {
+			secret.Fields[f.Name] = value
+		}
 	}
 
-	if err := e.app.SecretService.DeleteSecret(id); err != nil {
-		return err
+	if err = t.Validate(secret); err != nil {
+		return model.SecretDraft{}, err
 	}
 
-	return nil
+	content, err := secretContent(t, secret)
+	if err != nil {
+		return model.SecretDraft{}, err
+	}
+
+	return model.SecretDraft{Title: secret.Title, RecordType: t.Id, Content: content}, nil
 }
 
 // getSecretsByTypeId - is executor for "get-secrets-by-type" case in Execute method.
@@ -400,12 +640,12 @@ func (e *Executor) getSecretsByTypeId(args []string) ([]model.SecretList, error)
 		return nil, fmt.Errorf("validation error: Secret Type ID is missing")
 	}
 
-	id, convErr := strconv.Atoi(args[1])
-	if convErr != nil {
-		return nil, convErr
+	t, errType := e.app.SecretService.SecretType(args[1])
+	if errType != nil {
+		return nil, errType
 	}
 
-	list, err := e.app.SecretService.GetListOfSecretes(id)
+	list, err := e.app.SecretService.GetListOfSecretes(t.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -413,8 +653,9 @@ func (e *Executor) getSecretsByTypeId(args []string) ([]model.SecretList, error)
 	var models []model.SecretList
 	for _, secret := range list {
 		models = append(models, model.SecretList{
-			Id:    int(secret.Id),
-			Title: secret.Title,
+			Id:       int(secret.Id),
+			PublicId: secret.PublicId,
+			Title:    secret.Title,
 		})
 	}
 
@@ -422,25 +663,25 @@ func (e *Executor) getSecretsByTypeId(args []string) ([]model.SecretList, error)
 }
 
 // getSecret - is executor for "get-secret" case in Execute method.
-func (e *Executor) getSecret(args []string) (interface{}, error) {
+func (e *Executor) getSecret(args []string) (model.Secret, error) {
 	switch len(args) - 1 {
 	case 0:
-		return nil, fmt.Errorf("validation error: Secret ID is missing")
+		return model.Secret{}, fmt.Errorf("validation error: Secret ID is missing")
 	}
 
-	id, convErr := strconv.Atoi(args[1])
+	ref, convErr := e.secretRef(args[1])
 	if convErr != nil {
-		return nil, convErr
+		return model.Secret{}, convErr
 	}
 
-	secret, err := e.app.SecretService.GetSecret(id)
+	secret, err := e.app.SecretService.GetSecret(ref)
 	if err != nil {
 		st, _ := status.FromError(err)
 		switch st.Code() {
 		case codes.NotFound:
-			return nil, fmt.Errorf(st.Message())
+			return model.Secret{}, fmt.Errorf(st.Message())
 		default:
-			return nil, err
+			return model.Secret{}, err
 		}
 	}
 
@@ -456,12 +697,12 @@ func (e *Executor) getSecretBinary(args []string) error {
 		return fmt.Errorf("validation error: Path is missing")
 	}
 
-	id, errConv := strconv.Atoi(args[1])
+	ref, errConv := e.secretRef(args[1])
 	if errConv != nil {
 		return errConv
 	}
 
-	err := e.app.SecretService.GetBinarySecret(id, args[2])
+	err := e.app.SecretService.GetBinarySecret(ref, args[2])
 	if err != nil {
 		return err
 	}
@@ -470,101 +711,104 @@ func (e *Executor) getSecretBinary(args []string) error {
 }
 
 // editSecret - is executor for "edit-secret" case in Execute method.
-func (e *Executor) editSecret(args []string, isForce bool) error {
-	var (
-		recordType int
-		id         int
-		converted  []byte
-	)
-
-	numArgs := len(args) - 1
-	if numArgs >= 3 {
-		recordType, errConv := strconv.Atoi(args[3])
-		if errConv != nil {
-			return errConv
-		}
-
-		id, errConv = strconv.Atoi(args[1])
-		if errConv != nil {
-			return errConv
-		}
-
-		switch recordType {
-		case 1:
-			switch numArgs {
-			case 4:
-				return fmt.Errorf("validation error: Password is missing")
-			case 3:
-				return fmt.Errorf("validation error: Login and Password is missing")
-			default:
-				converted, errConv = json.Marshal(model.LoginPassSecret{
-					Id:         id,
-					Title:      args[2],
-					RecordType: 1,
-					Login:      args[4],
-					Password:   args[5],
-				})
-				if errConv != nil {
-					return errConv
-				}
-			}
-		case 2:
-			switch numArgs {
-			case 3:
-				return fmt.Errorf("validation error: Text is missing")
-			default:
-				converted, errConv = json.Marshal(model.TextSecret{
-					Id:         id,
-					Title:      args[2],
-					RecordType: 2,
-					Text:       strings.Join(args[4:], " "),
-				})
-				if errConv != nil {
-					return errConv
-				}
-			}
-		case 3:
-			switch numArgs {
-			case 3:
-				return fmt.Errorf("validation error: Filepath is missing")
-			default:
-				converted, errConv = json.Marshal(model.FileSecret{
-					Id:         id,
-					Title:      args[2],
-					RecordType: 3,
-					Path:       args[3],
-				})
-				if errConv != nil {
-					return errConv
-				}
-			}
-		case 4:
-			switch numArgs {
-			case 5:
-				return fmt.Errorf("validation error: Due date is missing")
-			case 4:
-				return fmt.Errorf("validation error: CVV and Due date is missing")
-			case 3:
-				return fmt.Errorf("validation error: Card number, CVV and Due date is missing")
-			default:
-				converted, errConv = json.Marshal(model.CardSecret{
-					Id:         id,
-					Title:      args[2],
-					RecordType: 4,
-					CardNumber: args[4],
-					CVV:        args[5],
-					Due:        args[6],
-				})
-				if errConv != nil {
-					return errConv
-				}
-			}
+//
+// Custom fields of a secret are kept, unless they are changed by provided ones. URIs of a secret are kept, unless
+// URIs are provided, then they replace all of them.
+//
+// If secret was changed on the server since last sync, then typed draft is kept and merged with the server version,
+// using local copy of a secret as a common base. Conflicting fields are resolved by user.
+func (e *Executor) editSecret(
+	args []string, customFields []model.CustomField, uris []model.LoginURI, isForce bool,
+) error {
+	if len(args)-1 < 3 {
+		return fmt.Errorf("validation error: Secret ID, Title, Secret Type ID and secret fields is missing")
+	}
+
+	ref, errRef := e.secretRef(args[1])
+	if errRef != nil {
+		return errRef
+	}
+
+	t, errType := e.app.SecretService.SecretType(args[3])
+	if errType != nil {
+		return errType
+	}
+
+	var base *model.Secret
+	if local, errLocal := e.app.SecretService.GetSecret(ref); errLocal == nil {
+		if local.RecordType != t.Id {
+			return fmt.Errorf("validation error: type of a secret can't be changed, create a new secret instead")
+		}
+
+		base = &local
+		customFields = model.WithCustomFields(local.CustomFields, customFields)
+		if uris == nil {
+			uris = local.URIs
 		}
 	} else {
-		return fmt.Errorf("validation error: Secret ID, Title, Secret Type ID and secret fields is missing")
+		customFields = model.WithCustomFields(nil, customFields)
+	}
+
+	draft, errDraft := buildDraft(t, ref, args[2], args[4:], customFields, uris)
+	if errDraft != nil {
+		return errDraft
+	}
+
+	converted, errContent := secretContent(t, draft)
+	if errContent != nil {
+		return errContent
 	}
 
-	if err := e.app.SecretService.EditSecret(id, args[3], recordType, string(converted), isForce); err != nil {
+	err := e.app.SecretService.EditSecret(ref, draft.Title, t.Id, converted, isForce)
+	for attempt := 0; err != nil && attempt < maxMergeAttempts; attempt++ {
+		st, _ := status.FromError(err)
+		if st.Code() != codes.FailedPrecondition {
+			break
+		}
+
+		fmt.Println(st.Message())
+
+		for _, detail := range st.Details() {
+			if conflict, ok := detail.(*pb.VersionConflict); ok {
+				fmt.Printf("server has version %d of the secret, updated at %s\n",
+					conflict.CurrentVersion, conflict.UpdatedAt.AsTime().Format(time.RFC3339))
+			}
+		}
+
+		if t.IsBinary() {
+			break
+		}
+
+		server, errServer := e.app.SecretService.GetServerSecret(ref)
+		if errServer != nil {
+			return errServer
+		}
+
+		fields, errDiff := merge.Diff(t, base, draft, server)
+		if errDiff != nil {
+			return errDiff
+		}
+
+		fmt.Print(merge.Format(fields))
+
+		merged, errMerge := merge.ThreeWay(t, base, draft, server, conflictResolver{})
+		if errMerge != nil {
+			fmt.Printf("local draft is kept: %+v\n", draft)
+
+			return errMerge
+		}
+
+		converted, errContent = secretContent(t, merged)
+		if errContent != nil {
+			return errContent
+		}
+
+		base, draft = &server, merged
+
+		err = e.app.SecretService.EditSecretVersion(ref, merged.Title, t.Id, converted, server.Version, false)
+	}
+
+	if err != nil {
 		st, _ := status.FromError(err)
 
 		fmt.Println(st.Message())
@@ -583,24 +827,428 @@ func (e *Executor) editSecret(args []string, isForce bool) error {
 	return nil
 }
 
-// getCommandArgsAndOptions - splits args to command args and options
-func getCommandArgsAndOptions(s string) ([]string, map[string]bool) {
-	s = strings.TrimSpace(s)
+// rename - is executor for "rename" case in Execute method, which changes title of a secret only.
+func (e *Executor) rename(args []string, isForce bool) error {
+	switch len(args) - 1 {
+	case 0:
+		return fmt.Errorf("validation error: Secret ID and Title is missing")
+	case 1:
+		return fmt.Errorf("validation error: Title is missing")
+	}
 
-	setCommand := strings.Split(s, " ")
+	ref, errRef := e.secretRef(args[1])
+	if errRef != nil {
+		return errRef
+	}
+
+	return e.app.SecretService.RenameSecret(ref, strings.Join(args[2:], " "), isForce)
+}
+
+// search - is executor for "search" case in Execute method.
+func (e *Executor) search(args []string) ([]search.Result, error) {
+	if len(args)-1 < 1 {
+		return nil, fmt.Errorf("validation error: Query is missing")
+	}
+
+	return e.app.SecretService.Search(strings.Join(args[1:], " "))
+}
+
+// findLogin - is executor for "find-login" case in Execute method.
+func (e *Executor) findLogin(args []string) ([]model.LoginMatch, error) {
+	if len(args)-1 < 1 {
+		return nil, fmt.Errorf("validation error: URL is missing")
+	}
+
+	return e.app.SecretService.FindLogins(args[1])
+}
+
+// loginOf - returns value of Login field of a login secret.
+func loginOf(secret model.Secret) string {
+	for name, value := range secret.Fields {
+		if strings.EqualFold(name, model.LoginField) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// trash - is executor for "trash" case in Execute method.
+func (e *Executor) trash() ([]*pb.SecretList, error) {
+	return e.app.SecretService.ListTrash()
+}
+
+// restoreSecret - is executor for "restore-secret" case in Execute method.
+func (e *Executor) restoreSecret(args []string) error {
+	switch len(args) - 1 {
+	case 0:
+		return fmt.Errorf("validation error: Secret ID is missing")
+	}
+
+	ref, errRef := parseSecretRef(args[1])
+	if errRef != nil {
+		return errRef
+	}
+
+	return e.app.SecretService.RestoreSecret(ref)
+}
+
+// purgeSecret - is executor for "purge-secret" case in Execute method.
+func (e *Executor) purgeSecret(args []string) error {
+	switch len(args) - 1 {
+	case 0:
+		return fmt.Errorf("validation error: Secret ID is missing")
+	}
+
+	ref, errRef := parseSecretRef(args[1])
+	if errRef != nil {
+		return errRef
+	}
+
+	return e.app.SecretService.PurgeSecret(ref)
+}
+
+// history - is executor for "history" case in Execute method.
+func (e *Executor) history(args []string) ([]*pb.SecretVersion, error) {
+	switch len(args) - 1 {
+	case 0:
+		return nil, fmt.Errorf("validation error: Secret ID is missing")
+	}
+
+	ref, errRef := e.secretRef(args[1])
+	if errRef != nil {
+		return nil, errRef
+	}
+
+	return e.app.SecretService.ListSecretVersions(ref)
+}
+
+// showVersion - is executor for "show-version" case in Execute method.
+func (e *Executor) showVersion(args []string) (model.Secret, error) {
+	ref, version, err := e.parseSecretVersionArgs(args)
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	return e.app.SecretService.GetSecretVersion(ref, version)
+}
+
+// restore - is executor for "restore" case in Execute method.
+func (e *Executor) restore(args []string) error {
+	ref, version, err := e.parseSecretVersionArgs(args)
+	if err != nil {
+		return err
+	}
+
+	return e.app.SecretService.RestoreSecretVersion(ref, version)
+}
+
+// diffVersions - is executor for "diff-versions" case in Execute method.
+func (e *Executor) diffVersions(args []string) (string, error) {
+	if len(args)-1 < 3 {
+		return "", fmt.Errorf("validation error: Secret ID and versions to compare are missing")
+	}
+
+	ref, from, err := e.parseSecretVersionArgs(args[:3])
+	if err != nil {
+		return "", err
+	}
+
+	to, errConv := strconv.ParseInt(args[3], 10, 64)
+	if errConv != nil {
+		return "", errConv
+	}
+
+	older, err := e.app.SecretService.GetSecretVersion(ref, from)
+	if err != nil {
+		return "", err
+	}
+
+	newer, err := e.app.SecretService.GetSecretVersion(ref, to)
+	if err != nil {
+		return "", err
+	}
+
+	olderType, err := e.app.SecretService.SecretType(strconv.Itoa(older.RecordType))
+	if err != nil {
+		return "", err
+	}
+
+	newerType, err := e.app.SecretService.SecretType(strconv.Itoa(newer.RecordType))
+	if err != nil {
+		return "", err
+	}
+
+	fields, err := merge.Compare(olderType, older, newerType, newer)
+	if err != nil {
+		return "", err
+	}
+
+	return merge.FormatChanges(fields, fmt.Sprintf("version %d", from), fmt.Sprintf("version %d", to)), nil
+}
+
+// buildDraft - builds a secret of type t from title, custom fields, URIs and values of its fields, which are passed
+// in order of the schema of the type. Multiline field, which goes last, takes all remaining values.
+func buildDraft(
+	t model.SecretType, ref model.SecretRef, title string, values []string, customFields []model.CustomField,
+	uris []model.LoginURI,
+) (model.Secret, error) {
+	draft := model.Secret{
+		Id:           ref.Id,
+		PublicId:     ref.PublicId,
+		Title:        title,
+		RecordType:   t.Id,
+		Fields:       make(map[string]string, len(t.Fields)),
+		CustomFields: customFields,
+		URIs:         uris,
+	}
+
+	for i, f := range t.Fields {
+		if i >= len(values) {
+			break
+		}
+
+		if i == len(t.Fields)-1 && f.Kind == model.SecretFieldKindMultiline {
+			draft.Fields[f.Name] = strings.Join(values[i:], " ")
+			break
+		}
+
+		draft.Fields[f.Name] = values[i]
+	}
+
+	if err := t.Validate(draft); err != nil {
+		return model.Secret{}, err
+	}
+
+	return draft, nil
+}
+
+// secretContent - returns content of a secret of type t, which is sent to the server.
+//
+// Content of a binary secret is a file, which path is a value of file field, content of other secrets is JSON.
+func secretContent(t model.SecretType, secret model.Secret) (string, error) {
+	for _, f := range t.Fields {
+		if f.Kind != model.SecretFieldKindFile {
+			continue
+		}
+
+		if len(secret.CustomFields) > 0 {
+			return "", fmt.Errorf("validation error: custom fields are not supported by %s secrets", t.Title)
+		}
+
+		data, err := ioutil.ReadFile(secret.Fields[f.Name])
+		if err != nil {
+			return "", err
+		}
+
+		return string(data), nil
+	}
+
+	content, err := json.Marshal(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// printSecret - prints a secret with labels of fields of its type, its folder and tags.
+func (e *Executor) printSecret(secret model.Secret, reveal bool) error {
+	t, err := e.app.SecretService.SecretType(strconv.Itoa(secret.RecordType))
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(formatSecret(t, secret, reveal))
+
+	if secret.FolderId != 0 {
+		fmt.Printf("Folder: %v\n", e.app.Storage.GetFolders().Path(secret.FolderId))
+	}
+	if len(secret.TagIds) > 0 {
+		fmt.Printf("Tags: %v\n", strings.Join(e.tagNames(secret.TagIds), ", "))
+	}
+
+	return nil
+}
+
+// formatSecret - returns printable secret of type t, values of sensitive fields are masked unless reveal is set.
+func formatSecret(t model.SecretType, secret model.Secret, reveal bool) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "ID: %v PublicID: %v Type: %v Version: %v UpdatedAt: %v\n",
+		secret.Id, secret.PublicId, t.Title, secret.Version, secret.UpdatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Title: %v\n", secret.Title)
+
+	for _, f := range t.Fields {
+		value := secret.Fields[f.Name]
+		if f.Sensitive && !reveal && value != "" {
+			value = sensitiveMask
+		}
+
+		fmt.Fprintf(&b, "%v: %v\n", f.Label, value)
+	}
+
+	for _, u := range secret.URIs {
+		fmt.Fprintf(&b, "URI [%v]: %v\n", u.Match, u.URI)
+	}
+
+	for _, f := range secret.CustomFields {
+		value := f.Value
+		if f.IsSensitive() && !reveal {
+			value = sensitiveMask
+		}
+
+		if f.Kind == model.CustomFieldKindTOTP {
+			if code, err := f.TOTPCode(time.Now()); err == nil {
+				value = fmt.Sprintf("%v (code %v)", value, code)
+			}
+		}
+
+		fmt.Fprintf(&b, "%v [%v]: %v\n", f.Name, f.Kind, value)
+	}
+
+	return b.String()
+}
+
+// formatSecretType - returns printable secret type with schema of its fields.
+func formatSecretType(t model.SecretType) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "ID: %v Title: %v\n", t.Id, t.Title)
+	for _, f := range t.Fields {
+		attrs := []string{f.Kind}
+		if f.Required {
+			attrs = append(attrs, "required")
+		}
+		if f.Sensitive {
+			attrs = append(attrs, "sensitive")
+		}
+		if f.Pattern != "" {
+			attrs = append(attrs, "pattern "+f.Pattern)
+		}
+
+		fmt.Fprintf(&b, "  %v (%v): %v\n", f.Label, f.Name, strings.Join(attrs, ", "))
+	}
+
+	return b.String()
+}
+
+// parseSecretRef - parses secret identifier from command args, which could be either numeric ID or public UUID.
+func parseSecretRef(arg string) (model.SecretRef, error) {
+	if id, err := strconv.Atoi(arg); err == nil {
+		return model.SecretRef{Id: id}, nil
+	}
+
+	if publicId, err := uuid.Parse(arg); err == nil {
+		return model.SecretRef{PublicId: publicId.String()}, nil
+	}
+
+	return model.SecretRef{}, fmt.Errorf("validation error: Secret ID must be a number or UUID")
+}
+
+// secretRef - resolves secret identifier from command args, which could be numeric ID, public UUID, "folder/title"
+// path or a prefix of a title of a secret. If several secrets match, then user picks one of them.
+func (e *Executor) secretRef(arg string) (model.SecretRef, error) {
+	if ref, err := parseSecretRef(arg); err == nil {
+		return ref, nil
+	}
+
+	found, err := e.app.SecretService.FindSecrets(arg)
+	if err != nil {
+		return model.SecretRef{}, err
+	}
+
+	switch len(found) {
+	case 0:
+		return model.SecretRef{}, fmt.Errorf("secret not found: %s", arg)
+	case 1:
+		return model.SecretRef{Id: found[0].Id, PublicId: found[0].PublicId}, nil
+	}
+
+	picked, errPick := secretPicker{folders: e.app.Storage.GetFolders()}.Pick(found)
+	if errPick != nil {
+		return model.SecretRef{}, errPick
+	}
+
+	return model.SecretRef{Id: picked.Id, PublicId: picked.PublicId}, nil
+}
+
+// parseSecretVersionArgs - parses secret identifier and its version from command args.
+func (e *Executor) parseSecretVersionArgs(args []string) (model.SecretRef, int64, error) {
+	switch len(args) - 1 {
+	case 0:
+		return model.SecretRef{}, 0, fmt.Errorf("validation error: Secret ID and Version is missing")
+	case 1:
+		return model.SecretRef{}, 0, fmt.Errorf("validation error: Version is missing")
+	}
+
+	ref, errRef := e.secretRef(args[1])
+	if errRef != nil {
+		return model.SecretRef{}, 0, errRef
+	}
+
+	version, errConv := strconv.ParseInt(args[2], 10, 64)
+	if errConv != nil {
+		return model.SecretRef{}, 0, errConv
+	}
+
+	return ref, version, nil
+}
+
+// commandOption - is an option of a command passed as "--name" or "--name=value".
+type commandOption struct {
+	name  string
+	value string
+}
+
+// commandOptions - are options of a command in order they were passed, option could be repeated.
+type commandOptions []commandOption
+
+// Values - returns values of all occurrences of option in order they were passed.
+func (o commandOptions) Values(name string) []string {
+	var values []string
+	for _, opt := range o {
+		if opt.name == name {
+			values = append(values, opt.value)
+		}
+	}
+
+	return values
+}
+
+// Has - reports whether option was passed.
+func (o commandOptions) Has(name string) bool {
+	for _, opt := range o {
+		if opt.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getCommandArgsAndOptions - splits args to command args and options
+func getCommandArgsAndOptions(s string) ([]string, commandOptions) {
+	s = strings.TrimSpace(s)
+
+	setCommand := strings.Split(s, " ")
 
 	l := len(setCommand)
 
 	filtered := make([]string, 0, l)
-	options := make(map[string]bool)
+	var options commandOptions
 
 	for i := 0; i < len(setCommand); i++ {
 		if strings.HasPrefix(setCommand[i], "-") {
 			opt := strings.TrimPrefix(setCommand[i], "--")
 			opt = strings.TrimPrefix(opt, "-")
 
-			optSplited := strings.Split(opt, "=")
-			options[optSplited[0]] = true
+			optSplited := strings.SplitN(opt, "=", 2)
+			option := commandOption{name: optSplited[0]}
+			if len(optSplited) > 1 {
+				option.value = optSplited[1]
+			}
+			options = append(options, option)
 
 			continue
 		}
@@ -609,3 +1257,237 @@ func getCommandArgsAndOptions(s string) ([]string, map[string]bool) {
 
 	return filtered, options
 }
+
+// parseCustomFields - parses custom fields from repeated "--field name[:kind]=value" and "--hidden-field name=value"
+// options in order they were passed, kind is text by default. Field with empty value is removed on edit.
+func parseCustomFields(options commandOptions) ([]model.CustomField, error) {
+	var fields []model.CustomField
+
+	for _, opt := range options {
+		if opt.name != "field" && opt.name != "hidden-field" {
+			continue
+		}
+
+		nameAndKind, value, ok := strings.Cut(opt.value, "=")
+		if !ok {
+			return nil, fmt.Errorf("validation error: --%s has to be passed as name=value", opt.name)
+		}
+
+		field := model.CustomField{Name: nameAndKind, Kind: model.CustomFieldKindText, Value: value}
+		if opt.name == "hidden-field" {
+			field.Kind = model.CustomFieldKindHidden
+		} else if name, kind, hasKind := strings.Cut(nameAndKind, ":"); hasKind {
+			field.Name, field.Kind = name, kind
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// parseURIs - parses URIs of a login secret from repeated "--uri=uri" and "--uri:match=uri" options in order they
+// were passed, URI is matched by domain by default. Returned URIs are nil if no option was passed and empty if only
+// "--uri=" was passed, which removes URIs on edit.
+func parseURIs(options commandOptions) []model.LoginURI {
+	var uris []model.LoginURI
+
+	for _, opt := range options {
+		name, match, hasMatch := strings.Cut(opt.name, ":")
+		if name != "uri" {
+			continue
+		}
+
+		if uris == nil {
+			uris = []model.LoginURI{}
+		}
+		if opt.value == "" {
+			continue
+		}
+
+		if !hasMatch {
+			match = model.URIMatchDomain
+		}
+		uris = append(uris, model.LoginURI{URI: opt.value, Match: match})
+	}
+
+	return uris
+}
+
+// audit - is executor for "audit" case in Execute method.
+func (e *Executor) audit(args []string) ([]*pb.AuditEvent, error) {
+	next := len(args) > 1 && args[1] == "next"
+	if len(args) > 1 && !next {
+		return nil, fmt.Errorf("validation error: unknown argument %q, expected \"next\"", args[1])
+	}
+
+	return e.app.AuditService.List(next)
+}
+
+// mkdir - is executor for "mkdir" case in Execute method, missing parent folders are created as well.
+func (e *Executor) mkdir(args []string) error {
+	if len(args)-1 < 1 {
+		return fmt.Errorf("validation error: Folder path is missing")
+	}
+
+	_, err := e.app.FolderService.Mkdir(args[1])
+
+	return err
+}
+
+// move - is executor for "mv" case in Execute method, secret is moved to root by "/" path.
+func (e *Executor) move(args []string) error {
+	switch len(args) - 1 {
+	case 0:
+		return fmt.Errorf("validation error: Secret ID and Folder path is missing")
+	case 1:
+		return fmt.Errorf("validation error: Folder path is missing")
+	}
+
+	ref, errRef := e.secretRef(args[1])
+	if errRef != nil {
+		return errRef
+	}
+
+	folder, errFolder := e.app.FolderService.Folder(args[2])
+	if errFolder != nil {
+		return errFolder
+	}
+
+	return e.app.SecretService.MoveSecret(ref, folder.Id)
+}
+
+// tag - is executor for "tag" case in Execute method, which adds passed tags to a secret and removes ones passed by
+// "--remove=name" options. Missing tags are created.
+//
+// If there are no tags to add or remove, then names of tags of a secret are returned.
+func (e *Executor) tag(args []string, options commandOptions) ([]string, error) {
+	if len(args)-1 < 1 {
+		return nil, fmt.Errorf("validation error: Secret ID is missing")
+	}
+
+	ref, errRef := e.secretRef(args[1])
+	if errRef != nil {
+		return nil, errRef
+	}
+
+	current, errCurrent := e.app.SecretService.SecretTagIds(ref)
+	if errCurrent != nil {
+		return nil, errCurrent
+	}
+
+	removedNames := options.Values("remove")
+	if len(args) == 2 && len(removedNames) == 0 {
+		return e.tagNames(current), nil
+	}
+
+	added, errAdded := e.app.FolderService.Tags(args[2:], true)
+	if errAdded != nil {
+		return nil, errAdded
+	}
+
+	removed, errRemoved := e.app.FolderService.Tags(removedNames, false)
+	if errRemoved != nil {
+		return nil, errRemoved
+	}
+
+	isRemoved := make(map[int]bool, len(removed))
+	for _, t := range removed {
+		isRemoved[t.Id] = true
+	}
+
+	var tagIds []int
+	for _, id := range current {
+		if !isRemoved[id] {
+			tagIds = append(tagIds, id)
+		}
+	}
+	for _, t := range added {
+		if !isRemoved[t.Id] {
+			tagIds = append(tagIds, t.Id)
+		}
+	}
+
+	return nil, e.app.SecretService.SetSecretTags(ref, tagIds)
+}
+
+// ls - is executor for "ls" case in Execute method, which prints folders and secrets of a folder, root by default.
+//
+// Secrets could be filtered by "--tag=name" option, in which case secrets of all folders are printed unless folder
+// is passed.
+func (e *Executor) ls(args []string, options commandOptions) error {
+	path := model.FolderPathSeparator
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	folder, errFolder := e.app.FolderService.Folder(path)
+	if errFolder != nil {
+		return errFolder
+	}
+
+	var tagId int
+	if names := options.Values("tag"); len(names) > 0 {
+		tags, errTags := e.app.FolderService.Tags(names[len(names)-1:], false)
+		if errTags != nil {
+			return errTags
+		}
+
+		tagId = tags[0].Id
+	}
+
+	anyFolder := tagId != 0 && len(args) == 1
+
+	list, err := e.app.SecretService.ListSecrets(folder.Id, !anyFolder && folder.Id == 0, tagId)
+	if err != nil {
+		return err
+	}
+
+	folders := e.app.Storage.GetFolders()
+	if !anyFolder {
+		for _, f := range folders {
+			if f.ParentId == folder.Id {
+				fmt.Printf("%v%v\n", folders.Path(f.Id), model.FolderPathSeparator)
+			}
+		}
+	}
+
+	for _, secret := range list {
+		line := fmt.Sprintf("ID:%v PublicID: %v Title: %v", secret.Id, secret.PublicId, secret.Title)
+		if anyFolder {
+			line += fmt.Sprintf(" Folder: %v", folders.Path(int(secret.FolderId)))
+		}
+		if len(secret.TagIds) > 0 {
+			line += fmt.Sprintf(" Tags: %v", strings.Join(e.tagNames(model.NewTagIds(secret.TagIds)), ", "))
+		}
+
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// tagNames - returns names of tags by their ids, tags unknown to memory storage are named by their ids.
+func (e *Executor) tagNames(ids []int) []string {
+	tags := e.app.Storage.GetTags()
+
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name := "#" + strconv.Itoa(id)
+		for _, t := range tags {
+			if t.Id == id {
+				name = t.Name
+				break
+			}
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// FolderPaths - returns paths of folders of logged user, which are known to memory storage.
+func (e *Executor) FolderPaths() []string {
+	return e.app.Storage.GetFolders().Paths()
+}
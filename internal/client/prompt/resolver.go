@@ -0,0 +1,113 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+
+	"github.com/sergalkin/gophkeeper/internal/client/merge"
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+)
+
+// errPickAborted - is returned, when user aborts picking of a secret.
+var errPickAborted = errors.New("secret picking is aborted")
+
+var _ merge.Resolver = (*conflictResolver)(nil)
+
+// conflictResolver - resolves conflicting fields of edited secret by asking user.
+type conflictResolver struct{}
+
+// Resolve - asks user to keep local or server value of conflicting field, or to enter a new one. Value of a field,
+// which has several lines, such as URIs, is entered line by line until an empty one.
+func (r conflictResolver) Resolve(f merge.Field) (string, error) {
+	fmt.Printf("conflict in %s: local %q, server %q\n", f.Name, f.Local, f.Server)
+
+	for {
+		choice := prompt.Input("keep [l]ocal, [s]erver, [e]nter new value or [a]bort: ", r.complete)
+
+		switch strings.TrimSpace(choice) {
+		case "l", "local":
+			return f.Local, nil
+		case "s", "server":
+			return f.Server, nil
+		case "e", "enter":
+			return r.enter(f), nil
+		case "a", "abort":
+			return "", merge.ErrAborted
+		}
+	}
+}
+
+// enter - asks user to enter a new value of a field, multi-line value is entered until an empty line.
+func (r conflictResolver) enter(f merge.Field) string {
+	noSuggest := func(prompt.Document) []prompt.Suggest { return nil }
+	if !strings.Contains(f.Local, "\n") && !strings.Contains(f.Server, "\n") {
+		return prompt.Input(f.Name+": ", noSuggest)
+	}
+
+	fmt.Printf("enter %s line by line, finish with an empty line\n", f.Name)
+
+	var lines []string
+	for {
+		line := prompt.Input(f.Name+": ", noSuggest)
+		if strings.TrimSpace(line) == "" {
+			return strings.Join(lines, "\n")
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+// complete - a list of suggestions for conflict resolution.
+func (r conflictResolver) complete(d prompt.Document) []prompt.Suggest {
+	s := []prompt.Suggest{
+		{Text: "local", Description: "Keep value typed locally"},
+		{Text: "server", Description: "Keep value stored on the server"},
+		{Text: "enter", Description: "Enter new value"},
+		{Text: "abort", Description: "Abort merge and keep draft"},
+	}
+
+	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+}
+
+// secretPicker - picks one of several secrets matching a reference by asking user.
+type secretPicker struct {
+	folders model.Folders
+}
+
+// Pick - prints matching secrets with their paths and asks user to choose one by its number.
+func (p secretPicker) Pick(secrets []model.Secret) (model.Secret, error) {
+	fmt.Println("several secrets match:")
+	for i, secret := range secrets {
+		fmt.Printf("[%d] %s ID: %d\n", i+1, p.folders.SecretPath(secret), secret.Id)
+	}
+
+	for {
+		choice := prompt.Input(fmt.Sprintf("choose secret [1-%d] or [a]bort: ", len(secrets)), p.complete(secrets))
+
+		choice = strings.TrimSpace(choice)
+		if choice == "a" || choice == "abort" {
+			return model.Secret{}, errPickAborted
+		}
+
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(secrets) {
+			return secrets[n-1], nil
+		}
+	}
+}
+
+// complete - returns a completer suggesting numbers of secrets with their paths.
+func (p secretPicker) complete(secrets []model.Secret) prompt.Completer {
+	s := make([]prompt.Suggest, 0, len(secrets)+1)
+	for i, secret := range secrets {
+		s = append(s, prompt.Suggest{Text: strconv.Itoa(i + 1), Description: p.folders.SecretPath(secret)})
+	}
+	s = append(s, prompt.Suggest{Text: "abort", Description: "Abort command"})
+
+	return func(d prompt.Document) []prompt.Suggest {
+		return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+	}
+}
@@ -0,0 +1,447 @@
+// Package search is a package that provides full-text search over decrypted secrets kept by the client.
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+)
+
+// Scopes of indexed values, which are not named after fields. Fields of secret types and custom fields are indexed
+// under their lowercase names as well, e.g. "login".
+const (
+	ScopeTitle  = "title"
+	ScopeNotes  = "notes"
+	ScopeURL    = "url"
+	ScopeCustom = "custom"
+)
+
+// scopeTypeFilter - is a scope of query terms, which filter secrets by type instead of matching indexed values.
+const scopeTypeFilter = "type"
+
+// scopeBoosts - are weights of matches in scopes, matches in other scopes have weight 1.
+var scopeBoosts = map[string]float64{ScopeTitle: 3, "login": 2, ScopeURL: 2}
+
+// Weights of matches of a query term with an indexed term.
+const (
+	exactWeight  = 1
+	prefixWeight = 0.75
+	fuzzyWeight  = 0.5
+)
+
+// Term - is a term of a query, which matches values of Scope only, or values of every scope if Scope is empty.
+type Term struct {
+	Scope string
+	Text  string
+}
+
+// Query - is a parsed search query, secrets have to match every term and, if Types are set, one of the types.
+type Query struct {
+	Terms []Term
+	// Types - are keys of secret types, see model.SecretType.Matches.
+	Types []string
+}
+
+// ParseQuery - parses query of space separated words. A word could be prefixed by a scope, e.g. "login:alice", or
+// "type:card" to filter secrets by type.
+func ParseQuery(query string) Query {
+	var q Query
+	for _, word := range strings.Fields(query) {
+		scope, text := "", word
+		if i := strings.Index(word, ":"); i > 0 && !strings.HasPrefix(word[i+1:], "//") {
+			scope, text = strings.ToLower(word[:i]), word[i+1:]
+		}
+
+		if scope == scopeTypeFilter {
+			q.Types = append(q.Types, text)
+			continue
+		}
+
+		for _, token := range Tokenize(text) {
+			q.Terms = append(q.Terms, Term{Scope: scope, Text: token})
+		}
+	}
+
+	return q
+}
+
+// Tokenize - splits text into lowercase terms of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Result - is a secret matching a query, higher Score is a better match. Scopes are scopes of matched values.
+type Result struct {
+	Secret model.Secret
+	Score  float64
+	Scopes []string
+}
+
+// document - is an indexed secret with its distinct terms, which are used to remove it from postings.
+type document struct {
+	secret model.Secret
+	terms  []string
+}
+
+// Index - is an inverted index of values of secrets. Sensitive fields, such as passwords, are not indexed.
+//
+// Index is not safe for concurrent use.
+type Index struct {
+	// postings - are counts of a term in scopes of values of secrets by term and id of a secret.
+	postings map[string]map[int]map[string]int
+	docs     map[int]document
+}
+
+// NewIndex - creates new empty Index.
+func NewIndex() *Index {
+	return &Index{postings: make(map[string]map[int]map[string]int), docs: make(map[int]document)}
+}
+
+// Update - makes index match passed secrets of types: secrets, which are new or whose version is changed, are
+// re-indexed, missing ones are removed and the rest are kept as they are, so index is not rebuilt on every sync.
+func (idx *Index) Update(types []model.SecretType, secrets []model.Secret) {
+	byID := make(map[int]model.SecretType, len(types))
+	for _, t := range types {
+		byID[t.Id] = t
+	}
+
+	present := make(map[int]bool, len(secrets))
+	for _, secret := range secrets {
+		present[secret.Id] = true
+
+		if doc, ok := idx.docs[secret.Id]; ok && doc.secret.Version == secret.Version &&
+			doc.secret.RecordType == secret.RecordType {
+			// folder and tags are changed without change of version and are not indexed
+			doc.secret = secret
+			idx.docs[secret.Id] = doc
+
+			continue
+		}
+
+		idx.remove(secret.Id)
+		idx.add(byID[secret.RecordType], secret)
+	}
+
+	for id := range idx.docs {
+		if !present[id] {
+			idx.remove(id)
+		}
+	}
+}
+
+// Len - returns number of indexed secrets.
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// add - indexes values of a secret of type t.
+func (idx *Index) add(t model.SecretType, secret model.Secret) {
+	var terms []string
+	for _, v := range values(t, secret) {
+		for _, term := range Tokenize(v.text) {
+			docs, ok := idx.postings[term]
+			if !ok {
+				docs = make(map[int]map[string]int)
+				idx.postings[term] = docs
+			}
+
+			scopes, ok := docs[secret.Id]
+			if !ok {
+				scopes = make(map[string]int)
+				docs[secret.Id] = scopes
+				terms = append(terms, term)
+			}
+
+			for _, scope := range v.scopes {
+				scopes[scope]++
+			}
+		}
+	}
+
+	idx.docs[secret.Id] = document{secret: secret, terms: terms}
+}
+
+// remove - removes a secret with id from index.
+func (idx *Index) remove(id int) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+
+	delete(idx.docs, id)
+}
+
+// value - is an indexed text with scopes it's matched in.
+type value struct {
+	scopes []string
+	text   string
+}
+
+// values - returns indexed values of a secret of type t: title, fields of the type, custom fields, except of
+// sensitive and file ones, and URIs.
+func values(t model.SecretType, secret model.Secret) []value {
+	list := []value{{scopes: []string{ScopeTitle}, text: secret.Title}}
+
+	for _, f := range t.Fields {
+		if f.Sensitive || f.Kind == model.SecretFieldKindFile {
+			continue
+		}
+
+		scopes := []string{strings.ToLower(f.Name)}
+		if f.Kind == model.SecretFieldKindMultiline {
+			scopes = append(scopes, ScopeNotes)
+		}
+
+		list = append(list, value{scopes: scopes, text: secret.Fields[f.Name]})
+	}
+
+	for _, f := range secret.CustomFields {
+		if f.IsSensitive() {
+			continue
+		}
+
+		scopes := []string{strings.ToLower(f.Name), ScopeCustom}
+		if f.Kind == model.CustomFieldKindURL {
+			scopes = append(scopes, ScopeURL)
+		}
+
+		list = append(list, value{scopes: scopes, text: f.Value})
+	}
+
+	for _, u := range secret.URIs {
+		list = append(list, value{scopes: []string{ScopeURL}, text: u.URI})
+	}
+
+	return list
+}
+
+// Search - returns secrets matching query q ordered by score and then by title.
+//
+// A query term matches indexed terms, which are equal to it, start with it or, for terms of 4 and more letters,
+// differ from it by 1 edit (2 edits for 8 and more letters). Exact matches and matches in title, login and url are
+// ranked higher. Type keys of query are resolved among types, unknown type is an error.
+func (idx *Index) Search(q Query, types []model.SecretType) ([]Result, error) {
+	typeIDs, err := resolveTypes(q.Types, types)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[int]*Result)
+	for id, doc := range idx.docs {
+		if typeIDs == nil || typeIDs[doc.secret.RecordType] {
+			results[id] = &Result{Secret: doc.secret}
+		}
+	}
+
+	for _, term := range q.Terms {
+		scores, scopes := idx.match(term)
+
+		for id, r := range results {
+			score, ok := scores[id]
+			if !ok {
+				delete(results, id)
+				continue
+			}
+
+			r.Score += score
+			r.Scopes = appendScopes(r.Scopes, scopes[id])
+		}
+	}
+
+	list := make([]Result, 0, len(results))
+	for _, r := range results {
+		sort.Strings(r.Scopes)
+		list = append(list, *r)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		if list[i].Secret.Title != list[j].Secret.Title {
+			return list[i].Secret.Title < list[j].Secret.Title
+		}
+
+		return list[i].Secret.Id < list[j].Secret.Id
+	})
+
+	return list, nil
+}
+
+// match - returns the best score of a query term for each secret matching it with scopes of matched values.
+func (idx *Index) match(term Term) (map[int]float64, map[int][]string) {
+	scores := make(map[int]float64)
+	scopes := make(map[int][]string)
+
+	for indexed, docs := range idx.postings {
+		weight := termWeight(term.Text, indexed)
+		if weight == 0 {
+			continue
+		}
+
+		for id, counts := range docs {
+			for scope, count := range counts {
+				if term.Scope != "" && term.Scope != scope {
+					continue
+				}
+
+				boost, ok := scopeBoosts[scope]
+				if !ok {
+					boost = 1
+				}
+
+				if score := weight * boost * (1 + math.Log(float64(count))); score > scores[id] {
+					scores[id] = score
+				}
+				scopes[id] = appendScopes(scopes[id], []string{scope})
+			}
+		}
+	}
+
+	return scores, scopes
+}
+
+// termWeight - returns weight of a match of a query term with an indexed one, 0 means they don't match.
+func termWeight(query, indexed string) float64 {
+	if query == indexed {
+		return exactWeight
+	}
+
+	if strings.HasPrefix(indexed, query) {
+		return prefixWeight
+	}
+
+	edits := maxEdits(query)
+	if edits == 0 {
+		return 0
+	}
+
+	if d := distance(query, indexed, edits); d <= edits {
+		return fuzzyWeight / float64(d)
+	}
+
+	return 0
+}
+
+// maxEdits - returns number of edits, which are tolerated for a query term.
+func maxEdits(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// distance - returns number of insertions, deletions, substitutions and transpositions of adjacent letters, which
+// turn a into b, any distance greater than limit is returned as limit+1.
+func distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	// rows of distances between prefixes of a of length i-2, i-1, i and prefixes of b
+	prev2, prev, cur := make([]int, len(rb)+1), make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+
+			if cur[j] < rowMin {
+				rowMin = cur[j]
+			}
+		}
+
+		if rowMin > limit {
+			return limit + 1
+		}
+
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+// minInt - returns the least of values.
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+// appendScopes - appends scopes to list, skipping ones, which are already there.
+func appendScopes(list []string, scopes []string) []string {
+	for _, scope := range scopes {
+		found := false
+		for _, s := range list {
+			if s == scope {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			list = append(list, scope)
+		}
+	}
+
+	return list
+}
+
+// resolveTypes - returns ids of types referenced by keys, or nil if there are no keys.
+func resolveTypes(keys []string, types []model.SecretType) (map[int]bool, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	ids := make(map[int]bool, len(keys))
+	for _, key := range keys {
+		found := false
+		for _, t := range types {
+			if t.Matches(key) {
+				ids[t.Id], found = true, true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("validation error: unknown secret type %s", key)
+		}
+	}
+
+	return ids, nil
+}
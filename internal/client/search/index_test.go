@@ -0,0 +1,128 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+)
+
+var types = []model.SecretType{
+	{Id: 1, Title: "login/pass", Fields: []model.SecretTypeField{
+		{Name: "Login", Label: "Login", Kind: model.SecretFieldKindText},
+		{Name: "Password", Label: "Password", Kind: model.SecretFieldKindText, Sensitive: true},
+	}},
+	{Id: 2, Title: "text", Fields: []model.SecretTypeField{
+		{Name: "Text", Label: "Text", Kind: model.SecretFieldKindMultiline},
+	}},
+}
+
+var secrets = []model.Secret{
+	{
+		Id: 1, Title: "Github", RecordType: 1, Version: 1,
+		Fields: map[string]string{"Login": "alice@example.com", "Password": "secret"},
+		CustomFields: []model.CustomField{
+			{Name: "Site", Kind: model.CustomFieldKindURL, Value: "https://github.com/login"},
+		},
+	},
+	{
+		Id: 2, Title: "Mail", RecordType: 1, Version: 1,
+		Fields: map[string]string{"Login": "bob", "Password": "github"},
+		URIs:   []model.LoginURI{{URI: "https://mail.example.org", Match: model.URIMatchDomain}},
+	},
+	{
+		Id: 3, Title: "Recovery codes", RecordType: 2, Version: 1,
+		Fields: map[string]string{"Text": "codes of github account of alice"},
+	},
+}
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery("login:Alice@example type:text https://github.com mail")
+
+	assert.Equal(t, []Term{
+		{Scope: "login", Text: "alice"},
+		{Scope: "login", Text: "example"},
+		{Text: "https"},
+		{Text: "github"},
+		{Text: "com"},
+		{Text: "mail"},
+	}, q.Terms)
+	assert.Equal(t, []string{"text"}, q.Types)
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Update(types, secrets)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []int
+		wantErr bool
+	}{
+		{name: "Title is ranked higher than notes", query: "github", want: []int{1, 3}},
+		{name: "Sensitive fields are not indexed", query: "secret", want: []int{}},
+		{name: "Field scope", query: "login:alice", want: []int{1}},
+		{name: "Notes scope", query: "notes:alice", want: []int{3}},
+		{name: "URL of custom field", query: "url:github.com", want: []int{1}},
+		{name: "URI of login secret", query: "url:mail.example", want: []int{2}},
+		{name: "Prefix", query: "recov", want: []int{3}},
+		{name: "Fuzzy", query: "gihtub", want: []int{1, 3}},
+		{name: "Short terms are not fuzzy", query: "bib", want: []int{}},
+		{name: "Every term has to match", query: "alice codes", want: []int{3}},
+		{name: "Type filter", query: "alice type:text", want: []int{3}},
+		{name: "Type filter only", query: "type:1", want: []int{1, 2}},
+		{name: "Unknown type", query: "type:card", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := idx.Search(ParseQuery(tt.query), types)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			ids := make([]int, 0, len(results))
+			for _, r := range results {
+				ids = append(ids, r.Secret.Id)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestIndex_Update(t *testing.T) {
+	idx := NewIndex()
+	idx.Update(types, secrets)
+	require.Equal(t, 3, idx.Len())
+
+	renamed := secrets[1]
+	renamed.Title, renamed.Version, renamed.URIs = "Postbox", 2, nil
+	moved := secrets[0]
+	moved.FolderId = 5
+
+	idx.Update(types, []model.Secret{moved, renamed})
+	assert.Equal(t, 2, idx.Len())
+
+	results, err := idx.Search(ParseQuery("postbox"), types)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Secret.Id)
+
+	results, err = idx.Search(ParseQuery("mail"), types)
+	require.NoError(t, err)
+	assert.Empty(t, results, "terms of previous version are removed")
+
+	results, err = idx.Search(ParseQuery("login:alice"), types)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 5, results[0].Secret.FolderId, "kept secret is replaced")
+	assert.Equal(t, []string{"login"}, results[0].Scopes)
+
+	results, err = idx.Search(ParseQuery("codes"), types)
+	require.NoError(t, err)
+	assert.Empty(t, results, "missing secret is removed")
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+)
+
+// ErrNoMoreAuditEvents - is returned when next page of audit events is requested after the last one.
+var ErrNoMoreAuditEvents = errors.New("there are no more audit events")
+
+// auditPageSize - is a number of audit events requested per page.
+const auditPageSize = 20
+
+type AuditClientService struct {
+	glCtx  *model.GlobalContext
+	client pb.AuditClient
+
+	nextPageToken string
+}
+
+// NewAuditClientService - creates new AuditClientService.
+func NewAuditClientService(glCtx *model.GlobalContext, client pb.AuditClient) *AuditClientService {
+	return &AuditClientService{
+		glCtx:  glCtx,
+		client: client,
+	}
+}
+
+// List - returns the latest page of audit events of logged user if next is false, otherwise the page following
+// previously returned one.
+func (a *AuditClientService) List(next bool) ([]*pb.AuditEvent, error) {
+	request := &pb.ListAuditEventsRequest{PageSize: auditPageSize}
+
+	if next {
+		if a.nextPageToken == "" {
+			return nil, ErrNoMoreAuditEvents
+		}
+
+		request.PageToken = a.nextPageToken
+	}
+
+	result, err := a.client.ListAuditEvents(a.glCtx.Ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	a.nextPageToken = result.NextPageToken
+
+	return result.Events, nil
+}
+
+// HasNext - returns true if there is a page following previously returned one.
+func (a *AuditClientService) HasNext() bool {
+	return a.nextPageToken != ""
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+	"github.com/sergalkin/gophkeeper/internal/client/storage"
+	"github.com/sergalkin/gophkeeper/pkg/crypt"
+)
+
+type FolderClientService struct {
+	glCtx   *model.GlobalContext
+	client  pb.FolderClient
+	storage storage.Memorier
+	crypt   crypt.Crypter
+	syncer  storage.Syncer
+}
+
+// NewFolderClientService - creates new FolderClientService.
+func NewFolderClientService(
+	glCtx *model.GlobalContext, client pb.FolderClient, st storage.Memorier, cr crypt.Crypter, sr storage.Syncer,
+) *FolderClientService {
+	return &FolderClientService{
+		glCtx:   glCtx,
+		client:  client,
+		storage: st,
+		crypt:   cr,
+		syncer:  sr,
+	}
+}
+
+// Mkdir - creates a folder by its path on the server with all missing parent folders and then makes re-sync of
+// folders in memory storage.
+//
+// Names of folders are encrypted before they are sent to the server.
+func (f *FolderClientService) Mkdir(path string) (model.Folder, error) {
+	names, err := model.SplitFolderPath(path)
+	if err != nil {
+		return model.Folder{}, err
+	}
+
+	if len(names) == 0 {
+		return model.Folder{}, errors.New("validation error: Folder path is missing")
+	}
+
+	folders := f.storage.GetFolders()
+
+	var folder model.Folder
+	var created bool
+	for _, name := range names {
+		if child, ok := folders.Child(folder.Id, name); ok {
+			folder = child
+			continue
+		}
+
+		result, errCreate := f.client.CreateFolder(f.glCtx.Ctx, &pb.CreateFolderRequest{
+			ParentId: uint32(folder.Id),
+			Name:     []byte(f.crypt.Encode(name)),
+		})
+		if errCreate != nil {
+			return model.Folder{}, errCreate
+		}
+
+		folder = model.Folder{Id: int(result.Folder.Id), ParentId: folder.Id, Name: name}
+		folders = append(folders, folder)
+		created = true
+	}
+
+	if !created {
+		return folder, fmt.Errorf("folder %s already exists", folders.Path(folder.Id))
+	}
+
+	fmt.Printf("created folder %s with ID: %d\n", folders.Path(folder.Id), folder.Id)
+
+	return folder, f.syncer.SyncFolders()
+}
+
+// Folder - returns a folder by its path, root is returned as a model.Folder with zero Id.
+//
+// Folders are taken from memory storage, which is re-synced if folder is not found there, so folders created by
+// other clients after the last sync are found as well.
+func (f *FolderClientService) Folder(path string) (model.Folder, error) {
+	if _, err := model.SplitFolderPath(path); err != nil {
+		return model.Folder{}, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if folder, ok := f.storage.GetFolders().Find(path); ok {
+			return folder, nil
+		}
+
+		if attempt == 0 {
+			if err := f.syncer.SyncFolders(); err != nil {
+				return model.Folder{}, err
+			}
+		}
+	}
+
+	return model.Folder{}, fmt.Errorf("unknown folder: %s", path)
+}
+
+// Tags - returns tags by their names, missing tags are created on the server if create is set, otherwise unknown
+// tag results in error.
+func (f *FolderClientService) Tags(names []string, create bool) ([]model.Tag, error) {
+	var tags []model.Tag
+	var missing []string
+	for _, name := range names {
+		tag, ok := model.FindTag(f.storage.GetTags(), name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if len(missing) == 0 {
+		return tags, nil
+	}
+
+	if err := f.syncer.SyncFolders(); err != nil {
+		return nil, err
+	}
+
+	var created bool
+	for _, name := range missing {
+		if tag, ok := model.FindTag(f.storage.GetTags(), name); ok {
+			tags = append(tags, tag)
+			continue
+		}
+
+		if !create {
+			return nil, fmt.Errorf("unknown tag: %s", name)
+		}
+
+		result, err := f.client.CreateTag(f.glCtx.Ctx, &pb.CreateTagRequest{Name: []byte(f.crypt.Encode(name))})
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("created tag %s with ID: %d\n", name, result.Tag.Id)
+
+		tags = append(tags, model.Tag{Id: int(result.Tag.Id), Name: name})
+		created = true
+	}
+
+	if created {
+		return tags, f.syncer.SyncFolders()
+	}
+
+	return tags, nil
+}
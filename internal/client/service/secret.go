@@ -5,12 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"time"
 
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	pb "github.com/sergalkin/gophkeeper/api/proto"
 	"github.com/sergalkin/gophkeeper/internal/client/model"
+	"github.com/sergalkin/gophkeeper/internal/client/search"
 	"github.com/sergalkin/gophkeeper/internal/client/storage"
 	"github.com/sergalkin/gophkeeper/pkg/crypt"
 )
@@ -36,8 +42,11 @@ func NewSecretClientService(
 	}
 }
 
-// GetListOfSecretes - attempts to return list of secrets from memory, if nothing is found then makes gRPC request
-// to server.
+// listFields - are fields of secrets requested to list them, content is not requested.
+var listFields = []string{"id", "public_id", "type_id", "title", "folder_id", "tag_ids"}
+
+// GetListOfSecretes - attempts to return list of secrets from memory, if nothing is found then requests list of
+// secrets of the type from server page by page without their content.
 func (s *SecretClientService) GetListOfSecretes(id int) ([]*pb.SecretList, error) {
 	var list []*pb.SecretList
 	list = s.storage.GetSecretList(id)
@@ -45,22 +54,29 @@ func (s *SecretClientService) GetListOfSecretes(id int) ([]*pb.SecretList, error
 		return list, nil
 	}
 
-	result, err := s.client.GetListOfSecretsByType(s.glCtx.Ctx, &pb.GetListOfSecretsByTypeRequest{TypeId: uint32(id)})
+	list, err := storage.ListSecrets(s.glCtx.Ctx, s.client, &pb.ListSecretsRequest{
+		TypeId: uint32(id), Fields: &fieldmaskpb.FieldMask{Paths: listFields},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return result.SecretLists, nil
+	return s.decryptTitles(list), nil
 }
 
 // GetBinarySecret - get binary data from server and stores it into file.
-func (s *SecretClientService) GetBinarySecret(id int, location string) error {
-	res, err := s.client.GetSecret(s.glCtx.Ctx, &pb.GetSecretRequest{Id: int32(id)})
+func (s *SecretClientService) GetBinarySecret(ref model.SecretRef, location string) error {
+	res, err := s.client.GetSecret(s.glCtx.Ctx, &pb.GetSecretRequest{Id: int32(ref.Id), PublicId: ref.PublicId})
 	if err != nil {
 		return err
 	}
 
-	if res.Type != 3 {
+	t, errType := s.SecretType(strconv.Itoa(int(res.Type)))
+	if errType != nil {
+		return errType
+	}
+
+	if !t.IsBinary() {
 		return errors.New("this method only works with binary data, please appropriate method next time")
 	}
 
@@ -85,64 +101,234 @@ func (s *SecretClientService) GetBinarySecret(id int, location string) error {
 	return nil
 }
 
-// GetSecret - attempts to get secret from memory by its id, if nothing is found then makes gRPC request to server.
-func (s *SecretClientService) GetSecret(id int) (interface{}, error) {
-	data, ok := s.storage.FindInStorage(id)
+// GetSecret - attempts to get secret from memory by its id or public id, if nothing is found then makes gRPC request
+// to server.
+func (s *SecretClientService) GetSecret(ref model.SecretRef) (model.Secret, error) {
+	data, ok := s.storage.FindInStorage(ref)
 
 	if ok {
-		//fmt.Printf("Content:%+v\n", data)
 		return data, nil
 	}
 
-	result, err := s.client.GetSecret(s.glCtx.Ctx, &pb.GetSecretRequest{Id: int32(id)})
+	return s.GetServerSecret(ref)
+}
+
+// FindSecrets - returns secrets matching query, which is either "folder/title" path or an unambiguous prefix of
+// a title, see model.Folders.FindSecrets.
+//
+// Secrets are searched in memory storage. Binary secrets are not kept there, so if nothing is found, then secrets
+// with exactly the same title are looked up on the server by blind index of the title, and if there are none, then
+// list of binary secrets is requested from server and searched as well.
+func (s *SecretClientService) FindSecrets(query string) ([]model.Secret, error) {
+	folders := s.storage.GetFolders()
+	if found := folders.FindSecrets(s.storage.GetSecrets(), query); len(found) > 0 {
+		return found, nil
+	}
+
+	exact, err := storage.ListSecrets(s.glCtx.Ctx, s.client, &pb.ListSecretsRequest{
+		TitleHash: s.titleHash(query), Fields: &fieldmaskpb.FieldMask{Paths: listFields},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if result.Type == 3 {
-		return nil, errors.New("to get binary data, pleas use proper method")
+	if len(exact) > 0 {
+		found := make([]model.Secret, 0, len(exact))
+		for _, secret := range s.decryptTitles(exact) {
+			found = append(found, model.Secret{
+				Id:         int(secret.Id),
+				PublicId:   secret.PublicId,
+				Title:      secret.Title,
+				RecordType: int(secret.TypeId),
+				FolderId:   int(secret.FolderId),
+				TagIds:     model.NewTagIds(secret.TagIds),
+			})
+		}
+
+		return found, nil
+	}
+
+	var binaries []model.Secret
+	for _, t := range s.storage.GetSecretTypes() {
+		if !t.IsBinary() {
+			continue
+		}
+
+		list, err := s.GetListOfSecretes(t.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, secret := range list {
+			binaries = append(binaries, model.Secret{
+				Id:         int(secret.Id),
+				PublicId:   secret.PublicId,
+				Title:      secret.Title,
+				RecordType: t.Id,
+				FolderId:   int(secret.FolderId),
+				TagIds:     model.NewTagIds(secret.TagIds),
+			})
+		}
+	}
+
+	return folders.FindSecrets(binaries, query), nil
+}
+
+// Search - returns secrets from memory storage, which match query, ranked by relevance, see search.ParseQuery for
+// syntax of query. Binary secrets are not kept in memory storage, so they are not searched.
+func (s *SecretClientService) Search(query string) ([]search.Result, error) {
+	q := search.ParseQuery(query)
+	if len(q.Terms) == 0 && len(q.Types) == 0 {
+		return nil, fmt.Errorf("validation error: Query is missing")
+	}
+
+	return s.storage.Search(q)
+}
+
+// FindLogins - returns login secrets from memory storage, which URIs match URL, ranked by specificity, see
+// model.FindLogins.
+func (s *SecretClientService) FindLogins(url string) ([]model.LoginMatch, error) {
+	return model.FindLogins(s.storage.GetSecrets(), url)
+}
+
+// GetServerSecret - makes gRPC request to server and returns decoded secret, bypassing memory storage.
+func (s *SecretClientService) GetServerSecret(ref model.SecretRef) (model.Secret, error) {
+	result, err := s.client.GetSecret(s.glCtx.Ctx, &pb.GetSecretRequest{Id: int32(ref.Id), PublicId: ref.PublicId})
+	if err != nil {
+		return model.Secret{}, err
 	}
 
-	decoded, errDecode := s.crypt.Decode(string(result.Content))
+	secret, errDecode := s.decodeSecret(
+		result.Type, result.Content, model.SecretRef{Id: int(result.Id), PublicId: result.PublicId}, result.Version,
+		result.UpdatedAt.AsTime(),
+	)
 	if errDecode != nil {
-		return nil, errDecode
+		return model.Secret{}, errDecode
 	}
+	secret.FolderId, secret.TagIds = int(result.FolderId), model.NewTagIds(result.TagIds)
+
+	return secret, nil
+}
 
-	var m interface{}
-	switch result.Type {
-	case 1:
-		m = model.LoginPassSecret{}
-	case 2:
-		m = model.TextSecret{}
-	case 4:
-		m = model.CardSecret{}
+// SecretType - returns secret type by its ID or title.
+//
+// Types are taken from memory storage, which is re-synced if type is not found there, so types added on the server
+// after the last sync are found as well.
+func (s *SecretClientService) SecretType(key string) (model.SecretType, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		for _, t := range s.storage.GetSecretTypes() {
+			if t.Matches(key) {
+				return t, nil
+			}
+		}
+
+		if attempt == 0 {
+			if err := s.syncer.SyncTypes(); err != nil {
+				return model.SecretType{}, err
+			}
+		}
 	}
 
-	errUnmarshal := json.Unmarshal([]byte(decoded), &m)
-	if errUnmarshal != nil {
-		return nil, errUnmarshal
+	return model.SecretType{}, fmt.Errorf("unknown secret type: %s", key)
+}
+
+// ListSecretVersions - makes gRPC request to server and returns versions of a secret without their content.
+func (s *SecretClientService) ListSecretVersions(ref model.SecretRef) ([]*pb.SecretVersion, error) {
+	result, err := s.client.ListSecretVersions(
+		s.glCtx.Ctx, &pb.ListSecretVersionsRequest{Id: uint32(ref.Id), PublicId: ref.PublicId},
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("Content:%+v\n", m)
+	for _, v := range result.Versions {
+		v.Title = s.decryptTitle(v.Title)
+	}
 
-	return m, nil
+	return result.Versions, nil
+}
+
+// GetSecretVersion - makes gRPC request to server and returns decoded version of a secret.
+func (s *SecretClientService) GetSecretVersion(ref model.SecretRef, version int64) (model.Secret, error) {
+	result, err := s.client.GetSecretVersion(
+		s.glCtx.Ctx, &pb.GetSecretVersionRequest{Id: uint32(ref.Id), PublicId: ref.PublicId, Version: version},
+	)
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	return s.decodeSecret(
+		result.Version.Type, result.Version.Content,
+		model.SecretRef{Id: int(result.Version.SecretId), PublicId: result.Version.PublicId}, result.Version.Version,
+		result.Version.ChangedAt.AsTime(),
+	)
+}
+
+// RestoreSecretVersion - restores secret on the server to provided version and then makes re-sync memory storage.
+func (s *SecretClientService) RestoreSecretVersion(ref model.SecretRef, version int64) error {
+	result, err := s.client.RestoreSecretVersion(
+		s.glCtx.Ctx, &pb.RestoreSecretVersionRequest{Id: uint32(ref.Id), PublicId: ref.PublicId, Version: version},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored secret to version %d, current version is %d\n", version, result.Version)
+
+	s.syncer.SyncAll()
+
+	return nil
+}
+
+// decodeSecret - decrypts content of a secret and unmarshalls it into a model.Secret of provided type.
+func (s *SecretClientService) decodeSecret(
+	typeID uint32, content []byte, ref model.SecretRef, version int64, updatedAt time.Time,
+) (model.Secret, error) {
+	t, err := s.SecretType(strconv.Itoa(int(typeID)))
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	if t.IsBinary() {
+		return model.Secret{}, errors.New("to get binary data, pleas use proper method")
+	}
+
+	decoded, errDecode := s.crypt.Decode(string(content))
+	if errDecode != nil {
+		return model.Secret{}, errDecode
+	}
+
+	secret := model.Secret{}
+	if err = json.Unmarshal([]byte(decoded), &secret); err != nil {
+		return model.Secret{}, err
+	}
+	secret.Id, secret.PublicId, secret.RecordType = ref.Id, ref.PublicId, t.Id
+	secret.Version, secret.UpdatedAt = version, updatedAt
+
+	return secret, nil
 }
 
 // CreateSecret - creates new secret on the server and then makes re-sync memory storage.
+//
+// Public ID of a secret is generated on the client, so repeated request with the same secret is not creating
+// a duplicate on the server.
 func (s *SecretClientService) CreateSecret(title string, recordType int, content string) error {
 	contentT := []byte(s.crypt.Encode(content))
+	titleT, titleHash := s.encryptTitle(title)
 
 	result, err := s.client.CreateSecret(s.glCtx.Ctx, &pb.CreateSecretRequest{
-		Title:   title,
-		Type:    uint32(recordType),
-		Content: contentT,
+		PublicId:  uuid.NewString(),
+		Title:     titleT,
+		Type:      uint32(recordType),
+		Content:   contentT,
+		TitleHash: titleHash,
 	})
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("created new secret with ID:", result.Id)
+	fmt.Printf("created new secret with ID: %d, public ID: %s\n", result.Id, result.PublicId)
 
 	s.syncer.SyncAll()
 
@@ -150,13 +336,13 @@ func (s *SecretClientService) CreateSecret(title string, recordType int, content
 }
 
 // DeleteSecret - deletes a secrete from server and then makes re-sync memory storage.
-func (s *SecretClientService) DeleteSecret(id int) error {
-	_, err := s.client.DeleteSecret(s.glCtx.Ctx, &pb.DeleteSecretRequest{Id: uint32(id)})
+func (s *SecretClientService) DeleteSecret(ref model.SecretRef) error {
+	_, err := s.client.DeleteSecret(s.glCtx.Ctx, &pb.DeleteSecretRequest{Id: uint32(ref.Id), PublicId: ref.PublicId})
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("successfully deleted secret")
+	fmt.Println("successfully moved secret to trash")
 
 	s.storage.ResetStorage()
 	s.syncer.SyncAll()
@@ -164,33 +350,285 @@ func (s *SecretClientService) DeleteSecret(id int) error {
 	return nil
 }
 
-// EditSecret - edits secret on the server and then makes re-sync memory storage.
-func (s *SecretClientService) EditSecret(id int, title string, recordType int, content string, isForce bool) error {
-	var updatedAt time.Time
-	localSecret, _ := s.GetSecret(id)
+// batchSize - is a number of secrets sent to the server in a batch request.
+const batchSize = 100
+
+// CreateSecrets - creates secrets on the server with batch requests and then makes re-sync memory storage once.
+//
+// Returned errors are in order of drafts, nil for created secrets. If a batch request fails, then errors of already
+// sent batches are returned with its error.
+func (s *SecretClientService) CreateSecrets(drafts []model.SecretDraft) ([]error, error) {
+	defer s.syncer.SyncAll()
+
+	errs := make([]error, 0, len(drafts))
+	for start := 0; start < len(drafts); start += batchSize {
+		end := start + batchSize
+		if end > len(drafts) {
+			end = len(drafts)
+		}
+
+		req := &pb.BatchCreateSecretsRequest{Secrets: make([]*pb.CreateSecretRequest, 0, end-start)}
+		for _, draft := range drafts[start:end] {
+			titleT, titleHash := s.encryptTitle(draft.Title)
+			req.Secrets = append(req.Secrets, &pb.CreateSecretRequest{
+				PublicId:  uuid.NewString(),
+				Title:     titleT,
+				Type:      uint32(draft.RecordType),
+				Content:   []byte(s.crypt.Encode(draft.Content)),
+				TitleHash: titleHash,
+			})
+		}
+
+		result, err := s.client.BatchCreateSecrets(s.glCtx.Ctx, req)
+		if err != nil {
+			return errs, err
+		}
+
+		for _, r := range result.Results {
+			errs = append(errs, batchItemError(r.Status))
+		}
+	}
+
+	return errs, nil
+}
+
+// DeleteSecrets - moves secrets to trash on the server with batch requests and then makes re-sync memory storage
+// once.
+//
+// Returned errors are in order of refs, nil for deleted secrets. If a batch request fails, then errors of already
+// sent batches are returned with its error.
+func (s *SecretClientService) DeleteSecrets(refs []model.SecretRef) ([]error, error) {
+	defer s.syncer.SyncAll()
+
+	errs := make([]error, 0, len(refs))
+	for start := 0; start < len(refs); start += batchSize {
+		end := start + batchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		req := &pb.BatchDeleteSecretsRequest{Secrets: make([]*pb.DeleteSecretRequest, 0, end-start)}
+		for _, ref := range refs[start:end] {
+			req.Secrets = append(req.Secrets, &pb.DeleteSecretRequest{Id: uint32(ref.Id), PublicId: ref.PublicId})
+		}
+
+		result, err := s.client.BatchDeleteSecrets(s.glCtx.Ctx, req)
+		if err != nil {
+			return errs, err
+		}
+
+		for _, r := range result.Results {
+			errs = append(errs, batchItemError(r.Status))
+		}
+	}
+
+	return errs, nil
+}
+
+// batchItemError - returns error of an item of a batch request, nil if it succeeded.
+func batchItemError(st *pb.BatchItemStatus) error {
+	if codes.Code(st.GetCode()) == codes.OK {
+		return nil
+	}
+
+	return status.Error(codes.Code(st.GetCode()), st.GetMessage())
+}
+
+// ListTrash - makes gRPC request to server and returns list of secrets, which are in trash.
+func (s *SecretClientService) ListTrash() ([]*pb.SecretList, error) {
+	result, err := s.client.ListTrash(s.glCtx.Ctx, &pb.ListTrashRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decryptTitles(result.Secrets), nil
+}
+
+// RestoreSecret - moves a secret out of trash on the server and then makes re-sync memory storage.
+func (s *SecretClientService) RestoreSecret(ref model.SecretRef) error {
+	_, err := s.client.RestoreSecret(s.glCtx.Ctx, &pb.RestoreSecretRequest{Id: uint32(ref.Id), PublicId: ref.PublicId})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("successfully restored secret")
+
+	s.syncer.SyncAll()
+
+	return nil
+}
+
+// ListSecrets - makes gRPC requests to server and returns list of secrets without content, which are not in trash,
+// of a folder with folderId and with a tag with tagId, sorted by title.
+//
+// Secrets of any folder are returned if folderId is 0 and inRoot is false, tag is ignored if tagId is 0. Titles are
+// encrypted on the server, so secrets are sorted after they are decrypted.
+func (s *SecretClientService) ListSecrets(folderId int, inRoot bool, tagId int) ([]*pb.SecretList, error) {
+	list, err := storage.ListSecrets(s.glCtx.Ctx, s.client, &pb.ListSecretsRequest{
+		FolderId: uint32(folderId),
+		InRoot:   inRoot,
+		TagId:    uint32(tagId),
+		Fields:   &fieldmaskpb.FieldMask{Paths: listFields},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list = s.decryptTitles(list)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Title < list[j].Title
+	})
+
+	return list, nil
+}
+
+// MoveSecret - moves a secret to a folder with folderId on the server and then makes re-sync memory storage, secret
+// is moved to root if folderId is 0.
+func (s *SecretClientService) MoveSecret(ref model.SecretRef, folderId int) error {
+	_, err := s.client.MoveSecret(s.glCtx.Ctx, &pb.MoveSecretRequest{
+		Id:       uint32(ref.Id),
+		PublicId: ref.PublicId,
+		FolderId: uint32(folderId),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("successfully moved secret")
+
+	s.syncer.SyncAll()
+
+	return nil
+}
+
+// SecretTagIds - returns ids of tags of a secret from memory storage, if secret is not found there then makes gRPC
+// request to server.
+func (s *SecretClientService) SecretTagIds(ref model.SecretRef) ([]int, error) {
+	if localSecret, ok := s.storage.FindInStorage(ref); ok {
+		return localSecret.TagIds, nil
+	}
+
+	// binary secrets are not kept in memory, so their tags are taken from the server
+	result, err := s.client.GetSecret(s.glCtx.Ctx, &pb.GetSecretRequest{Id: int32(ref.Id), PublicId: ref.PublicId})
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewTagIds(result.TagIds), nil
+}
+
+// SetSecretTags - replaces tags of a secret on the server and then makes re-sync memory storage.
+func (s *SecretClientService) SetSecretTags(ref model.SecretRef, tagIds []int) error {
+	ids := make([]uint32, 0, len(tagIds))
+	for _, id := range tagIds {
+		ids = append(ids, uint32(id))
+	}
 
-	switch localSecret.(type) {
-	case model.TextSecret:
-		updatedAt = localSecret.(model.TextSecret).UpdatedAt
-	case model.FileSecret:
-		updatedAt = localSecret.(model.FileSecret).UpdatedAt
-	case model.LoginPassSecret:
-		updatedAt = localSecret.(model.LoginPassSecret).UpdatedAt
-	case model.CardSecret:
-		updatedAt = localSecret.(model.CardSecret).UpdatedAt
-	default:
-		panic("unknown type")
+	_, err := s.client.SetSecretTags(s.glCtx.Ctx, &pb.SetSecretTagsRequest{
+		Id:       uint32(ref.Id),
+		PublicId: ref.PublicId,
+		TagIds:   ids,
+	})
+	if err != nil {
+		return err
 	}
+
+	fmt.Println("successfully updated tags of secret")
+
+	s.syncer.SyncAll()
+
+	return nil
+}
+
+// PurgeSecret - permanently deletes a secret, which is in trash, from server.
+func (s *SecretClientService) PurgeSecret(ref model.SecretRef) error {
+	_, err := s.client.PurgeSecret(s.glCtx.Ctx, &pb.PurgeSecretRequest{Id: uint32(ref.Id), PublicId: ref.PublicId})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("successfully purged secret")
+
+	return nil
+}
+
+// EditSecret - edits secret on the server, if local copy of secret has the same version as the server one, and then
+// makes re-sync memory storage.
+func (s *SecretClientService) EditSecret(
+	ref model.SecretRef, title string, recordType int, content string, isForce bool,
+) error {
+	version, err := s.secretVersion(ref)
+	if err != nil {
+		return err
+	}
+
+	return s.EditSecretVersion(ref, title, recordType, content, version, isForce)
+}
+
+// RenameSecret - changes title of a secret on the server, keeping its content, if local copy of secret has the same
+// version as the server one, and then makes re-sync memory storage.
+func (s *SecretClientService) RenameSecret(ref model.SecretRef, title string, isForce bool) error {
+	version, err := s.secretVersion(ref)
+	if err != nil {
+		return err
+	}
+
+	titleT, titleHash := s.encryptTitle(title)
+
+	_, err = s.client.EditSecret(s.glCtx.Ctx, &pb.EditSecretRequest{
+		Id:         uint32(ref.Id),
+		PublicId:   ref.PublicId,
+		Title:      titleT,
+		IsForce:    isForce,
+		Version:    version,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+		TitleHash:  titleHash,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("successfully renamed secret")
+
+	s.syncer.SyncAll()
+
+	return nil
+}
+
+// secretVersion - returns version of a secret from memory storage, if secret is not found there then makes gRPC
+// request to server.
+func (s *SecretClientService) secretVersion(ref model.SecretRef) (int64, error) {
+	if localSecret, ok := s.storage.FindInStorage(ref); ok {
+		return localSecret.Version, nil
+	}
+
+	// binary secrets are not kept in memory, so their version is taken from the server
+	result, err := s.client.GetSecret(s.glCtx.Ctx, &pb.GetSecretRequest{Id: int32(ref.Id), PublicId: ref.PublicId})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Version, nil
+}
+
+// EditSecretVersion - edits secret on the server, if server version of secret is equal to provided one, and then
+// makes re-sync memory storage.
+func (s *SecretClientService) EditSecretVersion(
+	ref model.SecretRef, title string, recordType int, content string, version int64, isForce bool,
+) error {
 	contentT := []byte(s.crypt.Encode(content))
+	titleT, titleHash := s.encryptTitle(title)
 
 	_, err := s.client.EditSecret(
 		s.glCtx.Ctx, &pb.EditSecretRequest{
-			Id:        uint32(id),
-			Title:     title,
+			Id:        uint32(ref.Id),
+			PublicId:  ref.PublicId,
+			Title:     titleT,
 			Type:      uint32(recordType),
 			Content:   contentT,
-			UpdatedAt: timestamppb.New(updatedAt),
 			IsForce:   isForce,
+			Version:   version,
+			TitleHash: titleHash,
 		},
 	)
 	if err != nil {
@@ -203,3 +641,38 @@ func (s *SecretClientService) EditSecret(id int, title string, recordType int, c
 
 	return nil
 }
+
+// encryptTitle - returns encrypted title and its blind index, which lets server find secrets by title without
+// knowing it. Empty title is returned as is, server keeps stored title then.
+func (s *SecretClientService) encryptTitle(title string) (string, []byte) {
+	if title == "" {
+		return "", nil
+	}
+
+	return s.crypt.Encode(title), s.titleHash(title)
+}
+
+// titleHash - returns blind index of a title, see model.IndexedTitle.
+func (s *SecretClientService) titleHash(title string) []byte {
+	return s.crypt.BlindIndex(model.IndexedTitle(title))
+}
+
+// decryptTitle - returns decrypted title, titles stored before they were encrypted are returned as is until they are
+// encrypted by Sync.
+func (s *SecretClientService) decryptTitle(title string) string {
+	decoded, err := s.crypt.Decode(title)
+	if err != nil {
+		return title
+	}
+
+	return decoded
+}
+
+// decryptTitles - replaces titles of secrets of list with decrypted ones.
+func (s *SecretClientService) decryptTitles(list []*pb.SecretList) []*pb.SecretList {
+	for _, secret := range list {
+		secret.Title = s.decryptTitle(secret.Title)
+	}
+
+	return list
+}
@@ -18,8 +18,8 @@ func NewSecretTypeClientService(glCtx *model.GlobalContext, client pb.SecretType
 	}
 }
 
-// List - returns secret types list from server.
-func (s *SecretTypeClientService) List() (*pb.SecretTypesListResponse, error) {
+// List - returns secret types list with schemas of their fields from server.
+func (s *SecretTypeClientService) List() ([]model.SecretType, error) {
 	request := &pb.SecretTypesListRequest{}
 
 	result, err := s.client.GetSecretTypesList(s.glCtx.Ctx, request)
@@ -27,5 +27,10 @@ func (s *SecretTypeClientService) List() (*pb.SecretTypesListResponse, error) {
 		return nil, err
 	}
 
-	return result, nil
+	types := make([]model.SecretType, 0, len(result.Secrets))
+	for _, t := range result.Secrets {
+		types = append(types, model.NewSecretType(t))
+	}
+
+	return types, nil
 }
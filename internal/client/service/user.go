@@ -1,27 +1,40 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+
 	"google.golang.org/grpc/metadata"
 
 	pb "github.com/sergalkin/gophkeeper/api/proto"
 	"github.com/sergalkin/gophkeeper/internal/client/model"
+	"github.com/sergalkin/gophkeeper/pkg/crypt"
 )
 
 type UserClientService struct {
 	glCtx  *model.GlobalContext
 	client pb.UserClient
+	crypt  *crypt.UserCrypt
+	// login - is a login of authorized user, key of a vault is wrapped by it along with password.
+	login string
 }
 
-// NewUserClientService - creates new UserClientService.
-func NewUserClientService(glCtx *model.GlobalContext, client pb.UserClient) *UserClientService {
+// NewUserClientService - creates new UserClientService, which unlocks crypt by key of a vault of a user on login.
+func NewUserClientService(glCtx *model.GlobalContext, client pb.UserClient, cr *crypt.UserCrypt) *UserClientService {
 	return &UserClientService{
 		glCtx:  glCtx,
 		client: client,
+		crypt:  cr,
 	}
 }
 
 // Login - authorizes a user by provided login and password. On successful authorization adds authorization token
-// to metadata in global shared context.
+// to metadata in global shared context and unlocks crypt by key of a vault of the user unwrapped by login and
+// password.
+//
+// User registered before vault keys has none, so key derived from its login and password, which its data is encrypted
+// by, is wrapped and becomes key of its vault. If key of the vault can't be unwrapped, e.g. password was reset by
+// admin, the user stays logged out.
 func (u *UserClientService) Login(user model.User) error {
 	request := &pb.LoginRequest{
 		Login:    user.Login,
@@ -35,24 +48,92 @@ func (u *UserClientService) Login(user model.User) error {
 
 	u.glCtx.Ctx = metadata.AppendToOutgoingContext(u.glCtx.Ctx, "authorization", "Bearer "+result.Token)
 
+	wrapped := result.VaultKey
+	if len(wrapped) == 0 {
+		wrapped, err = u.setVaultKey(user)
+	}
+	if err == nil {
+		err = u.crypt.Unlock(user.Login, user.Password, wrapped)
+	}
+	if err != nil {
+		u.Logout()
+
+		if errors.Is(err, crypt.ErrVaultKey) {
+			return errors.New("vault can't be opened by the password, e.g. the password was reset by admin")
+		}
+
+		return fmt.Errorf("crypt unlocking error: %w", err)
+	}
+
+	u.login = user.Login
+
 	return nil
 }
 
-// Register - creates a new user on server. On successful creation adds authorization token to metadata in global
-// shared context.
+// setVaultKey - wraps key derived from login and password of a user registered before vault keys and sets it as key
+// of its vault, then returns wrapped key kept by server, which is a key set by another client meanwhile, if there is
+// one.
+func (u *UserClientService) setVaultKey(user model.User) ([]byte, error) {
+	wrapped, err := crypt.WrapVaultKey(user.Login, user.Password, crypt.DerivedVaultKey(user.Login, user.Password))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.client.SetVaultKey(u.glCtx.Ctx, &pb.SetVaultKeyRequest{VaultKey: wrapped})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.VaultKey, nil
+}
+
+// Register - creates a new user on server along with random key of its vault wrapped by login and password. On
+// successful creation adds authorization token to metadata in global shared context and unlocks crypt by the key.
 func (u *UserClientService) Register(user model.User) error {
-	result, err := u.client.Register(u.glCtx.Ctx, &pb.RegisterRequest{Login: user.Login, Password: user.Password})
+	vaultKey, err := crypt.NewVaultKey()
 	if err != nil {
 		return err
 	}
 
+	wrapped, err := crypt.WrapVaultKey(user.Login, user.Password, vaultKey)
+	if err != nil {
+		return err
+	}
+
+	result, err := u.client.Register(u.glCtx.Ctx, &pb.RegisterRequest{
+		Login: user.Login, Password: user.Password, VaultKey: wrapped,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = u.crypt.Unlock(user.Login, user.Password, wrapped); err != nil {
+		return fmt.Errorf("crypt unlocking error: %w", err)
+	}
+
 	u.glCtx.Ctx = metadata.AppendToOutgoingContext(u.glCtx.Ctx, "authorization", "Bearer "+result.Token)
+	u.login = user.Login
 
 	return nil
 }
 
+// ChangePassword - replaces password of authorized user, key of its vault is wrapped by the new password, so data of
+// the user stays readable.
+func (u *UserClientService) ChangePassword(password, newPassword string) error {
+	wrapped, err := u.crypt.Rewrap(u.login, newPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.client.ChangePassword(u.glCtx.Ctx, &pb.ChangePasswordRequest{
+		Password: password, NewPassword: newPassword, VaultKey: wrapped,
+	})
+
+	return err
+}
+
 // Delete - deletes a user from server. On successful deletion, removes authorization token from metadata in global
-// shared context.
+// shared context and locks crypt.
 func (u *UserClientService) Delete() error {
 	_, err := u.client.Delete(u.glCtx.Ctx, &pb.DeleteRequest{})
 	if err != nil {
@@ -60,11 +141,15 @@ func (u *UserClientService) Delete() error {
 	}
 
 	u.glCtx.Ctx = metadata.NewOutgoingContext(u.glCtx.Ctx, metadata.MD{})
+	u.crypt.Lock()
+	u.login = ""
 
 	return nil
 }
 
-// Logout -  removes authorization token from metadata in global shared context.
+// Logout -  removes authorization token from metadata in global shared context and locks crypt.
 func (u *UserClientService) Logout() {
 	u.glCtx.Ctx = metadata.NewOutgoingContext(u.glCtx.Ctx, metadata.MD{})
+	u.crypt.Lock()
+	u.login = ""
 }
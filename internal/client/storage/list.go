@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+)
+
+// listPageSize - is a number of secrets requested in a page.
+const listPageSize = 500
+
+// ListSecrets - makes gRPC requests to server page by page and returns secrets selected by req from every page.
+//
+// Page size and token of req are overwritten.
+func ListSecrets(ctx context.Context, client pb.SecretClient, req *pb.ListSecretsRequest) ([]*pb.SecretList, error) {
+	var secrets []*pb.SecretList
+
+	req.PageSize, req.PageToken = listPageSize, ""
+	for {
+		resp, err := client.ListSecrets(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, resp.Secrets...)
+		if resp.NextPageToken == "" {
+			return secrets, nil
+		}
+
+		req.PageToken = resp.NextPageToken
+	}
+}
@@ -1,177 +1,171 @@
 package storage
 
 import (
-	"errors"
 	"sync"
 
 	"github.com/sergalkin/gophkeeper/api/proto"
 	"github.com/sergalkin/gophkeeper/internal/client/model"
+	"github.com/sergalkin/gophkeeper/internal/client/search"
 )
 
 type Memorier interface {
-	GetLoginPassSecret(id int) (model.LoginPassSecret, bool, error)
-	SetLoginPassSecrets([]model.LoginPassSecret)
-	GetCardSecret(id int) (model.CardSecret, bool, error)
-	SetCardSecrets([]model.CardSecret)
-	GetTextSecret(id int) (model.TextSecret, bool, error)
-	SetTextSecrets([]model.TextSecret)
-	FindInStorage(id int) (interface{}, bool)
-	GetSecretList(id int) []*proto.SecretList
+	SetSecretTypes([]model.SecretType)
+	GetSecretTypes() []model.SecretType
+	SetSecrets([]model.Secret)
+	SetFolders([]model.Folder, []model.Tag)
+	GetFolders() model.Folders
+	GetTags() []model.Tag
+	FindInStorage(ref model.SecretRef) (model.Secret, bool)
+	GetSecrets() []model.Secret
+	GetSecretList(typeId int) []*proto.SecretList
+	Search(q search.Query) ([]search.Result, error)
 	ResetStorage()
 }
 
 type MemoryStorage struct {
-	mu               sync.RWMutex
-	LoginPassSecrets map[int]model.LoginPassSecret
-	TextSecrets      map[int]model.TextSecret
-	CardSecrets      map[int]model.CardSecret
+	mu          sync.RWMutex
+	SecretTypes []model.SecretType
+	Secrets     map[int]model.Secret
+	Folders     model.Folders
+	Tags        []model.Tag
+	index       *search.Index
 }
 
 // NewMemoryStorage - creates new MemoryStorage.
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		LoginPassSecrets: make(map[int]model.LoginPassSecret, 0),
-		TextSecrets:      make(map[int]model.TextSecret, 0),
-		CardSecrets:      make(map[int]model.CardSecret, 0),
+		Secrets: make(map[int]model.Secret, 0),
+		index:   search.NewIndex(),
 	}
 }
 
-// GetLoginPassSecret - attempts to return model.LoginPassSecret from MemoryStorage.
-//
-// If record is found, then returns model.LoginPassSecret and true as representation of found record.
-//
-// If nothing is found, then returns empty model.LoginPassSecret and false as representation of not found record.
-func (ms *MemoryStorage) GetLoginPassSecret(id int) (model.LoginPassSecret, bool, error) {
+// SetSecretTypes - replaces secret types kept in MemoryStorage.
+func (ms *MemoryStorage) SetSecretTypes(types []model.SecretType) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	data, ok := ms.LoginPassSecrets[id]
-	if !ok {
-		return model.LoginPassSecret{}, ok, errors.New("Login/Pass not found")
-	}
-
-	return data, ok, nil
+	ms.SecretTypes = append([]model.SecretType(nil), types...)
 }
 
-// SetLoginPassSecrets - Sets []model.LoginPassSecret to MemoryStorage.
-func (ms *MemoryStorage) SetLoginPassSecrets(models []model.LoginPassSecret) {
+// GetSecretTypes - returns secret types kept in MemoryStorage, which is empty until first sync.
+func (ms *MemoryStorage) GetSecretTypes() []model.SecretType {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	for _, m := range models {
-		ms.LoginPassSecrets[m.Id] = m
-	}
-}
-
-// ResetStorage - removes all records from MemoryStorage.
-func (ms *MemoryStorage) ResetStorage() {
-	ms.LoginPassSecrets = make(map[int]model.LoginPassSecret, 0)
-	ms.TextSecrets = make(map[int]model.TextSecret, 0)
-	ms.CardSecrets = make(map[int]model.CardSecret, 0)
+	return append([]model.SecretType(nil), ms.SecretTypes...)
 }
 
-// SetCardSecrets - Sets []model.CardSecret to MemoryStorage.
-func (ms *MemoryStorage) SetCardSecrets(models []model.CardSecret) {
-	ms.mu.RLock()
-	defer ms.mu.RUnlock()
+// SetSecrets - replaces secrets kept in MemoryStorage and updates search index, only new and changed secrets are
+// re-indexed.
+func (ms *MemoryStorage) SetSecrets(models []model.Secret) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
 
+	ms.Secrets = make(map[int]model.Secret, len(models))
 	for _, m := range models {
-		ms.CardSecrets[m.Id] = m
+		ms.Secrets[m.Id] = m
 	}
+
+	ms.index.Update(ms.SecretTypes, models)
 }
 
-// GetCardSecret - attempts to return model.CardSecret from MemoryStorage.
-//
-// If record is found, then returns model.CardSecret and true as representation of found record.
-//
-// If nothing is found, then returns empty model.CardSecret and false as representation of not found record.
-func (ms *MemoryStorage) GetCardSecret(id int) (model.CardSecret, bool, error) {
+// SetFolders - replaces folders and tags kept in MemoryStorage.
+func (ms *MemoryStorage) SetFolders(folders []model.Folder, tags []model.Tag) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	data, ok := ms.CardSecrets[id]
-	if !ok {
-		return model.CardSecret{}, ok, errors.New("card data not found")
-	}
+	ms.Folders = append(model.Folders(nil), folders...)
+	ms.Tags = append([]model.Tag(nil), tags...)
+}
+
+// GetFolders - returns folders kept in MemoryStorage.
+func (ms *MemoryStorage) GetFolders() model.Folders {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
 
-	return data, ok, nil
+	return append(model.Folders(nil), ms.Folders...)
 }
 
-// SetTextSecrets - Sets []model.TextSecret to MemoryStorage.
-func (ms *MemoryStorage) SetTextSecrets(models []model.TextSecret) {
+// GetTags - returns tags kept in MemoryStorage.
+func (ms *MemoryStorage) GetTags() []model.Tag {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	for _, m := range models {
-		ms.TextSecrets[m.Id] = m
-	}
+	return append([]model.Tag(nil), ms.Tags...)
 }
 
-// GetTextSecret - attempts to return model.TextSecret from MemoryStorage.
-//
-// If record is found, then returns model.TextSecret and true as representation of found record.
-//
-// If nothing is found, then returns empty model.TextSecret and false as representation of not found record.
-func (ms *MemoryStorage) GetTextSecret(id int) (model.TextSecret, bool, error) {
+// ResetStorage - removes all records from MemoryStorage.
+func (ms *MemoryStorage) ResetStorage() {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	data, ok := ms.TextSecrets[id]
-	if !ok {
-		return model.TextSecret{}, ok, errors.New("text not found")
-	}
-
-	return data, ok, nil
+	ms.SecretTypes = nil
+	ms.Secrets = make(map[int]model.Secret, 0)
+	ms.Folders, ms.Tags = nil, nil
+	ms.index = search.NewIndex()
 }
 
-// FindInStorage - attempts to find record in MemoryStorage by provided id.
-//
-// If record is found in LoginPassSecrets then returns model.LoginPassSecret and true as representation of found record.
-//
-// If record is found in TextSecrets then returns model.TextSecret and true as representation of found record.
+// FindInStorage - attempts to find record in MemoryStorage by provided model.SecretRef.
 //
-// If record is found in CardSecrets then returns model.CardSecret and true as representation of found record.
+// If ref has no numeric ID, then it is resolved by public ID.
 //
-// Otherwise, returns nil and false as representation of not found record.
-func (ms *MemoryStorage) FindInStorage(id int) (interface{}, bool) {
-	data, ok, _ := ms.GetLoginPassSecret(id)
-	if ok {
-		return data, true
+// If record is found then returns model.Secret and true as representation of found record, otherwise returns empty
+// model.Secret and false.
+func (ms *MemoryStorage) FindInStorage(ref model.SecretRef) (model.Secret, bool) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	if ref.Id != 0 {
+		data, ok := ms.Secrets[ref.Id]
+
+		return data, ok
 	}
 
-	dataCard, okCard, _ := ms.GetCardSecret(id)
-	if okCard {
-		return dataCard, true
+	if ref.PublicId == "" {
+		return model.Secret{}, false
 	}
 
-	textData, okText, _ := ms.GetTextSecret(id)
-	if okText {
-		return textData, true
+	for _, data := range ms.Secrets {
+		if data.PublicId == ref.PublicId {
+			return data, true
+		}
 	}
 
-	return nil, false
+	return model.Secret{}, false
 }
 
-// GetSecretList - goes through all MemoryStorage records and returns []*GetSecretList.
-func (ms *MemoryStorage) GetSecretList(id int) []*proto.SecretList {
-	ms.mu.Lock()
-	defer ms.mu.Unlock()
+// GetSecrets - returns all secrets kept in MemoryStorage.
+func (ms *MemoryStorage) GetSecrets() []model.Secret {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	secrets := make([]model.Secret, 0, len(ms.Secrets))
+	for _, data := range ms.Secrets {
+		secrets = append(secrets, data)
+	}
+
+	return secrets
+}
+
+// GetSecretList - goes through MemoryStorage records of provided type and returns []*GetSecretList.
+func (ms *MemoryStorage) GetSecretList(typeId int) []*proto.SecretList {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
 
 	var list []*proto.SecretList
-	switch id {
-	case 1:
-		for _, data := range ms.LoginPassSecrets {
-			list = append(list, &proto.SecretList{Id: uint32(data.Id), Title: data.Title})
-		}
-	case 2:
-		for _, data := range ms.TextSecrets {
-			list = append(list, &proto.SecretList{Id: uint32(data.Id), Title: data.Title})
-		}
-	case 4:
-		for _, data := range ms.CardSecrets {
-			list = append(list, &proto.SecretList{Id: uint32(data.Id), Title: data.Title})
+	for _, data := range ms.Secrets {
+		if data.RecordType == typeId {
+			list = append(list, &proto.SecretList{Id: uint32(data.Id), PublicId: data.PublicId, Title: data.Title})
 		}
 	}
 
 	return list
 }
+
+// Search - returns secrets kept in MemoryStorage, which match query q, ranked by search.Index.
+func (ms *MemoryStorage) Search(q search.Query) ([]search.Result, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.index.Search(q, ms.SecretTypes)
+}
@@ -3,6 +3,12 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/sergalkin/gophkeeper/api/proto"
 	"github.com/sergalkin/gophkeeper/internal/client/model"
@@ -11,131 +17,274 @@ import (
 
 type Syncer interface {
 	SyncAll()
-	SyncPassLoginData() error
-	SyncCardData() error
-	SyncTextData() error
+	SyncTypes() error
+	SyncFolders() error
+	SyncSecrets() error
 }
 
 type Sync struct {
-	storage      Memorier
-	secretClient pb.SecretClient
-	glCtx        *model.GlobalContext
-	cr           crypt.Crypter
+	storage          Memorier
+	secretClient     pb.SecretClient
+	secretTypeClient pb.SecretTypeClient
+	folderClient     pb.FolderClient
+	glCtx            *model.GlobalContext
+	cr               crypt.Crypter
 }
 
 // NewSync - creates new Sync.
-func NewSync(s Memorier, sc pb.SecretClient, ctx *model.GlobalContext, cr crypt.Crypter) *Sync {
-	return &Sync{storage: s, secretClient: sc, glCtx: ctx, cr: cr}
+func NewSync(
+	s Memorier, sc pb.SecretClient, tc pb.SecretTypeClient, fc pb.FolderClient, ctx *model.GlobalContext,
+	cr crypt.Crypter,
+) *Sync {
+	return &Sync{storage: s, secretClient: sc, secretTypeClient: tc, folderClient: fc, glCtx: ctx, cr: cr}
 }
 
-// SyncAll - runs SyncTextData, SyncPassLoginData, SyncCardData under the hood.
+// SyncAll - runs SyncTypes, SyncFolders and SyncSecrets.
 func (s *Sync) SyncAll() {
-	if err := s.SyncTextData(); err != nil {
+	if err := s.SyncTypes(); err != nil {
 		fmt.Println(err)
+		return
 	}
 
-	if err := s.SyncPassLoginData(); err != nil {
+	if err := s.SyncFolders(); err != nil {
 		fmt.Println(err)
 	}
 
-	if err := s.SyncCardData(); err != nil {
+	if err := s.SyncSecrets(); err != nil {
 		fmt.Println(err)
 	}
 }
 
-// SyncTextData - makes gRPC request to server and on success sets acquired records to MemoryStorage.TextSecrets.
-func (s *Sync) SyncTextData() error {
-	texts, err := s.secretClient.GetListOfSecretsByType(s.glCtx.Ctx, &pb.GetListOfSecretsByTypeRequest{TypeId: 2})
+// SyncTypes - makes gRPC request to server and on success sets acquired secret types to MemoryStorage.
+func (s *Sync) SyncTypes() error {
+	resp, err := s.secretTypeClient.GetSecretTypesList(s.glCtx.Ctx, &pb.SecretTypesListRequest{})
+	if err != nil {
+		return err
+	}
+
+	types := make([]model.SecretType, 0, len(resp.Secrets))
+	for _, t := range resp.Secrets {
+		types = append(types, model.NewSecretType(t))
+	}
+
+	s.storage.SetSecretTypes(types)
+
+	return nil
+}
+
+// SyncFolders - makes gRPC requests to server and on success sets acquired folders and tags with decrypted names to
+// MemoryStorage.
+func (s *Sync) SyncFolders() error {
+	foldersResp, err := s.folderClient.ListFolders(s.glCtx.Ctx, &pb.ListFoldersRequest{})
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	var list []model.TextSecret
-	for _, text := range texts.SecretLists {
-		id := int(text.Id)
-		m := model.TextSecret{}
+	tagsResp, err := s.folderClient.ListTags(s.glCtx.Ctx, &pb.ListTagsRequest{})
+	if err != nil {
+		return err
+	}
 
-		decoded, errDecode := s.cr.Decode(string(text.Content))
+	folders := make([]model.Folder, 0, len(foldersResp.Folders))
+	for _, f := range foldersResp.Folders {
+		name, errDecode := s.cr.Decode(string(f.Name))
 		if errDecode != nil {
 			return errDecode
 		}
 
-		errUnmarshal := json.Unmarshal([]byte(decoded), &m)
-		if errUnmarshal != nil {
-			return errUnmarshal
+		folders = append(folders, model.Folder{Id: int(f.Id), ParentId: int(f.ParentId), Name: name})
+	}
+
+	tags := make([]model.Tag, 0, len(tagsResp.Tags))
+	for _, t := range tagsResp.Tags {
+		name, errDecode := s.cr.Decode(string(t.Name))
+		if errDecode != nil {
+			return errDecode
 		}
-		m.Id = id
-		m.UpdatedAt = text.UpdatedAt.AsTime()
 
-		list = append(list, m)
+		tags = append(tags, model.Tag{Id: int(t.Id), Name: name})
 	}
 
-	s.storage.SetTextSecrets(list)
+	s.storage.SetFolders(folders, tags)
 
 	return nil
 }
 
-// SyncCardData - makes gRPC request to server and on success sets acquired records to MemoryStorage.CardSecrets.
-func (s *Sync) SyncCardData() error {
-	cards, err := s.secretClient.GetListOfSecretsByType(s.glCtx.Ctx, &pb.GetListOfSecretsByTypeRequest{TypeId: 4})
+// syncMetadataFields - are fields of secrets requested to find out, which of them are changed or have to be encrypted
+// again.
+var syncMetadataFields = []string{
+	"id", "public_id", "type_id", "title", "title_hash", "updated_at", "version", "folder_id", "tag_ids",
+}
+
+// SyncSecrets - makes gRPC requests to server and on success replaces secrets kept in MemoryStorage with acquired
+// ones, except of binary secrets, which are not kept in memory.
+//
+// Secrets are requested without content first. Content is requested only for secrets, which are not kept or whose
+// version is changed, for each type starting from the oldest update of them. Kept secrets, which are missing on
+// server, e.g. moved to trash, are removed.
+//
+// Titles, which are stored in plain, and titles and content sealed by legacy key of the binary are encrypted again by
+// keys of the user and blind indexes of such titles are backfilled, see migrateSecret.
+func (s *Sync) SyncSecrets() error {
+	metadata, err := ListSecrets(s.glCtx.Ctx, s.secretClient, &pb.ListSecretsRequest{
+		Fields: &fieldmaskpb.FieldMask{Paths: syncMetadataFields},
+	})
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	var list []model.CardSecret
-	for _, card := range cards.SecretLists {
-		id := int(card.Id)
-		m := model.CardSecret{}
+	binary := make(map[int]bool)
+	for _, t := range s.storage.GetSecretTypes() {
+		binary[t.Id] = t.IsBinary()
+	}
 
-		decoded, errDecode := s.cr.Decode(string(card.Content))
-		if errDecode != nil {
-			return errDecode
+	kept := make(map[int]model.Secret)
+	for _, secret := range s.storage.GetSecrets() {
+		kept[secret.Id] = secret
+	}
+
+	var list []model.Secret
+	var listed []*pb.SecretList
+	changed := make(map[int]bool)
+	since := make(map[int]time.Time)
+	for _, secret := range metadata {
+		typeID, updatedAt := int(secret.TypeId), secret.UpdatedAt.AsTime()
+		if binary[typeID] {
+			// content of binary secrets isn't requested, so their titles are encrypted again only
+			listed = append(listed, secret)
+
+			continue
+		}
+
+		if m, ok := kept[int(secret.Id)]; ok && m.Version == secret.Version && m.RecordType == typeID {
+			m.FolderId = int(secret.FolderId)
+			m.TagIds = model.NewTagIds(secret.TagIds)
+			list = append(list, m)
+
+			// content of kept secret was checked, when it was requested
+			listed = append(listed, secret)
+
+			continue
 		}
 
-		errUnmarshal := json.Unmarshal([]byte(decoded), &m)
-		if errUnmarshal != nil {
-			return errUnmarshal
+		changed[int(secret.Id)] = true
+		if oldest, ok := since[typeID]; !ok || updatedAt.Before(oldest) {
+			since[typeID] = updatedAt
 		}
-		m.Id = id
-		m.UpdatedAt = card.UpdatedAt.AsTime()
+	}
+
+	for typeID, updatedSince := range since {
+		secrets, errList := ListSecrets(s.glCtx.Ctx, s.secretClient, &pb.ListSecretsRequest{
+			TypeId: uint32(typeID), UpdatedSince: timestamppb.New(updatedSince),
+		})
+		if errList != nil {
+			return errList
+		}
+
+		for _, secret := range secrets {
+			if !changed[int(secret.Id)] {
+				continue
+			}
 
-		list = append(list, m)
+			m, errDecode := s.decodeSecret(secret)
+			if errDecode != nil {
+				return errDecode
+			}
+
+			list = append(list, m)
+			listed = append(listed, secret)
+		}
 	}
 
-	s.storage.SetCardSecrets(list)
+	s.storage.SetSecrets(list)
 
-	return nil
+	// a secret, which can't be migrated, doesn't stop migration of others
+	var errMigrate error
+	for _, secret := range listed {
+		if err = s.migrateSecret(secret); err != nil && errMigrate == nil {
+			errMigrate = fmt.Errorf("secret %d encrypting error: %w", secret.Id, err)
+		}
+	}
+
+	return errMigrate
 }
 
-// SyncPassLoginData - makes gRPC request to server and on success sets acquired records to
-// MemoryStorage.LoginPassSecrets.
-func (s *Sync) SyncPassLoginData() error {
-	lists, err := s.secretClient.GetListOfSecretsByType(s.glCtx.Ctx, &pb.GetListOfSecretsByTypeRequest{TypeId: 1})
-	if err != nil {
-		panic(err)
+// migrateSecret - encrypts title and content of a secret again by keys of the user and backfills blind index of the
+// title, if title is stored in plain, as titles were before they became encrypted, or title and content are sealed
+// by legacy key. Only such fields are changed, content is checked only if it's requested.
+//
+// Encrypted titles always have blind indexes, so title is taken for a plain one only if it has none. Title with blind
+// index, which can't be decrypted, e.g. it's sealed by key of another vault, is never changed, an error is returned
+// instead.
+//
+// Secret is edited with its version, so a secret changed after it was requested is skipped and migrated by one of
+// the following syncs. Migration is done once, because encrypted fields aren't legacy anymore.
+func (s *Sync) migrateSecret(secret *pb.SecretList) error {
+	req := &pb.EditSecretRequest{
+		Id: secret.Id, PublicId: secret.PublicId, Version: secret.Version, UpdateMask: &fieldmaskpb.FieldMask{},
 	}
 
-	var list []model.LoginPassSecret
-	for _, sList := range lists.SecretLists {
-		id := int(sList.Id)
-		m := model.LoginPassSecret{}
+	if secret.Title != "" {
+		plain := len(secret.TitleHash) == 0
+
+		title := secret.Title
+		if !plain {
+			decoded, errDecode := s.cr.Decode(secret.Title)
+			if errDecode != nil {
+				return fmt.Errorf("title decoding error: %w", errDecode)
+			}
+
+			title = decoded
+		}
+
+		if plain || s.cr.Legacy(secret.Title) {
+			req.Title, req.TitleHash = s.cr.Encode(title), s.cr.BlindIndex(model.IndexedTitle(title))
+			req.UpdateMask.Paths = append(req.UpdateMask.Paths, "title")
+		}
+	}
 
-		decoded, errDecode := s.cr.Decode(string(sList.Content))
+	if secret.Content != nil && s.cr.Legacy(string(secret.Content)) {
+		content, errDecode := s.cr.Decode(string(secret.Content))
 		if errDecode != nil {
 			return errDecode
 		}
 
-		errUnmarshal := json.Unmarshal([]byte(decoded), &m)
-		if errUnmarshal != nil {
-			return errUnmarshal
-		}
-		m.Id = id
-		m.UpdatedAt = sList.UpdatedAt.AsTime()
+		req.Content = []byte(s.cr.Encode(content))
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "content")
+	}
 
-		list = append(list, m)
+	if len(req.UpdateMask.Paths) == 0 {
+		return nil
 	}
 
-	s.storage.SetLoginPassSecrets(list)
+	_, err := s.secretClient.EditSecret(s.glCtx.Ctx, req)
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil
+	}
 
-	return nil
+	return err
+}
+
+// decodeSecret - decrypts content of a secret acquired from server and returns it as model.Secret.
+func (s *Sync) decodeSecret(secret *pb.SecretList) (model.Secret, error) {
+	m := model.Secret{}
+
+	decoded, errDecode := s.cr.Decode(string(secret.Content))
+	if errDecode != nil {
+		return m, errDecode
+	}
+
+	errUnmarshal := json.Unmarshal([]byte(decoded), &m)
+	if errUnmarshal != nil {
+		return m, errUnmarshal
+	}
+	m.Id = int(secret.Id)
+	m.PublicId = secret.PublicId
+	m.RecordType = int(secret.TypeId)
+	m.UpdatedAt = secret.UpdatedAt.AsTime()
+	m.Version = secret.Version
+	m.FolderId = int(secret.FolderId)
+	m.TagIds = model.NewTagIds(secret.TagIds)
+
+	return m, nil
 }
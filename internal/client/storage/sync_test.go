@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/client/model"
+	"github.com/sergalkin/gophkeeper/pkg/crypt"
+)
+
+// Title and content of a secret sealed by legacy key of the binary with legacy nonce.
+const (
+	legacyTitle   = "acb898c71ecfe9ce8761ab68106671ac556db74e"
+	legacyContent = "bafba5c27212819d981208af6c1a51785a15adb3743b77cbd4fa5d231506af80e5596230bba050fd60f8a3681ec6717a500d" +
+		"88d459babff112393f"
+)
+
+// secretClientStub - is a pb.SecretClient, which lists provided secrets and records edits of them.
+type secretClientStub struct {
+	pb.SecretClient
+	secrets []*pb.SecretList
+	edits   []*pb.EditSecretRequest
+}
+
+func (c *secretClientStub) ListSecrets(
+	_ context.Context, in *pb.ListSecretsRequest, _ ...grpc.CallOption,
+) (*pb.ListSecretsResponse, error) {
+	resp := &pb.ListSecretsResponse{}
+	for _, secret := range c.secrets {
+		if in.TypeId == 0 || in.TypeId == secret.TypeId {
+			resp.Secrets = append(resp.Secrets, secret)
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *secretClientStub) EditSecret(
+	_ context.Context, in *pb.EditSecretRequest, _ ...grpc.CallOption,
+) (*pb.EditSecretResponse, error) {
+	c.edits = append(c.edits, in)
+
+	return &pb.EditSecretResponse{}, nil
+}
+
+func TestSync_SyncSecrets_MigratesLegacySecrets(t *testing.T) {
+	cr := crypt.NewUserCrypt()
+	unlock(t, cr, "alice")
+
+	other := crypt.NewUserCrypt()
+	unlock(t, other, "bob")
+
+	content := cr.Encode(`{"Title":"github"}`)
+	client := &secretClientStub{secrets: []*pb.SecretList{
+		{Id: 1, TypeId: 1, Title: "github", Content: []byte(content), Version: 1},
+		{Id: 2, TypeId: 1, Title: legacyTitle, TitleHash: []byte("legacy"), Content: []byte(legacyContent), Version: 3},
+		{
+			Id: 3, TypeId: 1, Title: cr.Encode("note"), TitleHash: cr.BlindIndex("note"),
+			Content: []byte(cr.Encode(`{"Title":"note"}`)), Version: 1,
+		},
+		{
+			Id: 4, TypeId: 1, Title: other.Encode("bank"), TitleHash: other.BlindIndex("bank"),
+			Content: []byte(cr.Encode(`{"Title":"bank"}`)), Version: 1,
+		},
+	}}
+
+	s := NewSync(NewMemoryStorage(), client, nil, nil, &model.GlobalContext{Ctx: context.Background()}, cr)
+	assert.ErrorContains(t, s.SyncSecrets(), "secret 4", "title, which can't be decrypted, is reported")
+	require.Len(t, s.storage.GetSecrets(), 4)
+	require.Len(t, client.edits, 2, "neither encrypted secret nor secret with unreadable title is edited")
+
+	plain := client.edits[0]
+	assert.Equal(t, uint32(1), plain.Id)
+	assert.Equal(t, []string{"title"}, plain.UpdateMask.Paths, "content encrypted by keys of the user is kept")
+	assert.Equal(t, cr.BlindIndex("github"), plain.TitleHash)
+
+	title, err := cr.Decode(plain.Title)
+	require.NoError(t, err)
+	assert.Equal(t, "github", title)
+
+	legacy := client.edits[1]
+	assert.Equal(t, int64(3), legacy.Version)
+	assert.Equal(t, []string{"title", "content"}, legacy.UpdateMask.Paths)
+	assert.Equal(t, cr.BlindIndex("mail"), legacy.TitleHash)
+	assert.False(t, cr.Legacy(legacy.Title))
+	assert.False(t, cr.Legacy(string(legacy.Content)))
+
+	decoded, err := cr.Decode(string(legacy.Content))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Title":"mail","Fields":{"Login":"alice"}}`, decoded)
+}
+
+// unlock - unlocks crypt by random key of a vault of a user with provided login and "secret" password.
+func unlock(t *testing.T, cr *crypt.UserCrypt, login string) {
+	t.Helper()
+
+	vaultKey, err := crypt.NewVaultKey()
+	require.NoError(t, err)
+
+	wrapped, err := crypt.WrapVaultKey(login, "secret", vaultKey)
+	require.NoError(t, err)
+	require.NoError(t, cr.Unlock(login, "secret", wrapped))
+}
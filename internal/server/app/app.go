@@ -3,28 +3,34 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
 
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	grpczap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpcrecovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
-	"github.com/jackc/pgx/v4"
 	"go.uber.org/zap"
 
 	"github.com/sergalkin/gophkeeper/internal/server/config"
+	"github.com/sergalkin/gophkeeper/internal/server/job"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/audit"
 	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/idempotency"
+	"github.com/sergalkin/gophkeeper/internal/server/scheduler"
 	"github.com/sergalkin/gophkeeper/internal/server/service"
-	"github.com/sergalkin/gophkeeper/internal/server/storage/postgres"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
 	"github.com/sergalkin/gophkeeper/pkg/crypt"
 	"github.com/sergalkin/gophkeeper/pkg/jwt"
 	"github.com/sergalkin/gophkeeper/pkg/logger"
-	"github.com/sergalkin/gophkeeper/pkg/migrations"
 	"github.com/sergalkin/gophkeeper/pkg/server"
+	"github.com/sergalkin/gophkeeper/pkg/signer"
 )
 
 type App struct {
-	DB     *pgx.Conn
-	Server *server.GrpcServer
-	Logger *zap.Logger
+	Server    *server.GrpcServer
+	Logger    *zap.Logger
+	Scheduler *scheduler.Scheduler
+
+	closeDB func()
 }
 
 // NewApp - creates new App.
@@ -37,13 +43,14 @@ func NewApp(ctx context.Context) (*App, error) {
 		return nil, fmt.Errorf("crypt creating error: %w", errCr)
 	}
 
-	dbConn, err := pgx.Connect(ctx, cfg.DSN)
-	if err != nil {
-		return nil, fmt.Errorf("db open: %w", err)
+	auditSigner, errSigner := signer.NewKeyPairSigner(cfg.SSLCertPath, cfg.SSLKeyPath)
+	if errSigner != nil {
+		return nil, fmt.Errorf("audit signer creating error: %w", errSigner)
 	}
 
-	if err = dbConn.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("db ping: %w", err)
+	st, err := newStorages(ctx, cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	jwtManager, errJwt := jwt.NewJWT(cfg.JWTSecret, cfg.JWTExp)
@@ -51,42 +58,86 @@ func NewApp(ctx context.Context) (*App, error) {
 		return nil, fmt.Errorf("jwtManager creating error: %w", errJwt)
 	}
 
-	migrationManager := migrations.NewMigrationManager(cfg)
-	err = migrationManager.Up()
-	if err != nil {
-		return nil, fmt.Errorf("migration error: %w", err)
-	}
+	auditRecorder := audit.NewRecorder(st.audit, log)
 
-	usersStorage := postgres.NewPostgresUserStorage(dbConn)
-	usersGrpcService := service.NewUserGrpc(usersStorage, jwtManager, cr)
+	usersGrpcService := service.NewUserGrpc(st.users, jwtManager, cr, auditRecorder)
+	secretTypeGrpcService := service.NewSecretTypeGrpc(st.secretTypes)
+	secretGrpcService := service.NewSecretGrpc(st.secrets, st.transactor, cfg.SecretVersionsRetention)
+	folderGrpcService := service.NewFolderGrpc(st.folders)
+	adminGrpcService := service.NewAdminGrpc(st.users, st.audit, st.jobs, auditRecorder, cfg.StorageDriver)
+	auditGrpcService := service.NewAuditGrpc(st.audit)
 
-	secretTypeStorage := postgres.NewPostgresSecretTypeStorage(dbConn)
-	secretTypeGrpcService := service.NewSecretTypeGrpc(secretTypeStorage)
+	jwtAuthMiddleware := auth.NewJwtMiddleware(jwtManager, cr, st.users).Auth
+	roleMiddleware := auth.NewRoleMiddleware()
+	auditMiddleware := audit.NewMiddleware(auditRecorder)
 
-	secretStorage := postgres.NewSecretPostgresStorage(dbConn)
-	secretGrpcService := service.NewSecretGrpc(secretStorage)
+	idempotencyMiddleware := idempotency.NewMiddleware(st.idempotency, cfg.IdempotencyKeyTTL, log)
 
-	jwtAuthMiddleware := auth.NewJwtMiddleware(jwtManager, cr).Auth
+	jobsScheduler := newScheduler(ctx, st.jobs, log)
+
+	if err = jobsScheduler.Register(
+		cfg.TrashPurgeSchedule, job.NewTrashPurger(st.secrets, cfg.TrashRetention),
+	); err != nil {
+		return nil, err
+	}
+
+	if err = jobsScheduler.Register(
+		cfg.IdempotencyKeysPurgeSchedule, job.NewIdempotencyKeysPurger(st.idempotency, cfg.IdempotencyKeyTTL),
+	); err != nil {
+		return nil, err
+	}
+
+	if err = jobsScheduler.Register(
+		cfg.AuditCheckpointSchedule, job.NewAuditCheckpointer(st.audit, auditSigner),
+	); err != nil {
+		return nil, err
+	}
 
 	gRPCServer := server.NewGrpcServer(
 		server.WithServerConfig(cfg),
 		server.WithLogger(log),
-		server.WithServices(usersGrpcService, secretTypeGrpcService, secretGrpcService),
+		server.WithServices(
+			usersGrpcService, secretTypeGrpcService, secretGrpcService, folderGrpcService, adminGrpcService,
+			auditGrpcService,
+		),
 		server.WithStreamInterceptors(
 			grpczap.StreamServerInterceptor(log),
 			grpcauth.StreamServerInterceptor(jwtAuthMiddleware),
+			roleMiddleware.StreamServerInterceptor(),
+			auditMiddleware.StreamServerInterceptor(),
 			grpcrecovery.StreamServerInterceptor(),
 		),
 		server.WithUnaryInterceptors(
 			grpczap.UnaryServerInterceptor(log),
 			grpcauth.UnaryServerInterceptor(jwtAuthMiddleware),
+			roleMiddleware.UnaryServerInterceptor(),
+			auditMiddleware.UnaryServerInterceptor(),
+			idempotencyMiddleware.UnaryServerInterceptor(),
 			grpcrecovery.UnaryServerInterceptor(),
 		),
 	)
 
 	return &App{
-		DB:     dbConn,
-		Server: gRPCServer,
-		Logger: log,
+		Server:    gRPCServer,
+		Logger:    log,
+		Scheduler: jobsScheduler,
+		closeDB:   st.close,
 	}, nil
 }
+
+// Close - closes database of the App, should be called after server and scheduler are stopped.
+func (a *App) Close() {
+	a.closeDB()
+}
+
+// newScheduler - creates scheduler.Scheduler of maintenance jobs.
+func newScheduler(ctx context.Context, jobs storage.JobServerStorage, log *zap.Logger) *scheduler.Scheduler {
+	host, errHost := os.Hostname()
+	if errHost != nil {
+		host = "unknown"
+	}
+
+	instance := fmt.Sprintf("%s:%d", host, os.Getpid())
+
+	return scheduler.NewScheduler(ctx, jobs, instance, log)
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/config"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/memory"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/postgres"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/sqlite"
+	"github.com/sergalkin/gophkeeper/pkg/migrations"
+)
+
+// storages - is a set of storages of the server, which are backed by database selected via STORAGE_DRIVER.
+type storages struct {
+	users       storage.UserServerStorage
+	secretTypes storage.SecretTypeServerStorage
+	secrets     storage.SecretServerStorage
+	folders     storage.FolderServerStorage
+	idempotency storage.IdempotencyServerStorage
+	jobs        storage.JobServerStorage
+	audit       storage.AuditServerStorage
+	transactor  storage.Transactor
+	close       func()
+}
+
+// newStorages - opens database selected by config, applies migrations to it and creates storages.
+func newStorages(ctx context.Context, cfg config.Config) (storages, error) {
+	switch cfg.StorageDriver {
+	case config.StorageDriverPostgres:
+		return newPostgresStorages(ctx, cfg)
+	case config.StorageDriverSQLite:
+		return newSQLiteStorages(ctx, cfg)
+	case config.StorageDriverMemory:
+		return newMemoryStorages(), nil
+	default:
+		return storages{}, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// newPostgresStorages - creates storages backed by Postgres.
+//
+// Locks of jobs are kept by connection, so storage of jobs gets its own one.
+func newPostgresStorages(ctx context.Context, cfg config.Config) (storages, error) {
+	dbPool, err := postgres.NewPool(ctx, cfg.DSN, postgres.PoolLimits{
+		MaxConns:        cfg.DBMaxConns,
+		MinConns:        cfg.DBMinConns,
+		MaxConnLifetime: cfg.DBMaxConnLifetime,
+		MaxConnIdleTime: cfg.DBMaxConnIdleTime,
+	})
+	if err != nil {
+		return storages{}, err
+	}
+
+	migrationManager := migrations.NewMigrationManager(cfg)
+	if err = migrationManager.Up(); err != nil {
+		dbPool.Close()
+
+		return storages{}, fmt.Errorf("migration error: %w", err)
+	}
+
+	jobsConn, err := pgx.Connect(ctx, cfg.DSN)
+	if err != nil {
+		dbPool.Close()
+
+		return storages{}, fmt.Errorf("jobs db open: %w", err)
+	}
+
+	return storages{
+		users:       postgres.NewPostgresUserStorage(dbPool),
+		secretTypes: postgres.NewPostgresSecretTypeStorage(dbPool),
+		secrets:     postgres.NewSecretPostgresStorage(dbPool),
+		folders:     postgres.NewFolderPostgresStorage(dbPool),
+		idempotency: postgres.NewIdempotencyPostgresStorage(dbPool),
+		jobs:        postgres.NewJobPostgresStorage(jobsConn),
+		audit:       postgres.NewAuditPostgresStorage(dbPool),
+		transactor:  postgres.NewTransactor(dbPool),
+		close: func() {
+			jobsConn.Close(context.Background())
+			dbPool.Close()
+		},
+	}, nil
+}
+
+// newSQLiteStorages - creates storages backed by SQLite file.
+func newSQLiteStorages(ctx context.Context, cfg config.Config) (storages, error) {
+	migrationManager := migrations.NewMigrationManager(cfg)
+	if err := migrationManager.Up(); err != nil {
+		return storages{}, fmt.Errorf("migration error: %w", err)
+	}
+
+	db, err := sqlite.NewDB(ctx, cfg.SQLitePath)
+	if err != nil {
+		return storages{}, err
+	}
+
+	return storages{
+		users:       sqlite.NewUserSQLiteStorage(db),
+		secretTypes: sqlite.NewSecretTypeSQLiteStorage(db),
+		secrets:     sqlite.NewSecretSQLiteStorage(db),
+		folders:     sqlite.NewFolderSQLiteStorage(db),
+		idempotency: sqlite.NewIdempotencySQLiteStorage(db),
+		jobs:        sqlite.NewJobSQLiteStorage(db),
+		audit:       sqlite.NewAuditSQLiteStorage(db),
+		transactor:  sqlite.NewTransactor(db),
+		close: func() {
+			db.Close()
+		},
+	}, nil
+}
+
+// newMemoryStorages - creates storages backed by memory of the process, data is lost on its stop.
+func newMemoryStorages() storages {
+	db := memory.NewDB()
+
+	return storages{
+		users:       memory.NewUserMemoryStorage(db),
+		secretTypes: memory.NewSecretTypeMemoryStorage(db),
+		secrets:     memory.NewSecretMemoryStorage(db),
+		folders:     memory.NewFolderMemoryStorage(db),
+		idempotency: memory.NewIdempotencyMemoryStorage(db),
+		jobs:        memory.NewJobMemoryStorage(db),
+		audit:       memory.NewAuditMemoryStorage(db),
+		transactor:  memory.NewTransactor(db),
+		close:       func() {},
+	}
+}
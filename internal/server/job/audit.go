@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/scheduler"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/pkg/signer"
+)
+
+var _ scheduler.Job = (*AuditCheckpointer)(nil)
+
+// AuditCheckpointer - is a job that signs hash of the latest audit event with the server key, so the chain of events
+// up to it can't be rewritten by someone with access to database only.
+type AuditCheckpointer struct {
+	storage storage.AuditServerStorage
+	signer  signer.Signer
+}
+
+// NewAuditCheckpointer - creates new AuditCheckpointer.
+func NewAuditCheckpointer(s storage.AuditServerStorage, sg signer.Signer) *AuditCheckpointer {
+	return &AuditCheckpointer{
+		storage: s,
+		signer:  sg,
+	}
+}
+
+// Name - returns name of the job.
+func (c *AuditCheckpointer) Name() string {
+	return "sign-audit-checkpoint"
+}
+
+// Run - signs checkpoint of the latest audit event, if it is not signed yet.
+func (c *AuditCheckpointer) Run(ctx context.Context) (string, error) {
+	events, err := c.storage.ListAuditEvents(ctx, model.AuditEventFilter{Limit: 1})
+	if err != nil {
+		return "", err
+	}
+
+	if len(events) == 0 || events[0].Hash == "" {
+		return "no chained audit events", nil
+	}
+
+	last, err := c.storage.GetLastAuditCheckpoint(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+
+	if err == nil && last.EventID == events[0].ID {
+		return "no new audit events", nil
+	}
+
+	checkpoint := model.AuditCheckpoint{EventID: events[0].ID, Hash: events[0].Hash, CreatedAt: time.Now()}
+
+	checkpoint.Signature, err = c.signer.Sign(checkpoint.SignedData())
+	if err != nil {
+		return "", fmt.Errorf("audit checkpoint signing err: %w", err)
+	}
+
+	if _, err = c.storage.CreateAuditCheckpoint(ctx, checkpoint); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("signed checkpoint of audit event %d", checkpoint.EventID), nil
+}
@@ -0,0 +1,85 @@
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+	signermock "github.com/sergalkin/gophkeeper/pkg/signer/mock"
+)
+
+func TestAuditCheckpointer_Run(t *testing.T) {
+	latest := model.AuditEvent{ID: 7, Hash: "hash"}
+
+	tests := []struct {
+		name       string
+		events     []model.AuditEvent
+		last       model.AuditCheckpoint
+		lastErr    error
+		wantSigned bool
+		want       string
+	}{
+		{
+			name:       "Latest event is signed",
+			events:     []model.AuditEvent{latest},
+			last:       model.AuditCheckpoint{EventID: 5},
+			wantSigned: true,
+			want:       "signed checkpoint of audit event 7",
+		},
+		{
+			name:       "First checkpoint is signed",
+			events:     []model.AuditEvent{latest},
+			lastErr:    pgx.ErrNoRows,
+			wantSigned: true,
+			want:       "signed checkpoint of audit event 7",
+		},
+		{
+			name:   "Signed event is not signed again",
+			events: []model.AuditEvent{latest},
+			last:   model.AuditCheckpoint{EventID: 7},
+			want:   "no new audit events",
+		},
+		{
+			name: "Empty log is not signed",
+			want: "no chained audit events",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			storageMock := storagemock.NewMockAuditServerStorage(ctl)
+			storageMock.EXPECT().
+				ListAuditEvents(gomock.Any(), gomock.Eq(model.AuditEventFilter{Limit: 1})).
+				Return(tt.events, nil)
+			storageMock.EXPECT().GetLastAuditCheckpoint(gomock.Any()).AnyTimes().Return(tt.last, tt.lastErr)
+
+			signerMock := signermock.NewMockSigner(ctl)
+
+			if tt.wantSigned {
+				signed := model.AuditCheckpoint{EventID: latest.ID, Hash: latest.Hash}
+
+				signerMock.EXPECT().Sign(gomock.Eq(signed.SignedData())).Return([]byte("signature"), nil)
+				storageMock.EXPECT().CreateAuditCheckpoint(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, c model.AuditCheckpoint) (model.AuditCheckpoint, error) {
+						assert.Equal(t, latest.ID, c.EventID)
+						assert.Equal(t, latest.Hash, c.Hash)
+						assert.Equal(t, []byte("signature"), c.Signature)
+
+						return c, nil
+					},
+				)
+			}
+
+			got, err := NewAuditCheckpointer(storageMock, signerMock).Run(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
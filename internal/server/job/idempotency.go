@@ -0,0 +1,43 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sergalkin/gophkeeper/internal/server/scheduler"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ scheduler.Job = (*IdempotencyKeysPurger)(nil)
+
+// IdempotencyKeysPurger - is a job that deletes idempotency keys, which are expired.
+//
+// Expired key is already ignored on reservation, so the job only reclaims space.
+type IdempotencyKeysPurger struct {
+	storage storage.IdempotencyServerStorage
+	ttl     time.Duration
+}
+
+// NewIdempotencyKeysPurger - creates new IdempotencyKeysPurger.
+func NewIdempotencyKeysPurger(s storage.IdempotencyServerStorage, ttl time.Duration) *IdempotencyKeysPurger {
+	return &IdempotencyKeysPurger{
+		storage: s,
+		ttl:     ttl,
+	}
+}
+
+// Name - returns name of the job.
+func (p *IdempotencyKeysPurger) Name() string {
+	return "purge-idempotency-keys"
+}
+
+// Run - purges expired idempotency keys.
+func (p *IdempotencyKeysPurger) Run(ctx context.Context) (string, error) {
+	purged, err := p.storage.PurgeKeys(ctx, time.Now().Add(-p.ttl))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("purged %d keys", purged), nil
+}
@@ -0,0 +1,56 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+)
+
+func TestIdempotencyKeysPurger_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "Expired keys are purged",
+			want:    "purged 2 keys",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "Storage error is returned",
+			err:     errors.New("test"),
+			want:    "",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			storageMock := storagemock.NewMockIdempotencyServerStorage(ctl)
+			storageMock.EXPECT().PurgeKeys(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, before time.Time) (int64, error) {
+					if before.After(time.Now().Add(-time.Hour)) {
+						t.Errorf("PurgeKeys() before = %v, want older than ttl", before)
+					}
+
+					return 2, tt.err
+				},
+			)
+
+			got, err := NewIdempotencyKeysPurger(storageMock, time.Hour).Run(context.Background())
+
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package job is a package that contains maintenance jobs of the server.
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sergalkin/gophkeeper/internal/server/scheduler"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ scheduler.Job = (*TrashPurger)(nil)
+
+// TrashPurger - is a job that permanently deletes secrets, which are in trash longer than retention.
+type TrashPurger struct {
+	storage   storage.SecretServerStorage
+	retention time.Duration
+}
+
+// NewTrashPurger - creates new TrashPurger.
+func NewTrashPurger(s storage.SecretServerStorage, retention time.Duration) *TrashPurger {
+	return &TrashPurger{
+		storage:   s,
+		retention: retention,
+	}
+}
+
+// Name - returns name of the job.
+func (p *TrashPurger) Name() string {
+	return "purge-trash"
+}
+
+// Run - purges trashed secrets.
+func (p *TrashPurger) Run(ctx context.Context) (string, error) {
+	purged, err := p.storage.PurgeTrash(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("purged %d secrets", purged), nil
+}
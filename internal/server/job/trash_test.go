@@ -0,0 +1,56 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+)
+
+func TestTrashPurger_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "Secrets out of retention are purged",
+			want:    "purged 1 secrets",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "Storage error is returned",
+			err:     errors.New("test"),
+			want:    "",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			storageMock := storagemock.NewMockSecretServerStorage(ctl)
+			storageMock.EXPECT().PurgeTrash(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, before time.Time) (int64, error) {
+					if before.After(time.Now().Add(-time.Hour)) {
+						t.Errorf("PurgeTrash() before = %v, want older than retention", before)
+					}
+
+					return 1, tt.err
+				},
+			)
+
+			got, err := NewTrashPurger(storageMock, time.Hour).Run(context.Background())
+
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package audit keeps audit log of security-relevant actions of users.
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+)
+
+type Middleware struct {
+	recorder     Recorder
+	methodEvents map[string]string
+	// batchEvents - are events of methods, which handle several secrets, an event is recorded for each of them.
+	// Lists of secrets are recorded as reads of each returned secret, if their content is returned.
+	batchEvents map[string]string
+}
+
+// NewMiddleware - creates audit Middleware.
+//
+// Actions, which details are known to their handlers only, such as login and register, are recorded by services.
+func NewMiddleware(r Recorder) *Middleware {
+	return &Middleware{
+		recorder: r,
+		methodEvents: map[string]string{
+			"/proto.User/Delete":                 model.AuditEventUserDelete,
+			"/proto.Secret/CreateSecret":         model.AuditEventSecretCreate,
+			"/proto.Secret/GetSecret":            model.AuditEventSecretRead,
+			"/proto.Secret/GetSecretVersion":     model.AuditEventSecretRead,
+			"/proto.Secret/EditSecret":           model.AuditEventSecretEdit,
+			"/proto.Secret/RestoreSecretVersion": model.AuditEventSecretEdit,
+			"/proto.Secret/MoveSecret":           model.AuditEventSecretEdit,
+			"/proto.Secret/SetSecretTags":        model.AuditEventSecretEdit,
+			"/proto.Secret/DeleteSecret":         model.AuditEventSecretDelete,
+			"/proto.Secret/RestoreSecret":        model.AuditEventSecretRestore,
+			"/proto.Secret/PurgeSecret":          model.AuditEventSecretPurge,
+		},
+		batchEvents: map[string]string{
+			"/proto.Secret/BatchCreateSecrets":     model.AuditEventSecretCreate,
+			"/proto.Secret/BatchGetSecrets":        model.AuditEventSecretRead,
+			"/proto.Secret/BatchDeleteSecrets":     model.AuditEventSecretDelete,
+			"/proto.Secret/GetListOfSecretsByType": model.AuditEventSecretRead,
+			"/proto.Secret/ListSecrets":            model.AuditEventSecretRead,
+		},
+	}
+}
+
+// UnaryServerInterceptor - returns interceptor that attaches RequestInfo to context of every request and records
+// audit events of handled secret and user actions.
+//
+// Interceptor has to be chained after auth interceptor, because events are attributed to user id from context.
+func (m *Middleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx = withRequestInfo(ctx)
+
+		resp, err := handler(ctx, req)
+
+		if event, ok := m.methodEvents[info.FullMethod]; ok {
+			m.recorder.Record(ctx, model.AuditEvent{Event: event, Success: err == nil, Target: target(req, resp)})
+		}
+
+		if event, ok := m.batchEvents[info.FullMethod]; ok {
+			for _, item := range batchItems(req, resp) {
+				m.recorder.Record(ctx, model.AuditEvent{
+					Event: event, Success: err == nil && item.success, Target: target(item.req, item.resp),
+				})
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor - returns interceptor that attaches RequestInfo to context of every stream.
+func (m *Middleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withRequestInfo(ss.Context())})
+	}
+}
+
+// serverStream - is a grpc.ServerStream with replaced context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context - returns context of the stream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// target - returns public id or id of a secret from request, or from response if request has none.
+func target(messages ...interface{}) string {
+	for _, msg := range messages {
+		if m, ok := msg.(interface{ GetPublicId() string }); ok && m.GetPublicId() != "" {
+			return m.GetPublicId()
+		}
+
+		switch m := msg.(type) {
+		case interface{ GetId() uint32 }:
+			if m.GetId() != 0 {
+				return fmt.Sprint(m.GetId())
+			}
+		case interface{ GetId() int32 }:
+			if m.GetId() != 0 {
+				return fmt.Sprint(m.GetId())
+			}
+		}
+	}
+
+	return ""
+}
+
+// batchItem - is an item of a batch request with its result.
+type batchItem struct {
+	req     interface{}
+	resp    interface{}
+	success bool
+}
+
+// batchItems - returns items of a batch request with their results from response, items of failed request have no
+// results. Lists of secrets have an item for each returned secret, and none if content of secrets isn't returned.
+func batchItems(req, resp interface{}) []batchItem {
+	var items []batchItem
+
+	switch r := req.(type) {
+	case *pb.BatchCreateSecretsRequest:
+		res, _ := resp.(*pb.BatchCreateSecretsResponse)
+		results := res.GetResults()
+		for i, secret := range r.Secrets {
+			item := batchItem{req: secret}
+			if i < len(results) {
+				item.resp, item.success = results[i].GetSecret(), results[i].GetStatus().GetCode() == 0
+			}
+
+			items = append(items, item)
+		}
+	case *pb.BatchGetSecretsRequest:
+		res, _ := resp.(*pb.BatchGetSecretsResponse)
+		results := res.GetResults()
+		for i, secret := range r.Secrets {
+			item := batchItem{req: secret}
+			if i < len(results) {
+				item.resp, item.success = results[i].GetSecret(), results[i].GetStatus().GetCode() == 0
+			}
+
+			items = append(items, item)
+		}
+	case *pb.BatchDeleteSecretsRequest:
+		res, _ := resp.(*pb.BatchDeleteSecretsResponse)
+		results := res.GetResults()
+		for i, secret := range r.Secrets {
+			item := batchItem{req: secret}
+			if i < len(results) {
+				item.success = results[i].GetStatus().GetCode() == 0
+			}
+
+			items = append(items, item)
+		}
+	case *pb.GetListOfSecretsByTypeRequest:
+		res, _ := resp.(*pb.GetListOfSecretsByTypeResponse)
+		items = listItems(res.GetSecretLists())
+	case *pb.ListSecretsRequest:
+		if paths := r.Fields.GetPaths(); len(paths) > 0 && !contains(paths, "content") {
+			break
+		}
+
+		res, _ := resp.(*pb.ListSecretsResponse)
+		items = listItems(res.GetSecrets())
+	}
+
+	return items
+}
+
+// listItems - returns an item for each secret of a list, which is successfully read.
+func listItems(secrets []*pb.SecretList) []batchItem {
+	items := make([]batchItem, 0, len(secrets))
+	for _, secret := range secrets {
+		items = append(items, batchItem{resp: secret, success: true})
+	}
+
+	return items
+}
+
+// contains - reports whether list contains provided value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
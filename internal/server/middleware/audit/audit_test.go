@@ -0,0 +1,215 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/memory"
+)
+
+func TestMiddleware_UnaryServerInterceptor(t *testing.T) {
+	uid := uuid.New()
+
+	tests := []struct {
+		name       string
+		method     string
+		req        interface{}
+		handlerErr error
+		wantEvent  *model.AuditEvent
+	}{
+		{
+			name:      "Secret read is recorded",
+			method:    "/proto.Secret/GetSecret",
+			req:       &pb.GetSecretRequest{Id: 7},
+			wantEvent: &model.AuditEvent{Event: model.AuditEventSecretRead, Success: true, Target: "7"},
+		},
+		{
+			name:       "Failed secret delete is recorded",
+			method:     "/proto.Secret/DeleteSecret",
+			req:        &pb.DeleteSecretRequest{Id: 3},
+			handlerErr: errors.New("not found"),
+			wantEvent:  &model.AuditEvent{Event: model.AuditEventSecretDelete, Success: false, Target: "3"},
+		},
+		{
+			name:   "Listing of secrets is not recorded",
+			method: "/proto.Secret/GetListOfSecretsByType",
+			req:    &pb.GetListOfSecretsByTypeRequest{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audits := memory.NewAuditMemoryStorage(memory.NewDB())
+			interceptor := NewMiddleware(NewRecorder(audits, zap.NewNop())).UnaryServerInterceptor()
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"user-agent", "gophkeeper-client/1.0", RequestIDKey, "req-1",
+			))
+			ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5555}})
+			ctx = context.WithValue(ctx, auth.JwtTokenCtx{}, uid.String())
+
+			_, err := interceptor(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return nil, tt.handlerErr
+				},
+			)
+			assert.Equal(t, tt.handlerErr, err)
+
+			events, err := audits.ListAuditEvents(context.Background(), model.AuditEventFilter{Limit: 10})
+			require.NoError(t, err)
+
+			if tt.wantEvent == nil {
+				assert.Empty(t, events)
+
+				return
+			}
+
+			require.Len(t, events, 1)
+			assert.Equal(t, tt.wantEvent.Event, events[0].Event)
+			assert.Equal(t, tt.wantEvent.Success, events[0].Success)
+			assert.Equal(t, tt.wantEvent.Target, events[0].Target)
+			assert.Equal(t, &uid, events[0].UserID)
+			assert.Equal(t, "10.0.0.1", events[0].IP)
+			assert.Equal(t, "gophkeeper-client/1.0", events[0].UserAgent)
+			assert.Equal(t, "req-1", events[0].RequestID)
+		})
+	}
+}
+
+func TestMiddleware_UnaryServerInterceptor_Batch(t *testing.T) {
+	audits := memory.NewAuditMemoryStorage(memory.NewDB())
+	interceptor := NewMiddleware(NewRecorder(audits, zap.NewNop())).UnaryServerInterceptor()
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, uuid.NewString())
+
+	req := &pb.BatchDeleteSecretsRequest{Secrets: []*pb.DeleteSecretRequest{{Id: 1}, {Id: 2}}}
+	_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/proto.Secret/BatchDeleteSecrets"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &pb.BatchDeleteSecretsResponse{Results: []*pb.BatchDeleteSecretsResult{
+				{Status: &pb.BatchItemStatus{}}, {Status: &pb.BatchItemStatus{Code: 5}},
+			}}, nil
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/proto.Secret/BatchDeleteSecrets"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.New("rolled back")
+		},
+	)
+	require.Error(t, err)
+
+	events, err := audits.ListAuditEvents(context.Background(), model.AuditEventFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 4, "an event is recorded for each item")
+
+	var succeeded []string
+	for _, event := range events {
+		assert.Equal(t, model.AuditEventSecretDelete, event.Event)
+		if event.Success {
+			succeeded = append(succeeded, event.Target)
+		}
+	}
+	assert.Equal(t, []string{"1"}, succeeded)
+}
+
+func TestMiddleware_UnaryServerInterceptor_List(t *testing.T) {
+	audits := memory.NewAuditMemoryStorage(memory.NewDB())
+	interceptor := NewMiddleware(NewRecorder(audits, zap.NewNop())).UnaryServerInterceptor()
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, uuid.NewString())
+
+	secrets := []*pb.SecretList{{Id: 1}, {Id: 2, PublicId: "public"}}
+
+	tests := []struct {
+		name   string
+		method string
+		req    interface{}
+		resp   interface{}
+		want   []string
+	}{
+		{
+			name:   "Secrets of a type are read",
+			method: "/proto.Secret/GetListOfSecretsByType",
+			req:    &pb.GetListOfSecretsByTypeRequest{TypeId: 1},
+			resp:   &pb.GetListOfSecretsByTypeResponse{SecretLists: secrets},
+			want:   []string{"1", "public"},
+		},
+		{
+			name:   "Listed secrets are read if all fields are returned",
+			method: "/proto.Secret/ListSecrets",
+			req:    &pb.ListSecretsRequest{},
+			resp:   &pb.ListSecretsResponse{Secrets: secrets},
+			want:   []string{"1", "public"},
+		},
+		{
+			name:   "Listed secrets are read if content is returned",
+			method: "/proto.Secret/ListSecrets",
+			req:    &pb.ListSecretsRequest{Fields: &fieldmaskpb.FieldMask{Paths: []string{"id", "content"}}},
+			resp:   &pb.ListSecretsResponse{Secrets: secrets},
+			want:   []string{"1", "public"},
+		},
+		{
+			name:   "Listed secrets aren't read if content isn't returned",
+			method: "/proto.Secret/ListSecrets",
+			req:    &pb.ListSecretsRequest{Fields: &fieldmaskpb.FieldMask{Paths: []string{"id", "title"}}},
+			resp:   &pb.ListSecretsResponse{Secrets: secrets},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, err := audits.ListAuditEvents(context.Background(), model.AuditEventFilter{Limit: 100})
+			require.NoError(t, err)
+
+			_, err = interceptor(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return tt.resp, nil
+				},
+			)
+			require.NoError(t, err)
+
+			events, err := audits.ListAuditEvents(context.Background(), model.AuditEventFilter{Limit: 100})
+			require.NoError(t, err)
+
+			var targets []string
+			// events are listed latest first
+			for idx := len(events) - len(before) - 1; idx >= 0; idx-- {
+				assert.Equal(t, model.AuditEventSecretRead, events[idx].Event)
+				assert.True(t, events[idx].Success)
+				targets = append(targets, events[idx].Target)
+			}
+			assert.Equal(t, tt.want, targets)
+		})
+	}
+}
+
+func TestWithRequestInfo_GeneratesRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "Missing request id is generated", id: ""},
+		{name: "Too long request id is replaced", id: string(make([]byte, maxRequestIDLen+1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDKey, tt.id))
+
+			info := RequestInfoFromContext(withRequestInfo(ctx))
+
+			_, err := uuid.Parse(info.RequestID)
+			assert.NoError(t, err)
+		})
+	}
+}
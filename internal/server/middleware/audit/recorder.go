@@ -0,0 +1,61 @@
+//go:generate mockgen -source=./recorder.go -destination=./mock/recorder.go -package=auditmock
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+type Recorder interface {
+	// Record - appends model.AuditEvent to audit log, request info and user from context are added to it.
+	Record(ctx context.Context, event model.AuditEvent)
+}
+
+var _ Recorder = (*StorageRecorder)(nil)
+
+// StorageRecorder - is a Recorder, which writes events to storage.
+type StorageRecorder struct {
+	storage storage.AuditServerStorage
+	logger  *zap.Logger
+}
+
+// NewRecorder - creates StorageRecorder.
+func NewRecorder(s storage.AuditServerStorage, l *zap.Logger) *StorageRecorder {
+	return &StorageRecorder{storage: s, logger: l}
+}
+
+// Record - writes model.AuditEvent to storage.
+//
+// If event has no user, then it is taken from auth token in context. Event is written even if request is cancelled,
+// and failure of writing is logged instead of failing the request, which is already handled.
+func (r *StorageRecorder) Record(ctx context.Context, event model.AuditEvent) {
+	if event.UserID == nil {
+		if uid, err := uuid.Parse(userIDFromContext(ctx)); err == nil {
+			event.UserID = &uid
+		}
+	}
+
+	info := RequestInfoFromContext(ctx)
+	event.IP, event.UserAgent, event.RequestID = info.IP, info.UserAgent, info.RequestID
+	event.CreatedAt = time.Now()
+
+	if _, err := r.storage.CreateAuditEvent(context.Background(), event); err != nil {
+		r.logger.Error("audit event writing failed",
+			zap.String("event", event.Event), zap.String("request_id", event.RequestID), zap.Error(err),
+		)
+	}
+}
+
+// userIDFromContext - returns id of a user from auth token in context, empty if request is not authorized.
+func userIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	return userID
+}
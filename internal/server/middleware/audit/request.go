@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+	"net"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RequestIDKey - name of metadata header that carries id of a request, it is generated if client doesn't send it.
+const RequestIDKey = "x-request-id"
+
+// maxRequestIDLen - is a max length of request id provided by client, longer ids are replaced with generated ones.
+const maxRequestIDLen = 64
+
+// RequestInfo - is an info about client of a request.
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// requestInfoCtx - is a key of RequestInfo in context.
+type requestInfoCtx struct{}
+
+// RequestInfoFromContext - returns RequestInfo attached to context by Middleware.
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoCtx{}).(RequestInfo)
+
+	return info
+}
+
+// withRequestInfo - attaches RequestInfo of incoming request to context and sends request id to client in header.
+func withRequestInfo(ctx context.Context) context.Context {
+	var info RequestInfo
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		info.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(info.IP); err == nil {
+			info.IP = host
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			info.UserAgent = values[0]
+		}
+
+		if values := md.Get(RequestIDKey); len(values) > 0 && values[0] != "" && len(values[0]) <= maxRequestIDLen {
+			info.RequestID = values[0]
+		}
+	}
+
+	if info.RequestID == "" {
+		info.RequestID = uuid.NewString()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, info.RequestID))
+
+	return context.WithValue(ctx, requestInfoCtx{}, info)
+}
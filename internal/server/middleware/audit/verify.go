@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/pkg/signer"
+)
+
+// verifyBatchSize - is a number of audit events read from storage at once by Verify.
+const verifyBatchSize = 500
+
+// BrokenLink - is the first audit event or checkpoint, which doesn't match the chain.
+type BrokenLink struct {
+	EventID      int64
+	CheckpointID int64
+	Reason       string
+}
+
+// String - returns description of the broken link.
+func (b BrokenLink) String() string {
+	if b.CheckpointID != 0 {
+		return fmt.Sprintf("checkpoint %d of event %d: %s", b.CheckpointID, b.EventID, b.Reason)
+	}
+
+	return fmt.Sprintf("event %d: %s", b.EventID, b.Reason)
+}
+
+// VerifyReport - is a result of verification of audit chain.
+type VerifyReport struct {
+	// Events - is a number of verified chained events.
+	Events int
+	// LegacyEvents - is a number of events recorded before chaining was added, they can't be verified.
+	LegacyEvents int
+	// Checkpoints - is a number of verified checkpoints.
+	Checkpoints int
+	// Unsigned - is a number of chained events after the last checkpoint, which are protected by chain only.
+	Unsigned int
+	// Broken - is the first broken link, nil if chain is intact.
+	Broken *BrokenLink
+}
+
+// Verify - walks audit chain from the first event and checks that every event is linked to the previous one, that its
+// content matches its hash, and that every checkpoint is signed by the server and matches hash of its event.
+//
+// Verification stops at the first broken link, which is returned in VerifyReport.
+func Verify(ctx context.Context, s storage.AuditServerStorage, v signer.Signer) (VerifyReport, error) {
+	var report VerifyReport
+
+	checkpoints, err := s.ListAuditCheckpoints(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	eventCheckpoints := make(map[int64][]model.AuditCheckpoint, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		eventCheckpoints[checkpoint.EventID] = append(eventCheckpoints[checkpoint.EventID], checkpoint)
+	}
+
+	var prevHash string
+	var afterID int64
+	for {
+		events, errList := s.ListAuditChain(ctx, afterID, verifyBatchSize)
+		if errList != nil {
+			return report, errList
+		}
+
+		for _, event := range events {
+			if report.Broken = verifyEvent(event, prevHash, report.Events > 0); report.Broken != nil {
+				return report, nil
+			}
+
+			if event.Hash == "" {
+				report.LegacyEvents++
+				continue
+			}
+
+			report.Events++
+			report.Unsigned++
+			prevHash = event.Hash
+
+			for _, checkpoint := range eventCheckpoints[event.ID] {
+				if report.Broken = verifyCheckpoint(checkpoint, event, v); report.Broken != nil {
+					return report, nil
+				}
+
+				report.Checkpoints++
+				report.Unsigned = 0
+			}
+			delete(eventCheckpoints, event.ID)
+		}
+
+		if len(events) < verifyBatchSize {
+			break
+		}
+
+		afterID = events[len(events)-1].ID
+	}
+
+	for _, checkpoint := range checkpoints {
+		if _, ok := eventCheckpoints[checkpoint.EventID]; ok {
+			report.Broken = &BrokenLink{
+				EventID: checkpoint.EventID, CheckpointID: checkpoint.ID, Reason: "event is missing",
+			}
+
+			return report, nil
+		}
+	}
+
+	return report, nil
+}
+
+// verifyEvent - returns BrokenLink if event doesn't follow event with prevHash or its content doesn't match its hash.
+//
+// Events without hash are allowed only before the first chained one.
+func verifyEvent(event model.AuditEvent, prevHash string, chained bool) *BrokenLink {
+	switch {
+	case event.Hash == "" && chained:
+		return &BrokenLink{EventID: event.ID, Reason: "hash is missing"}
+	case event.Hash == "":
+		return nil
+	case event.PrevHash != prevHash:
+		return &BrokenLink{EventID: event.ID, Reason: "previous event was changed, deleted or inserted"}
+	case event.Hash != event.ChainHash(event.PrevHash):
+		return &BrokenLink{EventID: event.ID, Reason: "content doesn't match hash"}
+	}
+
+	return nil
+}
+
+// verifyCheckpoint - returns BrokenLink if checkpoint doesn't match hash of its event or its signature is invalid.
+func verifyCheckpoint(checkpoint model.AuditCheckpoint, event model.AuditEvent, v signer.Signer) *BrokenLink {
+	if checkpoint.Hash != event.Hash {
+		return &BrokenLink{EventID: event.ID, CheckpointID: checkpoint.ID, Reason: "hash doesn't match event"}
+	}
+
+	if err := v.Verify(checkpoint.SignedData(), checkpoint.Signature); err != nil {
+		reason := "signature is invalid"
+		if !errors.Is(err, signer.ErrInvalidSignature) {
+			reason = fmt.Sprintf("signature can't be verified: %v", err)
+		}
+
+		return &BrokenLink{EventID: event.ID, CheckpointID: checkpoint.ID, Reason: reason}
+	}
+
+	return nil
+}
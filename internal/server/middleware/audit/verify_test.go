@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+	"github.com/sergalkin/gophkeeper/pkg/signer"
+	signermock "github.com/sergalkin/gophkeeper/pkg/signer/mock"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name         string
+		tamper       func(e []model.AuditEvent, c []model.AuditCheckpoint) chainState
+		wantBroken   *BrokenLink
+		wantUnsigned int
+	}{
+		{
+			name: "Intact chain is verified",
+			tamper: func(e []model.AuditEvent, c []model.AuditCheckpoint) chainState {
+				return chainState{e, c}
+			},
+			wantUnsigned: 2,
+		},
+		{
+			name: "Changed event is reported",
+			tamper: func(e []model.AuditEvent, c []model.AuditCheckpoint) chainState {
+				e[1].Success = false
+				return chainState{e, c}
+			},
+			wantBroken: &BrokenLink{EventID: 2, Reason: "content doesn't match hash"},
+		},
+		{
+			name: "Deleted event is reported at the next one",
+			tamper: func(e []model.AuditEvent, c []model.AuditCheckpoint) chainState {
+				return chainState{append(e[:1], e[2:]...), c}
+			},
+			wantBroken: &BrokenLink{EventID: 3, Reason: "previous event was changed, deleted or inserted"},
+		},
+		{
+			name: "Rewritten chain is reported at checkpoint",
+			tamper: func(e []model.AuditEvent, c []model.AuditCheckpoint) chainState {
+				e[0].Target = "rewritten"
+				return chainState{chain(e), c}
+			},
+			wantBroken: &BrokenLink{EventID: 3, CheckpointID: 1, Reason: "hash doesn't match event"},
+		},
+		{
+			name: "Forged checkpoint is reported",
+			tamper: func(e []model.AuditEvent, c []model.AuditCheckpoint) chainState {
+				c[0].Signature = []byte("forged")
+				return chainState{e, c}
+			},
+			wantBroken: &BrokenLink{EventID: 3, CheckpointID: 1, Reason: "signature is invalid"},
+		},
+		{
+			name: "Truncated chain is reported by checkpoint of deleted event",
+			tamper: func(e []model.AuditEvent, c []model.AuditCheckpoint) chainState {
+				return chainState{e[:2], c}
+			},
+			wantBroken: &BrokenLink{EventID: 3, CheckpointID: 1, Reason: "event is missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			events := []model.AuditEvent{{ID: 1, Event: model.AuditEventRegister}}
+			for i := int64(2); i <= 5; i++ {
+				events = append(events, model.AuditEvent{
+					ID: i, Event: model.AuditEventLogin, Success: true, Target: fmt.Sprint(i), CreatedAt: time.Now(),
+				})
+			}
+			events = chain(events)
+
+			checkpoints := []model.AuditCheckpoint{{ID: 1, EventID: 3, Hash: events[2].Hash}}
+			checkpoints[0].Signature = checkpoints[0].SignedData()
+
+			state := tt.tamper(events, checkpoints)
+			events, checkpoints = state.events, state.checkpoints
+
+			storageMock := storagemock.NewMockAuditServerStorage(ctl)
+			storageMock.EXPECT().ListAuditCheckpoints(gomock.Any()).Return(checkpoints, nil)
+			storageMock.EXPECT().ListAuditChain(gomock.Any(), int64(0), verifyBatchSize).Return(events, nil)
+
+			verifierMock := signermock.NewMockSigner(ctl)
+			verifierMock.EXPECT().Verify(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+				func(data, signature []byte) error {
+					if string(data) != string(signature) {
+						return signer.ErrInvalidSignature
+					}
+
+					return nil
+				},
+			)
+
+			report, err := Verify(context.Background(), storageMock, verifierMock)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBroken, report.Broken)
+
+			if tt.wantBroken == nil {
+				assert.Equal(t, 5, report.Events)
+				assert.Equal(t, 1, report.Checkpoints)
+				assert.Equal(t, tt.wantUnsigned, report.Unsigned)
+			}
+		})
+	}
+}
+
+func TestVerify_LegacyEventsPrecedeChain(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	events := append(
+		[]model.AuditEvent{{ID: 1, Event: model.AuditEventLogin}},
+		chain([]model.AuditEvent{{ID: 2, Event: model.AuditEventLogin}})...,
+	)
+	events = append(events, model.AuditEvent{ID: 3, Event: model.AuditEventLogin})
+
+	storageMock := storagemock.NewMockAuditServerStorage(ctl)
+	storageMock.EXPECT().ListAuditCheckpoints(gomock.Any()).Return(nil, nil)
+	storageMock.EXPECT().ListAuditChain(gomock.Any(), int64(0), verifyBatchSize).Return(events, nil)
+
+	report, err := Verify(context.Background(), storageMock, signermock.NewMockSigner(ctl))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.LegacyEvents)
+	assert.Equal(t, &BrokenLink{EventID: 3, Reason: "hash is missing"}, report.Broken)
+}
+
+// chainState - is a state of audit storage under test.
+type chainState struct {
+	events      []model.AuditEvent
+	checkpoints []model.AuditCheckpoint
+}
+
+// chain - links events one after another by their hashes.
+func chain(events []model.AuditEvent) []model.AuditEvent {
+	prevHash := ""
+	for i := range events {
+		events[i].PrevHash = prevHash
+		events[i].Hash = events[i].ChainHash(prevHash)
+		prevHash = events[i].Hash
+	}
+
+	return events
+}
@@ -2,12 +2,17 @@ package auth
 
 import (
 	"context"
+	"errors"
 
+	"github.com/google/uuid"
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/jackc/pgx/v4"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
 	"github.com/sergalkin/gophkeeper/pkg/crypt"
 	"github.com/sergalkin/gophkeeper/pkg/jwt"
 )
@@ -15,19 +20,24 @@ import (
 // JwtTokenCtx - a unique type to avoid collisions.
 type JwtTokenCtx struct{}
 
+// JwtRoleCtx - is a key of role claim of decoded token in context.
+type JwtRoleCtx struct{}
+
 var _ Auther = (*JwtMiddleware)(nil)
 
 type JwtMiddleware struct {
 	jwtManager         jwt.Manager
 	unProtectedMethods []string
 	crypter            crypt.Crypter
+	users              storage.UserServerStorage
 }
 
 // NewJwtMiddleware - creates JwtMiddleware.
-func NewJwtMiddleware(j jwt.Manager, c crypt.Crypter) *JwtMiddleware {
+func NewJwtMiddleware(j jwt.Manager, c crypt.Crypter, u storage.UserServerStorage) *JwtMiddleware {
 	return &JwtMiddleware{
 		jwtManager:         j,
 		crypter:            c,
+		users:              u,
 		unProtectedMethods: []string{"/proto.User/Register", "/proto.User/Login"},
 	}
 }
@@ -36,8 +46,10 @@ func NewJwtMiddleware(j jwt.Manager, c crypt.Crypter) *JwtMiddleware {
 //
 // It extracts and decodes bearer token from context.
 //
-// On successful decode it attaches decoded token to context with new value with JwtTokenCtx.
-// On failure attempt returns codes.Unauthenticated status.
+// On successful decode it attaches decoded token to context with new value with JwtTokenCtx and its role claim with
+// JwtRoleCtx.
+// On failure attempt returns codes.Unauthenticated status, which is also returned if user of the token is deleted or
+// disabled, or the token was issued before tokens of the user were revoked.
 func (a *JwtMiddleware) Auth(ctx context.Context) (context.Context, error) {
 	if a.isSkippingCurrentRoute(ctx) {
 		return ctx, nil
@@ -53,16 +65,47 @@ func (a *JwtMiddleware) Auth(ctx context.Context) (context.Context, error) {
 		return nil, status.Errorf(codes.Unauthenticated, "crypter decoding error: %v", errCrypter)
 	}
 
-	decodedToken, errDecode := a.jwtManager.Decode(token)
+	claims, errDecode := a.jwtManager.DecodeClaims(token)
 	if errDecode != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "invalid auth token: %v", errDecode)
 	}
 
-	newCtx := context.WithValue(ctx, JwtTokenCtx{}, decodedToken)
+	if err = a.checkUser(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	newCtx := context.WithValue(context.WithValue(ctx, JwtTokenCtx{}, claims.ID), JwtRoleCtx{}, claims.Role)
 
 	return newCtx, nil
 }
 
+// checkUser - validates that user of the token exists and is allowed to use it.
+func (a *JwtMiddleware) checkUser(ctx context.Context, claims jwt.Claims) error {
+	uid, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid auth token: %v", err)
+	}
+
+	user, err := a.users.GetUser(ctx, model.User{ID: &uid})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Error(codes.Unauthenticated, "user of auth token is not found")
+		}
+
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if user.DisabledAt != nil {
+		return status.Error(codes.Unauthenticated, "user is disabled")
+	}
+
+	if user.TokensRevokedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Before(*user.TokensRevokedAt)) {
+		return status.Error(codes.Unauthenticated, "auth token is revoked")
+	}
+
+	return nil
+}
+
 // isSkippingCurrentRoute - helper function for validating that extracted name of currently requested grpc.Method
 // from context is in list of unprotected JwtMiddleware a methods.
 func (a *JwtMiddleware) isSkippingCurrentRoute(ctx context.Context) bool {
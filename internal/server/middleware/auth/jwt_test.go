@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+	cryptmock "github.com/sergalkin/gophkeeper/pkg/crypt/mock"
+	"github.com/sergalkin/gophkeeper/pkg/jwt"
+	jwtmock "github.com/sergalkin/gophkeeper/pkg/jwt/mock"
+)
+
+func TestJwtMiddleware_Auth(t *testing.T) {
+	uid := uuid.New()
+	now := time.Now()
+	earlier, later := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		user     model.User
+		userErr  error
+		issuedAt time.Time
+		wantCode codes.Code
+	}{
+		{
+			name:     "Token of active user is accepted",
+			user:     model.User{ID: &uid},
+			issuedAt: now,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Token of disabled user is rejected",
+			user:     model.User{ID: &uid, DisabledAt: &earlier, TokensRevokedAt: &earlier},
+			issuedAt: now,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "Token issued before revocation is rejected after user is enabled",
+			user:     model.User{ID: &uid, TokensRevokedAt: &later},
+			issuedAt: now,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "Token issued after revocation is accepted",
+			user:     model.User{ID: &uid, TokensRevokedAt: &earlier},
+			issuedAt: now,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Token of deleted user is rejected",
+			userErr:  pgx.ErrNoRows,
+			issuedAt: now,
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			claims := jwt.Claims{}
+			claims.ID = uid.String()
+			claims.IssuedAt = gojwt.NewNumericDate(tt.issuedAt)
+
+			jwtM := jwtmock.NewMockManager(ctl)
+			jwtM.EXPECT().DecodeClaims("token").Return(claims, nil)
+
+			cryptM := cryptmock.NewMockCrypter(ctl)
+			cryptM.EXPECT().Decode("encoded").Return("token", nil)
+
+			usersM := storagemock.NewMockUserServerStorage(ctl)
+			usersM.EXPECT().GetUser(gomock.Any(), gomock.Eq(model.User{ID: &uid})).Return(tt.user, tt.userErr)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer encoded"))
+
+			got, err := NewJwtMiddleware(jwtM, cryptM, usersM).Auth(ctx)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, uid.String(), got.Value(JwtTokenCtx{}))
+			}
+		})
+	}
+}
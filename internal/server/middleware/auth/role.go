@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+)
+
+type RoleMiddleware struct {
+	serviceRoles map[string]string
+}
+
+// NewRoleMiddleware - creates RoleMiddleware.
+func NewRoleMiddleware() *RoleMiddleware {
+	return &RoleMiddleware{
+		serviceRoles: map[string]string{
+			"/proto.Admin/": model.RoleAdmin,
+		},
+	}
+}
+
+// UnaryServerInterceptor - returns interceptor that allows methods of protected services to callers with required
+// role only.
+//
+// Interceptor has to be chained after auth interceptor, because role is taken from claim of token in context. Callers
+// without required role receive codes.PermissionDenied status.
+func (r *RoleMiddleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := r.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor - returns stream version of UnaryServerInterceptor.
+func (r *RoleMiddleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := r.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// authorize - returns codes.PermissionDenied status if method requires a role, which caller doesn't have.
+func (r *RoleMiddleware) authorize(ctx context.Context, method string) error {
+	for prefix, role := range r.serviceRoles {
+		if !strings.HasPrefix(method, prefix) {
+			continue
+		}
+
+		if callerRole, _ := ctx.Value(JwtRoleCtx{}).(string); callerRole != role {
+			return status.Errorf(codes.PermissionDenied, "method requires %s role", role)
+		}
+	}
+
+	return nil
+}
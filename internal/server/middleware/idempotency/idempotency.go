@@ -0,0 +1,196 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+// HeaderKey - name of metadata header that carries idempotency key of a request.
+const HeaderKey = "idempotency-key"
+
+type Middleware struct {
+	storage           storage.IdempotencyServerStorage
+	ttl               time.Duration
+	logger            *zap.Logger
+	idempotentMethods map[string]bool
+}
+
+// NewMiddleware - creates idempotency Middleware.
+//
+// Responses of idempotent methods are kept in storage for ttl duration. Register isn't one of them, because its response
+// carries a token, which mustn't be stored and replayed to anyone presenting the same key, repeated registration is
+// refused by unique login anyway.
+func NewMiddleware(s storage.IdempotencyServerStorage, ttl time.Duration, l *zap.Logger) *Middleware {
+	return &Middleware{
+		storage: s,
+		ttl:     ttl,
+		logger:  l,
+		idempotentMethods: map[string]bool{
+			"/proto.Secret/CreateSecret":         true,
+			"/proto.Secret/EditSecret":           true,
+			"/proto.Secret/DeleteSecret":         true,
+			"/proto.Secret/RestoreSecretVersion": true,
+			"/proto.Secret/RestoreSecret":        true,
+			"/proto.Secret/PurgeSecret":          true,
+			"/proto.Secret/MoveSecret":           true,
+			"/proto.Secret/SetSecretTags":        true,
+			"/proto.Secret/BatchCreateSecrets":   true,
+			"/proto.Secret/BatchDeleteSecrets":   true,
+			"/proto.Folder/CreateFolder":         true,
+			"/proto.Folder/CreateTag":            true,
+			"/proto.User/Delete":                 true,
+			"/proto.User/ChangePassword":         true,
+		},
+	}
+}
+
+// UnaryServerInterceptor - returns interceptor that replays stored response for repeated idempotency key.
+//
+// Interceptor has to be chained after auth interceptor, because keys are scoped by user id from context.
+//
+// On first request with a key, key is reserved and handler response is stored. Repeated request with the same key
+// and the same payload receives stored response without calling a handler. Repeated request with different payload
+// receives codes.InvalidArgument status, and request with a key, which is still being processed, receives
+// codes.Aborted status. If handler returns an error, reservation is released, so request could be retried.
+func (m *Middleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !m.idempotentMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		idempotencyKey := keyFromMD(ctx)
+		if idempotencyKey == "" {
+			return handler(ctx, req)
+		}
+
+		requestHash, err := hashRequest(req)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		userID, _ := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+		key := model.IdempotencyKey{
+			Key:         idempotencyKey,
+			UserID:      userID,
+			Method:      info.FullMethod,
+			RequestHash: requestHash,
+		}
+
+		stored, reserved, err := m.storage.ReserveKey(ctx, key, m.ttl)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		if !reserved {
+			return replay(stored, requestHash)
+		}
+
+		resp, errHandler := handler(ctx, req)
+		if errHandler != nil {
+			if errRelease := m.storage.ReleaseKey(ctx, key); errRelease != nil {
+				m.logger.Error(errRelease.Error())
+			}
+
+			return resp, errHandler
+		}
+
+		if err = m.saveResponse(ctx, key, resp); err != nil {
+			m.logger.Error(err.Error())
+		}
+
+		return resp, nil
+	}
+}
+
+// saveResponse - wraps response into anypb.Any and stores it for reserved model.IdempotencyKey.
+func (m *Middleware) saveResponse(ctx context.Context, key model.IdempotencyKey, resp interface{}) error {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return m.storage.ReleaseKey(ctx, key)
+	}
+
+	wrapped, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+
+	key.Response, err = proto.Marshal(wrapped)
+	if err != nil {
+		return err
+	}
+
+	return m.storage.SaveResponse(ctx, key)
+}
+
+// replay - returns stored response of already reserved model.IdempotencyKey.
+func replay(stored model.IdempotencyKey, requestHash []byte) (interface{}, error) {
+	if !bytes.Equal(stored.RequestHash, requestHash) {
+		return nil, status.Error(codes.InvalidArgument, "idempotency key was already used with a different request")
+	}
+
+	if stored.Response == nil {
+		return nil, status.Error(codes.Aborted, "request with this idempotency key is still in progress")
+	}
+
+	wrapped := &anypb.Any{}
+	if err := proto.Unmarshal(stored.Response, wrapped); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp, err := wrapped.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return resp, nil
+}
+
+// keyFromMD - extracts idempotency key from incoming metadata.
+func keyFromMD(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(HeaderKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// hashRequest - returns sha256 of deterministically marshaled request.
+func hashRequest(req interface{}) ([]byte, error) {
+	var data []byte
+
+	if msg, ok := req.(proto.Message); ok {
+		var err error
+
+		data, err = proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sum := sha256.Sum256(data)
+
+	return sum[:], nil
+}
@@ -0,0 +1,175 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+)
+
+func TestMiddleware_UnaryServerInterceptor(t *testing.T) {
+	req := &pb.CreateSecretRequest{Title: "test"}
+	reqHash, _ := hashRequest(req)
+
+	storedResp, _ := anypb.New(&pb.CreateSecretResponse{Id: 7})
+	storedBytes, _ := proto.Marshal(storedResp)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.Secret/CreateSecret"}
+	ctxWithKey := metadata.NewIncomingContext(context.Background(), metadata.Pairs(HeaderKey, "key"))
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		info       *grpc.UnaryServerInfo
+		prepare    func(m *storagemock.MockIdempotencyServerStorage)
+		handlerErr error
+		wantID     uint32
+		wantCalled bool
+		wantCode   codes.Code
+	}{
+		{
+			name:       "Request without idempotency key is passed to handler",
+			ctx:        context.Background(),
+			info:       info,
+			prepare:    func(m *storagemock.MockIdempotencyServerStorage) {},
+			wantID:     1,
+			wantCalled: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "Non idempotent method is passed to handler",
+			ctx:        ctxWithKey,
+			info:       &grpc.UnaryServerInfo{FullMethod: "/proto.Secret/GetSecret"},
+			prepare:    func(m *storagemock.MockIdempotencyServerStorage) {},
+			wantID:     1,
+			wantCalled: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "Register, which response carries a token, is passed to handler and isn't stored",
+			ctx:        ctxWithKey,
+			info:       &grpc.UnaryServerInfo{FullMethod: "/proto.User/Register"},
+			prepare:    func(m *storagemock.MockIdempotencyServerStorage) {},
+			wantID:     1,
+			wantCalled: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name: "Response of first request is stored",
+			ctx:  ctxWithKey,
+			info: info,
+			prepare: func(m *storagemock.MockIdempotencyServerStorage) {
+				m.EXPECT().ReserveKey(gomock.Any(), gomock.Any(), time.Hour).Return(model.IdempotencyKey{}, true, nil)
+				m.EXPECT().SaveResponse(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantID:     1,
+			wantCalled: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name: "Stored response is replayed for repeated key",
+			ctx:  ctxWithKey,
+			info: info,
+			prepare: func(m *storagemock.MockIdempotencyServerStorage) {
+				m.EXPECT().ReserveKey(gomock.Any(), gomock.Any(), time.Hour).Return(
+					model.IdempotencyKey{RequestHash: reqHash, Response: storedBytes}, false, nil,
+				)
+			},
+			wantID:     7,
+			wantCalled: false,
+			wantCode:   codes.OK,
+		},
+		{
+			name: "Repeated key with different request is rejected",
+			ctx:  ctxWithKey,
+			info: info,
+			prepare: func(m *storagemock.MockIdempotencyServerStorage) {
+				m.EXPECT().ReserveKey(gomock.Any(), gomock.Any(), time.Hour).Return(
+					model.IdempotencyKey{RequestHash: []byte{1}, Response: storedBytes}, false, nil,
+				)
+			},
+			wantCalled: false,
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			name: "Repeated key of request in progress is aborted",
+			ctx:  ctxWithKey,
+			info: info,
+			prepare: func(m *storagemock.MockIdempotencyServerStorage) {
+				m.EXPECT().ReserveKey(gomock.Any(), gomock.Any(), time.Hour).Return(
+					model.IdempotencyKey{RequestHash: reqHash}, false, nil,
+				)
+			},
+			wantCalled: false,
+			wantCode:   codes.Aborted,
+		},
+		{
+			name: "Reservation is released on handler error",
+			ctx:  ctxWithKey,
+			info: info,
+			prepare: func(m *storagemock.MockIdempotencyServerStorage) {
+				m.EXPECT().ReserveKey(gomock.Any(), gomock.Any(), time.Hour).Return(model.IdempotencyKey{}, true, nil)
+				m.EXPECT().ReleaseKey(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			handlerErr: status.Error(codes.Internal, "test"),
+			wantCalled: true,
+			wantCode:   codes.Internal,
+		},
+		{
+			name: "Storage error is returned as internal error",
+			ctx:  ctxWithKey,
+			info: info,
+			prepare: func(m *storagemock.MockIdempotencyServerStorage) {
+				m.EXPECT().ReserveKey(gomock.Any(), gomock.Any(), time.Hour).Return(
+					model.IdempotencyKey{}, false, errors.New("test"),
+				)
+			},
+			wantCalled: false,
+			wantCode:   codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			storageMock := storagemock.NewMockIdempotencyServerStorage(ctl)
+			tt.prepare(storageMock)
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				if tt.handlerErr != nil {
+					return nil, tt.handlerErr
+				}
+
+				return &pb.CreateSecretResponse{Id: 1}, nil
+			}
+
+			m := NewMiddleware(storageMock, time.Hour, zap.NewNop())
+
+			resp, err := m.UnaryServerInterceptor()(tt.ctx, req, tt.info, handler)
+
+			assert.Equal(t, tt.wantCalled, called)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			if err == nil {
+				assert.Equal(t, tt.wantID, resp.(*pb.CreateSecretResponse).Id)
+			}
+		})
+	}
+}
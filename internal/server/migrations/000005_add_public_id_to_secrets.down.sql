@@ -0,0 +1,4 @@
+drop index if exists index_public_id_secrets;
+
+alter table secrets
+    drop column if exists public_id;
\ No newline at end of file
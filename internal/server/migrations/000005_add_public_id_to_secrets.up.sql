@@ -0,0 +1,4 @@
+alter table secrets
+    add column public_id uuid default gen_random_uuid() not null;
+
+create unique index index_public_id_secrets on secrets (public_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table secrets
+    drop column if exists version;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table secrets
+    add column version bigint default 1 not null;
\ No newline at end of file
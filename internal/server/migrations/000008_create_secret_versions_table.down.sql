@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trigger_archive_secret_version ON secrets;
+DROP FUNCTION IF EXISTS archive_secret_version;
+DROP TABLE IF EXISTS secret_versions;
+
+alter table secrets
+    drop column if exists updated_by;
\ No newline at end of file
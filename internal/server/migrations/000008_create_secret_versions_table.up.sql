@@ -0,0 +1,36 @@
+alter table secrets
+    add column updated_by uuid default null;
+
+create table secret_versions
+(
+    id         bigserial primary key,
+    secret_id  bigint      not null,
+    version    bigint      not null,
+    type_id    bigint      not null,
+    title      text        not null,
+    content    bytea       not null,
+    changed_by uuid        not null,
+    changed_at TIMESTAMPTZ not null,
+
+    constraint fk_secret_id foreign key (secret_id) references secrets (id) on delete cascade,
+    constraint unique_secret_id_version unique (secret_id, version)
+);
+
+create or replace function archive_secret_version() returns trigger as
+$$
+begin
+    insert into secret_versions (secret_id, version, type_id, title, content, changed_by, changed_at)
+    values (old.id, old.version, old.type_id, old.title, old.content, coalesce(old.updated_by, old.user_id),
+            old.updated_at)
+    on conflict do nothing;
+
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger trigger_archive_secret_version
+    before update
+    on secrets
+    for each row
+    when (old.version <> new.version)
+execute function archive_secret_version();
\ No newline at end of file
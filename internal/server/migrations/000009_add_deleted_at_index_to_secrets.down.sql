@@ -0,0 +1 @@
+drop index if exists index_deleted_at_secrets;
\ No newline at end of file
@@ -0,0 +1 @@
+create index index_deleted_at_secrets on secrets (deleted_at) where deleted_at is not null;
\ No newline at end of file
@@ -0,0 +1,12 @@
+create table job_runs
+(
+    id          bigserial primary key,
+    name        text        not null,
+    instance    text        not null,
+    status      text        not null,
+    message     text        not null default '',
+    started_at  TIMESTAMPTZ not null,
+    finished_at TIMESTAMPTZ          default null
+);
+
+create index index_name_started_at_job_runs on job_runs (name, started_at);
\ No newline at end of file
@@ -0,0 +1,3 @@
+alter table users
+    drop column disabled_at,
+    drop column tokens_revoked_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+alter table users
+    add column disabled_at TIMESTAMPTZ default null,
+    add column tokens_revoked_at TIMESTAMPTZ default null;
\ No newline at end of file
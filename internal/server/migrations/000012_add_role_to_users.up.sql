@@ -0,0 +1,2 @@
+alter table users
+    add column role TEXT not null default 'user';
\ No newline at end of file
@@ -0,0 +1,3 @@
+drop trigger if exists trigger_forbid_audit_event_change on audit_events;
+drop function if exists forbid_audit_event_change;
+drop table if exists audit_events;
\ No newline at end of file
@@ -0,0 +1,27 @@
+create table audit_events
+(
+    id         bigserial primary key,
+    user_id    uuid                 default null,
+    event      text        not null,
+    success    boolean     not null,
+    target     text        not null default '',
+    ip         text        not null default '',
+    user_agent text        not null default '',
+    request_id text        not null default '',
+    created_at TIMESTAMPTZ not null default now()
+);
+
+create index index_user_id_audit_events on audit_events (user_id, id);
+
+create or replace function forbid_audit_event_change() returns trigger as
+$$
+begin
+    raise exception 'audit events are append-only';
+end;
+$$ language plpgsql;
+
+create trigger trigger_forbid_audit_event_change
+    before update or delete
+    on audit_events
+    for each row
+execute function forbid_audit_event_change();
\ No newline at end of file
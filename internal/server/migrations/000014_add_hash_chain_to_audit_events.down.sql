@@ -0,0 +1,3 @@
+drop trigger if exists trigger_forbid_audit_checkpoint_change on audit_checkpoints;
+drop table if exists audit_checkpoints;
+alter table audit_events drop column if exists prev_hash, drop column if exists hash;
\ No newline at end of file
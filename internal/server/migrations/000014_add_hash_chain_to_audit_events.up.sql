@@ -0,0 +1,18 @@
+alter table audit_events
+    add column prev_hash text not null default '',
+    add column hash      text not null default '';
+
+create table audit_checkpoints
+(
+    id         bigserial primary key,
+    event_id   bigint      not null,
+    hash       text        not null,
+    signature  bytea       not null,
+    created_at TIMESTAMPTZ not null default now()
+);
+
+create trigger trigger_forbid_audit_checkpoint_change
+    before update or delete
+    on audit_checkpoints
+    for each row
+execute function forbid_audit_event_change();
\ No newline at end of file
@@ -0,0 +1,8 @@
+drop table if exists secret_tags;
+
+alter table secrets
+    drop column if exists folder_id;
+
+drop table if exists tags;
+
+drop table if exists folders;
\ No newline at end of file
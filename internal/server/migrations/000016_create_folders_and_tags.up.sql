@@ -0,0 +1,43 @@
+create table folders
+(
+    id         bigserial primary key,
+    user_id    uuid        not null,
+    parent_id  bigint      default null,
+    name       bytea       not null,
+    created_at TIMESTAMPTZ not null default now(),
+
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade,
+    constraint fk_parent_id foreign key (parent_id) references folders (id) on delete cascade
+);
+
+create index index_user_id_folders on folders (user_id);
+
+create table tags
+(
+    id         bigserial primary key,
+    user_id    uuid        not null,
+    name       bytea       not null,
+    created_at TIMESTAMPTZ not null default now(),
+
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade
+);
+
+create index index_user_id_tags on tags (user_id);
+
+alter table secrets
+    add column folder_id bigint default null,
+    add constraint fk_folder_id foreign key (folder_id) references folders (id) on delete set null;
+
+create index index_folder_id_secrets on secrets (folder_id) where folder_id is not null;
+
+create table secret_tags
+(
+    secret_id bigint not null,
+    tag_id    bigint not null,
+
+    primary key (secret_id, tag_id),
+    constraint fk_secret_id foreign key (secret_id) references secrets (id) on delete cascade,
+    constraint fk_tag_id foreign key (tag_id) references tags (id) on delete cascade
+);
+
+create index index_tag_id_secret_tags on secret_tags (tag_id);
\ No newline at end of file
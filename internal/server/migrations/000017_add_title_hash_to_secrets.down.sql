@@ -0,0 +1,19 @@
+create or replace function archive_secret_version() returns trigger as
+$$
+begin
+    insert into secret_versions (secret_id, version, type_id, title, content, changed_by, changed_at)
+    values (old.id, old.version, old.type_id, old.title, old.content, coalesce(old.updated_by, old.user_id),
+            old.updated_at)
+    on conflict do nothing;
+
+    return new;
+end;
+$$ language plpgsql;
+
+alter table secret_versions
+    drop column if exists title_hash;
+
+drop index if exists unique_user_id_title_hash_secrets;
+
+alter table secrets
+    drop column if exists title_hash;
@@ -0,0 +1,20 @@
+alter table secrets
+    add column title_hash bytea default null;
+
+create unique index unique_user_id_title_hash_secrets on secrets (user_id, title_hash)
+    where title_hash is not null and deleted_at is null;
+
+alter table secret_versions
+    add column title_hash bytea default null;
+
+create or replace function archive_secret_version() returns trigger as
+$$
+begin
+    insert into secret_versions (secret_id, version, type_id, title, title_hash, content, changed_by, changed_at)
+    values (old.id, old.version, old.type_id, old.title, old.title_hash, old.content,
+            coalesce(old.updated_by, old.user_id), old.updated_at)
+    on conflict do nothing;
+
+    return new;
+end;
+$$ language plpgsql;
@@ -0,0 +1,4 @@
+drop index if exists unique_user_id_title_hash_secrets;
+
+create index user_id_title_hash_secrets on secrets (user_id, title_hash)
+    where title_hash is not null and deleted_at is null;
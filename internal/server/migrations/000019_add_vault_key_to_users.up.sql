@@ -0,0 +1,2 @@
+alter table users
+    add column vault_key bytea default null;
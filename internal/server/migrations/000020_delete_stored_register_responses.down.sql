@@ -0,0 +1 @@
+-- stored responses of register carried auth tokens, so they aren't restored
@@ -0,0 +1,16 @@
+// Package migrations keeps migrations of database schema of the server.
+package migrations
+
+import "embed"
+
+// SQLite - migrations of SQLite storage, which are embedded into binary, so single-file deployment needs nothing but
+// the database file.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
+
+// Postgres - migrations of Postgres storage, which are embedded into binary, so the server could be started from any
+// directory.
+//
+//go:embed *.sql
+var Postgres embed.FS
@@ -0,0 +1,7 @@
+create table users
+(
+    id         text not null primary key,
+    login      text not null unique,
+    password   text not null,
+    created_at timestamp default current_timestamp
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS secret_types;
\ No newline at end of file
@@ -0,0 +1,11 @@
+create table secret_types
+(
+    id    integer primary key autoincrement,
+    title text not null
+);
+
+insert into secret_types (title)
+values ('login/pass'),
+       ('text'),
+       ('binary'),
+       ('card');
\ No newline at end of file
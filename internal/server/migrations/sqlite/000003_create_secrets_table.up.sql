@@ -0,0 +1,19 @@
+create table secrets
+(
+    id         integer primary key autoincrement,
+    public_id  text      not null unique,
+    user_id    text      not null,
+    type_id    integer   not null,
+    title      text      not null,
+    content    blob      not null,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    deleted_at timestamp default null,
+    version    integer   not null default 1,
+    updated_by text      default null,
+
+    constraint fk_type_id foreign key (type_id) references secret_types (id) on delete cascade,
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade
+);
+
+create index index_deleted_at_secrets on secrets (deleted_at) where deleted_at is not null;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trigger_archive_secret_version;
+DROP TABLE IF EXISTS secret_versions;
\ No newline at end of file
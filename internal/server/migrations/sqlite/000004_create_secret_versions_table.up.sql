@@ -0,0 +1,25 @@
+create table secret_versions
+(
+    id         integer primary key autoincrement,
+    secret_id  integer   not null,
+    version    integer   not null,
+    type_id    integer   not null,
+    title      text      not null,
+    content    blob      not null,
+    changed_by text      not null,
+    changed_at timestamp not null,
+
+    constraint fk_secret_id foreign key (secret_id) references secrets (id) on delete cascade,
+    constraint unique_secret_id_version unique (secret_id, version)
+);
+
+create trigger trigger_archive_secret_version
+    before update
+    on secrets
+    for each row
+    when old.version <> new.version
+begin
+    insert or ignore into secret_versions (secret_id, version, type_id, title, content, changed_by, changed_at)
+    values (old.id, old.version, old.type_id, old.title, old.content, coalesce(old.updated_by, old.user_id),
+            old.updated_at);
+end;
\ No newline at end of file
@@ -0,0 +1,13 @@
+create table idempotency_keys
+(
+    key          text      not null,
+    user_id      text      not null,
+    method       text      not null,
+    request_hash blob      not null,
+    response     blob      default null,
+    created_at   timestamp not null,
+
+    primary key (key, user_id, method)
+);
+
+create index index_created_at_idempotency_keys on idempotency_keys (created_at);
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table users drop column tokens_revoked_at;
+alter table users drop column disabled_at;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table users add column disabled_at timestamp default null;
+alter table users add column tokens_revoked_at timestamp default null;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table users add column role text not null default 'user';
\ No newline at end of file
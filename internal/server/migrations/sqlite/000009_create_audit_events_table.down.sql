@@ -0,0 +1,3 @@
+drop trigger if exists trigger_forbid_audit_event_delete;
+drop trigger if exists trigger_forbid_audit_event_update;
+drop table if exists audit_events;
\ No newline at end of file
@@ -0,0 +1,28 @@
+create table audit_events
+(
+    id         integer primary key autoincrement,
+    user_id    text               default null,
+    event      text      not null,
+    success    boolean   not null,
+    target     text      not null default '',
+    ip         text      not null default '',
+    user_agent text      not null default '',
+    request_id text      not null default '',
+    created_at timestamp not null
+);
+
+create index index_user_id_audit_events on audit_events (user_id, id);
+
+create trigger trigger_forbid_audit_event_update
+    before update
+    on audit_events
+begin
+    select raise(abort, 'audit events are append-only');
+end;
+
+create trigger trigger_forbid_audit_event_delete
+    before delete
+    on audit_events
+begin
+    select raise(abort, 'audit events are append-only');
+end;
\ No newline at end of file
@@ -0,0 +1,5 @@
+drop trigger if exists trigger_forbid_audit_checkpoint_delete;
+drop trigger if exists trigger_forbid_audit_checkpoint_update;
+drop table if exists audit_checkpoints;
+alter table audit_events drop column hash;
+alter table audit_events drop column prev_hash;
\ No newline at end of file
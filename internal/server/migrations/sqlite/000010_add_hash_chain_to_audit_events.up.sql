@@ -0,0 +1,25 @@
+alter table audit_events add column prev_hash text not null default '';
+alter table audit_events add column hash text not null default '';
+
+create table audit_checkpoints
+(
+    id         integer primary key autoincrement,
+    event_id   integer   not null,
+    hash       text      not null,
+    signature  blob      not null,
+    created_at timestamp not null
+);
+
+create trigger trigger_forbid_audit_checkpoint_update
+    before update
+    on audit_checkpoints
+begin
+    select raise(abort, 'audit checkpoints are append-only');
+end;
+
+create trigger trigger_forbid_audit_checkpoint_delete
+    before delete
+    on audit_checkpoints
+begin
+    select raise(abort, 'audit checkpoints are append-only');
+end;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table secret_types drop column fields;
\ No newline at end of file
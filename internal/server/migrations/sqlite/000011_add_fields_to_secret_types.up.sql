@@ -0,0 +1,28 @@
+alter table secret_types add column fields text not null default '[]';
+
+update secret_types
+set fields = '[
+        {"name": "Login", "label": "Login", "kind": "text", "required": true, "sensitive": false, "pattern": ""},
+        {"name": "Password", "label": "Password", "kind": "text", "required": true, "sensitive": true, "pattern": ""}
+    ]'
+where title = 'login/pass';
+
+update secret_types
+set fields = '[
+        {"name": "Text", "label": "Text", "kind": "multiline", "required": true, "sensitive": false, "pattern": ""}
+    ]'
+where title = 'text';
+
+update secret_types
+set fields = '[
+        {"name": "File", "label": "File", "kind": "file", "required": true, "sensitive": false, "pattern": ""}
+    ]'
+where title = 'binary';
+
+update secret_types
+set fields = '[
+        {"name": "CardNumber", "label": "Card number", "kind": "text", "required": true, "sensitive": true, "pattern": "^[0-9]{12,19}$"},
+        {"name": "CVV", "label": "CVV", "kind": "text", "required": true, "sensitive": true, "pattern": "^[0-9]{3,4}$"},
+        {"name": "Due", "label": "Due date", "kind": "text", "required": true, "sensitive": false, "pattern": "^(0[1-9]|1[0-2])/([0-9]{2}|[0-9]{4})$"}
+    ]'
+where title = 'card';
\ No newline at end of file
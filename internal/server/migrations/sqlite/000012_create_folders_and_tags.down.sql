@@ -0,0 +1,10 @@
+drop table if exists secret_tags;
+
+drop index if exists index_folder_id_secrets;
+
+alter table secrets
+    drop column folder_id;
+
+drop table if exists tags;
+
+drop table if exists folders;
\ No newline at end of file
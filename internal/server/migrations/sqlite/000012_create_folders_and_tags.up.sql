@@ -0,0 +1,42 @@
+create table folders
+(
+    id         integer primary key autoincrement,
+    user_id    text      not null,
+    parent_id  integer   default null,
+    name       blob      not null,
+    created_at timestamp not null,
+
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade,
+    constraint fk_parent_id foreign key (parent_id) references folders (id) on delete cascade
+);
+
+create index index_user_id_folders on folders (user_id);
+
+create table tags
+(
+    id         integer primary key autoincrement,
+    user_id    text      not null,
+    name       blob      not null,
+    created_at timestamp not null,
+
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade
+);
+
+create index index_user_id_tags on tags (user_id);
+
+alter table secrets
+    add column folder_id integer default null references folders (id) on delete set null;
+
+create index index_folder_id_secrets on secrets (folder_id) where folder_id is not null;
+
+create table secret_tags
+(
+    secret_id integer not null,
+    tag_id    integer not null,
+
+    primary key (secret_id, tag_id),
+    constraint fk_secret_id foreign key (secret_id) references secrets (id) on delete cascade,
+    constraint fk_tag_id foreign key (tag_id) references tags (id) on delete cascade
+);
+
+create index index_tag_id_secret_tags on secret_tags (tag_id);
\ No newline at end of file
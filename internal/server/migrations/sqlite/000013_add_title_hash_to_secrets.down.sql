@@ -0,0 +1,20 @@
+drop trigger if exists trigger_archive_secret_version;
+
+create trigger trigger_archive_secret_version
+    before update
+    on secrets
+    for each row
+    when old.version <> new.version
+begin
+    insert or ignore into secret_versions (secret_id, version, type_id, title, content, changed_by, changed_at)
+    values (old.id, old.version, old.type_id, old.title, old.content, coalesce(old.updated_by, old.user_id),
+            old.updated_at);
+end;
+
+alter table secret_versions
+    drop column title_hash;
+
+drop index if exists unique_user_id_title_hash_secrets;
+
+alter table secrets
+    drop column title_hash;
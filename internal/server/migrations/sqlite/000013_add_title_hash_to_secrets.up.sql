@@ -0,0 +1,22 @@
+alter table secrets
+    add column title_hash blob default null;
+
+create unique index unique_user_id_title_hash_secrets on secrets (user_id, title_hash)
+    where title_hash is not null and deleted_at is null;
+
+alter table secret_versions
+    add column title_hash blob default null;
+
+drop trigger if exists trigger_archive_secret_version;
+
+create trigger trigger_archive_secret_version
+    before update
+    on secrets
+    for each row
+    when old.version <> new.version
+begin
+    insert or ignore into secret_versions (secret_id, version, type_id, title, title_hash, content, changed_by,
+                                           changed_at)
+    values (old.id, old.version, old.type_id, old.title, old.title_hash, old.content,
+            coalesce(old.updated_by, old.user_id), old.updated_at);
+end;
@@ -0,0 +1 @@
+delete from idempotency_keys where method = '/proto.User/Register';
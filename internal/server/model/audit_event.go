@@ -0,0 +1,116 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditEventLogin          = "login"
+	AuditEventRegister       = "register"
+	AuditEventUserDelete     = "user_delete"
+	AuditEventSecretCreate   = "secret_create"
+	AuditEventSecretRead     = "secret_read"
+	AuditEventSecretEdit     = "secret_edit"
+	AuditEventSecretDelete   = "secret_delete"
+	AuditEventSecretRestore  = "secret_restore"
+	AuditEventSecretPurge    = "secret_purge"
+	AuditEventUserDisable    = "user_disable"
+	AuditEventUserEnable     = "user_enable"
+	AuditEventPasswordReset  = "password_reset"
+	AuditEventPasswordChange = "password_change"
+)
+
+const (
+	// AuditEventsDefaultLimit - is a number of audit events in a page, if it's not provided.
+	AuditEventsDefaultLimit = 50
+	// AuditEventsMaxLimit - is a max number of audit events in a page.
+	AuditEventsMaxLimit = 500
+)
+
+// AuditEvent - is a record of security-relevant action of a user.
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// UserID - is an id of a user, who made the action or whose account was targeted by failed login, nil if unknown.
+	UserID *uuid.UUID `json:"user_id"`
+	Event  string     `json:"event"`
+	// Success - is false if action was rejected.
+	Success bool `json:"success"`
+	// Target - is an id of affected secret or user, or login used by login attempt.
+	Target    string    `json:"target"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// PrevHash - is a Hash of previous event in the chain, empty for the first event.
+	PrevHash string `json:"prev_hash"`
+	// Hash - is a hash of content of the event and PrevHash, empty for events recorded before chaining was added.
+	Hash string `json:"hash"`
+}
+
+// ChainHash - returns hex encoded sha256 hash of content of the event chained to provided hash of previous event.
+//
+// Id is not hashed, because it is assigned by database, deleted or reordered events are detected by chain itself.
+// CreatedAt is hashed with microsecond precision, which is kept by every storage.
+func (e AuditEvent) ChainHash(prevHash string) string {
+	var userID string
+	if e.UserID != nil {
+		userID = e.UserID.String()
+	}
+
+	// marshalling of a struct with plain fields can't fail and keeps order of fields
+	content, _ := json.Marshal(struct {
+		PrevHash  string `json:"prev_hash"`
+		UserID    string `json:"user_id"`
+		Event     string `json:"event"`
+		Success   bool   `json:"success"`
+		Target    string `json:"target"`
+		IP        string `json:"ip"`
+		UserAgent string `json:"user_agent"`
+		RequestID string `json:"request_id"`
+		CreatedAt string `json:"created_at"`
+	}{
+		PrevHash:  prevHash,
+		UserID:    userID,
+		Event:     e.Event,
+		Success:   e.Success,
+		Target:    e.Target,
+		IP:        e.IP,
+		UserAgent: e.UserAgent,
+		RequestID: e.RequestID,
+		CreatedAt: e.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditCheckpoint - is a signed Hash of an audit event, which proves that the chain up to the event was not changed
+// after checkpoint was made.
+type AuditCheckpoint struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"event_id"`
+	Hash      string    `json:"hash"`
+	Signature []byte    `json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SignedData - returns data of the checkpoint, which is signed by the server.
+func (c AuditCheckpoint) SignedData() []byte {
+	return []byte(fmt.Sprintf("audit-checkpoint:%d:%s", c.EventID, c.Hash))
+}
+
+// AuditEventFilter - selects audit events, latest go first.
+type AuditEventFilter struct {
+	// UserID - selects events of the user only if it's not nil.
+	UserID *uuid.UUID
+	// BeforeID - selects events with smaller id if it's not 0, is used to get next page.
+	BeforeID int64
+	Limit    int
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Folder - is a folder of secrets, folders are nested by ParentID.
+//
+// Name is encrypted by client, so server can't read it and doesn't check its uniqueness.
+type Folder struct {
+	ID        int       `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	ParentID  *int      `json:"parent_id"`
+	Name      []byte    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Tag - is a free-form label of secrets, Name is encrypted by client.
+type Tag struct {
+	ID        int       `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      []byte    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
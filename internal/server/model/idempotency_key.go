@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	UserID      string    `json:"user_id"`
+	Method      string    `json:"method"`
+	RequestHash []byte    `json:"request_hash"`
+	Response    []byte    `json:"response"`
+	CreatedAt   time.Time `json:"created_at"`
+}
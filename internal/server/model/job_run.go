@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	JobRunStatusRunning   = "running"
+	JobRunStatusSucceeded = "succeeded"
+	JobRunStatusFailed    = "failed"
+)
+
+const (
+	// JobRunsDefaultLimit - is a number of listed job runs, if it's not provided.
+	JobRunsDefaultLimit = 20
+	// JobRunsMaxLimit - is a max number of listed job runs.
+	JobRunsMaxLimit = 500
+)
+
+type JobRun struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Instance   string     `json:"instance"`
+	Status     string     `json:"status"`
+	Message    string     `json:"message"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
@@ -6,13 +6,116 @@ import (
 	"github.com/google/uuid"
 )
 
+// Secret - is a stored secret, its Content and Title are encrypted by the client.
+//
+// TitleHash is a blind index of a title, which is a keyed hash computed by the client, so secrets could be found by
+// title and titles could be kept unique without revealing them to the server.
 type Secret struct {
 	ID        int        `json:"ID"`
+	PublicID  uuid.UUID  `json:"public_id"`
 	UserID    uuid.UUID  `json:"user_id"`
 	TypeID    int        `json:"type_id"`
 	Title     string     `json:"title"`
+	TitleHash []byte     `json:"title_hash"`
 	Content   []byte     `json:"content"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at"`
+	Version   int64      `json:"version"`
+	FolderID  *int       `json:"folder_id"`
+	TagIDs    []int      `json:"tag_ids"`
+}
+
+const (
+	// SecretsDefaultLimit - is a number of secrets in a page, if it's not provided.
+	SecretsDefaultLimit = 100
+	// SecretsMaxLimit - is a max number of secrets in a page.
+	SecretsMaxLimit = 1000
+	// SecretsMaxBatchSize - is a max number of secrets in a batch request.
+	SecretsMaxBatchSize = 500
+)
+
+// SecretTrash - selects secrets by their presence in trash.
+type SecretTrash int
+
+const (
+	// SecretsActive - selects secrets, which are not in trash.
+	SecretsActive SecretTrash = iota
+	// SecretsTrashed - selects secrets, which are in trash.
+	SecretsTrashed
+	// SecretsAll - selects secrets regardless of trash.
+	SecretsAll
+)
+
+// SecretOrder - is a key secrets are sorted by, secrets with equal keys are sorted by ID.
+type SecretOrder string
+
+const (
+	SecretOrderID        SecretOrder = "id"
+	SecretOrderUpdatedAt SecretOrder = "updated_at"
+)
+
+// SecretCursor - is a position of a secret in a sorted list of secrets, next page starts after it.
+//
+// Only ID and a value of a key secrets are sorted by are compared.
+type SecretCursor struct {
+	ID        int       `json:"id"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// NewSecretCursor - returns SecretCursor pointing to provided secret.
+func NewSecretCursor(secret Secret) SecretCursor {
+	return SecretCursor{ID: secret.ID, UpdatedAt: secret.UpdatedAt}
+}
+
+// SecretFilter - selects a page of secrets of a user.
+type SecretFilter struct {
+	UserID uuid.UUID
+	// FolderID - selects secrets of a folder, zero value selects secrets without folder, nil selects all of them.
+	FolderID *int
+	// TagID - selects secrets with a tag, zero value selects all of them.
+	TagID int
+	// TypeID - selects secrets of a type, zero value selects all of them.
+	TypeID int
+	// TitleHash - selects secrets with a blind index of a title, empty one selects all of them.
+	TitleHash []byte
+	// UpdatedSince - selects secrets updated at or after provided time, nil selects all of them.
+	UpdatedSince *time.Time
+	Trash        SecretTrash
+	// Order - is a key secrets are sorted by, secrets are sorted by ID if it's empty.
+	Order SecretOrder
+	Desc  bool
+	// After - selects secrets following provided one in sorted list, nil selects from the first one.
+	After *SecretCursor
+	// Limit - is a max number of selected secrets, zero value selects all of them.
+	Limit int
+	// WithoutContent - skips Content of selected secrets.
+	WithoutContent bool
+}
+
+// OrderKey - returns Order of the filter, SecretOrderID if it's not set.
+func (f SecretFilter) OrderKey() SecretOrder {
+	if f.Order == "" {
+		return SecretOrderID
+	}
+
+	return f.Order
+}
+
+// Less - reports whether a goes before b in a list sorted by the filter.
+func (f SecretFilter) Less(a, b SecretCursor) bool {
+	less, equal := a.ID < b.ID, true
+	switch f.OrderKey() {
+	case SecretOrderUpdatedAt:
+		less, equal = a.UpdatedAt.Before(b.UpdatedAt), a.UpdatedAt.Equal(b.UpdatedAt)
+	}
+	if equal {
+		less = a.ID < b.ID
+	}
+
+	if f.Desc {
+		return !less && a.ID != b.ID
+	}
+
+	return less
 }
@@ -1,6 +1,30 @@
 package model
 
+const (
+	// SecretFieldKindText - is a kind of single line text field.
+	SecretFieldKindText = "text"
+	// SecretFieldKindMultiline - is a kind of text field, which could take multiple words and lines.
+	SecretFieldKindMultiline = "multiline"
+	// SecretFieldKindFile - is a kind of field, which content is a file, it is stored as raw content of a secret.
+	SecretFieldKindFile = "file"
+)
+
 type SecretType struct {
-	ID    uint   `json:"id"`
-	Title string `json:"title"`
+	ID     uint              `json:"id"`
+	Title  string            `json:"title"`
+	Fields []SecretTypeField `json:"fields"`
+}
+
+// SecretTypeField - describes a field of content of secrets of a type, which is used by clients to build their flows.
+type SecretTypeField struct {
+	// Name - is a key of the field in content of a secret.
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+	// Required - is true if value of the field can't be empty.
+	Required bool `json:"required"`
+	// Sensitive - is true if value of the field has to be hidden on display.
+	Sensitive bool `json:"sensitive"`
+	// Pattern - is a regular expression, which value of the field has to match, if it's not empty.
+	Pattern string `json:"pattern"`
 }
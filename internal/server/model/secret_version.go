@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SecretVersion struct {
+	SecretID  int       `json:"secret_id"`
+	PublicID  uuid.UUID `json:"public_id"`
+	Version   int64     `json:"version"`
+	TypeID    int       `json:"type_id"`
+	Title     string    `json:"title"`
+	TitleHash []byte    `json:"title_hash"`
+	Content   []byte    `json:"content"`
+	ChangedBy uuid.UUID `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
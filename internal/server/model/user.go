@@ -1,9 +1,54 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
 type User struct {
-	ID       *uuid.UUID `json:"id"`
-	Login    string     `json:"login" validate:"gte=3"`
-	Password string     `json:"-" validate:"gte=3"`
+	ID              *uuid.UUID `json:"id"`
+	Login           string     `json:"login" validate:"gte=3"`
+	Password        string     `json:"-" validate:"gte=3"`
+	Role            string     `json:"role"`
+	CreatedAt       time.Time  `json:"created_at"`
+	DisabledAt      *time.Time `json:"disabled_at"`
+	TokensRevokedAt *time.Time `json:"-"`
+	// VaultKey - is a key of a vault of the user wrapped by the client, it's returned on login only.
+	VaultKey []byte `json:"-"`
+}
+
+// UserUsage - is a user with amount of data stored by it.
+type UserUsage struct {
+	User           User  `json:"user"`
+	Secrets        int64 `json:"secrets"`
+	TrashedSecrets int64 `json:"trashed_secrets"`
+	Size           int64 `json:"size"`
+}
+
+// UsersStats - is a summary of users and data stored by them.
+type UsersStats struct {
+	Users          int64 `json:"users"`
+	DisabledUsers  int64 `json:"disabled_users"`
+	Secrets        int64 `json:"secrets"`
+	TrashedSecrets int64 `json:"trashed_secrets"`
+	SecretVersions int64 `json:"secret_versions"`
+	Size           int64 `json:"size"`
+}
+
+// TemporaryPassword - generates random password, which is given to a user on reset.
+func TemporaryPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
@@ -0,0 +1,139 @@
+// Package scheduler is a package that runs registered maintenance jobs of the server on schedules.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+// Job - is a maintenance task, which is run by Scheduler.
+type Job interface {
+	// Name - returns unique name of a job, which is used as a name of its lock and in run history.
+	Name() string
+	// Run - runs a job and returns short summary of done work.
+	Run(ctx context.Context) (string, error)
+}
+
+// Scheduler - runs registered jobs on their schedules and keeps history of runs.
+//
+// Only one replica of the server runs each job: before run replica has to take a lock of a job, which is kept
+// until connection of JobServerStorage is lost, so the replica stays a leader of a job for its lifetime.
+type Scheduler struct {
+	ctx      context.Context
+	cron     *cron.Cron
+	storage  storage.JobServerStorage
+	instance string
+	logger   *zap.Logger
+
+	mu     sync.Mutex
+	leader map[string]bool
+}
+
+// NewScheduler - creates new Scheduler, instance is a name of the replica, which is stored in run history.
+func NewScheduler(ctx context.Context, s storage.JobServerStorage, instance string, l *zap.Logger) *Scheduler {
+	return &Scheduler{
+		ctx:      ctx,
+		cron:     cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		storage:  s,
+		instance: instance,
+		logger:   l,
+		leader:   map[string]bool{},
+	}
+}
+
+// Register - schedules a job by cron spec.
+func (s *Scheduler) Register(spec string, job Job) error {
+	if _, err := s.cron.AddFunc(spec, func() { s.run(job) }); err != nil {
+		return fmt.Errorf("job %s scheduling error: %w", job.Name(), err)
+	}
+
+	return nil
+}
+
+// Start - starts running of registered jobs in background.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop - stops scheduling of jobs, returned context is done when running jobs are finished.
+func (s *Scheduler) Stop() context.Context {
+	return s.cron.Stop()
+}
+
+// run - runs a job if the replica is a leader of it and stores result of run.
+func (s *Scheduler) run(job Job) {
+	isLeader, err := s.elect(job.Name())
+	if err != nil {
+		s.logger.Error("job leader election error", zap.String("job", job.Name()), zap.Error(err))
+
+		return
+	}
+
+	if !isLeader {
+		s.logger.Debug("job is run by another replica", zap.String("job", job.Name()))
+
+		return
+	}
+
+	run, err := s.storage.CreateJobRun(s.ctx, model.JobRun{
+		Name:      job.Name(),
+		Instance:  s.instance,
+		Status:    model.JobRunStatusRunning,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("job run storing error", zap.String("job", job.Name()), zap.Error(err))
+
+		return
+	}
+
+	message, errRun := job.Run(s.ctx)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status, run.Message = model.JobRunStatusSucceeded, message
+
+	if errRun != nil {
+		run.Status, run.Message = model.JobRunStatusFailed, errRun.Error()
+
+		s.logger.Error("job run error", zap.String("job", job.Name()), zap.Error(errRun))
+	}
+
+	if err = s.storage.FinishJobRun(s.ctx, run); err != nil {
+		s.logger.Error("job run storing error", zap.String("job", job.Name()), zap.Error(err))
+	}
+}
+
+// elect - reports whether the replica is a leader of a job, trying to take its lock if it's not.
+func (s *Scheduler) elect(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.leader[name] {
+		if err := s.storage.Ping(s.ctx); err != nil {
+			// every lock is released along with lost connection, so leadership of all jobs is lost
+			s.leader = map[string]bool{}
+
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	locked, err := s.storage.TryLock(s.ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	s.leader[name] = locked
+
+	return locked, nil
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+)
+
+type testJob struct {
+	err   error
+	calls int
+}
+
+func (j *testJob) Name() string {
+	return "test"
+}
+
+func (j *testJob) Run(ctx context.Context) (string, error) {
+	j.calls++
+
+	return "done", j.err
+}
+
+func TestScheduler_Register(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	s := NewScheduler(context.Background(), storagemock.NewMockJobServerStorage(ctl), "test", zap.NewNop())
+
+	assert.NoError(t, s.Register("@hourly", &testJob{}))
+	assert.Error(t, s.Register("not a spec", &testJob{}))
+}
+
+func TestScheduler_run(t *testing.T) {
+	tests := []struct {
+		name       string
+		job        *testJob
+		prepare    func(m *storagemock.MockJobServerStorage)
+		wantCalls  int
+		wantStatus string
+	}{
+		{
+			name: "Job is run by leader and its run is stored",
+			job:  &testJob{},
+			prepare: func(m *storagemock.MockJobServerStorage) {
+				m.EXPECT().TryLock(gomock.Any(), "test").Return(true, nil)
+				m.EXPECT().CreateJobRun(gomock.Any(), gomock.Any()).Return(model.JobRun{ID: 1}, nil)
+			},
+			wantCalls:  1,
+			wantStatus: model.JobRunStatusSucceeded,
+		},
+		{
+			name: "Failed job run is stored",
+			job:  &testJob{err: errors.New("test")},
+			prepare: func(m *storagemock.MockJobServerStorage) {
+				m.EXPECT().TryLock(gomock.Any(), "test").Return(true, nil)
+				m.EXPECT().CreateJobRun(gomock.Any(), gomock.Any()).Return(model.JobRun{ID: 1}, nil)
+			},
+			wantCalls:  1,
+			wantStatus: model.JobRunStatusFailed,
+		},
+		{
+			name: "Job is not run if lock is taken by another replica",
+			job:  &testJob{},
+			prepare: func(m *storagemock.MockJobServerStorage) {
+				m.EXPECT().TryLock(gomock.Any(), "test").Return(false, nil)
+			},
+			wantCalls: 0,
+		},
+		{
+			name: "Job is not run on lock error",
+			job:  &testJob{},
+			prepare: func(m *storagemock.MockJobServerStorage) {
+				m.EXPECT().TryLock(gomock.Any(), "test").Return(false, errors.New("test"))
+			},
+			wantCalls: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			storageMock := storagemock.NewMockJobServerStorage(ctl)
+			tt.prepare(storageMock)
+
+			var stored model.JobRun
+			storageMock.EXPECT().FinishJobRun(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+				func(ctx context.Context, run model.JobRun) error {
+					stored = run
+
+					return nil
+				},
+			)
+
+			s := NewScheduler(context.Background(), storageMock, "test", zap.NewNop())
+			s.run(tt.job)
+
+			assert.Equal(t, tt.wantCalls, tt.job.calls)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+		})
+	}
+}
+
+func TestScheduler_elect(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	storageMock := storagemock.NewMockJobServerStorage(ctl)
+
+	s := NewScheduler(context.Background(), storageMock, "test", zap.NewNop())
+
+	storageMock.EXPECT().TryLock(gomock.Any(), "test").Return(true, nil)
+	isLeader, err := s.elect("test")
+	assert.NoError(t, err)
+	assert.True(t, isLeader)
+
+	storageMock.EXPECT().Ping(gomock.Any()).Return(nil)
+	isLeader, err = s.elect("test")
+	assert.NoError(t, err)
+	assert.True(t, isLeader, "taken lock is kept without taking it again")
+
+	storageMock.EXPECT().Ping(gomock.Any()).Return(errors.New("test"))
+	isLeader, err = s.elect("test")
+	assert.Error(t, err)
+	assert.False(t, isLeader, "leadership is lost along with connection")
+
+	storageMock.EXPECT().TryLock(gomock.Any(), "test").Return(false, nil)
+	isLeader, err = s.elect("test")
+	assert.NoError(t, err)
+	assert.False(t, isLeader)
+}
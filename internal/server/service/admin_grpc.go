@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/audit"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+type AdminGrpc struct {
+	pb.UnimplementedAdminServer
+
+	users         storage.UserServerStorage
+	audit         storage.AuditServerStorage
+	jobs          storage.JobServerStorage
+	recorder      audit.Recorder
+	storageDriver string
+	startedAt     time.Time
+}
+
+// NewAdminGrpc - creates new admin grpc service, storageDriver is a name of storage reported in server stats.
+func NewAdminGrpc(
+	u storage.UserServerStorage,
+	a storage.AuditServerStorage,
+	j storage.JobServerStorage,
+	r audit.Recorder,
+	storageDriver string,
+) *AdminGrpc {
+	return &AdminGrpc{
+		users:         u,
+		audit:         a,
+		jobs:          j,
+		recorder:      r,
+		storageDriver: storageDriver,
+		startedAt:     time.Now(),
+	}
+}
+
+// RegisterService - registers service via grpc server.
+func (a *AdminGrpc) RegisterService(r grpc.ServiceRegistrar) {
+	pb.RegisterAdminServer(r, a)
+}
+
+// ListUsers - returns list of users with amount of their data.
+//
+// Can be accessed only by admins.
+func (a *AdminGrpc) ListUsers(ctx context.Context, in *pb.AdminListUsersRequest) (*pb.AdminListUsersResponse, error) {
+	list, err := a.users.ListUsers(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.AdminListUsersResponse{}
+	for _, usage := range list {
+		user := &pb.AdminUser{
+			Id:             usage.User.ID.String(),
+			Login:          usage.User.Login,
+			Role:           usage.User.Role,
+			CreatedAt:      timestamppb.New(usage.User.CreatedAt),
+			Secrets:        usage.Secrets,
+			TrashedSecrets: usage.TrashedSecrets,
+			Size:           usage.Size,
+		}
+
+		if usage.User.DisabledAt != nil {
+			user.DisabledAt = timestamppb.New(*usage.User.DisabledAt)
+		}
+
+		resp.Users = append(resp.Users, user)
+	}
+
+	return resp, nil
+}
+
+// SetUserDisabled - disables or enables a user, disabled user can't login and its issued tokens are revoked.
+//
+// Can be accessed only by admins, admin can't disable itself.
+func (a *AdminGrpc) SetUserDisabled(
+	ctx context.Context, in *pb.SetUserDisabledRequest,
+) (*pb.SetUserDisabledResponse, error) {
+	uid, err := uuid.Parse(in.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if callerID, _ := ctx.Value(auth.JwtTokenCtx{}).(string); in.Disabled && callerID == uid.String() {
+		return nil, status.Error(codes.InvalidArgument, "admin can't disable itself")
+	}
+
+	_, err = a.users.SetUserDisabled(ctx, model.User{ID: &uid}, in.Disabled)
+
+	event := model.AuditEventUserEnable
+	if in.Disabled {
+		event = model.AuditEventUserDisable
+	}
+	a.recorder.Record(ctx, model.AuditEvent{Event: event, Success: err == nil, Target: uid.String()})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.SetUserDisabledResponse{}, nil
+}
+
+// ForcePasswordReset - replaces password of a user with generated temporary one and revokes its issued tokens.
+//
+// Data of the user is encrypted by key of its vault, which can't be unwrapped without the previous password, so reset
+// deletes the vault: secrets, folders and tags of the user. It's refused with codes.FailedPrecondition status unless
+// admin confirms vault loss.
+//
+// Temporary password is returned to admin, who has to pass it to the user. Can be accessed only by admins.
+func (a *AdminGrpc) ForcePasswordReset(
+	ctx context.Context, in *pb.ForcePasswordResetRequest,
+) (*pb.ForcePasswordResetResponse, error) {
+	uid, err := uuid.Parse(in.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if !in.ConfirmVaultLoss {
+		return nil, status.Error(codes.FailedPrecondition,
+			"reset deletes secrets of the user, which can't be decrypted without the previous password, "+
+				"confirm vault loss to reset it")
+	}
+
+	password, err := model.TemporaryPassword()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	_, err = a.users.ResetPassword(ctx, model.User{ID: &uid}, password)
+	a.recorder.Record(ctx, model.AuditEvent{
+		Event: model.AuditEventPasswordReset, Success: err == nil, Target: uid.String(),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ForcePasswordResetResponse{TemporaryPassword: password}, nil
+}
+
+// GetServerStats - returns number of users and number and size of stored secrets.
+//
+// Can be accessed only by admins.
+func (a *AdminGrpc) GetServerStats(ctx context.Context, in *pb.ServerStatsRequest) (*pb.ServerStatsResponse, error) {
+	stats, err := a.users.GetUsersStats(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ServerStatsResponse{
+		Users:          stats.Users,
+		DisabledUsers:  stats.DisabledUsers,
+		Secrets:        stats.Secrets,
+		TrashedSecrets: stats.TrashedSecrets,
+		SecretVersions: stats.SecretVersions,
+		Size:           stats.Size,
+		StorageDriver:  a.storageDriver,
+		StartedAt:      timestamppb.New(a.startedAt),
+	}, nil
+}
+
+// ListAuditEvents - returns a page of audit events of a user, or of all users if user id is empty, latest go first.
+//
+// Can be accessed only by admins.
+func (a *AdminGrpc) ListAuditEvents(
+	ctx context.Context, in *pb.AdminListAuditEventsRequest,
+) (*pb.ListAuditEventsResponse, error) {
+	var userID *uuid.UUID
+
+	if in.UserId != "" {
+		uid, err := uuid.Parse(in.UserId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		userID = &uid
+	}
+
+	return listAuditEvents(ctx, a.audit, userID, in.PageSize, in.PageToken)
+}
+
+// ListJobRuns - returns history of maintenance job runs, latest go first, filtered by job name if it's not empty.
+//
+// Can be accessed only by admins.
+func (a *AdminGrpc) ListJobRuns(
+	ctx context.Context, in *pb.AdminListJobRunsRequest,
+) (*pb.AdminListJobRunsResponse, error) {
+	limit := int(in.Limit)
+	if limit <= 0 {
+		limit = model.JobRunsDefaultLimit
+	}
+	if limit > model.JobRunsMaxLimit {
+		limit = model.JobRunsMaxLimit
+	}
+
+	runs, err := a.jobs.ListJobRuns(ctx, in.Name, limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.AdminListJobRunsResponse{}
+	for _, run := range runs {
+		jobRun := &pb.AdminJobRun{
+			Id:        run.ID,
+			Name:      run.Name,
+			Instance:  run.Instance,
+			Status:    run.Status,
+			Message:   run.Message,
+			StartedAt: timestamppb.New(run.StartedAt),
+		}
+
+		if run.FinishedAt != nil {
+			jobRun.FinishedAt = timestamppb.New(*run.FinishedAt)
+		}
+
+		resp.JobRuns = append(resp.JobRuns, jobRun)
+	}
+
+	return resp, nil
+}
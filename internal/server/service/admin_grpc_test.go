@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	auditmock "github.com/sergalkin/gophkeeper/internal/server/middleware/audit/mock"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/memory"
+	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+	cryptmock "github.com/sergalkin/gophkeeper/pkg/crypt/mock"
+	"github.com/sergalkin/gophkeeper/pkg/jwt"
+	jwtmock "github.com/sergalkin/gophkeeper/pkg/jwt/mock"
+)
+
+func TestAdminGrpc_RegularUserIsDenied(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	client := adminTestClient(t, uuid.New(), model.RoleUser)
+
+	_, err := client.ListUsers(ctx, &pb.AdminListUsersRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetServerStats(ctx, &pb.ServerStatsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAdminGrpc_ListUsers(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	client := adminTestClient(t, uuid.New(), model.RoleAdmin)
+
+	resp, err := client.ListUsers(ctx, &pb.AdminListUsersRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Users, 1)
+	assert.Equal(t, "bob", resp.Users[0].Login)
+	assert.NotNil(t, resp.Users[0].DisabledAt)
+	assert.Equal(t, int64(3), resp.Users[0].Secrets)
+}
+
+func TestAdminGrpc_SetUserDisabled(t *testing.T) {
+	adminID := uuid.New()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	client := adminTestClient(t, adminID, model.RoleAdmin)
+
+	tests := []struct {
+		name     string
+		userID   string
+		wantCode codes.Code
+	}{
+		{name: "User can be disabled", userID: bobID.String(), wantCode: codes.OK},
+		{name: "Unknown user can't be disabled", userID: uuid.NewString(), wantCode: codes.NotFound},
+		{name: "Invalid id is rejected", userID: "bob", wantCode: codes.InvalidArgument},
+		{name: "Admin can't disable itself", userID: adminID.String(), wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.SetUserDisabled(ctx, &pb.SetUserDisabledRequest{UserId: tt.userID, Disabled: true})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestAdminGrpc_ForcePasswordReset(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	client := adminTestClient(t, uuid.New(), model.RoleAdmin)
+
+	_, err := client.ForcePasswordReset(ctx, &pb.ForcePasswordResetRequest{UserId: bobID.String()})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "vault loss has to be confirmed")
+
+	resp, err := client.ForcePasswordReset(ctx, &pb.ForcePasswordResetRequest{
+		UserId: bobID.String(), ConfirmVaultLoss: true,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.TemporaryPassword)
+}
+
+func TestAdminGrpc_GetServerStats(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	client := adminTestClient(t, uuid.New(), model.RoleAdmin)
+
+	resp, err := client.GetServerStats(ctx, &pb.ServerStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Users)
+	assert.Equal(t, "memory", resp.StorageDriver)
+}
+
+func TestAdminGrpc_ListAuditEvents(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	audits := memory.NewAuditMemoryStorage(memory.NewDB())
+	aliceID := uuid.New()
+	for _, uid := range []uuid.UUID{bobID, aliceID, bobID} {
+		uid := uid
+		_, err := audits.CreateAuditEvent(context.Background(), model.AuditEvent{UserID: &uid, Event: model.AuditEventLogin})
+		require.NoError(t, err)
+	}
+
+	jobs := memory.NewJobMemoryStorage(memory.NewDB())
+
+	client := adminTestClientWithStorages(t, uuid.New(), model.RoleAdmin, audits, jobs)
+
+	resp, err := client.ListAuditEvents(ctx, &pb.AdminListAuditEventsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Events, 3)
+
+	resp, err = client.ListAuditEvents(ctx, &pb.AdminListAuditEventsRequest{UserId: bobID.String()})
+	require.NoError(t, err)
+	require.Len(t, resp.Events, 2)
+	assert.Equal(t, bobID.String(), resp.Events[0].UserId)
+
+	_, err = client.ListAuditEvents(ctx, &pb.AdminListAuditEventsRequest{UserId: "bob"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAdminGrpc_ListJobRuns(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	db := memory.NewDB()
+	jobs := memory.NewJobMemoryStorage(db)
+	finishedAt := time.Now()
+	for _, name := range []string{"purge-trash", "sign-audit-checkpoint", "purge-trash"} {
+		_, err := jobs.CreateJobRun(context.Background(), model.JobRun{
+			Name: name, Status: model.JobRunStatusSucceeded, StartedAt: finishedAt, FinishedAt: &finishedAt,
+		})
+		require.NoError(t, err)
+	}
+
+	client := adminTestClientWithStorages(t, uuid.New(), model.RoleAdmin, memory.NewAuditMemoryStorage(db), jobs)
+
+	resp, err := client.ListJobRuns(ctx, &pb.AdminListJobRunsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.JobRuns, 3)
+	assert.Equal(t, int64(3), resp.JobRuns[0].Id, "latest run goes first")
+	assert.NotNil(t, resp.JobRuns[0].FinishedAt)
+
+	resp, err = client.ListJobRuns(ctx, &pb.AdminListJobRunsRequest{Name: "purge-trash", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.JobRuns, 1)
+	assert.Equal(t, "purge-trash", resp.JobRuns[0].Name)
+
+	userClient := adminTestClient(t, uuid.New(), model.RoleUser)
+
+	_, err = userClient.ListJobRuns(ctx, &pb.AdminListJobRunsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// bobID - is an id of a user managed by admin in tests.
+var bobID = uuid.New()
+
+// adminTestClient - creates client of admin service, which is called by a user with provided id and role.
+func adminTestClient(t *testing.T, uid uuid.UUID, role string) pb.AdminClient {
+	db := memory.NewDB()
+
+	return adminTestClientWithStorages(t, uid, role, memory.NewAuditMemoryStorage(db), memory.NewJobMemoryStorage(db))
+}
+
+// adminTestClientWithStorages - creates client of admin service with provided storages of audit events and job runs.
+func adminTestClientWithStorages(
+	t *testing.T, uid uuid.UUID, role string, audits storage.AuditServerStorage, jobs storage.JobServerStorage,
+) pb.AdminClient {
+	ctl := gomock.NewController(t)
+	disabledAt := time.Now()
+
+	userStorageMock := storagemock.NewMockUserServerStorage(ctl)
+	userStorageMock.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(model.User{ID: &uid})).
+		AnyTimes().
+		Return(model.User{ID: &uid, Role: role}, nil)
+	userStorageMock.EXPECT().
+		ListUsers(gomock.Any()).
+		AnyTimes().
+		Return([]model.UserUsage{
+			{User: model.User{ID: &bobID, Login: "bob", Role: model.RoleUser, DisabledAt: &disabledAt}, Secrets: 3},
+		}, nil)
+	userStorageMock.EXPECT().
+		SetUserDisabled(gomock.Any(), gomock.Eq(model.User{ID: &bobID}), true).
+		AnyTimes().
+		Return(model.User{ID: &bobID}, nil)
+	userStorageMock.EXPECT().
+		SetUserDisabled(gomock.Any(), gomock.Any(), true).
+		AnyTimes().
+		Return(model.User{}, pgx.ErrNoRows)
+	userStorageMock.EXPECT().
+		ResetPassword(gomock.Any(), gomock.Eq(model.User{ID: &bobID}), gomock.Any()).
+		AnyTimes().
+		Return(model.User{ID: &bobID}, nil)
+	userStorageMock.EXPECT().
+		GetUsersStats(gomock.Any()).
+		AnyTimes().
+		Return(model.UsersStats{Users: 2}, nil)
+
+	claims := jwt.Claims{Role: role}
+	claims.ID = uid.String()
+
+	jwtM := jwtmock.NewMockManager(ctl)
+	jwtM.EXPECT().DecodeClaims(gomock.Any()).AnyTimes().Return(claims, nil)
+
+	cryptM := cryptmock.NewMockCrypter(ctl)
+	cryptM.EXPECT().Decode(gomock.Any()).AnyTimes().Return("token", nil)
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(
+			grpc_middleware.ChainUnaryServer(
+				grpcauth.UnaryServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM, userStorageMock).Auth),
+				auth.NewRoleMiddleware().UnaryServerInterceptor(),
+			)),
+	)
+
+	recorderM := auditmock.NewMockRecorder(ctl)
+	recorderM.EXPECT().Record(gomock.Any(), gomock.Any()).AnyTimes()
+
+	NewAdminGrpc(userStorageMock, audits, jobs, recorderM, "memory").RegisterService(server)
+
+	go func() {
+		if errServe := server.Serve(l); errServe != nil && errServe != grpc.ErrServerStopped {
+			panic(errServe)
+		}
+	}()
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return pb.NewAdminClient(conn)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+type AuditGrpc struct {
+	pb.UnimplementedAuditServer
+
+	storage storage.AuditServerStorage
+}
+
+// NewAuditGrpc - creates new audit grpc service.
+func NewAuditGrpc(s storage.AuditServerStorage) *AuditGrpc {
+	return &AuditGrpc{storage: s}
+}
+
+// RegisterService - registers service via grpc server.
+func (a *AuditGrpc) RegisterService(r grpc.ServiceRegistrar) {
+	pb.RegisterAuditServer(r, a)
+}
+
+// ListAuditEvents - returns a page of audit events of authorized user, latest go first.
+//
+// Next page is requested with next_page_token of previous response, which is empty on the last page.
+func (a *AuditGrpc) ListAuditEvents(
+	ctx context.Context, in *pb.ListAuditEventsRequest,
+) (*pb.ListAuditEventsResponse, error) {
+	uid, err := uuid.Parse(ctx.Value(auth.JwtTokenCtx{}).(string))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return listAuditEvents(ctx, a.storage, &uid, in.PageSize, in.PageToken)
+}
+
+// listAuditEvents - returns a page of audit events of a user, or of all users if userID is nil.
+func listAuditEvents(
+	ctx context.Context, s storage.AuditServerStorage, userID *uuid.UUID, pageSize int32, pageToken string,
+) (*pb.ListAuditEventsResponse, error) {
+	filter := model.AuditEventFilter{UserID: userID, Limit: int(pageSize)}
+
+	if filter.Limit <= 0 {
+		filter.Limit = model.AuditEventsDefaultLimit
+	}
+	if filter.Limit > model.AuditEventsMaxLimit {
+		filter.Limit = model.AuditEventsMaxLimit
+	}
+
+	if pageToken != "" {
+		beforeID, err := strconv.ParseInt(pageToken, 10, 64)
+		if err != nil || beforeID <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+
+		filter.BeforeID = beforeID
+	}
+
+	events, err := s.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.ListAuditEventsResponse{}
+	for _, event := range events {
+		e := &pb.AuditEvent{
+			Id:        event.ID,
+			Event:     event.Event,
+			Success:   event.Success,
+			Target:    event.Target,
+			Ip:        event.IP,
+			UserAgent: event.UserAgent,
+			RequestId: event.RequestID,
+			CreatedAt: timestamppb.New(event.CreatedAt),
+		}
+
+		if event.UserID != nil {
+			e.UserId = event.UserID.String()
+		}
+
+		resp.Events = append(resp.Events, e)
+	}
+
+	if len(events) == filter.Limit {
+		resp.NextPageToken = strconv.FormatInt(events[len(events)-1].ID, 10)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/memory"
+)
+
+func TestAuditGrpc_ListAuditEvents(t *testing.T) {
+	aliceID, bobID := uuid.New(), uuid.New()
+
+	audits := memory.NewAuditMemoryStorage(memory.NewDB())
+	for i := 0; i < 5; i++ {
+		_, err := audits.CreateAuditEvent(context.Background(), model.AuditEvent{
+			UserID: &aliceID, Event: model.AuditEventSecretRead, Target: "secret",
+		})
+		require.NoError(t, err)
+
+		_, err = audits.CreateAuditEvent(context.Background(), model.AuditEvent{
+			UserID: &bobID, Event: model.AuditEventSecretRead,
+		})
+		require.NoError(t, err)
+	}
+
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, aliceID.String())
+	s := NewAuditGrpc(audits)
+
+	var events []*pb.AuditEvent
+	req := &pb.ListAuditEventsRequest{PageSize: 2}
+	for pages := 1; ; pages++ {
+		resp, err := s.ListAuditEvents(ctx, req)
+		require.NoError(t, err)
+		require.LessOrEqual(t, pages, 3)
+
+		events = append(events, resp.Events...)
+		if resp.NextPageToken == "" {
+			break
+		}
+
+		req.PageToken = resp.NextPageToken
+	}
+
+	require.Len(t, events, 5, "only events of the user are returned")
+	for i, event := range events {
+		assert.Equal(t, aliceID.String(), event.UserId)
+
+		if i > 0 {
+			assert.Less(t, event.Id, events[i-1].Id, "latest events go first")
+		}
+	}
+
+	_, err := s.ListAuditEvents(ctx, &pb.ListAuditEventsRequest{PageToken: "next"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
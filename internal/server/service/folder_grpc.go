@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+type FolderGrpc struct {
+	pb.UnimplementedFolderServer
+
+	storage storage.FolderServerStorage
+}
+
+// NewFolderGrpc - creates new folder grpc service, which serves folders and tags of secrets.
+//
+// Names of folders and tags are encrypted by a client, so server keeps them as is and can't check their uniqueness.
+func NewFolderGrpc(s storage.FolderServerStorage) *FolderGrpc {
+	return &FolderGrpc{storage: s}
+}
+
+// RegisterService - registers service via grpc server.
+func (s *FolderGrpc) RegisterService(r grpc.ServiceRegistrar) {
+	pb.RegisterFolderServer(r, s)
+}
+
+// CreateFolder - stores a folder of a user from ctx, folder is created in root if parent id is 0.
+func (s *FolderGrpc) CreateFolder(ctx context.Context, in *pb.CreateFolderRequest) (*pb.CreateFolderResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	if len(in.Name) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "name of a folder can't be empty")
+	}
+
+	folder := model.Folder{UserID: uuid.MustParse(tok), Name: in.Name, CreatedAt: time.Now()}
+	if in.ParentId != 0 {
+		parentID := int(in.ParentId)
+		folder.ParentID = &parentID
+	}
+
+	created, err := s.storage.CreateFolder(ctx, folder)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.CreateFolderResponse{Folder: folderToPb(created)}, nil
+}
+
+// ListFolders - returns list of all folders of a user from ctx.
+func (s *FolderGrpc) ListFolders(ctx context.Context, in *pb.ListFoldersRequest) (*pb.ListFoldersResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	userID := uuid.MustParse(tok)
+	folders, err := s.storage.ListFolders(ctx, model.User{ID: &userID})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var list []*pb.FolderItem
+	for _, folder := range folders {
+		list = append(list, folderToPb(folder))
+	}
+
+	return &pb.ListFoldersResponse{Folders: list}, nil
+}
+
+// CreateTag - stores a tag of a user from ctx.
+func (s *FolderGrpc) CreateTag(ctx context.Context, in *pb.CreateTagRequest) (*pb.CreateTagResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	if len(in.Name) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "name of a tag can't be empty")
+	}
+
+	created, err := s.storage.CreateTag(ctx, model.Tag{UserID: uuid.MustParse(tok), Name: in.Name, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.CreateTagResponse{Tag: tagToPb(created)}, nil
+}
+
+// ListTags - returns list of all tags of a user from ctx.
+func (s *FolderGrpc) ListTags(ctx context.Context, in *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	userID := uuid.MustParse(tok)
+	tags, err := s.storage.ListTags(ctx, model.User{ID: &userID})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var list []*pb.TagItem
+	for _, tag := range tags {
+		list = append(list, tagToPb(tag))
+	}
+
+	return &pb.ListTagsResponse{Tags: list}, nil
+}
+
+// folderToPb - casts model.Folder to pb.FolderItem.
+func folderToPb(folder model.Folder) *pb.FolderItem {
+	return &pb.FolderItem{
+		Id:        uint32(folder.ID),
+		ParentId:  folderIDToPb(folder.ParentID),
+		Name:      folder.Name,
+		CreatedAt: timestamppb.New(folder.CreatedAt),
+	}
+}
+
+// tagToPb - casts model.Tag to pb.TagItem.
+func tagToPb(tag model.Tag) *pb.TagItem {
+	return &pb.TagItem{
+		Id:        uint32(tag.ID),
+		Name:      tag.Name,
+		CreatedAt: timestamppb.New(tag.CreatedAt),
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/memory"
+)
+
+func TestFolderGrpc_Folders(t *testing.T) {
+	db := memory.NewDB()
+	users := memory.NewUserMemoryStorage(db)
+
+	alice, err := users.Create(context.Background(), model.User{Login: "alice", Password: "test"})
+	require.NoError(t, err)
+	bob, err := users.Create(context.Background(), model.User{Login: "bob", Password: "test"})
+	require.NoError(t, err)
+
+	s := NewFolderGrpc(memory.NewFolderMemoryStorage(db))
+	aliceCtx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+	bobCtx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, bob.ID.String())
+
+	parent, err := s.CreateFolder(aliceCtx, &pb.CreateFolderRequest{Name: []byte("work")})
+	require.NoError(t, err)
+	assert.Zero(t, parent.Folder.ParentId)
+
+	child, err := s.CreateFolder(aliceCtx, &pb.CreateFolderRequest{ParentId: parent.Folder.Id, Name: []byte("db")})
+	require.NoError(t, err)
+	assert.Equal(t, parent.Folder.Id, child.Folder.ParentId)
+
+	_, err = s.CreateFolder(bobCtx, &pb.CreateFolderRequest{ParentId: parent.Folder.Id, Name: []byte("db")})
+	assert.Equal(t, codes.NotFound, status.Code(err), "folder can't be created in a folder of another user")
+
+	_, err = s.CreateFolder(aliceCtx, &pb.CreateFolderRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := s.ListFolders(aliceCtx, &pb.ListFoldersRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.Folders, 2)
+
+	list, err = s.ListFolders(bobCtx, &pb.ListFoldersRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Folders)
+}
+
+func TestFolderGrpc_Tags(t *testing.T) {
+	db := memory.NewDB()
+
+	alice, err := memory.NewUserMemoryStorage(db).Create(
+		context.Background(), model.User{Login: "alice", Password: "test"},
+	)
+	require.NoError(t, err)
+
+	s := NewFolderGrpc(memory.NewFolderMemoryStorage(db))
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+
+	created, err := s.CreateTag(ctx, &pb.CreateTagRequest{Name: []byte("personal")})
+	require.NoError(t, err)
+	assert.NotZero(t, created.Tag.Id)
+
+	_, err = s.CreateTag(ctx, &pb.CreateTagRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := s.ListTags(ctx, &pb.ListTagsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Tags, 1)
+	assert.Equal(t, []byte("personal"), list.Tags[0].Name)
+}
+
+func TestFolderGrpc_RegisterService(t *testing.T) {
+	s := NewFolderGrpc(memory.NewFolderMemoryStorage(memory.NewDB()))
+
+	s.RegisterService(grpc.NewServer())
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+)
+
+// BatchCreateSecrets - stores provided secrets in one transaction, see CreateSecret.
+//
+// Results are returned in order of secrets of request. Secrets rejected by a client error, e.g. a public id of
+// another user, are reported by status of their results and don't affect other ones, any other error rolls back
+// the whole batch.
+func (s *SecretGrpc) BatchCreateSecrets(
+	ctx context.Context, in *pb.BatchCreateSecretsRequest,
+) (*pb.BatchCreateSecretsResponse, error) {
+	if err := checkBatchSize(len(in.Secrets)); err != nil {
+		return nil, err
+	}
+
+	var resp *pb.BatchCreateSecretsResponse
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		resp = &pb.BatchCreateSecretsResponse{Results: make([]*pb.BatchCreateSecretsResult, 0, len(in.Secrets))}
+
+		for _, item := range in.Secrets {
+			secret, err := s.CreateSecret(ctx, item)
+
+			st, errItem := batchItemStatus(err)
+			if errItem != nil {
+				return errItem
+			}
+
+			resp.Results = append(resp.Results, &pb.BatchCreateSecretsResult{Status: st, Secret: secret})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	return resp, nil
+}
+
+// BatchGetSecrets - returns stored secrets in one transaction, see GetSecret.
+//
+// Results are returned in order of secrets of request, secrets, which are not found, are reported by status of their
+// results.
+func (s *SecretGrpc) BatchGetSecrets(
+	ctx context.Context, in *pb.BatchGetSecretsRequest,
+) (*pb.BatchGetSecretsResponse, error) {
+	if err := checkBatchSize(len(in.Secrets)); err != nil {
+		return nil, err
+	}
+
+	var resp *pb.BatchGetSecretsResponse
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		resp = &pb.BatchGetSecretsResponse{Results: make([]*pb.BatchGetSecretsResult, 0, len(in.Secrets))}
+
+		for _, item := range in.Secrets {
+			secret, err := s.GetSecret(ctx, item)
+
+			st, errItem := batchItemStatus(err)
+			if errItem != nil {
+				return errItem
+			}
+
+			resp.Results = append(resp.Results, &pb.BatchGetSecretsResult{Status: st, Secret: secret})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	return resp, nil
+}
+
+// BatchDeleteSecrets - moves user secrets to trash in one transaction, see DeleteSecret.
+//
+// Results are returned in order of secrets of request, secrets, which are not found, are reported by status of their
+// results and don't affect other ones.
+func (s *SecretGrpc) BatchDeleteSecrets(
+	ctx context.Context, in *pb.BatchDeleteSecretsRequest,
+) (*pb.BatchDeleteSecretsResponse, error) {
+	if err := checkBatchSize(len(in.Secrets)); err != nil {
+		return nil, err
+	}
+
+	var resp *pb.BatchDeleteSecretsResponse
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		resp = &pb.BatchDeleteSecretsResponse{Results: make([]*pb.BatchDeleteSecretsResult, 0, len(in.Secrets))}
+
+		for _, item := range in.Secrets {
+			_, err := s.DeleteSecret(ctx, item)
+
+			st, errItem := batchItemStatus(err)
+			if errItem != nil {
+				return errItem
+			}
+
+			resp.Results = append(resp.Results, &pb.BatchDeleteSecretsResult{Status: st})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	return resp, nil
+}
+
+// checkBatchSize - returns codes.InvalidArgument status if a batch has more than model.SecretsMaxBatchSize items.
+func checkBatchSize(size int) error {
+	if size > model.SecretsMaxBatchSize {
+		return status.Error(
+			codes.InvalidArgument, fmt.Sprintf("batch has %d items, max is %d", size, model.SecretsMaxBatchSize),
+		)
+	}
+
+	return nil
+}
+
+// batchItemStatus - returns status of an item of a batch from an error of its handler.
+//
+// Client errors are returned as status of an item, other errors are returned as is, so the batch is rolled back.
+func batchItemStatus(err error) (*pb.BatchItemStatus, error) {
+	st := status.Convert(err)
+
+	switch st.Code() {
+	case codes.OK, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition:
+		return &pb.BatchItemStatus{Code: int32(st.Code()), Message: st.Message()}, nil
+	default:
+		return nil, err
+	}
+}
+
+// batchError - returns a status error of a rolled back batch.
+func batchError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
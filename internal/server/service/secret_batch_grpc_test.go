@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/memory"
+)
+
+func TestSecretGrpc_BatchSecrets(t *testing.T) {
+	db := memory.NewDB()
+
+	users := memory.NewUserMemoryStorage(db)
+	alice, err := users.Create(context.Background(), model.User{Login: "alice", Password: "test"})
+	require.NoError(t, err)
+	bob, err := users.Create(context.Background(), model.User{Login: "bob", Password: "test"})
+	require.NoError(t, err)
+
+	s := NewSecretGrpc(memory.NewSecretMemoryStorage(db), memory.NewTransactor(db), 10)
+	aliceCtx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+	bobCtx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, bob.ID.String())
+
+	bobsPublicID := uuid.NewString()
+	_, err = s.CreateSecret(bobCtx, &pb.CreateSecretRequest{Title: "bob", Type: 1, PublicId: bobsPublicID})
+	require.NoError(t, err)
+
+	created, err := s.BatchCreateSecrets(aliceCtx, &pb.BatchCreateSecretsRequest{Secrets: []*pb.CreateSecretRequest{
+		{Title: "first", Type: 1, Content: []byte("1"), PublicId: uuid.NewString()},
+		{Title: "taken", Type: 1, PublicId: bobsPublicID},
+		{Title: "second", Type: 2, Content: []byte("2")},
+	}})
+	require.NoError(t, err)
+	require.Len(t, created.Results, 3)
+	assert.Equal(t, int32(codes.OK), created.Results[0].Status.Code)
+	assert.Equal(t, int32(codes.AlreadyExists), created.Results[1].Status.Code, "failed item doesn't stop others")
+	assert.Equal(t, "second", created.Results[2].Secret.Title)
+
+	got, err := s.BatchGetSecrets(aliceCtx, &pb.BatchGetSecretsRequest{Secrets: []*pb.GetSecretRequest{
+		{Id: int32(created.Results[2].Secret.Id)}, {Id: 1000},
+	}})
+	require.NoError(t, err)
+	require.Len(t, got.Results, 2)
+	assert.Equal(t, []byte("2"), got.Results[0].Secret.Content)
+	assert.Equal(t, int32(codes.NotFound), got.Results[1].Status.Code)
+	assert.Nil(t, got.Results[1].Secret)
+
+	deleted, err := s.BatchDeleteSecrets(aliceCtx, &pb.BatchDeleteSecretsRequest{Secrets: []*pb.DeleteSecretRequest{
+		{PublicId: created.Results[0].Secret.PublicId}, {PublicId: bobsPublicID},
+	}})
+	require.NoError(t, err)
+	require.Len(t, deleted.Results, 2)
+	assert.Equal(t, int32(codes.OK), deleted.Results[0].Status.Code)
+	assert.Equal(t, int32(codes.NotFound), deleted.Results[1].Status.Code, "secret of another user isn't deleted")
+
+	list, err := s.ListSecrets(aliceCtx, &pb.ListSecretsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Secrets, 1)
+	assert.Equal(t, "second", list.Secrets[0].Title)
+
+	tooBig := &pb.BatchDeleteSecretsRequest{Secrets: make([]*pb.DeleteSecretRequest, model.SecretsMaxBatchSize+1)}
+	_, err = s.BatchDeleteSecrets(aliceCtx, tooBig)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSecretGrpc_BatchCreateSecrets_RollsBack(t *testing.T) {
+	db := memory.NewDB()
+
+	alice, err := memory.NewUserMemoryStorage(db).Create(
+		context.Background(), model.User{Login: "alice", Password: "test"},
+	)
+	require.NoError(t, err)
+
+	s := NewSecretGrpc(memory.NewSecretMemoryStorage(db), memory.NewTransactor(db), 10)
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+
+	_, err = s.BatchCreateSecrets(ctx, &pb.BatchCreateSecretsRequest{Secrets: []*pb.CreateSecretRequest{
+		{Title: "first", Type: 1}, {Title: "unknown type", Type: 100},
+	}})
+	assert.Error(t, err)
+
+	list, err := s.ListSecrets(ctx, &pb.ListSecretsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Secrets, "batch is created in one transaction")
+}
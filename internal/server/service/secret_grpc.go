@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +13,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/sergalkin/gophkeeper/api/proto"
@@ -22,29 +26,53 @@ import (
 type SecretGrpc struct {
 	pb.UnimplementedSecretServer
 
-	storage storage.SecretServerStorage
+	storage           storage.SecretServerStorage
+	tx                storage.Transactor
+	versionsRetention int
 }
 
 // NewSecretGrpc - creates new secret grpc service.
-func NewSecretGrpc(s storage.SecretServerStorage) *SecretGrpc {
+//
+// On each change of a secret only versionsRetention latest prior versions of it are kept, zero value keeps all of them.
+// Change of a secret and trimming of its versions are done in one transaction of tx.
+func NewSecretGrpc(s storage.SecretServerStorage, tx storage.Transactor, versionsRetention int) *SecretGrpc {
 	return &SecretGrpc{
-		storage: s,
+		storage:           s,
+		tx:                tx,
+		versionsRetention: versionsRetention,
 	}
 }
 
+// maxTitleHashSize - is a max size of a blind index of a title.
+const maxTitleHashSize = 64
+
 // RegisterService - registers service via grpc server.
 func (s *SecretGrpc) RegisterService(r grpc.ServiceRegistrar) {
 	pb.RegisterSecretServer(r, s)
 }
 
 // CreateSecret - stores a provided secret via initialised storage.
+//
+// Title is encrypted by the client, which sends blind index of the title along with it, so the secret could be found
+// by title. Titles aren't unique, so several secrets could have the same blind index.
 func (s *SecretGrpc) CreateSecret(ctx context.Context, in *pb.CreateSecretRequest) (*pb.CreateSecretResponse, error) {
 	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
 
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	if errHash := checkTitleHash(in.TitleHash); errHash != nil {
+		return nil, errHash
+	}
+
 	secret := model.Secret{
+		PublicID:  publicID,
 		UserID:    uuid.MustParse(tok),
 		TypeID:    int(in.Type),
 		Title:     in.Title,
+		TitleHash: in.TitleHash,
 		Content:   in.Content,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -52,6 +80,10 @@ func (s *SecretGrpc) CreateSecret(ctx context.Context, in *pb.CreateSecretReques
 
 	m, err := s.storage.CreateSecret(ctx, secret)
 	if err != nil {
+		if errors.Is(err, apperr.ErrConflict) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -64,6 +96,8 @@ func (s *SecretGrpc) CreateSecret(ctx context.Context, in *pb.CreateSecretReques
 
 	return &pb.CreateSecretResponse{
 		Id:        uint32(m.ID),
+		PublicId:  m.PublicID.String(),
+		Version:   m.Version,
 		Title:     m.Title,
 		Type:      uint32(m.TypeID),
 		CreatedAt: timestamppb.New(m.CreatedAt),
@@ -76,12 +110,18 @@ func (s *SecretGrpc) CreateSecret(ctx context.Context, in *pb.CreateSecretReques
 func (s *SecretGrpc) GetSecret(ctx context.Context, in *pb.GetSecretRequest) (*pb.GetSecretResponse, error) {
 	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
 
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
 	secret := model.Secret{
-		UserID: uuid.MustParse(tok),
-		ID:     int(in.Id),
+		UserID:   uuid.MustParse(tok),
+		ID:       int(in.Id),
+		PublicID: publicID,
 	}
 
-	m, err := s.storage.GetSecret(ctx, secret)
+	m, err := s.storage.GetSecret(ctx, secret, in.IncludeTrashed)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -100,23 +140,33 @@ func (s *SecretGrpc) GetSecret(ctx context.Context, in *pb.GetSecretRequest) (*p
 
 	return &pb.GetSecretResponse{
 		Id:        uint32(m.ID),
+		PublicId:  m.PublicID.String(),
+		Version:   m.Version,
 		Title:     m.Title,
 		Type:      uint32(m.TypeID),
 		Content:   m.Content,
 		CreatedAt: timestamppb.New(m.CreatedAt),
 		UpdatedAt: timestamppb.New(m.UpdatedAt),
 		DeletedAt: &deletedAt,
+		FolderId:  folderIDToPb(m.FolderID),
+		TagIds:    tagIDsToPb(m.TagIDs),
 	}, nil
 
 }
 
-// DeleteSecret - deletes a user secret from the storage by provide id and userId from ctx.
+// DeleteSecret - moves a user secret to trash by provided id or public id and userId from ctx.
 func (s *SecretGrpc) DeleteSecret(ctx context.Context, in *pb.DeleteSecretRequest) (*pb.DeleteSecretResponse, error) {
 	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
 
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
 	secret := model.Secret{
-		UserID: uuid.MustParse(tok),
-		ID:     int(in.Id),
+		UserID:   uuid.MustParse(tok),
+		ID:       int(in.Id),
+		PublicID: publicID,
 	}
 
 	_, err := s.storage.DeleteSecret(ctx, secret)
@@ -130,25 +180,130 @@ func (s *SecretGrpc) DeleteSecret(ctx context.Context, in *pb.DeleteSecretReques
 	return &pb.DeleteSecretResponse{}, nil
 }
 
-// EditSecret - edits secret in storage.
+// editPaths - are paths of fields of pb.EditSecretRequest, which can be updated.
+var editPaths = map[string]bool{"title": true, "content": true, "folder_id": true, "tag_ids": true}
+
+// errTypeChange - is returned on attempt to change type of a secret.
+var errTypeChange = status.Error(
+	codes.InvalidArgument, "type of a secret can't be changed, its encrypted content can't be re-validated by server",
+)
+
+// EditSecret - edits secret in storage, only fields listed in update mask are changed: title, content, folder_id and
+// tag_ids. Title and content are changed if mask is empty, empty title and content keep the stored ones then.
+// Blind index of a title is replaced along with title.
+//
+// Change of title or content increments version of a secret. If version of a secret from request doesn't match with
+// stored one, then returns codes.FailedPrecondition status with pb.VersionConflict details, which contains current
+// version of a secret. Folder and tags are changed regardless of version.
+//
+// Type of a secret can't be changed, because its content is encrypted and can't be re-validated against schema of
+// another type by server, so type path or a type, which differs from stored one, is rejected with
+// codes.InvalidArgument status before anything is changed.
 func (s *SecretGrpc) EditSecret(ctx context.Context, in *pb.EditSecretRequest) (*pb.EditSecretResponse, error) {
 	token := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	paths := []string{"title", "content"}
+	if len(in.UpdateMask.GetPaths()) > 0 {
+		paths = in.UpdateMask.GetPaths()
+		for _, path := range paths {
+			if path == "type" {
+				return nil, errTypeChange
+			}
+
+			if !editPaths[path] {
+				return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("field %s can't be updated", path))
+			}
+		}
+
+		if hasPath(paths, "title") && in.Title == "" {
+			return nil, status.Error(codes.InvalidArgument, "title is required")
+		}
+	}
+
 	secret := model.Secret{
-		ID:        int(in.Id),
-		UserID:    uuid.MustParse(token),
-		Title:     in.Title,
-		TypeID:    int(in.Type),
-		Content:   in.Content,
-		UpdatedAt: in.UpdatedAt.AsTime(),
+		ID:       int(in.Id),
+		PublicID: publicID,
+		UserID:   uuid.MustParse(token),
+		Version:  in.Version,
+	}
+	if hasPath(paths, "title") {
+		if errHash := checkTitleHash(in.TitleHash); errHash != nil {
+			return nil, errHash
+		}
+
+		secret.Title, secret.TitleHash = in.Title, in.TitleHash
+	}
+	if hasPath(paths, "content") {
+		secret.Content = in.Content
+		if len(in.UpdateMask.GetPaths()) > 0 && secret.Content == nil {
+			secret.Content = []byte{}
+		}
+	}
+
+	var folderID *int
+	if in.FolderId != 0 {
+		id := int(in.FolderId)
+		folderID = &id
 	}
 
-	updatedSecret, err := s.storage.EditSecret(ctx, secret, in.IsForce)
+	tagIDs, errTags := parseTagIDs(in.TagIds)
+	if errTags != nil && hasPath(paths, "tag_ids") {
+		return nil, errTags
+	}
+
+	var updatedSecret model.Secret
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// type is checked before any change, whatever fields are updated
+		if in.Type != 0 {
+			stored, errGet := s.storage.GetSecret(ctx, secret, false)
+			if errGet != nil {
+				return errGet
+			}
+
+			if int(in.Type) != stored.TypeID {
+				return errTypeChange
+			}
+		}
+
+		var errEdit error
+		if hasPath(paths, "title") || hasPath(paths, "content") {
+			if updatedSecret, errEdit = s.storage.EditSecret(ctx, secret, in.IsForce); errEdit != nil {
+				return errEdit
+			}
+
+			if errEdit = s.trimVersions(ctx, updatedSecret); errEdit != nil {
+				return errEdit
+			}
+		}
+
+		if hasPath(paths, "folder_id") {
+			if updatedSecret, errEdit = s.storage.MoveSecret(ctx, secret, folderID); errEdit != nil {
+				return errEdit
+			}
+		}
+
+		if hasPath(paths, "tag_ids") {
+			if updatedSecret, errEdit = s.storage.SetSecretTags(ctx, secret, tagIDs); errEdit != nil {
+				return errEdit
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
-		if errors.Is(err, apperr.ErrUpdatedAtDoesntMatch) {
-			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		if errors.Is(err, apperr.ErrVersionDoesntMatch) {
+			return nil, versionConflictStatus(updatedSecret, err)
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
 		}
 
 		return nil, status.Error(codes.Internal, err.Error())
@@ -165,11 +320,15 @@ func (s *SecretGrpc) EditSecret(ctx context.Context, in *pb.EditSecretRequest) (
 
 	return &pb.EditSecretResponse{
 		Id:        uint32(updatedSecret.ID),
+		PublicId:  updatedSecret.PublicID.String(),
 		Title:     updatedSecret.Title,
 		Type:      uint32(updatedSecret.TypeID),
 		CreatedAt: timestamppb.New(updatedSecret.CreatedAt),
 		UpdatedAt: timestamppb.New(updatedSecret.UpdatedAt),
 		DeletedAt: &deletedAt,
+		Version:   updatedSecret.Version,
+		FolderId:  folderIDToPb(updatedSecret.FolderID),
+		TagIds:    tagIDsToPb(updatedSecret.TagIDs),
 	}, nil
 }
 
@@ -187,11 +346,372 @@ func (s *SecretGrpc) GetListOfSecretsByType(
 	user := model.User{ID: &userId}
 	secretType := model.SecretType{ID: uint(in.TypeId)}
 
-	secrets, err := s.storage.GetListOfSecretByType(ctx, secretType, user)
+	secrets, err := s.storage.GetListOfSecretByType(ctx, secretType, user, in.IncludeTrashed)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.GetListOfSecretsByTypeResponse{SecretLists: secretsToPb(secrets)}, nil
+}
+
+// ListTrash - returns list of user secrets, which are in trash.
+func (s *SecretGrpc) ListTrash(ctx context.Context, in *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	token := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	userId, errParse := uuid.Parse(token)
+	if errParse != nil {
+		return nil, status.Error(codes.Internal, errParse.Error())
+	}
+
+	secrets, err := s.storage.ListTrash(ctx, model.User{ID: &userId})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ListTrashResponse{Secrets: secretsToPb(secrets)}, nil
+}
+
+// RestoreSecret - moves a user secret out of trash by provided id or public id and userId from ctx.
+func (s *SecretGrpc) RestoreSecret(ctx context.Context, in *pb.RestoreSecretRequest) (*pb.RestoreSecretResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	secret := model.Secret{
+		UserID:   uuid.MustParse(tok),
+		ID:       int(in.Id),
+		PublicID: publicID,
+	}
+
+	restored, err := s.storage.RestoreSecret(ctx, secret)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.RestoreSecretResponse{
+		Id:        uint32(restored.ID),
+		PublicId:  restored.PublicID.String(),
+		Title:     restored.Title,
+		Type:      uint32(restored.TypeID),
+		CreatedAt: timestamppb.New(restored.CreatedAt),
+		UpdatedAt: timestamppb.New(restored.UpdatedAt),
+		DeletedAt: &pb.NullableDeletedAt{Kind: nil},
+		Version:   restored.Version,
+	}, nil
+}
+
+// PurgeSecret - permanently deletes a user secret, which is in trash, by provided id or public id and userId from ctx.
+func (s *SecretGrpc) PurgeSecret(ctx context.Context, in *pb.PurgeSecretRequest) (*pb.PurgeSecretResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	secret := model.Secret{
+		UserID:   uuid.MustParse(tok),
+		ID:       int(in.Id),
+		PublicID: publicID,
+	}
+
+	_, err := s.storage.PurgeSecret(ctx, secret)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.PurgeSecretResponse{}, nil
+}
+
+// ListSecrets - returns a page of user secrets selected by folder, tag, type, blind index of a title, time of update
+// and presence in trash, sorted by provided order. Titles are encrypted by the client, so secrets can't be sorted by
+// them and title order is rejected with codes.InvalidArgument status.
+//
+// Next page is requested with next_page_token of previous response, which is empty on the last page. Only fields
+// listed in fields mask are returned, content isn't read from storage unless it's listed.
+func (s *SecretGrpc) ListSecrets(ctx context.Context, in *pb.ListSecretsRequest) (*pb.ListSecretsResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	filter := model.SecretFilter{
+		UserID:    uuid.MustParse(tok),
+		TagID:     int(in.TagId),
+		TypeID:    int(in.TypeId),
+		TitleHash: in.TitleHash,
+		Desc:      in.Desc,
+		Limit:     int(in.PageSize),
+	}
+	if in.FolderId != 0 || in.InRoot {
+		folderID := int(in.FolderId)
+		filter.FolderID = &folderID
+	}
+
+	if in.UpdatedSince != nil {
+		updatedSince := in.UpdatedSince.AsTime()
+		filter.UpdatedSince = &updatedSince
+	}
+
+	switch in.Trash {
+	case pb.SecretTrash_SECRET_TRASH_ACTIVE:
+		filter.Trash = model.SecretsActive
+	case pb.SecretTrash_SECRET_TRASH_TRASHED:
+		filter.Trash = model.SecretsTrashed
+	case pb.SecretTrash_SECRET_TRASH_ALL:
+		filter.Trash = model.SecretsAll
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown trash filter")
+	}
+
+	switch in.Order {
+	case pb.SecretOrder_SECRET_ORDER_ID:
+		filter.Order = model.SecretOrderID
+	case pb.SecretOrder_SECRET_ORDER_UPDATED_AT:
+		filter.Order = model.SecretOrderUpdatedAt
+	case pb.SecretOrder_SECRET_ORDER_TITLE:
+		return nil, status.Error(
+			codes.InvalidArgument, "secrets can't be sorted by encrypted titles, sort them by decrypted ones instead",
+		)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown order")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = model.SecretsDefaultLimit
+	}
+	if filter.Limit > model.SecretsMaxLimit {
+		filter.Limit = model.SecretsMaxLimit
+	}
+
+	if in.PageToken != "" {
+		cursor, err := decodeSecretCursor(in.PageToken)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+
+		filter.After = &cursor
+	}
+
+	if len(in.Fields.GetPaths()) > 0 {
+		if !in.Fields.IsValid(&pb.SecretList{}) {
+			return nil, status.Error(codes.InvalidArgument, "invalid fields mask")
+		}
+
+		filter.WithoutContent = !hasPath(in.Fields.GetPaths(), "content")
+	}
+
+	secrets, err := s.storage.ListSecrets(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.ListSecretsResponse{Secrets: secretsToPb(secrets)}
+	if len(in.Fields.GetPaths()) > 0 {
+		for _, secret := range resp.Secrets {
+			maskSecret(secret, in.Fields.GetPaths())
+		}
+	}
+
+	if len(secrets) == filter.Limit {
+		resp.NextPageToken = encodeSecretCursor(model.NewSecretCursor(secrets[len(secrets)-1]))
+	}
+
+	return resp, nil
+}
+
+// MoveSecret - moves a user secret to a folder by provided id or public id and userId from ctx.
+//
+// Secret is moved to root if folder id is 0.
+func (s *SecretGrpc) MoveSecret(ctx context.Context, in *pb.MoveSecretRequest) (*pb.MoveSecretResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	secret := model.Secret{
+		UserID:   uuid.MustParse(tok),
+		ID:       int(in.Id),
+		PublicID: publicID,
+	}
+
+	var folderID *int
+	if in.FolderId != 0 {
+		id := int(in.FolderId)
+		folderID = &id
+	}
+
+	moved, err := s.storage.MoveSecret(ctx, secret, folderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.MoveSecretResponse{Secret: secretsToPb([]model.Secret{moved})[0]}, nil
+}
+
+// SetSecretTags - replaces tags of a user secret by provided id or public id and userId from ctx.
+func (s *SecretGrpc) SetSecretTags(
+	ctx context.Context, in *pb.SetSecretTagsRequest,
+) (*pb.SetSecretTagsResponse, error) {
+	tok := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	secret := model.Secret{
+		UserID:   uuid.MustParse(tok),
+		ID:       int(in.Id),
+		PublicID: publicID,
+	}
+
+	tagIDs, errTags := parseTagIDs(in.TagIds)
+	if errTags != nil {
+		return nil, errTags
+	}
+
+	tagged, err := s.storage.SetSecretTags(ctx, secret, tagIDs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.SetSecretTagsResponse{Secret: secretsToPb([]model.Secret{tagged})[0]}, nil
+}
+
+// ListSecretVersions - returns list of versions of a secret without their content, including the current one.
+func (s *SecretGrpc) ListSecretVersions(
+	ctx context.Context, in *pb.ListSecretVersionsRequest,
+) (*pb.ListSecretVersionsResponse, error) {
+	token := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	secret := model.Secret{ID: int(in.Id), PublicID: publicID, UserID: uuid.MustParse(token)}
+
+	versions, err := s.storage.ListSecretVersions(ctx, secret)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if len(versions) == 0 {
+		return nil, status.Error(codes.NotFound, "secret not found")
+	}
+
+	resp := &pb.ListSecretVersionsResponse{}
+	for _, version := range versions {
+		castedVersion := secretVersionToPb(version)
+		castedVersion.Content = nil
+
+		resp.Versions = append(resp.Versions, castedVersion)
+	}
+
+	return resp, nil
+}
+
+// GetSecretVersion - returns provided version of a secret.
+func (s *SecretGrpc) GetSecretVersion(
+	ctx context.Context, in *pb.GetSecretVersionRequest,
+) (*pb.GetSecretVersionResponse, error) {
+	token := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	secret := model.Secret{ID: int(in.Id), PublicID: publicID, UserID: uuid.MustParse(token)}
+
+	version, err := s.storage.GetSecretVersion(ctx, secret, in.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.GetSecretVersionResponse{Version: secretVersionToPb(version)}, nil
+}
+
+// RestoreSecretVersion - restores a secret to provided version, current content of a secret is kept as a prior
+// version.
+func (s *SecretGrpc) RestoreSecretVersion(
+	ctx context.Context, in *pb.RestoreSecretVersionRequest,
+) (*pb.RestoreSecretVersionResponse, error) {
+	token := ctx.Value(auth.JwtTokenCtx{}).(string)
+
+	publicID, errParse := parsePublicID(in.PublicId)
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	secret := model.Secret{ID: int(in.Id), PublicID: publicID, UserID: uuid.MustParse(token)}
+
+	var restored model.Secret
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var errRestore error
+		if restored, errRestore = s.storage.RestoreSecretVersion(ctx, secret, in.Version); errRestore != nil {
+			return errRestore
+		}
+
+		return s.trimVersions(ctx, restored)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var deletedAt pb.NullableDeletedAt
+	if restored.DeletedAt != nil {
+		deletedAt = pb.NullableDeletedAt{Kind: &pb.NullableDeletedAt_Data{Data: timestamppb.New(*restored.DeletedAt)}}
+	} else {
+		deletedAt = pb.NullableDeletedAt{Kind: nil}
+	}
+
+	return &pb.RestoreSecretVersionResponse{
+		Id:        uint32(restored.ID),
+		PublicId:  restored.PublicID.String(),
+		Title:     restored.Title,
+		Type:      uint32(restored.TypeID),
+		CreatedAt: timestamppb.New(restored.CreatedAt),
+		UpdatedAt: timestamppb.New(restored.UpdatedAt),
+		DeletedAt: &deletedAt,
+		Version:   restored.Version,
+	}, nil
+}
+
+// trimVersions - removes prior versions of a secret, which are out of configured retention.
+func (s *SecretGrpc) trimVersions(ctx context.Context, secret model.Secret) error {
+	if s.versionsRetention <= 0 {
+		return nil
+	}
+
+	return s.storage.TrimSecretVersions(ctx, secret, s.versionsRetention)
+}
+
+// secretsToPb - casts []model.Secret to []*pb.SecretList.
+func secretsToPb(secrets []model.Secret) []*pb.SecretList {
 	var castedSecrets []*pb.SecretList
 
 	for _, val := range secrets {
@@ -204,6 +724,8 @@ func (s *SecretGrpc) GetListOfSecretsByType(
 
 		castedSecrets = append(castedSecrets, &pb.SecretList{
 			Id:        uint32(val.ID),
+			PublicId:  val.PublicID.String(),
+			Version:   val.Version,
 			UserId:    val.UserID.String(),
 			TypeId:    uint32(val.TypeID),
 			Title:     val.Title,
@@ -211,8 +733,153 @@ func (s *SecretGrpc) GetListOfSecretsByType(
 			CreatedAt: timestamppb.New(val.CreatedAt),
 			UpdatedAt: timestamppb.New(val.UpdatedAt),
 			DeletedAt: &deletedAt,
+			FolderId:  folderIDToPb(val.FolderID),
+			TagIds:    tagIDsToPb(val.TagIDs),
+			TitleHash: val.TitleHash,
 		})
 	}
 
-	return &pb.GetListOfSecretsByTypeResponse{SecretLists: castedSecrets}, nil
+	return castedSecrets
+}
+
+// checkTitleHash - returns codes.InvalidArgument status if blind index of a title is too long.
+func checkTitleHash(hash []byte) error {
+	if len(hash) > maxTitleHashSize {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("title hash must be at most %d bytes", maxTitleHashSize))
+	}
+
+	return nil
+}
+
+// parseTagIDs - returns ids of tags without duplicates, codes.InvalidArgument status is returned for zero id.
+func parseTagIDs(ids []uint32) ([]int, error) {
+	var tagIDs []int
+
+	seen := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			return nil, status.Error(codes.InvalidArgument, "tag id must be positive")
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			tagIDs = append(tagIDs, int(id))
+		}
+	}
+
+	return tagIDs, nil
+}
+
+// maskSecret - clears fields of a secret, which are not listed in paths.
+func maskSecret(secret *pb.SecretList, paths []string) {
+	m := secret.ProtoReflect()
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !hasPath(paths, string(fd.Name())) {
+			m.Clear(fd)
+		}
+
+		return true
+	})
+}
+
+// hasPath - reports whether path is listed in paths.
+func hasPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodeSecretCursor - encodes model.SecretCursor to an opaque page token.
+func encodeSecretCursor(cursor model.SecretCursor) string {
+	b, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeSecretCursor - decodes model.SecretCursor from a page token.
+func decodeSecretCursor(token string) (model.SecretCursor, error) {
+	var cursor model.SecretCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, err
+	}
+
+	if err = json.Unmarshal(b, &cursor); err != nil {
+		return cursor, err
+	}
+
+	if cursor.ID <= 0 {
+		return cursor, errors.New("cursor without id")
+	}
+
+	return cursor, nil
+}
+
+// folderIDToPb - casts optional id of a folder of a secret to pb, 0 stands for root.
+func folderIDToPb(folderID *int) uint32 {
+	if folderID == nil {
+		return 0
+	}
+
+	return uint32(*folderID)
+}
+
+// tagIDsToPb - casts ids of tags of a secret to pb.
+func tagIDsToPb(tagIDs []int) []uint32 {
+	var ids []uint32
+	for _, id := range tagIDs {
+		ids = append(ids, uint32(id))
+	}
+
+	return ids
+}
+
+// secretVersionToPb - casts model.SecretVersion to pb.SecretVersion.
+func secretVersionToPb(version model.SecretVersion) *pb.SecretVersion {
+	return &pb.SecretVersion{
+		SecretId:  uint32(version.SecretID),
+		PublicId:  version.PublicID.String(),
+		Version:   version.Version,
+		Type:      uint32(version.TypeID),
+		Title:     version.Title,
+		Content:   version.Content,
+		ChangedBy: version.ChangedBy.String(),
+		ChangedAt: timestamppb.New(version.ChangedAt),
+	}
+}
+
+// versionConflictStatus - returns codes.FailedPrecondition status with current version of a secret in details.
+func versionConflictStatus(current model.Secret, err error) error {
+	st, errDetails := status.New(codes.FailedPrecondition, err.Error()).WithDetails(&pb.VersionConflict{
+		Id:             uint32(current.ID),
+		PublicId:       current.PublicID.String(),
+		CurrentVersion: current.Version,
+		UpdatedAt:      timestamppb.New(current.UpdatedAt),
+	})
+	if errDetails != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return st.Err()
+}
+
+// parsePublicID - parses optional public id of a secret from request.
+//
+// Empty public id is treated as uuid.Nil, malformed one results in codes.InvalidArgument status.
+func parsePublicID(publicID string) (uuid.UUID, error) {
+	if publicID == "" {
+		return uuid.Nil, nil
+	}
+
+	parsed, err := uuid.Parse(publicID)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "public_id must be a valid uuid")
+	}
+
+	return parsed, nil
 }
@@ -11,22 +11,31 @@ import (
 	"github.com/google/uuid"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/sergalkin/gophkeeper/api/proto"
 	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
 	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/memory"
 	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
+	"github.com/sergalkin/gophkeeper/pkg/apperr"
 	cryptmock "github.com/sergalkin/gophkeeper/pkg/crypt/mock"
+	"github.com/sergalkin/gophkeeper/pkg/jwt"
 	jwtmock "github.com/sergalkin/gophkeeper/pkg/jwt/mock"
 )
 
 var loc, _ = time.LoadLocation("UTC")
 var now = time.Now().In(loc)
+var publicID = uuid.New()
 
 func TestSecretGrpc_RegisterService(t *testing.T) {
 	ctl := gomock.NewController(t)
@@ -43,7 +52,7 @@ func TestSecretGrpc_RegisterService(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewSecretGrpc(secretMock)
+			s := NewSecretGrpc(secretMock, storagemock.NewMockTransactor(ctl), 10)
 
 			server := grpc.NewServer()
 
@@ -64,6 +73,12 @@ func TestSecretGrpc_CreateSecret(t *testing.T) {
 
 	_, err := client.CreateSecret(ctx, &pb.CreateSecretRequest{})
 	assert.NoError(t, err)
+
+	_, err = client.CreateSecret(ctx, &pb.CreateSecretRequest{PublicId: uuid.NewString()})
+	assert.NoError(t, err)
+
+	_, err = client.CreateSecret(ctx, &pb.CreateSecretRequest{PublicId: "not-a-uuid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
 }
 
 func TestSecretGrpc_GetSecret(t *testing.T) {
@@ -81,6 +96,13 @@ func TestSecretGrpc_GetSecret(t *testing.T) {
 
 	_, err = client.GetSecret(ctx, &pb.GetSecretRequest{Id: 0})
 	assert.Error(t, err)
+
+	_, err = client.GetSecret(ctx, &pb.GetSecretRequest{PublicId: publicID.String()})
+	assert.NoError(t, err)
+
+	res, err := client.GetSecret(ctx, &pb.GetSecretRequest{Id: 3, IncludeTrashed: true})
+	assert.NoError(t, err)
+	assert.NotNil(t, res.DeletedAt.GetData())
 }
 
 func TestSecretGrpc_DeleteSecret(t *testing.T) {
@@ -115,6 +137,16 @@ func TestSecretGrpc_EditSecret(t *testing.T) {
 
 	_, err = client.EditSecret(ctx, &pb.EditSecretRequest{Id: 0, IsForce: true, UpdatedAt: timestamppb.New(now)})
 	assert.Error(t, err)
+
+	_, err = client.EditSecret(ctx, &pb.EditSecretRequest{Id: 2, Version: 1})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+
+	if assert.Len(t, st.Details(), 1) {
+		conflict, ok := st.Details()[0].(*pb.VersionConflict)
+		assert.True(t, ok)
+		assert.Equal(t, int64(3), conflict.CurrentVersion)
+	}
 }
 
 func TestSecretGrpc_GetListOfSecretsByType(t *testing.T) {
@@ -132,6 +164,183 @@ func TestSecretGrpc_GetListOfSecretsByType(t *testing.T) {
 	assert.Len(t, res.SecretLists, 2)
 }
 
+func TestSecretGrpc_ListSecretVersions(t *testing.T) {
+	uid := uuid.New()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := secretTestClient(t, ctl, uid)
+	defer close(done)
+
+	res, err := client.ListSecretVersions(ctx, &pb.ListSecretVersionsRequest{Id: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Versions, 2) {
+		assert.Equal(t, int64(2), res.Versions[0].Version)
+		assert.Nil(t, res.Versions[0].Content)
+	}
+
+	_, err = client.ListSecretVersions(ctx, &pb.ListSecretVersionsRequest{Id: 0})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSecretGrpc_GetSecretVersion(t *testing.T) {
+	uid := uuid.New()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := secretTestClient(t, ctl, uid)
+	defer close(done)
+
+	res, err := client.GetSecretVersion(ctx, &pb.GetSecretVersionRequest{Id: 1, Version: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("old"), res.Version.Content)
+
+	_, err = client.GetSecretVersion(ctx, &pb.GetSecretVersionRequest{Id: 1, Version: 5})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSecretGrpc_RestoreSecretVersion(t *testing.T) {
+	uid := uuid.New()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := secretTestClient(t, ctl, uid)
+	defer close(done)
+
+	res, err := client.RestoreSecretVersion(ctx, &pb.RestoreSecretVersionRequest{Id: 1, Version: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.Version)
+
+	_, err = client.RestoreSecretVersion(ctx, &pb.RestoreSecretVersionRequest{Id: 1, Version: 5})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSecretGrpc_ListTrash(t *testing.T) {
+	uid := uuid.New()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := secretTestClient(t, ctl, uid)
+	defer close(done)
+
+	res, err := client.ListTrash(ctx, &pb.ListTrashRequest{})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Secrets, 1) {
+		assert.NotNil(t, res.Secrets[0].DeletedAt.GetData())
+	}
+}
+
+func TestSecretGrpc_RestoreSecret(t *testing.T) {
+	uid := uuid.New()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := secretTestClient(t, ctl, uid)
+	defer close(done)
+
+	res, err := client.RestoreSecret(ctx, &pb.RestoreSecretRequest{Id: 3})
+	assert.NoError(t, err)
+	assert.Nil(t, res.DeletedAt.GetData())
+
+	_, err = client.RestoreSecret(ctx, &pb.RestoreSecretRequest{Id: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSecretGrpc_PurgeSecret(t *testing.T) {
+	uid := uuid.New()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := secretTestClient(t, ctl, uid)
+	defer close(done)
+
+	_, err := client.PurgeSecret(ctx, &pb.PurgeSecretRequest{Id: 3})
+	assert.NoError(t, err)
+
+	_, err = client.PurgeSecret(ctx, &pb.PurgeSecretRequest{Id: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSecretGrpc_OrganiseSecrets(t *testing.T) {
+	db := memory.NewDB()
+
+	alice, err := memory.NewUserMemoryStorage(db).Create(
+		context.Background(), model.User{Login: "alice", Password: "test"},
+	)
+	require.NoError(t, err)
+
+	secrets := memory.NewSecretMemoryStorage(db)
+	folders := memory.NewFolderMemoryStorage(db)
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+
+	folder, err := folders.CreateFolder(ctx, model.Folder{UserID: *alice.ID, Name: []byte("work")})
+	require.NoError(t, err)
+	tag, err := folders.CreateTag(ctx, model.Tag{UserID: *alice.ID, Name: []byte("personal")})
+	require.NoError(t, err)
+
+	s := NewSecretGrpc(secrets, memory.NewTransactor(db), 10)
+
+	var ids []uint32
+	for _, title := range []string{"moved", "kept"} {
+		created, errCreate := s.CreateSecret(ctx, &pb.CreateSecretRequest{Title: title, Type: 1, Content: []byte("{}")})
+		require.NoError(t, errCreate)
+
+		ids = append(ids, created.Id)
+	}
+
+	moved, err := s.MoveSecret(ctx, &pb.MoveSecretRequest{Id: ids[0], FolderId: uint32(folder.ID)})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(folder.ID), moved.Secret.FolderId)
+	assert.Equal(t, int64(1), moved.Secret.Version, "moving doesn't change version")
+
+	_, err = s.MoveSecret(ctx, &pb.MoveSecretRequest{Id: ids[1], FolderId: 100})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	tagged, err := s.SetSecretTags(ctx, &pb.SetSecretTagsRequest{
+		Id: ids[1], TagIds: []uint32{uint32(tag.ID), uint32(tag.ID)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{uint32(tag.ID)}, tagged.Secret.TagIds)
+
+	_, err = s.SetSecretTags(ctx, &pb.SetSecretTagsRequest{Id: ids[1], TagIds: []uint32{0}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	tests := []struct {
+		name string
+		req  *pb.ListSecretsRequest
+		want []uint32
+	}{
+		{name: "All secrets", req: &pb.ListSecretsRequest{}, want: ids},
+		{name: "Secrets of a folder", req: &pb.ListSecretsRequest{FolderId: uint32(folder.ID)}, want: ids[:1]},
+		{name: "Secrets in root", req: &pb.ListSecretsRequest{InRoot: true}, want: ids[1:]},
+		{name: "Secrets with a tag", req: &pb.ListSecretsRequest{TagId: uint32(tag.ID)}, want: ids[1:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, errList := s.ListSecrets(ctx, tt.req)
+			require.NoError(t, errList)
+
+			var got []uint32
+			for _, secret := range resp.Secrets {
+				got = append(got, secret.Id)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func secretTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.SecretClient, chan<- struct{}) {
 	done := make(chan struct{})
 
@@ -140,13 +349,17 @@ func secretTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.S
 	secretStorageMock.EXPECT().CreateSecret(gomock.Any(), gomock.Any()).AnyTimes().Return(model.Secret{}, nil)
 
 	secretStorageMock.EXPECT().
-		GetSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid})).
+		GetSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid}), gomock.Eq(false)).
 		AnyTimes().
 		Return(model.Secret{}, nil)
 	secretStorageMock.EXPECT().
-		GetSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 0, UserID: uid})).
+		GetSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 0, UserID: uid}), gomock.Eq(false)).
 		AnyTimes().
 		Return(model.Secret{}, errors.New("test"))
+	secretStorageMock.EXPECT().
+		GetSecret(gomock.Any(), gomock.Eq(model.Secret{PublicID: publicID, UserID: uid}), gomock.Eq(false)).
+		AnyTimes().
+		Return(model.Secret{PublicID: publicID}, nil)
 
 	secretStorageMock.EXPECT().
 		DeleteSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid})).
@@ -158,23 +371,90 @@ func secretTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.S
 		Return(model.Secret{}, errors.New("test"))
 
 	secretStorageMock.EXPECT().
-		EditSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid, UpdatedAt: now}), gomock.Eq(true)).
+		EditSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid}), gomock.Eq(true)).
 		AnyTimes().
 		Return(model.Secret{}, nil)
 	secretStorageMock.EXPECT().
-		EditSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 0, UserID: uid, UpdatedAt: now}), gomock.Eq(true)).
+		EditSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 0, UserID: uid}), gomock.Eq(true)).
 		AnyTimes().
 		Return(model.Secret{}, errors.New("test"))
+	secretStorageMock.EXPECT().
+		EditSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 2, UserID: uid, Version: 1}), gomock.Eq(false)).
+		AnyTimes().
+		Return(model.Secret{ID: 2, UserID: uid, Version: 3}, apperr.ErrVersionDoesntMatch)
 
-	secretStorageMock.EXPECT().GetListOfSecretByType(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+	secretStorageMock.EXPECT().
+		GetSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 3, UserID: uid}), gomock.Eq(true)).
+		AnyTimes().
+		Return(model.Secret{ID: 3, DeletedAt: &now}, nil)
+
+	secretStorageMock.EXPECT().GetListOfSecretByType(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
 		Return([]model.Secret{
 			{ID: 1},
 			{ID: 2},
 		}, nil)
 
+	secretStorageMock.EXPECT().
+		ListSecretVersions(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid})).
+		AnyTimes().
+		Return([]model.SecretVersion{{SecretID: 1, Version: 2, Content: []byte("new")}, {SecretID: 1, Version: 1}}, nil)
+	secretStorageMock.EXPECT().
+		ListSecretVersions(gomock.Any(), gomock.Eq(model.Secret{ID: 0, UserID: uid})).
+		AnyTimes().
+		Return(nil, nil)
+
+	secretStorageMock.EXPECT().
+		GetSecretVersion(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid}), gomock.Eq(int64(1))).
+		AnyTimes().
+		Return(model.SecretVersion{SecretID: 1, Version: 1, Content: []byte("old")}, nil)
+	secretStorageMock.EXPECT().
+		GetSecretVersion(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid}), gomock.Eq(int64(5))).
+		AnyTimes().
+		Return(model.SecretVersion{}, pgx.ErrNoRows)
+
+	secretStorageMock.EXPECT().
+		RestoreSecretVersion(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid}), gomock.Eq(int64(1))).
+		AnyTimes().
+		Return(model.Secret{ID: 1, Version: 3}, nil)
+	secretStorageMock.EXPECT().
+		RestoreSecretVersion(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid}), gomock.Eq(int64(5))).
+		AnyTimes().
+		Return(model.Secret{}, pgx.ErrNoRows)
+
+	secretStorageMock.EXPECT().ListTrash(gomock.Any(), gomock.Eq(model.User{ID: &uid})).AnyTimes().
+		Return([]model.Secret{{ID: 3, DeletedAt: &now}}, nil)
+
+	secretStorageMock.EXPECT().
+		RestoreSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 3, UserID: uid})).
+		AnyTimes().
+		Return(model.Secret{ID: 3}, nil)
+	secretStorageMock.EXPECT().
+		RestoreSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid})).
+		AnyTimes().
+		Return(model.Secret{}, pgx.ErrNoRows)
+
+	secretStorageMock.EXPECT().
+		PurgeSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 3, UserID: uid})).
+		AnyTimes().
+		Return(model.Secret{}, nil)
+	secretStorageMock.EXPECT().
+		PurgeSecret(gomock.Any(), gomock.Eq(model.Secret{ID: 1, UserID: uid})).
+		AnyTimes().
+		Return(model.Secret{}, pgx.ErrNoRows)
+
+	secretStorageMock.EXPECT().TrimSecretVersions(gomock.Any(), gomock.Any(), gomock.Eq(10)).AnyTimes().Return(nil)
+
 	jwtM := jwtmock.NewMockManager(ctl)
-	jwtM.EXPECT().Issue(uid.String()).AnyTimes().Return("token", nil)
-	jwtM.EXPECT().Decode(gomock.Any()).AnyTimes().Return(uid.String(), nil)
+	jwtM.EXPECT().Issue(uid.String(), gomock.Any()).AnyTimes().Return("token", nil)
+	claims := jwt.Claims{}
+	claims.ID = uid.String()
+	jwtM.EXPECT().DecodeClaims(gomock.Any()).AnyTimes().Return(claims, nil)
+
+	userStorageMock := storagemock.NewMockUserServerStorage(ctl)
+	userStorageMock.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(model.User{ID: &uid})).
+		AnyTimes().
+		Return(model.User{ID: &uid, Login: "test"}, nil)
 
 	cryptM := cryptmock.NewMockCrypter(ctl)
 	cryptM.EXPECT().Encode(gomock.Any()).AnyTimes().Return("token")
@@ -185,16 +465,23 @@ func secretTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.S
 		t.Fatal(err)
 	}
 
-	secretRpc := NewSecretGrpc(secretStorageMock)
+	txMock := storagemock.NewMockTransactor(ctl)
+	txMock.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+
+	secretRpc := NewSecretGrpc(secretStorageMock, txMock, 10)
 
 	server := grpc.NewServer(
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
-				grpcauth.UnaryServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM).Auth),
+				grpcauth.UnaryServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM, userStorageMock).Auth),
 			)),
 		grpc.StreamInterceptor(
 			grpc_middleware.ChainStreamServer(
-				grpcauth.StreamServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM).Auth),
+				grpcauth.StreamServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM, userStorageMock).Auth),
 			)),
 	)
 
@@ -226,3 +513,203 @@ func secretTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.S
 
 	return client, done
 }
+
+func TestSecretGrpc_ListSecrets(t *testing.T) {
+	db := memory.NewDB()
+
+	alice, err := memory.NewUserMemoryStorage(db).Create(
+		context.Background(), model.User{Login: "alice", Password: "test"},
+	)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+	s := NewSecretGrpc(memory.NewSecretMemoryStorage(db), memory.NewTransactor(db), 10)
+
+	for i, title := range []string{"c", "a", "e", "b", "d"} {
+		_, err = s.CreateSecret(ctx, &pb.CreateSecretRequest{Title: title, Type: uint32(1 + i%2), Content: []byte("{}")})
+		require.NoError(t, err)
+	}
+
+	var titles []string
+	req := &pb.ListSecretsRequest{
+		Order: pb.SecretOrder_SECRET_ORDER_ID, PageSize: 2, Fields: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	}
+	for pages := 1; ; pages++ {
+		resp, errList := s.ListSecrets(ctx, req)
+		require.NoError(t, errList)
+		require.LessOrEqual(t, pages, 3)
+
+		for _, secret := range resp.Secrets {
+			assert.Zero(t, secret.Id, "fields out of mask are cleared")
+			assert.Nil(t, secret.Content)
+
+			titles = append(titles, secret.Title)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+
+		req.PageToken = resp.NextPageToken
+	}
+	assert.Equal(t, []string{"c", "a", "e", "b", "d"}, titles)
+
+	_, err = s.ListSecrets(ctx, &pb.ListSecretsRequest{Order: pb.SecretOrder_SECRET_ORDER_TITLE})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "encrypted titles can't be sorted")
+
+	resp, err := s.ListSecrets(ctx, &pb.ListSecretsRequest{TypeId: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Secrets, 2)
+	assert.Equal(t, []byte("{}"), resp.Secrets[0].Content)
+	assert.Empty(t, resp.NextPageToken)
+
+	_, err = s.ListSecrets(ctx, &pb.ListSecretsRequest{PageToken: "next"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.ListSecrets(ctx, &pb.ListSecretsRequest{Fields: &fieldmaskpb.FieldMask{Paths: []string{"secret"}}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSecretGrpc_EditSecret_UpdateMask(t *testing.T) {
+	db := memory.NewDB()
+
+	alice, err := memory.NewUserMemoryStorage(db).Create(
+		context.Background(), model.User{Login: "alice", Password: "test"},
+	)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+	folder, err := memory.NewFolderMemoryStorage(db).CreateFolder(
+		ctx, model.Folder{UserID: *alice.ID, Name: []byte("work")},
+	)
+	require.NoError(t, err)
+
+	s := NewSecretGrpc(memory.NewSecretMemoryStorage(db), memory.NewTransactor(db), 10)
+
+	created, err := s.CreateSecret(ctx, &pb.CreateSecretRequest{Title: "old", Type: 1, Content: []byte("{}")})
+	require.NoError(t, err)
+
+	renamed, err := s.EditSecret(ctx, &pb.EditSecretRequest{
+		Id: created.Id, Title: "new", Version: 1, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new", renamed.Title)
+	assert.Equal(t, int64(2), renamed.Version)
+
+	got, err := s.GetSecret(ctx, &pb.GetSecretRequest{Id: int32(created.Id)})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("{}"), got.Content, "content out of mask is kept")
+
+	moved, err := s.EditSecret(ctx, &pb.EditSecretRequest{
+		Id: created.Id, FolderId: uint32(folder.ID), UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"folder_id"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(folder.ID), moved.FolderId)
+	assert.Equal(t, "new", moved.Title)
+	assert.Equal(t, int64(2), moved.Version, "moving doesn't change version")
+
+	tests := []struct {
+		name string
+		req  *pb.EditSecretRequest
+		want codes.Code
+	}{
+		{
+			name: "Type path is rejected",
+			req:  &pb.EditSecretRequest{Id: created.Id, Type: 2, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"type"}}},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Unknown path is rejected",
+			req:  &pb.EditSecretRequest{Id: created.Id, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"version"}}},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Title can't be cleared",
+			req:  &pb.EditSecretRequest{Id: created.Id, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}}},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Another type is rejected without mask",
+			req:  &pb.EditSecretRequest{Id: created.Id, Title: "changed", Type: 2, Version: 2, Content: []byte("{}")},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Another type is rejected on moving",
+			req: &pb.EditSecretRequest{
+				Id: created.Id, Type: 2, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"folder_id"}},
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Another type is rejected on tagging",
+			req: &pb.EditSecretRequest{
+				Id: created.Id, Type: 2, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tag_ids"}},
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Type of unknown secret is not found",
+			req:  &pb.EditSecretRequest{Id: created.Id + 100, Title: "changed", Type: 1, Version: 2},
+			want: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errEdit := s.EditSecret(ctx, tt.req)
+			assert.Equal(t, tt.want, status.Code(errEdit))
+		})
+	}
+
+	got, err = s.GetSecret(ctx, &pb.GetSecretRequest{Id: int32(created.Id)})
+	require.NoError(t, err)
+	assert.Equal(t, "new", got.Title, "rejected edit is rolled back")
+	assert.Equal(t, int64(2), got.Version)
+	assert.Equal(t, uint32(folder.ID), got.FolderId, "secret isn't moved out of folder on rejected edit")
+}
+
+func TestSecretGrpc_TitleHash(t *testing.T) {
+	db := memory.NewDB()
+
+	alice, err := memory.NewUserMemoryStorage(db).Create(
+		context.Background(), model.User{Login: "alice", Password: "test"},
+	)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), auth.JwtTokenCtx{}, alice.ID.String())
+	s := NewSecretGrpc(memory.NewSecretMemoryStorage(db), memory.NewTransactor(db), 10)
+
+	created, err := s.CreateSecret(ctx, &pb.CreateSecretRequest{
+		Title: "encrypted", TitleHash: []byte("hash"), Type: 1, Content: []byte("{}"),
+	})
+	require.NoError(t, err)
+
+	duplicate, err := s.CreateSecret(ctx, &pb.CreateSecretRequest{
+		Title: "encrypted again", TitleHash: []byte("hash"), Type: 1, Content: []byte("{}"),
+	})
+	require.NoError(t, err, "titles aren't unique")
+
+	_, err = s.CreateSecret(ctx, &pb.CreateSecretRequest{
+		Title: "encrypted", TitleHash: make([]byte, maxTitleHashSize+1), Type: 1, Content: []byte("{}"),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	other, err := s.CreateSecret(ctx, &pb.CreateSecretRequest{
+		Title: "other", TitleHash: []byte("other"), Type: 1, Content: []byte("{}"),
+	})
+	require.NoError(t, err)
+
+	_, err = s.EditSecret(ctx, &pb.EditSecretRequest{
+		Id: other.Id, Title: "renamed", TitleHash: []byte("renamed"), Version: 1,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	})
+	require.NoError(t, err)
+
+	resp, err := s.ListSecrets(ctx, &pb.ListSecretsRequest{TitleHash: []byte("hash")})
+	require.NoError(t, err)
+	require.Len(t, resp.Secrets, 2)
+	assert.Equal(t, []uint32{created.Id, duplicate.Id}, []uint32{resp.Secrets[0].Id, resp.Secrets[1].Id})
+
+	resp, err = s.ListSecrets(ctx, &pb.ListSecretsRequest{TitleHash: []byte("renamed")})
+	require.NoError(t, err)
+	require.Len(t, resp.Secrets, 1)
+	assert.Equal(t, other.Id, resp.Secrets[0].Id)
+}
@@ -27,7 +27,7 @@ func (s *SecretTypeGrpc) RegisterService(r grpc.ServiceRegistrar) {
 	pb.RegisterSecretTypeServer(r, s)
 }
 
-// GetSecretTypesList - returns list of secret types.
+// GetSecretTypesList - returns list of secret types with schemas of their fields.
 //
 // Can be accessed only by authorized users.
 func (s *SecretTypeGrpc) GetSecretTypesList(
@@ -40,10 +40,23 @@ func (s *SecretTypeGrpc) GetSecretTypesList(
 
 	resp := &pb.SecretTypesListResponse{}
 	for _, secret := range list {
-		resp.Secrets = append(resp.Secrets, &pb.Type{
+		t := &pb.Type{
 			Id:    uint32(secret.ID),
 			Title: secret.Title,
-		})
+		}
+
+		for _, field := range secret.Fields {
+			t.Fields = append(t.Fields, &pb.TypeField{
+				Name:      field.Name,
+				Label:     field.Label,
+				Kind:      field.Kind,
+				Required:  field.Required,
+				Sensitive: field.Sensitive,
+				Pattern:   field.Pattern,
+			})
+		}
+
+		resp.Secrets = append(resp.Secrets, t)
 	}
 
 	return resp, nil
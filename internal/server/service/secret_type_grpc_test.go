@@ -25,7 +25,9 @@ func Test_secretTypeGrpc_GetSecretTypesList(t *testing.T) {
 
 	secretTypeMock.EXPECT().GetSecretTypes(gomock.Any()).AnyTimes().Return(
 		[]model.SecretType{
-			{ID: 1, Title: "Test"},
+			{ID: 1, Title: "Test", Fields: []model.SecretTypeField{
+				{Name: "Pin", Label: "PIN", Kind: model.SecretFieldKindText, Required: true, Sensitive: true, Pattern: "^[0-9]{4}$"},
+			}},
 			{ID: 2, Title: "Test 2"},
 		},
 		nil,
@@ -60,6 +62,16 @@ func Test_secretTypeGrpc_GetSecretTypesList(t *testing.T) {
 	resp, err := client.GetSecretTypesList(ctx, &pb.SecretTypesListRequest{})
 	assert.NoError(t, err)
 	assert.Len(t, resp.Secrets, 2)
+	if assert.Len(t, resp.Secrets[0].Fields, 1) {
+		field := resp.Secrets[0].Fields[0]
+		assert.Equal(t, "Pin", field.Name)
+		assert.Equal(t, "PIN", field.Label)
+		assert.Equal(t, model.SecretFieldKindText, field.Kind)
+		assert.True(t, field.Required)
+		assert.True(t, field.Sensitive)
+		assert.Equal(t, "^[0-9]{4}$", field.Pattern)
+	}
+	assert.Empty(t, resp.Secrets[1].Fields)
 }
 
 func Test_secretTypeGrpc_RegisterService(t *testing.T) {
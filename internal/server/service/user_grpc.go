@@ -12,6 +12,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/sergalkin/gophkeeper/api/proto"
+	"github.com/sergalkin/gophkeeper/internal/server/middleware/audit"
 	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
 	"github.com/sergalkin/gophkeeper/internal/server/model"
 	"github.com/sergalkin/gophkeeper/internal/server/storage"
@@ -20,20 +21,25 @@ import (
 	"github.com/sergalkin/gophkeeper/pkg/jwt"
 )
 
+// maxVaultKeySize - is a max size of a wrapped key of a vault.
+const maxVaultKeySize = 256
+
 type userGrpc struct {
 	pb.UnimplementedUserServer
 
 	storage    storage.UserServerStorage
 	jwtManager jwt.Manager
 	crypter    crypt.Crypter
+	recorder   audit.Recorder
 }
 
 // NewUserGrpc - creates new user grpc service.
-func NewUserGrpc(s storage.UserServerStorage, m jwt.Manager, c crypt.Crypter) *userGrpc {
+func NewUserGrpc(s storage.UserServerStorage, m jwt.Manager, c crypt.Crypter, r audit.Recorder) *userGrpc {
 	return &userGrpc{
 		storage:    s,
 		jwtManager: m,
 		crypter:    c,
+		recorder:   r,
 	}
 }
 
@@ -42,18 +48,25 @@ func (u *userGrpc) RegisterService(r grpc.ServiceRegistrar) {
 	pb.RegisterUserServer(r, u)
 }
 
-// Register - registers a new user.
+// Register - registers a new user along with wrapped key of its vault.
 //
-//On successful creation returns JwtToken.
+// On successful creation returns JwtToken.
 func (u *userGrpc) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	m := model.User{Login: in.Login, Password: in.Password}
+	m := model.User{Login: in.Login, Password: in.Password, VaultKey: in.VaultKey}
 
 	validate := validator.New()
 	if errV := validate.Struct(m); errV != nil {
 		return nil, status.Error(codes.InvalidArgument, errV.Error())
 	}
 
+	if len(m.VaultKey) > maxVaultKeySize {
+		return nil, status.Errorf(codes.InvalidArgument, "vault key is longer than %d bytes", maxVaultKeySize)
+	}
+
 	userModel, err := u.storage.Create(ctx, m)
+	u.recorder.Record(ctx, model.AuditEvent{
+		UserID: userModel.ID, Event: model.AuditEventRegister, Success: err == nil, Target: m.Login,
+	})
 
 	if errors.Is(err, apperr.ErrConflict) {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -63,7 +76,7 @@ func (u *userGrpc) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.Re
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	token, errToken := u.jwtManager.Issue(userModel.ID.String())
+	token, errToken := u.jwtManager.Issue(userModel.ID.String(), userModel.Role)
 	if errToken != nil {
 		return nil, status.Error(codes.Internal, errToken.Error())
 	}
@@ -71,9 +84,14 @@ func (u *userGrpc) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.Re
 	return &pb.RegisterResponse{Token: u.crypter.Encode(token)}, nil
 }
 
-// Login - Will return JwtToken on successful authentication via provided login and password.
+// Login - Will return JwtToken and wrapped key of a vault on successful authentication via provided login and
+// password.
+//
+// Disabled user gets codes.PermissionDenied status. Both successful and failed attempts are recorded to audit log,
+// failed ones are attributed to a user with provided login, if it exists.
 func (u *userGrpc) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
 	userModel, err := u.storage.GetByLoginAndPassword(ctx, model.User{Login: in.Login, Password: in.Password})
+	u.recordLogin(ctx, in.Login, userModel, err == nil && userModel.DisabledAt == nil)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, status.Error(codes.NotFound, err.Error())
@@ -83,12 +101,29 @@ func (u *userGrpc) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginRes
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	token, errToken := u.jwtManager.Issue(userModel.ID.String())
+	if userModel.DisabledAt != nil {
+		return nil, status.Error(codes.PermissionDenied, "user is disabled")
+	}
+
+	token, errToken := u.jwtManager.Issue(userModel.ID.String(), userModel.Role)
 	if errToken != nil {
 		return nil, status.Error(codes.Internal, errToken.Error())
 	}
 
-	return &pb.LoginResponse{Token: u.crypter.Encode(token)}, nil
+	return &pb.LoginResponse{Token: u.crypter.Encode(token), VaultKey: userModel.VaultKey}, nil
+}
+
+// recordLogin - records login attempt to audit log.
+func (u *userGrpc) recordLogin(ctx context.Context, login string, user model.User, success bool) {
+	event := model.AuditEvent{UserID: user.ID, Event: model.AuditEventLogin, Success: success, Target: login}
+
+	if event.UserID == nil {
+		if found, err := u.storage.GetUser(ctx, model.User{Login: login}); err == nil {
+			event.UserID = found.ID
+		}
+	}
+
+	u.recorder.Record(ctx, event)
 }
 
 // Delete - will delete a user from storage by provided ID.
@@ -110,3 +145,73 @@ func (u *userGrpc) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.Delete
 
 	return &pb.DeleteResponse{}, nil
 }
+
+// SetVaultKey - sets wrapped key of a vault of the user, if it isn't set yet, then returns stored key.
+//
+// User registered before vault keys has none, so it's set by the client on login. Stored key is never replaced, so
+// concurrent clients end up with the same one.
+func (u *userGrpc) SetVaultKey(ctx context.Context, in *pb.SetVaultKeyRequest) (*pb.SetVaultKeyResponse, error) {
+	uid, err := uuid.Parse(ctx.Value(auth.JwtTokenCtx{}).(string))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if len(in.VaultKey) == 0 || len(in.VaultKey) > maxVaultKeySize {
+		return nil, status.Errorf(codes.InvalidArgument, "vault key has to be from 1 to %d bytes", maxVaultKeySize)
+	}
+
+	stored, err := u.storage.SetVaultKey(ctx, model.User{ID: &uid}, in.VaultKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.SetVaultKeyResponse{VaultKey: stored}, nil
+}
+
+// ChangePassword - replaces password of the user, if current one is provided, along with key of a vault wrapped by
+// the new password.
+//
+// Key of the vault itself is the same, so data of the user stays readable and issued tokens stay valid. Both
+// successful and failed attempts are recorded to audit log.
+func (u *userGrpc) ChangePassword(
+	ctx context.Context, in *pb.ChangePasswordRequest,
+) (*pb.ChangePasswordResponse, error) {
+	uid, err := uuid.Parse(ctx.Value(auth.JwtTokenCtx{}).(string))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if errV := validator.New().Var(in.NewPassword, "gte=3"); errV != nil {
+		return nil, status.Error(codes.InvalidArgument, errV.Error())
+	}
+
+	if len(in.VaultKey) == 0 || len(in.VaultKey) > maxVaultKeySize {
+		return nil, status.Errorf(codes.InvalidArgument, "vault key has to be from 1 to %d bytes", maxVaultKeySize)
+	}
+
+	user, err := u.storage.GetUser(ctx, model.User{ID: &uid})
+	if err == nil {
+		_, err = u.storage.GetByLoginAndPassword(ctx, model.User{Login: user.Login, Password: in.Password})
+	}
+	if err == nil {
+		_, err = u.storage.ChangePassword(ctx, model.User{ID: &uid}, in.NewPassword, in.VaultKey)
+	}
+
+	u.recorder.Record(ctx, model.AuditEvent{
+		UserID: &uid, Event: model.AuditEventPasswordChange, Success: err == nil, Target: user.Login,
+	})
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Error(codes.PermissionDenied, "password is wrong")
+	}
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ChangePasswordResponse{}, nil
+}
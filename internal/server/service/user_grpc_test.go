@@ -5,22 +5,28 @@ import (
 	"errors"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/sergalkin/gophkeeper/api/proto"
+	auditmock "github.com/sergalkin/gophkeeper/internal/server/middleware/audit/mock"
 	"github.com/sergalkin/gophkeeper/internal/server/middleware/auth"
 	"github.com/sergalkin/gophkeeper/internal/server/model"
 	storagemock "github.com/sergalkin/gophkeeper/internal/server/storage/mock"
 	"github.com/sergalkin/gophkeeper/pkg/apperr"
 	cryptmock "github.com/sergalkin/gophkeeper/pkg/crypt/mock"
+	"github.com/sergalkin/gophkeeper/pkg/jwt"
 	jwtmock "github.com/sergalkin/gophkeeper/pkg/jwt/mock"
 )
 
@@ -41,7 +47,7 @@ func Test_userGrpc_RegisterService(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewUserGrpc(userMock, jwtMock, cryptMock)
+			s := NewUserGrpc(userMock, jwtMock, cryptMock, auditmock.NewMockRecorder(ctl))
 
 			server := grpc.NewServer()
 
@@ -83,17 +89,73 @@ func Test_userGrpc_Login(t *testing.T) {
 	client, done := userTestClient(t, ctl, uid)
 	defer close(done)
 
-	_, err := client.Login(ctx, &pb.LoginRequest{
+	resp, err := client.Login(ctx, &pb.LoginRequest{
 		Login:    "loginOk",
 		Password: "pass",
 	})
 	assert.NoError(t, err)
+	assert.Equal(t, []byte("wrapped"), resp.VaultKey)
 
 	_, err = client.Login(ctx, &pb.LoginRequest{
 		Login:    "logineErr",
 		Password: "pass",
 	})
 	assert.Error(t, err)
+
+	_, err = client.Login(ctx, &pb.LoginRequest{
+		Login:    "loginDisabled",
+		Password: "pass",
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func Test_userGrpc_LoginIsAudited(t *testing.T) {
+	uid := uuid.New()
+
+	tests := []struct {
+		name        string
+		user        model.User
+		userErr     error
+		wantSuccess bool
+	}{
+		{
+			name:        "Successful login is recorded",
+			user:        model.User{ID: &uid, Login: "alice"},
+			wantSuccess: true,
+		},
+		{
+			name:        "Failed login is recorded and attributed to user with attempted login",
+			userErr:     errors.New("user login error"),
+			wantSuccess: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			userStorageMock := storagemock.NewMockUserServerStorage(ctl)
+			userStorageMock.EXPECT().GetByLoginAndPassword(gomock.Any(), gomock.Any()).Return(tt.user, tt.userErr)
+			userStorageMock.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(model.User{Login: "alice"})).
+				AnyTimes().
+				Return(model.User{ID: &uid, Login: "alice"}, nil)
+
+			jwtM := jwtmock.NewMockManager(ctl)
+			jwtM.EXPECT().Issue(gomock.Any(), gomock.Any()).AnyTimes().Return("token", nil)
+
+			cryptM := cryptmock.NewMockCrypter(ctl)
+			cryptM.EXPECT().Encode(gomock.Any()).AnyTimes().Return("token")
+
+			recorderM := auditmock.NewMockRecorder(ctl)
+			recorderM.EXPECT().Record(gomock.Any(), gomock.Eq(model.AuditEvent{
+				UserID: &uid, Event: model.AuditEventLogin, Success: tt.wantSuccess, Target: "alice",
+			}))
+
+			_, _ = NewUserGrpc(userStorageMock, jwtM, cryptM, recorderM).
+				Login(context.Background(), &pb.LoginRequest{Login: "alice", Password: "pass"})
+		})
+	}
 }
 
 func Test_userGrpc_Delete(t *testing.T) {
@@ -111,6 +173,58 @@ func Test_userGrpc_Delete(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_userGrpc_SetVaultKey(t *testing.T) {
+	uid := uuid.New()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := userTestClient(t, ctl, uid)
+	defer close(done)
+
+	resp, err := client.SetVaultKey(ctx, &pb.SetVaultKeyRequest{VaultKey: []byte("wrapped")})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("stored"), resp.VaultKey, "stored key is returned")
+
+	_, err = client.SetVaultKey(ctx, &pb.SetVaultKeyRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.SetVaultKey(ctx, &pb.SetVaultKeyRequest{VaultKey: make([]byte, maxVaultKeySize+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_userGrpc_ChangePassword(t *testing.T) {
+	uid := uuid.New()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	client, done := userTestClient(t, ctl, uid)
+	defer close(done)
+
+	_, err := client.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		Password: "wrong", NewPassword: "changed", VaultKey: []byte("rewrapped"),
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{Password: "pass", NewPassword: "changed"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "password can't be changed without wrapped vault key")
+
+	_, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		Password: "pass", NewPassword: "ch", VaultKey: []byte("rewrapped"),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		Password: "pass", NewPassword: "changed", VaultKey: []byte("rewrapped"),
+	})
+	assert.NoError(t, err)
+}
+
 func userTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.UserClient, chan<- struct{}) {
 	done := make(chan struct{})
 
@@ -119,7 +233,31 @@ func userTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.Use
 		EXPECT().
 		GetByLoginAndPassword(gomock.Any(), gomock.Eq(model.User{Login: "loginOk", Password: "pass"})).
 		AnyTimes().
-		Return(model.User{ID: &uid, Login: "test", Password: "pass"}, nil)
+		Return(model.User{ID: &uid, Login: "test", Password: "pass", VaultKey: []byte("wrapped")}, nil)
+
+	userStorageMock.
+		EXPECT().
+		GetByLoginAndPassword(gomock.Any(), gomock.Eq(model.User{Login: "test", Password: "pass"})).
+		AnyTimes().
+		Return(model.User{ID: &uid, Login: "test"}, nil)
+
+	userStorageMock.
+		EXPECT().
+		GetByLoginAndPassword(gomock.Any(), gomock.Eq(model.User{Login: "test", Password: "wrong"})).
+		AnyTimes().
+		Return(model.User{}, pgx.ErrNoRows)
+
+	userStorageMock.
+		EXPECT().
+		SetVaultKey(gomock.Any(), gomock.Eq(model.User{ID: &uid}), gomock.Any()).
+		AnyTimes().
+		Return([]byte("stored"), nil)
+
+	userStorageMock.
+		EXPECT().
+		ChangePassword(gomock.Any(), gomock.Eq(model.User{ID: &uid}), "changed", []byte("rewrapped")).
+		AnyTimes().
+		Return(model.User{ID: &uid, Login: "test"}, nil)
 
 	userStorageMock.
 		EXPECT().
@@ -127,6 +265,25 @@ func userTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.Use
 		AnyTimes().
 		Return(model.User{Login: "test", Password: "pass"}, errors.New("user login error"))
 
+	disabledAt := time.Now()
+	userStorageMock.
+		EXPECT().
+		GetByLoginAndPassword(gomock.Any(), gomock.Eq(model.User{Login: "loginDisabled", Password: "pass"})).
+		AnyTimes().
+		Return(model.User{ID: &uid, Login: "loginDisabled", DisabledAt: &disabledAt}, nil)
+
+	userStorageMock.
+		EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(model.User{ID: &uid})).
+		AnyTimes().
+		Return(model.User{ID: &uid, Login: "test"}, nil)
+
+	userStorageMock.
+		EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(model.User{Login: "logineErr"})).
+		AnyTimes().
+		Return(model.User{}, pgx.ErrNoRows)
+
 	userStorageMock.
 		EXPECT().
 		Create(gomock.Any(), gomock.Eq(model.User{Login: "RegConflict", Password: "pass"})).
@@ -149,8 +306,10 @@ func userTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.Use
 		Return(model.User{}, nil)
 
 	jwtM := jwtmock.NewMockManager(ctl)
-	jwtM.EXPECT().Issue(uid.String()).AnyTimes().Return("token", nil)
-	jwtM.EXPECT().Decode(gomock.Any()).AnyTimes().Return(uid.String(), nil)
+	jwtM.EXPECT().Issue(uid.String(), gomock.Any()).AnyTimes().Return("token", nil)
+	claims := jwt.Claims{}
+	claims.ID = uid.String()
+	jwtM.EXPECT().DecodeClaims(gomock.Any()).AnyTimes().Return(claims, nil)
 
 	cryptM := cryptmock.NewMockCrypter(ctl)
 	cryptM.EXPECT().Encode(gomock.Any()).AnyTimes().Return("token")
@@ -161,16 +320,19 @@ func userTestClient(t *testing.T, ctl *gomock.Controller, uid uuid.UUID) (pb.Use
 		t.Fatal(err)
 	}
 
-	userRpc := NewUserGrpc(userStorageMock, jwtM, cryptM)
+	recorderM := auditmock.NewMockRecorder(ctl)
+	recorderM.EXPECT().Record(gomock.Any(), gomock.Any()).AnyTimes()
+
+	userRpc := NewUserGrpc(userStorageMock, jwtM, cryptM, recorderM)
 
 	server := grpc.NewServer(
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
-				grpcauth.UnaryServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM).Auth),
+				grpcauth.UnaryServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM, userStorageMock).Auth),
 			)),
 		grpc.StreamInterceptor(
 			grpc_middleware.ChainStreamServer(
-				grpcauth.StreamServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM).Auth),
+				grpcauth.StreamServerInterceptor(auth.NewJwtMiddleware(jwtM, cryptM, userStorageMock).Auth),
 			)),
 	)
 
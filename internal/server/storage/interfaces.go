@@ -3,10 +3,16 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/sergalkin/gophkeeper/internal/server/model"
 )
 
+type Transactor interface {
+	// WithinTransaction - runs fn atomically, storages called with context passed to fn take part in a transaction.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type UserServerStorage interface {
 	// Create - create a new model.User in storage.
 	Create(ctx context.Context, user model.User) (model.User, error)
@@ -14,6 +20,23 @@ type UserServerStorage interface {
 	GetByLoginAndPassword(ctx context.Context, user model.User) (model.User, error)
 	// DeleteUser - deletes a user from storage.
 	DeleteUser(ctx context.Context, user model.User) (model.User, error)
+	// GetUser - returns model.User from storage by its ID, or by its login if ID is nil.
+	GetUser(ctx context.Context, user model.User) (model.User, error)
+	// ListUsers - returns a list of []model.UserUsage from storage ordered by login.
+	ListUsers(ctx context.Context) ([]model.UserUsage, error)
+	// SetUserDisabled - disables or enables a model.User in storage, disabling also revokes its issued tokens.
+	SetUserDisabled(ctx context.Context, user model.User, disabled bool) (model.User, error)
+	// SetUserRole - changes role of a model.User in storage and revokes its issued tokens.
+	SetUserRole(ctx context.Context, user model.User, role string) (model.User, error)
+	// ResetPassword - replaces password of a model.User in storage, revokes its issued tokens and deletes its vault:
+	// wrapped key, secrets, folders and tags, which can't be decrypted without the previous password.
+	ResetPassword(ctx context.Context, user model.User, password string) (model.User, error)
+	// SetVaultKey - sets wrapped key of a vault of a model.User in storage, if it isn't set yet, then returns stored one.
+	SetVaultKey(ctx context.Context, user model.User, vaultKey []byte) ([]byte, error)
+	// ChangePassword - replaces password and wrapped key of a vault of a model.User in storage.
+	ChangePassword(ctx context.Context, user model.User, password string, vaultKey []byte) (model.User, error)
+	// GetUsersStats - returns model.UsersStats of storage.
+	GetUsersStats(ctx context.Context) (model.UsersStats, error)
 }
 
 type SecretTypeServerStorage interface {
@@ -21,15 +44,98 @@ type SecretTypeServerStorage interface {
 	GetSecretTypes(ctx context.Context) ([]model.SecretType, error)
 }
 
+// SecretServerStorage - stores secrets of users. Methods, which take a stored model.Secret, find it by PublicID if
+// it's set and by ID otherwise, so ID is ignored if both are set.
 type SecretServerStorage interface {
 	// CreateSecret - creates new model.Secret in storage.
 	CreateSecret(ctx context.Context, secret model.Secret) (model.Secret, error)
-	// GetSecret - gets a model.Secret from storage.
-	GetSecret(ctx context.Context, secret model.Secret) (model.Secret, error)
-	// DeleteSecret - deletes a model.Secret from storage.
+	// GetSecret - gets a model.Secret from storage, model.Secret in trash is returned only if withTrashed is true.
+	GetSecret(ctx context.Context, secret model.Secret, withTrashed bool) (model.Secret, error)
+	// DeleteSecret - moves a model.Secret to trash in storage.
 	DeleteSecret(ctx context.Context, secret model.Secret) (model.Secret, error)
-	// EditSecret - updates a model.Secret in storage.
+	// EditSecret - updates a model.Secret in storage, empty Title and nil Content keep the stored ones.
 	EditSecret(ctx context.Context, secret model.Secret, isForce bool) (model.Secret, error)
-	// GetListOfSecretByType - returns a list of []model.Secret from storage.
-	GetListOfSecretByType(ctx context.Context, secretType model.SecretType, user model.User) ([]model.Secret, error)
+	// GetListOfSecretByType - returns a list of []model.Secret from storage, secrets in trash are included only if
+	// withTrashed is true.
+	GetListOfSecretByType(
+		ctx context.Context, secretType model.SecretType, user model.User, withTrashed bool,
+	) ([]model.Secret, error)
+	// ListTrash - returns a list of []model.Secret in trash of model.User from storage.
+	ListTrash(ctx context.Context, user model.User) ([]model.Secret, error)
+	// RestoreSecret - moves a model.Secret out of trash in storage.
+	RestoreSecret(ctx context.Context, secret model.Secret) (model.Secret, error)
+	// PurgeSecret - permanently deletes a model.Secret in trash from storage.
+	PurgeSecret(ctx context.Context, secret model.Secret) (model.Secret, error)
+	// PurgeTrash - permanently deletes secrets moved to trash before provided time from storage.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	// ListSecretVersions - returns a list of []model.SecretVersion of a model.Secret from storage.
+	ListSecretVersions(ctx context.Context, secret model.Secret) ([]model.SecretVersion, error)
+	// GetSecretVersion - gets a model.SecretVersion of a model.Secret from storage.
+	GetSecretVersion(ctx context.Context, secret model.Secret, version int64) (model.SecretVersion, error)
+	// RestoreSecretVersion - restores a model.Secret in storage to provided version.
+	RestoreSecretVersion(ctx context.Context, secret model.Secret, version int64) (model.Secret, error)
+	// TrimSecretVersions - removes prior versions of a model.Secret from storage except keep latest ones.
+	TrimSecretVersions(ctx context.Context, secret model.Secret, keep int) error
+	// ListSecrets - returns a list of []model.Secret selected by model.SecretFilter from storage.
+	ListSecrets(ctx context.Context, filter model.SecretFilter) ([]model.Secret, error)
+	// MoveSecret - moves a model.Secret to a folder of its user in storage, nil folderID moves it out of folders.
+	MoveSecret(ctx context.Context, secret model.Secret, folderID *int) (model.Secret, error)
+	// SetSecretTags - replaces tags of a model.Secret in storage with provided tags of its user.
+	SetSecretTags(ctx context.Context, secret model.Secret, tagIDs []int) (model.Secret, error)
+}
+
+type FolderServerStorage interface {
+	// CreateFolder - creates new model.Folder in storage, parent folder has to belong to the same user.
+	CreateFolder(ctx context.Context, folder model.Folder) (model.Folder, error)
+	// ListFolders - returns a list of []model.Folder of model.User from storage.
+	ListFolders(ctx context.Context, user model.User) ([]model.Folder, error)
+	// CreateTag - creates new model.Tag in storage.
+	CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error)
+	// ListTags - returns a list of []model.Tag of model.User from storage.
+	ListTags(ctx context.Context, user model.User) ([]model.Tag, error)
+}
+
+type IdempotencyServerStorage interface {
+	// ReserveKey - reserves model.IdempotencyKey in storage, keys older than ttl are treated as absent.
+	//
+	// If key is already reserved, then returns stored model.IdempotencyKey and false.
+	ReserveKey(ctx context.Context, key model.IdempotencyKey, ttl time.Duration) (model.IdempotencyKey, bool, error)
+	// SaveResponse - stores response of reserved model.IdempotencyKey.
+	SaveResponse(ctx context.Context, key model.IdempotencyKey) error
+	// ReleaseKey - removes reservation of model.IdempotencyKey from storage.
+	ReleaseKey(ctx context.Context, key model.IdempotencyKey) error
+	// PurgeKeys - removes keys created before provided time from storage.
+	PurgeKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+type JobServerStorage interface {
+	// TryLock - attempts to take a lock of a job by its name, which is kept until storage is closed.
+	//
+	// Returns true if lock is taken by the caller.
+	TryLock(ctx context.Context, name string) (bool, error)
+	// Ping - checks that taken locks are still kept by storage.
+	Ping(ctx context.Context) error
+	// CreateJobRun - stores new model.JobRun in storage.
+	CreateJobRun(ctx context.Context, run model.JobRun) (model.JobRun, error)
+	// FinishJobRun - updates status, message and finish time of model.JobRun in storage.
+	FinishJobRun(ctx context.Context, run model.JobRun) error
+	// ListJobRuns - returns a list of []model.JobRun from storage, latest go first, filtered by name if it's not empty.
+	ListJobRuns(ctx context.Context, name string, limit int) ([]model.JobRun, error)
+}
+
+type AuditServerStorage interface {
+	// CreateAuditEvent - appends new model.AuditEvent to storage chaining it to the last stored one, stored events
+	// can't be changed or deleted.
+	CreateAuditEvent(ctx context.Context, event model.AuditEvent) (model.AuditEvent, error)
+	// ListAuditEvents - returns a list of []model.AuditEvent selected by model.AuditEventFilter from storage, latest go
+	// first.
+	ListAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error)
+	// ListAuditChain - returns up to limit []model.AuditEvent with id greater than afterID in order of the chain.
+	ListAuditChain(ctx context.Context, afterID int64, limit int) ([]model.AuditEvent, error)
+	// CreateAuditCheckpoint - appends new model.AuditCheckpoint to storage.
+	CreateAuditCheckpoint(ctx context.Context, checkpoint model.AuditCheckpoint) (model.AuditCheckpoint, error)
+	// GetLastAuditCheckpoint - returns the latest model.AuditCheckpoint from storage.
+	GetLastAuditCheckpoint(ctx context.Context) (model.AuditCheckpoint, error)
+	// ListAuditCheckpoints - returns all []model.AuditCheckpoint from storage in order of creation.
+	ListAuditCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error)
 }
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.AuditServerStorage = (*AuditMemoryStorage)(nil)
+
+// AuditMemoryStorage - is an append-only storage of audit events.
+type AuditMemoryStorage struct {
+	db *DB
+}
+
+// NewAuditMemoryStorage - creates AuditMemoryStorage instance.
+func NewAuditMemoryStorage(db *DB) *AuditMemoryStorage {
+	return &AuditMemoryStorage{db: db}
+}
+
+// CreateAuditEvent - appends provided model.AuditEvent chaining it to the last stored event, then returns it
+// populated with id and hashes.
+func (a *AuditMemoryStorage) CreateAuditEvent(ctx context.Context, event model.AuditEvent) (model.AuditEvent, error) {
+	defer a.db.lock(ctx)()
+
+	event.ID = int64(len(a.db.data.auditEvents)) + 1
+	event.CreatedAt = event.CreatedAt.Truncate(time.Microsecond)
+	event.PrevHash = ""
+	if last := len(a.db.data.auditEvents); last > 0 {
+		event.PrevHash = a.db.data.auditEvents[last-1].Hash
+	}
+	event.Hash = event.ChainHash(event.PrevHash)
+	a.db.data.auditEvents = append(a.db.data.auditEvents, event)
+
+	return event, nil
+}
+
+// ListAuditEvents - returns audit events selected by model.AuditEventFilter, latest go first.
+func (a *AuditMemoryStorage) ListAuditEvents(
+	ctx context.Context, filter model.AuditEventFilter,
+) ([]model.AuditEvent, error) {
+	defer a.db.lock(ctx)()
+
+	var events []model.AuditEvent
+	for i := len(a.db.data.auditEvents) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		event := a.db.data.auditEvents[i]
+
+		if filter.BeforeID != 0 && event.ID >= filter.BeforeID {
+			continue
+		}
+
+		if filter.UserID != nil && (event.UserID == nil || *event.UserID != *filter.UserID) {
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// ListAuditChain - returns up to limit audit events with id greater than afterID in order of the chain.
+func (a *AuditMemoryStorage) ListAuditChain(ctx context.Context, afterID int64, limit int) ([]model.AuditEvent, error) {
+	defer a.db.lock(ctx)()
+
+	var events []model.AuditEvent
+	for _, event := range a.db.data.auditEvents {
+		if event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// CreateAuditCheckpoint - appends provided model.AuditCheckpoint, then returns it populated with id.
+func (a *AuditMemoryStorage) CreateAuditCheckpoint(
+	ctx context.Context, checkpoint model.AuditCheckpoint,
+) (model.AuditCheckpoint, error) {
+	defer a.db.lock(ctx)()
+
+	checkpoint.ID = int64(len(a.db.data.auditCheckpoints)) + 1
+	a.db.data.auditCheckpoints = append(a.db.data.auditCheckpoints, checkpoint)
+
+	return checkpoint, nil
+}
+
+// GetLastAuditCheckpoint - returns the latest audit checkpoint, pgx.ErrNoRows is returned if there is none.
+func (a *AuditMemoryStorage) GetLastAuditCheckpoint(ctx context.Context) (model.AuditCheckpoint, error) {
+	defer a.db.lock(ctx)()
+
+	if len(a.db.data.auditCheckpoints) == 0 {
+		return model.AuditCheckpoint{}, pgx.ErrNoRows
+	}
+
+	return a.db.data.auditCheckpoints[len(a.db.data.auditCheckpoints)-1], nil
+}
+
+// ListAuditCheckpoints - returns all audit checkpoints in order of creation.
+func (a *AuditMemoryStorage) ListAuditCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error) {
+	defer a.db.lock(ctx)()
+
+	return append([]model.AuditCheckpoint(nil), a.db.data.auditCheckpoints...), nil
+}
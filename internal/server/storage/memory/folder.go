@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.FolderServerStorage = (*FolderMemoryStorage)(nil)
+
+type FolderMemoryStorage struct {
+	db *DB
+}
+
+// NewFolderMemoryStorage - creates a memory storage for folders and tags.
+func NewFolderMemoryStorage(db *DB) *FolderMemoryStorage {
+	return &FolderMemoryStorage{db: db}
+}
+
+// CreateFolder - stores provided model.Folder.
+//
+// If parent folder doesn't belong to the user of a folder, then pgx.ErrNoRows is returned.
+func (s *FolderMemoryStorage) CreateFolder(ctx context.Context, folder model.Folder) (model.Folder, error) {
+	defer s.db.lock(ctx)()
+
+	if _, ok := s.db.data.users[folder.UserID]; !ok {
+		return folder, fmt.Errorf("folder creation error: unknown user %s", folder.UserID)
+	}
+
+	if folder.ParentID != nil && !s.db.data.hasFolder(folder.UserID, *folder.ParentID) {
+		return folder, fmt.Errorf("parent folder not found: %w", pgx.ErrNoRows)
+	}
+
+	s.db.data.lastFolderID++
+	folder.ID = s.db.data.lastFolderID
+	folder.Name = clone(folder.Name)
+	s.db.data.folders = append(s.db.data.folders, folder)
+
+	return folder, nil
+}
+
+// ListFolders - returns a []model.Folder of model.User.
+func (s *FolderMemoryStorage) ListFolders(ctx context.Context, user model.User) ([]model.Folder, error) {
+	defer s.db.lock(ctx)()
+
+	var folders []model.Folder
+
+	for _, folder := range s.db.data.folders {
+		if user.ID != nil && folder.UserID == *user.ID {
+			folder.Name = clone(folder.Name)
+			folders = append(folders, folder)
+		}
+	}
+
+	return folders, nil
+}
+
+// CreateTag - stores provided model.Tag.
+func (s *FolderMemoryStorage) CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	defer s.db.lock(ctx)()
+
+	if _, ok := s.db.data.users[tag.UserID]; !ok {
+		return tag, fmt.Errorf("tag creation error: unknown user %s", tag.UserID)
+	}
+
+	s.db.data.lastTagID++
+	tag.ID = s.db.data.lastTagID
+	tag.Name = clone(tag.Name)
+	s.db.data.tags = append(s.db.data.tags, tag)
+
+	return tag, nil
+}
+
+// ListTags - returns a []model.Tag of model.User.
+func (s *FolderMemoryStorage) ListTags(ctx context.Context, user model.User) ([]model.Tag, error) {
+	defer s.db.lock(ctx)()
+
+	var tags []model.Tag
+
+	for _, tag := range s.db.data.tags {
+		if user.ID != nil && tag.UserID == *user.ID {
+			tag.Name = clone(tag.Name)
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+// hasFolder - reports whether folder with provided id belongs to the user.
+func (d data) hasFolder(userID uuid.UUID, id int) bool {
+	for _, folder := range d.folders {
+		if folder.ID == id && folder.UserID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasTag - reports whether tag with provided id belongs to the user.
+func (d data) hasTag(userID uuid.UUID, id int) bool {
+	for _, tag := range d.tags {
+		if tag.ID == id && tag.UserID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteFoldersAndTags - deletes all folders and tags of the user.
+func (d *data) deleteFoldersAndTags(userID uuid.UUID) {
+	var folders []model.Folder
+	for _, folder := range d.folders {
+		if folder.UserID != userID {
+			folders = append(folders, folder)
+		}
+	}
+
+	var tags []model.Tag
+	for _, tag := range d.tags {
+		if tag.UserID != userID {
+			tags = append(tags, tag)
+		}
+	}
+
+	d.folders, d.tags = folders, tags
+}
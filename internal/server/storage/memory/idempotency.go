@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.IdempotencyServerStorage = (*IdempotencyMemoryStorage)(nil)
+
+type IdempotencyMemoryStorage struct {
+	db *DB
+}
+
+// idempotencyKeyID - is a unique identifier of model.IdempotencyKey.
+type idempotencyKeyID struct {
+	key    string
+	userID string
+	method string
+}
+
+// NewIdempotencyMemoryStorage - creates a memory storage for idempotency keys.
+func NewIdempotencyMemoryStorage(db *DB) *IdempotencyMemoryStorage {
+	return &IdempotencyMemoryStorage{db: db}
+}
+
+// ReserveKey - stores provided model.IdempotencyKey without response.
+//
+// Expired record with the same key is replaced. If record with the same key already exists, then returns stored
+// model.IdempotencyKey and false as representation of already reserved key.
+func (i *IdempotencyMemoryStorage) ReserveKey(
+	ctx context.Context, key model.IdempotencyKey, ttl time.Duration,
+) (model.IdempotencyKey, bool, error) {
+	defer i.db.lock(ctx)()
+
+	now := time.Now()
+	id := idempotencyKeyID{key: key.Key, userID: key.UserID, method: key.Method}
+
+	if stored, ok := i.db.data.idempotencyKeys[id]; ok && !stored.CreatedAt.Before(now.Add(-ttl)) {
+		// response is nil till it is saved, so its copy must keep it
+		stored.RequestHash, stored.Response = clone(stored.RequestHash), append([]byte(nil), stored.Response...)
+
+		return stored, false, nil
+	}
+
+	key.RequestHash, key.Response, key.CreatedAt = clone(key.RequestHash), nil, now
+	i.db.data.idempotencyKeys[id] = key
+
+	return key, true, nil
+}
+
+// SaveResponse - stores response of reserved model.IdempotencyKey.
+func (i *IdempotencyMemoryStorage) SaveResponse(ctx context.Context, key model.IdempotencyKey) error {
+	defer i.db.lock(ctx)()
+
+	id := idempotencyKeyID{key: key.Key, userID: key.UserID, method: key.Method}
+
+	stored, ok := i.db.data.idempotencyKeys[id]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+
+	stored.Response = clone(key.Response)
+	i.db.data.idempotencyKeys[id] = stored
+
+	return nil
+}
+
+// ReleaseKey - deletes model.IdempotencyKey, so request with the same key could be executed again.
+func (i *IdempotencyMemoryStorage) ReleaseKey(ctx context.Context, key model.IdempotencyKey) error {
+	defer i.db.lock(ctx)()
+
+	delete(i.db.data.idempotencyKeys, idempotencyKeyID{key: key.Key, userID: key.UserID, method: key.Method})
+
+	return nil
+}
+
+// PurgeKeys - removes keys created before provided time.
+//
+// Returns number of removed keys.
+func (i *IdempotencyMemoryStorage) PurgeKeys(ctx context.Context, before time.Time) (int64, error) {
+	defer i.db.lock(ctx)()
+
+	var purged int64
+
+	for id, key := range i.db.data.idempotencyKeys {
+		if key.CreatedAt.Before(before) {
+			delete(i.db.data.idempotencyKeys, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
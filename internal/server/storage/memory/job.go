@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.JobServerStorage = (*JobMemoryStorage)(nil)
+
+// JobMemoryStorage - is a storage of job runs.
+//
+// Memory of a process is not shared with other servers, so there is no leadership to take and every lock is granted.
+type JobMemoryStorage struct {
+	db *DB
+}
+
+// NewJobMemoryStorage - creates a memory storage for job runs.
+func NewJobMemoryStorage(db *DB) *JobMemoryStorage {
+	return &JobMemoryStorage{db: db}
+}
+
+// TryLock - always takes the lock, because memory is not shared with other servers.
+func (j *JobMemoryStorage) TryLock(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+
+// Ping - does nothing, because memory is always reachable.
+func (j *JobMemoryStorage) Ping(_ context.Context) error {
+	return nil
+}
+
+// CreateJobRun - stores provided model.JobRun.
+func (j *JobMemoryStorage) CreateJobRun(ctx context.Context, run model.JobRun) (model.JobRun, error) {
+	defer j.db.lock(ctx)()
+
+	run.ID = int64(len(j.db.data.jobRuns) + 1)
+	j.db.data.jobRuns = append(j.db.data.jobRuns, run)
+
+	return run, nil
+}
+
+// FinishJobRun - updates status, message and finish time of provided model.JobRun.
+func (j *JobMemoryStorage) FinishJobRun(ctx context.Context, run model.JobRun) error {
+	defer j.db.lock(ctx)()
+
+	for idx, stored := range j.db.data.jobRuns {
+		if stored.ID == run.ID {
+			stored.Status, stored.Message, stored.FinishedAt = run.Status, run.Message, run.FinishedAt
+			j.db.data.jobRuns[idx] = stored
+		}
+	}
+
+	return nil
+}
+
+// ListJobRuns - returns a []model.JobRun, latest started go first.
+func (j *JobMemoryStorage) ListJobRuns(ctx context.Context, name string, limit int) ([]model.JobRun, error) {
+	defer j.db.lock(ctx)()
+
+	var runs []model.JobRun
+
+	// runs are stored in order of their start
+	for idx := len(j.db.data.jobRuns) - 1; idx >= 0 && len(runs) < limit; idx-- {
+		if run := j.db.data.jobRuns[idx]; name == "" || run.Name == name {
+			runs = append(runs, run)
+		}
+	}
+
+	return runs, nil
+}
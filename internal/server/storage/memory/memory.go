@@ -0,0 +1,185 @@
+// Package memory is a storage of the server, which keeps all data in memory of the process.
+//
+// Data is lost on restart, so it is intended for tests and demos only.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.Transactor = (*Transactor)(nil)
+
+// DB - is an in-memory database shared by memory storages.
+//
+// All access to data is serialized by one mutex. Transaction holds it till its end, so it is isolated from other
+// queries, and restores snapshot of data taken on its begin when it is rolled back.
+type DB struct {
+	mu   sync.Mutex
+	data data
+}
+
+// data - is a content of DB.
+type data struct {
+	users            map[uuid.UUID]userRecord
+	secretTypes      []model.SecretType
+	secrets          map[int]secretRecord
+	secretVersions   map[int][]model.SecretVersion
+	lastSecretID     int
+	folders          []model.Folder
+	lastFolderID     int
+	tags             []model.Tag
+	lastTagID        int
+	idempotencyKeys  map[idempotencyKeyID]model.IdempotencyKey
+	jobRuns          []model.JobRun
+	auditEvents      []model.AuditEvent
+	auditCheckpoints []model.AuditCheckpoint
+}
+
+// txCtx - is a key of transaction of DB in context, its value is DB which transaction is running.
+type txCtx struct{}
+
+// NewDB - creates empty DB with default secret types.
+func NewDB() *DB {
+	return &DB{data: data{
+		users:           map[uuid.UUID]userRecord{},
+		secretTypes:     defaultSecretTypes(),
+		secrets:         map[int]secretRecord{},
+		secretVersions:  map[int][]model.SecretVersion{},
+		idempotencyKeys: map[idempotencyKeyID]model.IdempotencyKey{},
+	}}
+}
+
+// defaultSecretTypes - returns secret types with their fields, which are the same as ones created by migrations of
+// other storages.
+func defaultSecretTypes() []model.SecretType {
+	return []model.SecretType{
+		{ID: 1, Title: "login/pass", Fields: []model.SecretTypeField{
+			{Name: "Login", Label: "Login", Kind: model.SecretFieldKindText, Required: true},
+			{Name: "Password", Label: "Password", Kind: model.SecretFieldKindText, Required: true, Sensitive: true},
+		}},
+		{ID: 2, Title: "text", Fields: []model.SecretTypeField{
+			{Name: "Text", Label: "Text", Kind: model.SecretFieldKindMultiline, Required: true},
+		}},
+		{ID: 3, Title: "binary", Fields: []model.SecretTypeField{
+			{Name: "File", Label: "File", Kind: model.SecretFieldKindFile, Required: true},
+		}},
+		{ID: 4, Title: "card", Fields: []model.SecretTypeField{
+			{
+				Name: "CardNumber", Label: "Card number", Kind: model.SecretFieldKindText, Required: true, Sensitive: true,
+				Pattern: "^[0-9]{12,19}$",
+			},
+			{
+				Name: "CVV", Label: "CVV", Kind: model.SecretFieldKindText, Required: true, Sensitive: true,
+				Pattern: "^[0-9]{3,4}$",
+			},
+			{
+				Name: "Due", Label: "Due date", Kind: model.SecretFieldKindText, Required: true,
+				Pattern: "^(0[1-9]|1[0-2])/([0-9]{2}|[0-9]{4})$",
+			},
+		}},
+	}
+}
+
+// Transactor - runs functions in a transaction of DB.
+type Transactor struct {
+	db *DB
+}
+
+// NewTransactor - creates new Transactor.
+func NewTransactor(db *DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction - runs fn in a transaction, which changes are kept if fn returns no error and discarded otherwise.
+//
+// Memory storages called with context passed to fn run in the transaction. If context already carries a
+// transaction, then fn joins it.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.db.withinTransaction(ctx, fn)
+}
+
+// withinTransaction - runs fn in a transaction from context or in a new transaction of DB.
+func (d *DB) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if d.inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	snapshot := d.data.clone()
+
+	if err := fn(context.WithValue(ctx, txCtx{}, d)); err != nil {
+		d.data = snapshot
+
+		return err
+	}
+
+	return nil
+}
+
+// lock - locks DB for a query, unless query is run in a transaction, which already holds the lock. Returned function
+// releases the lock.
+func (d *DB) lock(ctx context.Context) func() {
+	if d.inTransaction(ctx) {
+		return func() {}
+	}
+
+	d.mu.Lock()
+
+	return d.mu.Unlock
+}
+
+// inTransaction - reports whether context carries a transaction of DB.
+func (d *DB) inTransaction(ctx context.Context) bool {
+	db, ok := ctx.Value(txCtx{}).(*DB)
+
+	return ok && db == d
+}
+
+// clone - returns a copy of data, which could be changed without affecting the original one.
+//
+// Records are replaced on change and their content is never changed in place, so it is shared by copies.
+func (d data) clone() data {
+	c := data{
+		users:            make(map[uuid.UUID]userRecord, len(d.users)),
+		secretTypes:      append([]model.SecretType(nil), d.secretTypes...),
+		secrets:          make(map[int]secretRecord, len(d.secrets)),
+		secretVersions:   make(map[int][]model.SecretVersion, len(d.secretVersions)),
+		lastSecretID:     d.lastSecretID,
+		folders:          append([]model.Folder(nil), d.folders...),
+		lastFolderID:     d.lastFolderID,
+		tags:             append([]model.Tag(nil), d.tags...),
+		lastTagID:        d.lastTagID,
+		idempotencyKeys:  make(map[idempotencyKeyID]model.IdempotencyKey, len(d.idempotencyKeys)),
+		jobRuns:          append([]model.JobRun(nil), d.jobRuns...),
+		auditEvents:      append([]model.AuditEvent(nil), d.auditEvents...),
+		auditCheckpoints: append([]model.AuditCheckpoint(nil), d.auditCheckpoints...),
+	}
+
+	for id, user := range d.users {
+		c.users[id] = user
+	}
+	for id, secret := range d.secrets {
+		c.secrets[id] = secret
+	}
+	for id, versions := range d.secretVersions {
+		c.secretVersions[id] = append([]model.SecretVersion(nil), versions...)
+	}
+	for id, key := range d.idempotencyKeys {
+		c.idempotencyKeys[id] = key
+	}
+
+	return c
+}
+
+// clone - returns a copy of b, so stored data is not changed through slices passed to or returned from storages.
+func clone(b []byte) []byte {
+	return append([]byte{}, b...)
+}
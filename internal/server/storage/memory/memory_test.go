@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/storagetest"
+)
+
+// newTestStorages - creates storages backed by new DB.
+func newTestStorages(_ *testing.T) storagetest.Storages {
+	db := NewDB()
+
+	return storagetest.Storages{
+		Users:       NewUserMemoryStorage(db),
+		SecretTypes: NewSecretTypeMemoryStorage(db),
+		Secrets:     NewSecretMemoryStorage(db),
+		Folders:     NewFolderMemoryStorage(db),
+		Audit:       NewAuditMemoryStorage(db),
+		Transactor:  NewTransactor(db),
+	}
+}
+
+func TestMemoryStorages(t *testing.T) {
+	storagetest.Run(t, newTestStorages)
+}
+
+func TestSecretMemoryStorage_ContentIsNotShared(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+
+	user, err := NewUserMemoryStorage(db).Create(ctx, model.User{Login: "test", Password: "test"})
+	require.NoError(t, err)
+
+	content := []byte{1, 2}
+	secrets := NewSecretMemoryStorage(db)
+
+	secret, err := secrets.CreateSecret(ctx, model.Secret{UserID: *user.ID, TypeID: 1, Content: content})
+	require.NoError(t, err)
+
+	content[0] = 10
+
+	got, err := secrets.GetSecret(ctx, secret, false)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, got.Content, "changes of passed content are not stored")
+
+	got.Content[1] = 20
+
+	got, err = secrets.GetSecret(ctx, secret, false)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, got.Content, "changes of returned content are not stored")
+}
@@ -0,0 +1,580 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/pkg/apperr"
+)
+
+var _ storage.SecretServerStorage = (*SecretMemoryStorage)(nil)
+
+type SecretMemoryStorage struct {
+	db *DB
+}
+
+// secretRecord - is a stored secret.
+type secretRecord struct {
+	model.Secret
+	updatedBy uuid.UUID
+}
+
+// trashFilter - selects secrets by their presence in trash.
+type trashFilter int
+
+const (
+	active trashFilter = iota
+	inTrash
+	anyState
+)
+
+// NewSecretMemoryStorage - creates a memory storage for secrets.
+func NewSecretMemoryStorage(db *DB) *SecretMemoryStorage {
+	return &SecretMemoryStorage{db: db}
+}
+
+// CreateSecret - stores provided model.Secret.
+//
+// Creation is idempotent by PublicID: if a secret with the same PublicID already exists for the user, then the stored
+// model.Secret is returned instead of creating a duplicate. If PublicID belongs to another user, then
+// apperr.ErrConflict is returned.
+func (s *SecretMemoryStorage) CreateSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	if secret.PublicID == uuid.Nil {
+		secret.PublicID = uuid.New()
+	}
+
+	for _, stored := range s.db.data.secrets {
+		if stored.PublicID != secret.PublicID {
+			continue
+		}
+
+		if stored.UserID != secret.UserID {
+			return secret, apperr.ErrConflict
+		}
+
+		return stored.copy(), nil
+	}
+
+	if _, ok := s.db.data.users[secret.UserID]; !ok {
+		return secret, fmt.Errorf("error in storing secret: unknown user %s", secret.UserID)
+	}
+
+	if !s.hasType(secret.TypeID) {
+		return secret, fmt.Errorf("error in storing secret: unknown type %d", secret.TypeID)
+	}
+
+	s.db.data.lastSecretID++
+	secret.ID, secret.Version, secret.DeletedAt = s.db.data.lastSecretID, 1, nil
+	secret.FolderID, secret.TagIDs = nil, nil
+
+	stored := secretRecord{Secret: secret, updatedBy: secret.UserID}
+	stored.Content, stored.TitleHash = clone(secret.Content), append([]byte(nil), secret.TitleHash...)
+	s.db.data.secrets[secret.ID] = stored
+
+	return secret, nil
+}
+
+// GetSecret - returns stored model.Secret.
+//
+// Searches by UserID and ID or PublicID from provided model.Secret, secrets in trash are found only if withTrashed
+// is true.
+func (s *SecretMemoryStorage) GetSecret(
+	ctx context.Context, secret model.Secret, withTrashed bool,
+) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	filter := active
+	if withTrashed {
+		filter = anyState
+	}
+
+	stored, ok := s.find(secret, filter)
+	if !ok {
+		return secret, fmt.Errorf("error in getting secret: %w", pgx.ErrNoRows)
+	}
+
+	return stored.copy(), nil
+}
+
+// DeleteSecret - moves a model.Secret to trash by setting its DeletedAt.
+func (s *SecretMemoryStorage) DeleteSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, active)
+	if !ok {
+		return secret, pgx.ErrNoRows
+	}
+
+	now := time.Now()
+	stored.DeletedAt = &now
+	s.db.data.secrets[stored.ID] = stored
+
+	return model.Secret{}, nil
+}
+
+// EditSecret - updates title and content of a model.Secret, empty Title and nil Content keep the stored ones.
+// TitleHash is replaced along with Title.
+//
+// Update is applied only if Version of provided model.Secret matches the stored one, unless isForce is true. On
+// successful update Version is incremented and replaced content is kept as a prior version. On mismatch returns
+// apperr.ErrVersionDoesntMatch and model.Secret with current Version and UpdatedAt.
+func (s *SecretMemoryStorage) EditSecret(ctx context.Context, secret model.Secret, isForce bool) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, active)
+	if !ok {
+		return secret, pgx.ErrNoRows
+	}
+
+	if !isForce && stored.Version != secret.Version {
+		secret.ID, secret.PublicID = stored.ID, stored.PublicID
+		secret.UpdatedAt, secret.Version = stored.UpdatedAt, stored.Version
+
+		return secret, apperr.ErrVersionDoesntMatch
+	}
+
+	s.archive(stored)
+
+	if secret.Title != "" {
+		stored.Title, stored.TitleHash = secret.Title, append([]byte(nil), secret.TitleHash...)
+	}
+	if secret.Content != nil {
+		stored.Content = clone(secret.Content)
+	}
+	stored.UpdatedAt, stored.updatedBy, stored.Version = time.Now(), secret.UserID, stored.Version+1
+	s.db.data.secrets[stored.ID] = stored
+
+	secret.ID, secret.PublicID, secret.TypeID, secret.Title = stored.ID, stored.PublicID, stored.TypeID, stored.Title
+	secret.CreatedAt, secret.UpdatedAt, secret.DeletedAt = stored.CreatedAt, stored.UpdatedAt, stored.DeletedAt
+	secret.Version = stored.Version
+	copied := stored.copy()
+	secret.FolderID, secret.TagIDs = copied.FolderID, copied.TagIDs
+
+	return secret, nil
+}
+
+// GetListOfSecretByType - returns a []model.Secret by provided type via model.SecretType and user via model.User.
+//
+// Secrets in trash are returned only if withTrashed is true.
+func (s *SecretMemoryStorage) GetListOfSecretByType(
+	ctx context.Context, secretType model.SecretType, user model.User, withTrashed bool,
+) ([]model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	var secrets []model.Secret
+
+	for _, stored := range s.db.data.secrets {
+		if user.ID == nil || stored.UserID != *user.ID || stored.TypeID != int(secretType.ID) {
+			continue
+		}
+
+		if stored.DeletedAt != nil && !withTrashed {
+			continue
+		}
+
+		secrets = append(secrets, stored.copy())
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].ID < secrets[j].ID })
+
+	return secrets, nil
+}
+
+// ListTrash - returns a []model.Secret in trash of model.User, latest deleted go first.
+func (s *SecretMemoryStorage) ListTrash(ctx context.Context, user model.User) ([]model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	var secrets []model.Secret
+
+	for _, stored := range s.db.data.secrets {
+		if user.ID != nil && stored.UserID == *user.ID && stored.DeletedAt != nil {
+			secrets = append(secrets, stored.copy())
+		}
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].DeletedAt.After(*secrets[j].DeletedAt) })
+
+	return secrets, nil
+}
+
+// RestoreSecret - moves a model.Secret out of trash by resetting its DeletedAt.
+func (s *SecretMemoryStorage) RestoreSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, inTrash)
+	if !ok {
+		return secret, pgx.ErrNoRows
+	}
+
+	stored.DeletedAt = nil
+	s.db.data.secrets[stored.ID] = stored
+
+	secret.ID, secret.PublicID, secret.TypeID, secret.Title = stored.ID, stored.PublicID, stored.TypeID, stored.Title
+	secret.CreatedAt, secret.UpdatedAt, secret.DeletedAt = stored.CreatedAt, stored.UpdatedAt, nil
+	secret.Version = stored.Version
+
+	return secret, nil
+}
+
+// PurgeSecret - permanently deletes a model.Secret, which is in trash, with its versions.
+func (s *SecretMemoryStorage) PurgeSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, inTrash)
+	if !ok {
+		return secret, pgx.ErrNoRows
+	}
+
+	delete(s.db.data.secrets, stored.ID)
+	delete(s.db.data.secretVersions, stored.ID)
+
+	return model.Secret{}, nil
+}
+
+// PurgeTrash - permanently deletes all secrets, which were moved to trash before provided time.
+//
+// Returns number of deleted secrets.
+func (s *SecretMemoryStorage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	defer s.db.lock(ctx)()
+
+	var purged int64
+
+	for id, stored := range s.db.data.secrets {
+		if stored.DeletedAt != nil && stored.DeletedAt.Before(before) {
+			delete(s.db.data.secrets, id)
+			delete(s.db.data.secretVersions, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// ListSecretVersions - returns a []model.SecretVersion of a model.Secret, including current version.
+//
+// Versions are ordered from the latest to the oldest one.
+func (s *SecretMemoryStorage) ListSecretVersions(
+	ctx context.Context, secret model.Secret,
+) ([]model.SecretVersion, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, active)
+	if !ok {
+		return nil, nil
+	}
+
+	versions := []model.SecretVersion{stored.version()}
+	for _, version := range s.db.data.secretVersions[stored.ID] {
+		version.Content = clone(version.Content)
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+
+	return versions, nil
+}
+
+// GetSecretVersion - returns a model.SecretVersion of a model.Secret by its version.
+func (s *SecretMemoryStorage) GetSecretVersion(
+	ctx context.Context, secret model.Secret, version int64,
+) (model.SecretVersion, error) {
+	versions, err := s.ListSecretVersions(ctx, secret)
+	if err != nil {
+		return model.SecretVersion{}, err
+	}
+
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+
+	return model.SecretVersion{}, pgx.ErrNoRows
+}
+
+// RestoreSecretVersion - sets title, type and content of a model.Secret to the values of provided prior version.
+//
+// Restoring is a change of a secret, so Version is incremented and replaced content is kept as a prior version.
+func (s *SecretMemoryStorage) RestoreSecretVersion(
+	ctx context.Context, secret model.Secret, version int64,
+) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, active)
+	if !ok {
+		return secret, pgx.ErrNoRows
+	}
+
+	for _, prior := range s.db.data.secretVersions[stored.ID] {
+		if prior.Version != version {
+			continue
+		}
+
+		s.archive(stored)
+
+		stored.TypeID, stored.Title, stored.Content = prior.TypeID, prior.Title, prior.Content
+		stored.TitleHash = prior.TitleHash
+		stored.UpdatedAt, stored.updatedBy, stored.Version = time.Now(), secret.UserID, stored.Version+1
+		s.db.data.secrets[stored.ID] = stored
+
+		secret.ID, secret.PublicID, secret.TypeID, secret.Title = stored.ID, stored.PublicID, stored.TypeID, stored.Title
+		secret.CreatedAt, secret.UpdatedAt, secret.DeletedAt = stored.CreatedAt, stored.UpdatedAt, stored.DeletedAt
+		secret.Version = stored.Version
+
+		return secret, nil
+	}
+
+	return secret, pgx.ErrNoRows
+}
+
+// TrimSecretVersions - deletes prior versions of a model.Secret, except keep latest ones.
+func (s *SecretMemoryStorage) TrimSecretVersions(ctx context.Context, secret model.Secret, keep int) error {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, anyState)
+	if !ok {
+		return nil
+	}
+
+	var kept []model.SecretVersion
+	for _, version := range s.db.data.secretVersions[stored.ID] {
+		if version.Version >= stored.Version-int64(keep) {
+			kept = append(kept, version)
+		}
+	}
+
+	s.db.data.secretVersions[stored.ID] = kept
+
+	return nil
+}
+
+// ListSecrets - returns a page of []model.Secret of a user selected and sorted by model.SecretFilter.
+func (s *SecretMemoryStorage) ListSecrets(ctx context.Context, filter model.SecretFilter) ([]model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	var secrets []model.Secret
+
+	for _, stored := range s.db.data.secrets {
+		if stored.UserID != filter.UserID || !matchTrash(stored.Secret, filter.Trash) {
+			continue
+		}
+
+		if filter.FolderID != nil && stored.folderID() != *filter.FolderID {
+			continue
+		}
+
+		if filter.TagID != 0 && !stored.hasTag(filter.TagID) {
+			continue
+		}
+
+		if filter.TypeID != 0 && stored.TypeID != filter.TypeID {
+			continue
+		}
+
+		if len(filter.TitleHash) > 0 && !bytes.Equal(stored.TitleHash, filter.TitleHash) {
+			continue
+		}
+
+		if filter.UpdatedSince != nil && stored.UpdatedAt.Before(*filter.UpdatedSince) {
+			continue
+		}
+
+		if filter.After != nil && !filter.Less(*filter.After, model.NewSecretCursor(stored.Secret)) {
+			continue
+		}
+
+		secret := stored.copy()
+		if filter.WithoutContent {
+			secret.Content = nil
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool {
+		return filter.Less(model.NewSecretCursor(secrets[i]), model.NewSecretCursor(secrets[j]))
+	})
+
+	if filter.Limit > 0 && len(secrets) > filter.Limit {
+		secrets = secrets[:filter.Limit]
+	}
+
+	return secrets, nil
+}
+
+// matchTrash - reports whether secret is selected by trash filter.
+func matchTrash(secret model.Secret, trash model.SecretTrash) bool {
+	switch trash {
+	case model.SecretsTrashed:
+		return secret.DeletedAt != nil
+	case model.SecretsAll:
+		return true
+	default:
+		return secret.DeletedAt == nil
+	}
+}
+
+// MoveSecret - sets folder of a model.Secret, folder has to belong to the user of a secret.
+//
+// Moving is not a change of content, so Version of a secret is kept.
+func (s *SecretMemoryStorage) MoveSecret(
+	ctx context.Context, secret model.Secret, folderID *int,
+) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, active)
+	if !ok {
+		return secret, pgx.ErrNoRows
+	}
+
+	if folderID != nil && !s.db.data.hasFolder(secret.UserID, *folderID) {
+		return secret, pgx.ErrNoRows
+	}
+
+	stored.FolderID = nil
+	if folderID != nil {
+		id := *folderID
+		stored.FolderID = &id
+	}
+	s.db.data.secrets[stored.ID] = stored
+
+	return stored.copy(), nil
+}
+
+// SetSecretTags - replaces tags of a model.Secret, every tag has to belong to the user of a secret.
+//
+// Tags are not a content of a secret, so Version of a secret is kept.
+func (s *SecretMemoryStorage) SetSecretTags(
+	ctx context.Context, secret model.Secret, tagIDs []int,
+) (model.Secret, error) {
+	defer s.db.lock(ctx)()
+
+	stored, ok := s.find(secret, active)
+	if !ok {
+		return secret, pgx.ErrNoRows
+	}
+
+	var tags []int
+	for _, tagID := range tagIDs {
+		if !s.db.data.hasTag(secret.UserID, tagID) {
+			return secret, fmt.Errorf("unknown tag %d: %w", tagID, pgx.ErrNoRows)
+		}
+
+		tags = append(tags, tagID)
+	}
+
+	sort.Ints(tags)
+
+	stored.TagIDs = tags
+	s.db.data.secrets[stored.ID] = stored
+
+	return stored.copy(), nil
+}
+
+// find - returns stored secret of UserID with PublicID of provided model.Secret, or with its ID if PublicID is not
+// set, which matches filter.
+func (s *SecretMemoryStorage) find(secret model.Secret, filter trashFilter) (secretRecord, bool) {
+	for _, stored := range s.db.data.secrets {
+		if stored.UserID != secret.UserID || !stored.matches(secret) {
+			continue
+		}
+
+		if filter == active && stored.DeletedAt != nil || filter == inTrash && stored.DeletedAt == nil {
+			return secretRecord{}, false
+		}
+
+		return stored, true
+	}
+
+	return secretRecord{}, false
+}
+
+// matches - reports whether stored secret is referenced by provided one: by PublicID if it's set, by ID otherwise,
+// so a reference with ID of one secret and PublicID of another one doesn't match both of them.
+func (r secretRecord) matches(secret model.Secret) bool {
+	if secret.PublicID != uuid.Nil {
+		return r.PublicID == secret.PublicID
+	}
+
+	return r.ID == secret.ID
+}
+
+// hasType - reports whether secret type with provided id exists.
+func (s *SecretMemoryStorage) hasType(id int) bool {
+	for _, secretType := range s.db.data.secretTypes {
+		if int(secretType.ID) == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// archive - keeps current state of stored secret as its prior version.
+func (s *SecretMemoryStorage) archive(stored secretRecord) {
+	for _, version := range s.db.data.secretVersions[stored.ID] {
+		if version.Version == stored.Version {
+			return
+		}
+	}
+
+	s.db.data.secretVersions[stored.ID] = append(s.db.data.secretVersions[stored.ID], stored.version())
+}
+
+// copy - returns model.Secret of record with its own copy of content.
+func (r secretRecord) copy() model.Secret {
+	secret := r.Secret
+	secret.Content, secret.TitleHash = clone(r.Content), append([]byte(nil), r.TitleHash...)
+	secret.TagIDs = append([]int(nil), r.TagIDs...)
+	if r.FolderID != nil {
+		folderID := *r.FolderID
+		secret.FolderID = &folderID
+	}
+
+	return secret
+}
+
+// folderID - returns id of a folder of record, 0 is returned for secrets in root.
+func (r secretRecord) folderID() int {
+	if r.FolderID == nil {
+		return 0
+	}
+
+	return *r.FolderID
+}
+
+// hasTag - reports whether record is tagged by tag with provided id.
+func (r secretRecord) hasTag(tagID int) bool {
+	for _, id := range r.TagIDs {
+		if id == tagID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// version - returns current state of record as model.SecretVersion with its own copy of content.
+func (r secretRecord) version() model.SecretVersion {
+	return model.SecretVersion{
+		SecretID:  r.ID,
+		PublicID:  r.PublicID,
+		Version:   r.Version,
+		TypeID:    r.TypeID,
+		Title:     r.Title,
+		TitleHash: r.TitleHash,
+		Content:   clone(r.Content),
+		ChangedBy: r.updatedBy,
+		ChangedAt: r.UpdatedAt,
+	}
+}
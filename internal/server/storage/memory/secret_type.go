@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.SecretTypeServerStorage = (*SecretTypeMemoryStorage)(nil)
+
+type SecretTypeMemoryStorage struct {
+	db *DB
+}
+
+// NewSecretTypeMemoryStorage - creates a memory storage for secret types.
+func NewSecretTypeMemoryStorage(db *DB) *SecretTypeMemoryStorage {
+	return &SecretTypeMemoryStorage{db: db}
+}
+
+// GetSecretTypes - returns list of all available secret types.
+func (s *SecretTypeMemoryStorage) GetSecretTypes(ctx context.Context) ([]model.SecretType, error) {
+	defer s.db.lock(ctx)()
+
+	return append([]model.SecretType(nil), s.db.data.secretTypes...), nil
+}
@@ -0,0 +1,321 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/pkg/apperr"
+)
+
+var _ storage.UserServerStorage = (*UserMemoryStorage)(nil)
+
+type UserMemoryStorage struct {
+	db *DB
+}
+
+// userRecord - is a stored user, its password is kept as hash only.
+type userRecord struct {
+	model.User
+	password []byte
+	vaultKey []byte
+}
+
+// NewUserMemoryStorage - Creates UserMemoryStorage instance.
+func NewUserMemoryStorage(db *DB) *UserMemoryStorage {
+	return &UserMemoryStorage{db: db}
+}
+
+// Create - stores a user with data provided from model.User, then returns model.User populated with generated user id.
+//
+// Password is hashed with bcrypt of minimal cost, which is enough for data that never leaves memory and keeps tests
+// fast.
+func (u *UserMemoryStorage) Create(ctx context.Context, user model.User) (model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.MinCost)
+	if err != nil {
+		return user, fmt.Errorf("user password hashing err: %w", err)
+	}
+
+	defer u.db.lock(ctx)()
+
+	for _, stored := range u.db.data.users {
+		if stored.Login == user.Login {
+			return user, apperr.ErrConflict
+		}
+	}
+
+	id := uuid.New()
+	u.db.data.users[id] = userRecord{
+		User:     model.User{ID: &id, Login: user.Login, Role: model.RoleUser, CreatedAt: time.Now()},
+		password: hash,
+		vaultKey: user.VaultKey,
+	}
+	user.ID, user.Role = &id, model.RoleUser
+
+	return user, nil
+}
+
+// GetByLoginAndPassword - searches a user by login from provided model.User, if it is found and password matches,
+// then populates model.User with user id.
+func (u *UserMemoryStorage) GetByLoginAndPassword(ctx context.Context, user model.User) (model.User, error) {
+	defer u.db.lock(ctx)()
+
+	for id, stored := range u.db.data.users {
+		if stored.Login != user.Login {
+			continue
+		}
+
+		err := bcrypt.CompareHashAndPassword(stored.password, []byte(user.Password))
+		if err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				break
+			}
+
+			return user, fmt.Errorf("user login err: %w", err)
+		}
+
+		id := id
+		user.ID, user.Role, user.DisabledAt, user.VaultKey = &id, stored.Role, stored.DisabledAt, stored.vaultKey
+
+		return user, nil
+	}
+
+	return user, fmt.Errorf("user login err: %w", pgx.ErrNoRows)
+}
+
+// DeleteUser - deletes a user and its secrets, then sets ID to nil to model.User.
+func (u *UserMemoryStorage) DeleteUser(ctx context.Context, user model.User) (model.User, error) {
+	defer u.db.lock(ctx)()
+
+	if user.ID == nil {
+		return user, pgx.ErrNoRows
+	}
+
+	if _, ok := u.db.data.users[*user.ID]; !ok {
+		return user, pgx.ErrNoRows
+	}
+
+	u.db.data.deleteSecretsAndFolders(*user.ID)
+
+	delete(u.db.data.users, *user.ID)
+
+	return model.User{}, nil
+}
+
+// GetUser - searches a user by ID from provided model.User, or by its login if ID is nil.
+func (u *UserMemoryStorage) GetUser(ctx context.Context, user model.User) (model.User, error) {
+	defer u.db.lock(ctx)()
+
+	stored, ok := u.find(user)
+	if !ok {
+		return user, pgx.ErrNoRows
+	}
+
+	return stored.User, nil
+}
+
+// ListUsers - returns all users with number and size of their secrets, ordered by login.
+func (u *UserMemoryStorage) ListUsers(ctx context.Context) ([]model.UserUsage, error) {
+	defer u.db.lock(ctx)()
+
+	usages := make(map[uuid.UUID]*model.UserUsage, len(u.db.data.users))
+	users := make([]model.UserUsage, 0, len(u.db.data.users))
+
+	for id, stored := range u.db.data.users {
+		usages[id] = &model.UserUsage{User: stored.User}
+	}
+
+	for id, secret := range u.db.data.secrets {
+		usage, ok := usages[secret.UserID]
+		if !ok {
+			continue
+		}
+
+		if secret.DeletedAt == nil {
+			usage.Secrets++
+		} else {
+			usage.TrashedSecrets++
+		}
+
+		usage.Size += int64(len(secret.Content))
+		for _, version := range u.db.data.secretVersions[id] {
+			usage.Size += int64(len(version.Content))
+		}
+	}
+
+	for _, usage := range usages {
+		users = append(users, *usage)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].User.Login < users[j].User.Login
+	})
+
+	return users, nil
+}
+
+// SetUserDisabled - disables or enables a user by ID from provided model.User, then returns updated model.User.
+//
+// Disabling moves revocation time of user tokens to now, so tokens issued before it stay invalid after the user is
+// enabled again.
+func (u *UserMemoryStorage) SetUserDisabled(ctx context.Context, user model.User, disabled bool) (model.User, error) {
+	return u.update(ctx, user, func(stored *userRecord, now time.Time) {
+		if !disabled {
+			stored.DisabledAt = nil
+
+			return
+		}
+
+		if stored.DisabledAt == nil {
+			stored.DisabledAt = &now
+		}
+		stored.TokensRevokedAt = &now
+	})
+}
+
+// SetUserRole - changes role of a user by ID from provided model.User, then returns updated model.User.
+//
+// Tokens carry role of the user, so tokens issued before the change are revoked.
+func (u *UserMemoryStorage) SetUserRole(ctx context.Context, user model.User, role string) (model.User, error) {
+	return u.update(ctx, user, func(stored *userRecord, now time.Time) {
+		stored.Role, stored.TokensRevokedAt = role, &now
+	})
+}
+
+// ResetPassword - replaces password of a user by ID from provided model.User, revokes its tokens and deletes its
+// vault: wrapped key, secrets, folders and tags, which can't be decrypted without the previous password. Then returns
+// updated model.User.
+func (u *UserMemoryStorage) ResetPassword(ctx context.Context, user model.User, password string) (model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		return user, fmt.Errorf("user password hashing err: %w", err)
+	}
+
+	return u.update(ctx, user, func(stored *userRecord, now time.Time) {
+		stored.password, stored.vaultKey, stored.TokensRevokedAt = hash, nil, &now
+
+		u.db.data.deleteSecretsAndFolders(*stored.ID)
+	})
+}
+
+// SetVaultKey - sets wrapped key of a vault of a user by ID from provided model.User, if it isn't set yet, then
+// returns stored key.
+func (u *UserMemoryStorage) SetVaultKey(ctx context.Context, user model.User, vaultKey []byte) ([]byte, error) {
+	var stored []byte
+
+	_, err := u.update(ctx, user, func(record *userRecord, _ time.Time) {
+		if record.vaultKey == nil {
+			record.vaultKey = vaultKey
+		}
+
+		stored = record.vaultKey
+	})
+
+	return stored, err
+}
+
+// ChangePassword - replaces password and wrapped key of a vault of a user by ID from provided model.User, then
+// returns updated model.User.
+func (u *UserMemoryStorage) ChangePassword(
+	ctx context.Context, user model.User, password string, vaultKey []byte,
+) (model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		return user, fmt.Errorf("user password hashing err: %w", err)
+	}
+
+	return u.update(ctx, user, func(stored *userRecord, _ time.Time) {
+		stored.password, stored.vaultKey = hash, vaultKey
+	})
+}
+
+// update - applies fn to a stored user by ID from provided model.User, then returns updated model.User.
+func (u *UserMemoryStorage) update(
+	ctx context.Context, user model.User, fn func(stored *userRecord, now time.Time),
+) (model.User, error) {
+	defer u.db.lock(ctx)()
+
+	if user.ID == nil {
+		return user, pgx.ErrNoRows
+	}
+
+	stored, ok := u.db.data.users[*user.ID]
+	if !ok {
+		return user, pgx.ErrNoRows
+	}
+
+	fn(&stored, time.Now())
+	u.db.data.users[*user.ID] = stored
+
+	return stored.User, nil
+}
+
+// GetUsersStats - returns number of users and number and size of stored secrets.
+func (u *UserMemoryStorage) GetUsersStats(ctx context.Context) (model.UsersStats, error) {
+	defer u.db.lock(ctx)()
+
+	stats := model.UsersStats{Users: int64(len(u.db.data.users))}
+
+	for _, stored := range u.db.data.users {
+		if stored.DisabledAt != nil {
+			stats.DisabledUsers++
+		}
+	}
+
+	for _, secret := range u.db.data.secrets {
+		if secret.DeletedAt == nil {
+			stats.Secrets++
+		} else {
+			stats.TrashedSecrets++
+		}
+
+		stats.Size += int64(len(secret.Content))
+	}
+
+	for _, versions := range u.db.data.secretVersions {
+		stats.SecretVersions += int64(len(versions))
+
+		for _, version := range versions {
+			stats.Size += int64(len(version.Content))
+		}
+	}
+
+	return stats, nil
+}
+
+// find - returns stored user by ID of provided model.User, or by its login if ID is nil.
+func (u *UserMemoryStorage) find(user model.User) (userRecord, bool) {
+	if user.ID != nil {
+		stored, ok := u.db.data.users[*user.ID]
+
+		return stored, ok
+	}
+
+	for _, stored := range u.db.data.users {
+		if stored.Login == user.Login {
+			return stored, true
+		}
+	}
+
+	return userRecord{}, false
+}
+
+// deleteSecretsAndFolders - deletes all secrets with their versions, folders and tags of the user.
+func (d *data) deleteSecretsAndFolders(userID uuid.UUID) {
+	for id, secret := range d.secrets {
+		if secret.UserID == userID {
+			delete(d.secrets, id)
+			delete(d.secretVersions, id)
+		}
+	}
+
+	d.deleteFoldersAndTags(userID)
+}
@@ -7,11 +7,49 @@ package storagemock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/sergalkin/gophkeeper/internal/server/model"
 )
 
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockTransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTransactor)(nil).WithinTransaction), ctx, fn)
+}
+
 // MockUserServerStorage is a mock of UserServerStorage interface.
 type MockUserServerStorage struct {
 	ctrl     *gomock.Controller
@@ -35,6 +73,21 @@ func (m *MockUserServerStorage) EXPECT() *MockUserServerStorageMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUserServerStorage) ChangePassword(ctx context.Context, user model.User, password string, vaultKey []byte) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, user, password, vaultKey)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserServerStorageMockRecorder) ChangePassword(ctx, user, password, vaultKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserServerStorage)(nil).ChangePassword), ctx, user, password, vaultKey)
+}
+
 // Create mocks base method.
 func (m *MockUserServerStorage) Create(ctx context.Context, user model.User) (model.User, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +133,111 @@ func (mr *MockUserServerStorageMockRecorder) GetByLoginAndPassword(ctx, user int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByLoginAndPassword", reflect.TypeOf((*MockUserServerStorage)(nil).GetByLoginAndPassword), ctx, user)
 }
 
+// GetUser mocks base method.
+func (m *MockUserServerStorage) GetUser(ctx context.Context, user model.User) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, user)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockUserServerStorageMockRecorder) GetUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserServerStorage)(nil).GetUser), ctx, user)
+}
+
+// GetUsersStats mocks base method.
+func (m *MockUserServerStorage) GetUsersStats(ctx context.Context) (model.UsersStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersStats", ctx)
+	ret0, _ := ret[0].(model.UsersStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersStats indicates an expected call of GetUsersStats.
+func (mr *MockUserServerStorageMockRecorder) GetUsersStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersStats", reflect.TypeOf((*MockUserServerStorage)(nil).GetUsersStats), ctx)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserServerStorage) ListUsers(ctx context.Context) ([]model.UserUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx)
+	ret0, _ := ret[0].([]model.UserUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserServerStorageMockRecorder) ListUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserServerStorage)(nil).ListUsers), ctx)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserServerStorage) ResetPassword(ctx context.Context, user model.User, password string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, user, password)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserServerStorageMockRecorder) ResetPassword(ctx, user, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserServerStorage)(nil).ResetPassword), ctx, user, password)
+}
+
+// SetUserDisabled mocks base method.
+func (m *MockUserServerStorage) SetUserDisabled(ctx context.Context, user model.User, disabled bool) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDisabled", ctx, user, disabled)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserDisabled indicates an expected call of SetUserDisabled.
+func (mr *MockUserServerStorageMockRecorder) SetUserDisabled(ctx, user, disabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockUserServerStorage)(nil).SetUserDisabled), ctx, user, disabled)
+}
+
+// SetUserRole mocks base method.
+func (m *MockUserServerStorage) SetUserRole(ctx context.Context, user model.User, role string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, user, role)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockUserServerStorageMockRecorder) SetUserRole(ctx, user, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockUserServerStorage)(nil).SetUserRole), ctx, user, role)
+}
+
+// SetVaultKey mocks base method.
+func (m *MockUserServerStorage) SetVaultKey(ctx context.Context, user model.User, vaultKey []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVaultKey", ctx, user, vaultKey)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVaultKey indicates an expected call of SetVaultKey.
+func (mr *MockUserServerStorageMockRecorder) SetVaultKey(ctx, user, vaultKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVaultKey", reflect.TypeOf((*MockUserServerStorage)(nil).SetVaultKey), ctx, user, vaultKey)
+}
+
 // MockSecretTypeServerStorage is a mock of SecretTypeServerStorage interface.
 type MockSecretTypeServerStorage struct {
 	ctrl     *gomock.Controller
@@ -187,31 +345,569 @@ func (mr *MockSecretServerStorageMockRecorder) EditSecret(ctx, secret, isForce i
 }
 
 // GetListOfSecretByType mocks base method.
-func (m *MockSecretServerStorage) GetListOfSecretByType(ctx context.Context, secretType model.SecretType, user model.User) ([]model.Secret, error) {
+func (m *MockSecretServerStorage) GetListOfSecretByType(ctx context.Context, secretType model.SecretType, user model.User, withTrashed bool) ([]model.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetListOfSecretByType", ctx, secretType, user)
+	ret := m.ctrl.Call(m, "GetListOfSecretByType", ctx, secretType, user, withTrashed)
 	ret0, _ := ret[0].([]model.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetListOfSecretByType indicates an expected call of GetListOfSecretByType.
-func (mr *MockSecretServerStorageMockRecorder) GetListOfSecretByType(ctx, secretType, user interface{}) *gomock.Call {
+func (mr *MockSecretServerStorageMockRecorder) GetListOfSecretByType(ctx, secretType, user, withTrashed interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListOfSecretByType", reflect.TypeOf((*MockSecretServerStorage)(nil).GetListOfSecretByType), ctx, secretType, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListOfSecretByType", reflect.TypeOf((*MockSecretServerStorage)(nil).GetListOfSecretByType), ctx, secretType, user, withTrashed)
 }
 
 // GetSecret mocks base method.
-func (m *MockSecretServerStorage) GetSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+func (m *MockSecretServerStorage) GetSecret(ctx context.Context, secret model.Secret, withTrashed bool) (model.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSecret", ctx, secret)
+	ret := m.ctrl.Call(m, "GetSecret", ctx, secret, withTrashed)
 	ret0, _ := ret[0].(model.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSecret indicates an expected call of GetSecret.
-func (mr *MockSecretServerStorageMockRecorder) GetSecret(ctx, secret interface{}) *gomock.Call {
+func (mr *MockSecretServerStorageMockRecorder) GetSecret(ctx, secret, withTrashed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockSecretServerStorage)(nil).GetSecret), ctx, secret, withTrashed)
+}
+
+// GetSecretVersion mocks base method.
+func (m *MockSecretServerStorage) GetSecretVersion(ctx context.Context, secret model.Secret, version int64) (model.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretVersion", ctx, secret, version)
+	ret0, _ := ret[0].(model.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretVersion indicates an expected call of GetSecretVersion.
+func (mr *MockSecretServerStorageMockRecorder) GetSecretVersion(ctx, secret, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretVersion", reflect.TypeOf((*MockSecretServerStorage)(nil).GetSecretVersion), ctx, secret, version)
+}
+
+// ListSecretVersions mocks base method.
+func (m *MockSecretServerStorage) ListSecretVersions(ctx context.Context, secret model.Secret) ([]model.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecretVersions", ctx, secret)
+	ret0, _ := ret[0].([]model.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecretVersions indicates an expected call of ListSecretVersions.
+func (mr *MockSecretServerStorageMockRecorder) ListSecretVersions(ctx, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecretVersions", reflect.TypeOf((*MockSecretServerStorage)(nil).ListSecretVersions), ctx, secret)
+}
+
+// ListSecrets mocks base method.
+func (m *MockSecretServerStorage) ListSecrets(ctx context.Context, filter model.SecretFilter) ([]model.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecrets", ctx, filter)
+	ret0, _ := ret[0].([]model.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecrets indicates an expected call of ListSecrets.
+func (mr *MockSecretServerStorageMockRecorder) ListSecrets(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockSecretServerStorage)(nil).ListSecrets), ctx, filter)
+}
+
+// ListTrash mocks base method.
+func (m *MockSecretServerStorage) ListTrash(ctx context.Context, user model.User) ([]model.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", ctx, user)
+	ret0, _ := ret[0].([]model.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockSecretServerStorageMockRecorder) ListTrash(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockSecretServerStorage)(nil).ListTrash), ctx, user)
+}
+
+// MoveSecret mocks base method.
+func (m *MockSecretServerStorage) MoveSecret(ctx context.Context, secret model.Secret, folderID *int) (model.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveSecret", ctx, secret, folderID)
+	ret0, _ := ret[0].(model.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveSecret indicates an expected call of MoveSecret.
+func (mr *MockSecretServerStorageMockRecorder) MoveSecret(ctx, secret, folderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveSecret", reflect.TypeOf((*MockSecretServerStorage)(nil).MoveSecret), ctx, secret, folderID)
+}
+
+// PurgeSecret mocks base method.
+func (m *MockSecretServerStorage) PurgeSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSecret", ctx, secret)
+	ret0, _ := ret[0].(model.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeSecret indicates an expected call of PurgeSecret.
+func (mr *MockSecretServerStorageMockRecorder) PurgeSecret(ctx, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSecret", reflect.TypeOf((*MockSecretServerStorage)(nil).PurgeSecret), ctx, secret)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockSecretServerStorage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockSecretServerStorageMockRecorder) PurgeTrash(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockSecretServerStorage)(nil).PurgeTrash), ctx, before)
+}
+
+// RestoreSecret mocks base method.
+func (m *MockSecretServerStorage) RestoreSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSecret", ctx, secret)
+	ret0, _ := ret[0].(model.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSecret indicates an expected call of RestoreSecret.
+func (mr *MockSecretServerStorageMockRecorder) RestoreSecret(ctx, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSecret", reflect.TypeOf((*MockSecretServerStorage)(nil).RestoreSecret), ctx, secret)
+}
+
+// RestoreSecretVersion mocks base method.
+func (m *MockSecretServerStorage) RestoreSecretVersion(ctx context.Context, secret model.Secret, version int64) (model.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSecretVersion", ctx, secret, version)
+	ret0, _ := ret[0].(model.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSecretVersion indicates an expected call of RestoreSecretVersion.
+func (mr *MockSecretServerStorageMockRecorder) RestoreSecretVersion(ctx, secret, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSecretVersion", reflect.TypeOf((*MockSecretServerStorage)(nil).RestoreSecretVersion), ctx, secret, version)
+}
+
+// SetSecretTags mocks base method.
+func (m *MockSecretServerStorage) SetSecretTags(ctx context.Context, secret model.Secret, tagIDs []int) (model.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSecretTags", ctx, secret, tagIDs)
+	ret0, _ := ret[0].(model.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSecretTags indicates an expected call of SetSecretTags.
+func (mr *MockSecretServerStorageMockRecorder) SetSecretTags(ctx, secret, tagIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSecretTags", reflect.TypeOf((*MockSecretServerStorage)(nil).SetSecretTags), ctx, secret, tagIDs)
+}
+
+// TrimSecretVersions mocks base method.
+func (m *MockSecretServerStorage) TrimSecretVersions(ctx context.Context, secret model.Secret, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrimSecretVersions", ctx, secret, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrimSecretVersions indicates an expected call of TrimSecretVersions.
+func (mr *MockSecretServerStorageMockRecorder) TrimSecretVersions(ctx, secret, keep interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrimSecretVersions", reflect.TypeOf((*MockSecretServerStorage)(nil).TrimSecretVersions), ctx, secret, keep)
+}
+
+// MockFolderServerStorage is a mock of FolderServerStorage interface.
+type MockFolderServerStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderServerStorageMockRecorder
+}
+
+// MockFolderServerStorageMockRecorder is the mock recorder for MockFolderServerStorage.
+type MockFolderServerStorageMockRecorder struct {
+	mock *MockFolderServerStorage
+}
+
+// NewMockFolderServerStorage creates a new mock instance.
+func NewMockFolderServerStorage(ctrl *gomock.Controller) *MockFolderServerStorage {
+	mock := &MockFolderServerStorage{ctrl: ctrl}
+	mock.recorder = &MockFolderServerStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderServerStorage) EXPECT() *MockFolderServerStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateFolder mocks base method.
+func (m *MockFolderServerStorage) CreateFolder(ctx context.Context, folder model.Folder) (model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFolder", ctx, folder)
+	ret0, _ := ret[0].(model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFolder indicates an expected call of CreateFolder.
+func (mr *MockFolderServerStorageMockRecorder) CreateFolder(ctx, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockFolderServerStorage)(nil).CreateFolder), ctx, folder)
+}
+
+// CreateTag mocks base method.
+func (m *MockFolderServerStorage) CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", ctx, tag)
+	ret0, _ := ret[0].(model.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockFolderServerStorageMockRecorder) CreateTag(ctx, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockFolderServerStorage)(nil).CreateTag), ctx, tag)
+}
+
+// ListFolders mocks base method.
+func (m *MockFolderServerStorage) ListFolders(ctx context.Context, user model.User) ([]model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFolders", ctx, user)
+	ret0, _ := ret[0].([]model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFolders indicates an expected call of ListFolders.
+func (mr *MockFolderServerStorageMockRecorder) ListFolders(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolders", reflect.TypeOf((*MockFolderServerStorage)(nil).ListFolders), ctx, user)
+}
+
+// ListTags mocks base method.
+func (m *MockFolderServerStorage) ListTags(ctx context.Context, user model.User) ([]model.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", ctx, user)
+	ret0, _ := ret[0].([]model.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockFolderServerStorageMockRecorder) ListTags(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockFolderServerStorage)(nil).ListTags), ctx, user)
+}
+
+// MockIdempotencyServerStorage is a mock of IdempotencyServerStorage interface.
+type MockIdempotencyServerStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyServerStorageMockRecorder
+}
+
+// MockIdempotencyServerStorageMockRecorder is the mock recorder for MockIdempotencyServerStorage.
+type MockIdempotencyServerStorageMockRecorder struct {
+	mock *MockIdempotencyServerStorage
+}
+
+// NewMockIdempotencyServerStorage creates a new mock instance.
+func NewMockIdempotencyServerStorage(ctrl *gomock.Controller) *MockIdempotencyServerStorage {
+	mock := &MockIdempotencyServerStorage{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyServerStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyServerStorage) EXPECT() *MockIdempotencyServerStorageMockRecorder {
+	return m.recorder
+}
+
+// PurgeKeys mocks base method.
+func (m *MockIdempotencyServerStorage) PurgeKeys(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeKeys", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeKeys indicates an expected call of PurgeKeys.
+func (mr *MockIdempotencyServerStorageMockRecorder) PurgeKeys(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeKeys", reflect.TypeOf((*MockIdempotencyServerStorage)(nil).PurgeKeys), ctx, before)
+}
+
+// ReleaseKey mocks base method.
+func (m *MockIdempotencyServerStorage) ReleaseKey(ctx context.Context, key model.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseKey indicates an expected call of ReleaseKey.
+func (mr *MockIdempotencyServerStorageMockRecorder) ReleaseKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseKey", reflect.TypeOf((*MockIdempotencyServerStorage)(nil).ReleaseKey), ctx, key)
+}
+
+// ReserveKey mocks base method.
+func (m *MockIdempotencyServerStorage) ReserveKey(ctx context.Context, key model.IdempotencyKey, ttl time.Duration) (model.IdempotencyKey, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveKey", ctx, key, ttl)
+	ret0, _ := ret[0].(model.IdempotencyKey)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReserveKey indicates an expected call of ReserveKey.
+func (mr *MockIdempotencyServerStorageMockRecorder) ReserveKey(ctx, key, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveKey", reflect.TypeOf((*MockIdempotencyServerStorage)(nil).ReserveKey), ctx, key, ttl)
+}
+
+// SaveResponse mocks base method.
+func (m *MockIdempotencyServerStorage) SaveResponse(ctx context.Context, key model.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveResponse", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveResponse indicates an expected call of SaveResponse.
+func (mr *MockIdempotencyServerStorageMockRecorder) SaveResponse(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveResponse", reflect.TypeOf((*MockIdempotencyServerStorage)(nil).SaveResponse), ctx, key)
+}
+
+// MockJobServerStorage is a mock of JobServerStorage interface.
+type MockJobServerStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobServerStorageMockRecorder
+}
+
+// MockJobServerStorageMockRecorder is the mock recorder for MockJobServerStorage.
+type MockJobServerStorageMockRecorder struct {
+	mock *MockJobServerStorage
+}
+
+// NewMockJobServerStorage creates a new mock instance.
+func NewMockJobServerStorage(ctrl *gomock.Controller) *MockJobServerStorage {
+	mock := &MockJobServerStorage{ctrl: ctrl}
+	mock.recorder = &MockJobServerStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobServerStorage) EXPECT() *MockJobServerStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateJobRun mocks base method.
+func (m *MockJobServerStorage) CreateJobRun(ctx context.Context, run model.JobRun) (model.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobRun", ctx, run)
+	ret0, _ := ret[0].(model.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJobRun indicates an expected call of CreateJobRun.
+func (mr *MockJobServerStorageMockRecorder) CreateJobRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobRun", reflect.TypeOf((*MockJobServerStorage)(nil).CreateJobRun), ctx, run)
+}
+
+// FinishJobRun mocks base method.
+func (m *MockJobServerStorage) FinishJobRun(ctx context.Context, run model.JobRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJobRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishJobRun indicates an expected call of FinishJobRun.
+func (mr *MockJobServerStorageMockRecorder) FinishJobRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJobRun", reflect.TypeOf((*MockJobServerStorage)(nil).FinishJobRun), ctx, run)
+}
+
+// ListJobRuns mocks base method.
+func (m *MockJobServerStorage) ListJobRuns(ctx context.Context, name string, limit int) ([]model.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRuns", ctx, name, limit)
+	ret0, _ := ret[0].([]model.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRuns indicates an expected call of ListJobRuns.
+func (mr *MockJobServerStorageMockRecorder) ListJobRuns(ctx, name, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRuns", reflect.TypeOf((*MockJobServerStorage)(nil).ListJobRuns), ctx, name, limit)
+}
+
+// Ping mocks base method.
+func (m *MockJobServerStorage) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockJobServerStorageMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockJobServerStorage)(nil).Ping), ctx)
+}
+
+// TryLock mocks base method.
+func (m *MockJobServerStorage) TryLock(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryLock", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryLock indicates an expected call of TryLock.
+func (mr *MockJobServerStorageMockRecorder) TryLock(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryLock", reflect.TypeOf((*MockJobServerStorage)(nil).TryLock), ctx, name)
+}
+
+// MockAuditServerStorage is a mock of AuditServerStorage interface.
+type MockAuditServerStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServerStorageMockRecorder
+}
+
+// MockAuditServerStorageMockRecorder is the mock recorder for MockAuditServerStorage.
+type MockAuditServerStorageMockRecorder struct {
+	mock *MockAuditServerStorage
+}
+
+// NewMockAuditServerStorage creates a new mock instance.
+func NewMockAuditServerStorage(ctrl *gomock.Controller) *MockAuditServerStorage {
+	mock := &MockAuditServerStorage{ctrl: ctrl}
+	mock.recorder = &MockAuditServerStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditServerStorage) EXPECT() *MockAuditServerStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateAuditCheckpoint mocks base method.
+func (m *MockAuditServerStorage) CreateAuditCheckpoint(ctx context.Context, checkpoint model.AuditCheckpoint) (model.AuditCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditCheckpoint", ctx, checkpoint)
+	ret0, _ := ret[0].(model.AuditCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditCheckpoint indicates an expected call of CreateAuditCheckpoint.
+func (mr *MockAuditServerStorageMockRecorder) CreateAuditCheckpoint(ctx, checkpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditCheckpoint", reflect.TypeOf((*MockAuditServerStorage)(nil).CreateAuditCheckpoint), ctx, checkpoint)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockAuditServerStorage) CreateAuditEvent(ctx context.Context, event model.AuditEvent) (model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", ctx, event)
+	ret0, _ := ret[0].(model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockAuditServerStorageMockRecorder) CreateAuditEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockAuditServerStorage)(nil).CreateAuditEvent), ctx, event)
+}
+
+// GetLastAuditCheckpoint mocks base method.
+func (m *MockAuditServerStorage) GetLastAuditCheckpoint(ctx context.Context) (model.AuditCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAuditCheckpoint", ctx)
+	ret0, _ := ret[0].(model.AuditCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAuditCheckpoint indicates an expected call of GetLastAuditCheckpoint.
+func (mr *MockAuditServerStorageMockRecorder) GetLastAuditCheckpoint(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAuditCheckpoint", reflect.TypeOf((*MockAuditServerStorage)(nil).GetLastAuditCheckpoint), ctx)
+}
+
+// ListAuditChain mocks base method.
+func (m *MockAuditServerStorage) ListAuditChain(ctx context.Context, afterID int64, limit int) ([]model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditChain", ctx, afterID, limit)
+	ret0, _ := ret[0].([]model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditChain indicates an expected call of ListAuditChain.
+func (mr *MockAuditServerStorageMockRecorder) ListAuditChain(ctx, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditChain", reflect.TypeOf((*MockAuditServerStorage)(nil).ListAuditChain), ctx, afterID, limit)
+}
+
+// ListAuditCheckpoints mocks base method.
+func (m *MockAuditServerStorage) ListAuditCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditCheckpoints", ctx)
+	ret0, _ := ret[0].([]model.AuditCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditCheckpoints indicates an expected call of ListAuditCheckpoints.
+func (mr *MockAuditServerStorageMockRecorder) ListAuditCheckpoints(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditCheckpoints", reflect.TypeOf((*MockAuditServerStorage)(nil).ListAuditCheckpoints), ctx)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockAuditServerStorage) ListAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", ctx, filter)
+	ret0, _ := ret[0].([]model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockAuditServerStorageMockRecorder) ListAuditEvents(ctx, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockSecretServerStorage)(nil).GetSecret), ctx, secret)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockAuditServerStorage)(nil).ListAuditEvents), ctx, filter)
 }
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.AuditServerStorage = (*AuditPostgresStorage)(nil)
+
+// AuditPostgresStorage - is an append-only storage of audit events, changes of stored events are rejected by trigger.
+type AuditPostgresStorage struct {
+	pool *pgxpool.Pool
+}
+
+const (
+	LockAuditChain   = `select pg_advisory_xact_lock(hashtext('audit_events'))`
+	GetLastAuditHash = `select hash from audit_events order by id desc limit 1`
+	CreateAuditEvent = `insert into audit_events (user_id, event, success, target, ip, user_agent, request_id, created_at,
+						prev_hash, hash)
+						values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+						returning id`
+	ListAuditEvents = `select id, user_id, event, success, target, ip, user_agent, request_id, created_at, prev_hash, hash
+					   from audit_events
+					   where ($1::uuid is null or user_id = $1) and ($2 = 0 or id < $2)
+					   order by id desc
+					   limit $3`
+	ListAuditChain = `select id, user_id, event, success, target, ip, user_agent, request_id, created_at, prev_hash, hash
+					  from audit_events
+					  where id > $1
+					  order by id
+					  limit $2`
+	CreateAuditCheckpoint = `insert into audit_checkpoints (event_id, hash, signature, created_at)
+							 values ($1, $2, $3, $4)
+							 returning id`
+	GetLastAuditCheckpoint = `select id, event_id, hash, signature, created_at
+							  from audit_checkpoints
+							  order by id desc
+							  limit 1`
+	ListAuditCheckpoints = `select id, event_id, hash, signature, created_at from audit_checkpoints order by id`
+)
+
+// NewAuditPostgresStorage - creates AuditPostgresStorage instance.
+func NewAuditPostgresStorage(p *pgxpool.Pool) *AuditPostgresStorage {
+	return &AuditPostgresStorage{pool: p}
+}
+
+// CreateAuditEvent - inserts provided model.AuditEvent into DB chaining it to the last stored event, then returns it
+// populated with id and hashes.
+//
+// Chain is locked by transaction-level advisory lock, so concurrent events are chained one after another in order of
+// their ids.
+func (a *AuditPostgresStorage) CreateAuditEvent(ctx context.Context, event model.AuditEvent) (model.AuditEvent, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	event.CreatedAt = event.CreatedAt.Truncate(time.Microsecond)
+
+	err := withinTransaction(ctxWithTimeOut, a.pool, func(ctx context.Context) error {
+		if _, err := conn(ctx, a.pool).Exec(ctx, LockAuditChain); err != nil {
+			return err
+		}
+
+		err := conn(ctx, a.pool).QueryRow(ctx, GetLastAuditHash).Scan(&event.PrevHash)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		event.Hash = event.ChainHash(event.PrevHash)
+
+		return conn(ctx, a.pool).QueryRow(ctx, CreateAuditEvent,
+			event.UserID, event.Event, event.Success, event.Target, event.IP, event.UserAgent, event.RequestID,
+			event.CreatedAt, event.PrevHash, event.Hash,
+		).Scan(&event.ID)
+	})
+	if err != nil {
+		return event, fmt.Errorf("audit event insertion err: %w", err)
+	}
+
+	return event, nil
+}
+
+// ListAuditEvents - returns audit events selected by model.AuditEventFilter from DB, latest go first.
+func (a *AuditPostgresStorage) ListAuditEvents(
+	ctx context.Context, filter model.AuditEventFilter,
+) ([]model.AuditEvent, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, a.pool).Query(ctxWithTimeOut, ListAuditEvents, filter.UserID, filter.BeforeID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("audit events list err: %w", err)
+	}
+
+	return scanAuditEvents(rows)
+}
+
+// ListAuditChain - returns up to limit audit events with id greater than afterID from DB in order of the chain.
+func (a *AuditPostgresStorage) ListAuditChain(
+	ctx context.Context, afterID int64, limit int,
+) ([]model.AuditEvent, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, a.pool).Query(ctxWithTimeOut, ListAuditChain, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("audit chain list err: %w", err)
+	}
+
+	return scanAuditEvents(rows)
+}
+
+// CreateAuditCheckpoint - inserts provided model.AuditCheckpoint into DB, then returns it populated with id.
+func (a *AuditPostgresStorage) CreateAuditCheckpoint(
+	ctx context.Context, checkpoint model.AuditCheckpoint,
+) (model.AuditCheckpoint, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := conn(ctx, a.pool).QueryRow(ctxWithTimeOut, CreateAuditCheckpoint,
+		checkpoint.EventID, checkpoint.Hash, checkpoint.Signature, checkpoint.CreatedAt,
+	).Scan(&checkpoint.ID)
+	if err != nil {
+		return checkpoint, fmt.Errorf("audit checkpoint insertion err: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// GetLastAuditCheckpoint - returns the latest audit checkpoint from DB, pgx.ErrNoRows is returned if there is none.
+func (a *AuditPostgresStorage) GetLastAuditCheckpoint(ctx context.Context) (model.AuditCheckpoint, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var checkpoint model.AuditCheckpoint
+	err := conn(ctx, a.pool).QueryRow(ctxWithTimeOut, GetLastAuditCheckpoint).Scan(
+		&checkpoint.ID, &checkpoint.EventID, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt,
+	)
+	if err != nil {
+		return checkpoint, fmt.Errorf("audit checkpoint get err: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// ListAuditCheckpoints - returns all audit checkpoints from DB in order of creation.
+func (a *AuditPostgresStorage) ListAuditCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, a.pool).Query(ctxWithTimeOut, ListAuditCheckpoints)
+	if err != nil {
+		return nil, fmt.Errorf("audit checkpoints list err: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []model.AuditCheckpoint
+	for rows.Next() {
+		var checkpoint model.AuditCheckpoint
+		if err = rows.Scan(
+			&checkpoint.ID, &checkpoint.EventID, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("audit checkpoints list err: %w", err)
+		}
+
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("audit checkpoints list err: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+// scanAuditEvents - scans audit events from rows and closes them.
+func scanAuditEvents(rows pgx.Rows) ([]model.AuditEvent, error) {
+	defer rows.Close()
+
+	var events []model.AuditEvent
+	for rows.Next() {
+		var event model.AuditEvent
+		if err := rows.Scan(
+			&event.ID, &event.UserID, &event.Event, &event.Success, &event.Target, &event.IP, &event.UserAgent,
+			&event.RequestID, &event.CreatedAt, &event.PrevHash, &event.Hash,
+		); err != nil {
+			return nil, fmt.Errorf("audit events list err: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("audit events list err: %w", err)
+	}
+
+	return events, nil
+}
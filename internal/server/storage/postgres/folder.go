@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.FolderServerStorage = (*FolderPostgresStorage)(nil)
+
+type FolderPostgresStorage struct {
+	pool *pgxpool.Pool
+}
+
+const (
+	CreateFolder = `insert into folders (user_id, parent_id, name, created_at)
+					select $1, $2, $3, $4
+					where $2::bigint is null or exists(select 1 from folders where id = $2 and user_id = $1)
+					returning id
+`
+	ListFolders = `select id, user_id, parent_id, name, created_at from folders where user_id = $1 order by id`
+	CreateTag   = `insert into tags (user_id, name, created_at) values ($1, $2, $3) returning id`
+	ListTags    = `select id, user_id, name, created_at from tags where user_id = $1 order by id`
+)
+
+// NewFolderPostgresStorage - creates a postgres storage for folders and tags.
+func NewFolderPostgresStorage(p *pgxpool.Pool) *FolderPostgresStorage {
+	return &FolderPostgresStorage{pool: p}
+}
+
+// CreateFolder - stores provided model.Folder in database.
+//
+// If parent folder doesn't belong to the user of a folder, then pgx.ErrNoRows is returned.
+func (s *FolderPostgresStorage) CreateFolder(ctx context.Context, folder model.Folder) (model.Folder, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, CreateFolder, folder.UserID, folder.ParentID, folder.Name,
+		folder.CreatedAt,
+	).Scan(&folder.ID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return folder, fmt.Errorf("folder creation error: %w", err)
+		}
+
+		return folder, fmt.Errorf("parent folder not found: %w", err)
+	}
+
+	return folder, nil
+}
+
+// ListFolders - returns a []model.Folder of model.User from database.
+func (s *FolderPostgresStorage) ListFolders(ctx context.Context, user model.User) ([]model.Folder, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, s.pool).Query(ctxWithTimeOut, ListFolders, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of folders error: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []model.Folder
+	for rows.Next() {
+		var folder model.Folder
+		if err = rows.Scan(&folder.ID, &folder.UserID, &folder.ParentID, &folder.Name, &folder.CreatedAt); err != nil {
+			return folders, fmt.Errorf("error in scanning folder: %w", err)
+		}
+
+		folders = append(folders, folder)
+	}
+
+	return folders, rows.Err()
+}
+
+// CreateTag - stores provided model.Tag in database.
+func (s *FolderPostgresStorage) CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, CreateTag, tag.UserID, tag.Name, tag.CreatedAt).Scan(&tag.ID)
+	if err != nil {
+		return tag, fmt.Errorf("tag creation error: %w", err)
+	}
+
+	return tag, nil
+}
+
+// ListTags - returns a []model.Tag of model.User from database.
+func (s *FolderPostgresStorage) ListTags(ctx context.Context, user model.User) ([]model.Tag, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, s.pool).Query(ctxWithTimeOut, ListTags, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of tags error: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []model.Tag
+	for rows.Next() {
+		var tag model.Tag
+		if err = rows.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt); err != nil {
+			return tags, fmt.Errorf("error in scanning tag: %w", err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/pkg/utils"
+)
+
+func TestNewIdempotencyPostgresStorage(t *testing.T) {
+	tests := []struct {
+		name string
+		want *IdempotencyPostgresStorage
+	}{
+		{
+			name: "New Postgres Idempotency Storage could be created",
+			want: &IdempotencyPostgresStorage{&pgxpool.Pool{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewIdempotencyPostgresStorage(&pgxpool.Pool{})
+
+			assert.Equalf(t, tt.want, got, "NewIdempotencyPostgresStorage() = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestIdempotencyPostgresStorage_ReserveKey(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
+
+	key := model.IdempotencyKey{Key: "key", UserID: "user", Method: "method", RequestHash: []byte{1}}
+
+	tests := []struct {
+		name         string
+		ttl          time.Duration
+		wantReserved bool
+		wantResponse []byte
+		do           func(s *IdempotencyPostgresStorage)
+	}{
+		{
+			name:         "Key can be reserved",
+			ttl:          time.Hour,
+			wantReserved: true,
+			do: func(s *IdempotencyPostgresStorage) {
+				utils.RefreshTestDatabase()
+			},
+		},
+		{
+			name:         "Already reserved key returns stored response",
+			ttl:          time.Hour,
+			wantReserved: false,
+			wantResponse: []byte{2},
+			do: func(s *IdempotencyPostgresStorage) {
+				utils.RefreshTestDatabase()
+
+				s.ReserveKey(ctx, key, time.Hour)
+				s.SaveResponse(ctx, model.IdempotencyKey{
+					Key: key.Key, UserID: key.UserID, Method: key.Method, Response: []byte{2},
+				})
+			},
+		},
+		{
+			name:         "Expired key can be reserved again",
+			ttl:          time.Nanosecond,
+			wantReserved: true,
+			do: func(s *IdempotencyPostgresStorage) {
+				utils.RefreshTestDatabase()
+
+				s.ReserveKey(ctx, key, time.Hour)
+				time.Sleep(time.Millisecond)
+			},
+		},
+		{
+			name:         "Released key can be reserved again",
+			ttl:          time.Hour,
+			wantReserved: true,
+			do: func(s *IdempotencyPostgresStorage) {
+				utils.RefreshTestDatabase()
+
+				s.ReserveKey(ctx, key, time.Hour)
+				s.ReleaseKey(ctx, key)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &IdempotencyPostgresStorage{pool: con}
+			tt.do(s)
+
+			got, reserved, err := s.ReserveKey(ctx, key, tt.ttl)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantReserved, reserved)
+			assert.Equal(t, tt.wantResponse, got.Response)
+		})
+	}
+}
+
+func TestIdempotencyPostgresStorage_SaveResponse(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
+
+	utils.RefreshTestDatabase()
+
+	s := &IdempotencyPostgresStorage{pool: con}
+
+	err := s.SaveResponse(ctx, model.IdempotencyKey{Key: "missing", Response: []byte{1}})
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestIdempotencyPostgresStorage_PurgeKeys(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
+
+	utils.RefreshTestDatabase()
+
+	s := &IdempotencyPostgresStorage{pool: con}
+
+	s.ReserveKey(ctx, model.IdempotencyKey{Key: "key", UserID: "user", Method: "method", RequestHash: []byte{1}}, time.Hour)
+
+	purged, err := s.PurgeKeys(ctx, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = s.PurgeKeys(ctx, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.JobServerStorage = (*JobPostgresStorage)(nil)
+
+// JobPostgresStorage - is a storage of job locks and job runs.
+//
+// Locks are session-level advisory locks, so storage must own its connection: locks are released by Postgres as soon
+// as connection is closed, which lets another replica take leadership of a job.
+type JobPostgresStorage struct {
+	conn *pgx.Conn
+	mu   sync.Mutex
+}
+
+const (
+	TryLockJob   = `select pg_try_advisory_lock(hashtext($1))`
+	CreateJobRun = `insert into job_runs (name, instance, status, started_at) 
+					values ($1,$2,$3,$4) 
+					returning id
+`
+	FinishJobRun = `update job_runs set status = $1, message = $2, finished_at = $3 where id = $4`
+	ListJobRuns  = `select id, name, instance, status, message, started_at, finished_at
+					from job_runs
+					where ($1 = '' or name = $1)
+					order by started_at desc
+					limit $2
+`
+)
+
+// NewJobPostgresStorage - creates a postgres storage for job locks and job runs, provided connection must not be
+// shared with other storages.
+func NewJobPostgresStorage(c *pgx.Conn) *JobPostgresStorage {
+	return &JobPostgresStorage{conn: c}
+}
+
+// TryLock - attempts to take advisory lock, which key is a hash of job name.
+func (j *JobPostgresStorage) TryLock(ctx context.Context, name string) (bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var locked bool
+	if err := j.conn.QueryRow(ctxWithTimeOut, TryLockJob, name).Scan(&locked); err != nil {
+		return false, fmt.Errorf("job lock taking err: %w", err)
+	}
+
+	return locked, nil
+}
+
+// Ping - checks that connection, which keeps advisory locks, is alive.
+func (j *JobPostgresStorage) Ping(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	if err := j.conn.Ping(ctxWithTimeOut); err != nil {
+		return fmt.Errorf("job locks connection err: %w", err)
+	}
+
+	return nil
+}
+
+// CreateJobRun - stores provided model.JobRun in database.
+func (j *JobPostgresStorage) CreateJobRun(ctx context.Context, run model.JobRun) (model.JobRun, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := j.conn.QueryRow(ctxWithTimeOut, CreateJobRun, run.Name, run.Instance, run.Status, run.StartedAt).
+		Scan(&run.ID)
+	if err != nil {
+		return run, fmt.Errorf("job run storing err: %w", err)
+	}
+
+	return run, nil
+}
+
+// FinishJobRun - updates status, message and finished_at of provided model.JobRun in database.
+func (j *JobPostgresStorage) FinishJobRun(ctx context.Context, run model.JobRun) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	_, err := j.conn.Exec(ctxWithTimeOut, FinishJobRun, run.Status, run.Message, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("job run updating err: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobRuns - returns a []model.JobRun from database, latest started go first.
+func (j *JobPostgresStorage) ListJobRuns(ctx context.Context, name string, limit int) ([]model.JobRun, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var runs []model.JobRun
+
+	rows, err := j.conn.Query(ctxWithTimeOut, ListJobRuns, name, limit)
+	if err != nil {
+		return runs, fmt.Errorf("getting list of job runs error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run model.JobRun
+
+		if scanErr := rows.Scan(
+			&run.ID, &run.Name, &run.Instance, &run.Status, &run.Message, &run.StartedAt, &run.FinishedAt,
+		); scanErr != nil {
+			return runs, fmt.Errorf("error in scanning gotten row: %w", scanErr)
+		}
+
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
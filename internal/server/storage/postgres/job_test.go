@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/pkg/utils"
+)
+
+func TestNewJobPostgresStorage(t *testing.T) {
+	conn := &pgx.Conn{}
+
+	got := NewJobPostgresStorage(conn)
+
+	assert.Equal(t, conn, got.conn)
+}
+
+func TestJobPostgresStorage_TryLock(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestConn()
+	defer con.Close(ctx)
+
+	another := utils.CreatePostgresTestConn()
+	defer another.Close(ctx)
+
+	s := NewJobPostgresStorage(con)
+	anotherS := NewJobPostgresStorage(another)
+
+	locked, err := s.TryLock(ctx, "test")
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	locked, err = anotherS.TryLock(ctx, "test")
+	assert.NoError(t, err)
+	assert.False(t, locked, "lock of a job is taken by one connection only")
+
+	locked, err = anotherS.TryLock(ctx, "another")
+	assert.NoError(t, err)
+	assert.True(t, locked, "locks of different jobs are independent")
+
+	con.Close(ctx)
+
+	locked, err = anotherS.TryLock(ctx, "test")
+	assert.NoError(t, err)
+	assert.True(t, locked, "lock is released along with connection")
+}
+
+func TestJobPostgresStorage_JobRuns(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestConn()
+	defer con.Close(ctx)
+
+	utils.RefreshTestDatabase()
+
+	s := NewJobPostgresStorage(con)
+
+	now := time.Now()
+	for i, name := range []string{"first", "second", "first"} {
+		run, err := s.CreateJobRun(ctx, model.JobRun{
+			Name:      name,
+			Instance:  "test",
+			Status:    model.JobRunStatusRunning,
+			StartedAt: now.Add(time.Duration(i) * time.Second),
+		})
+		assert.NoError(t, err)
+		assert.NotZero(t, run.ID)
+
+		finishedAt := run.StartedAt.Add(time.Second)
+		run.FinishedAt, run.Status, run.Message = &finishedAt, model.JobRunStatusSucceeded, "done"
+
+		assert.NoError(t, s.FinishJobRun(ctx, run))
+	}
+
+	tests := []struct {
+		name    string
+		job     string
+		limit   int
+		wantLen int
+	}{
+		{name: "All runs are listed", job: "", limit: 10, wantLen: 3},
+		{name: "Runs are filtered by job name", job: "first", limit: 10, wantLen: 2},
+		{name: "Runs are limited", job: "", limit: 1, wantLen: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ListJobRuns(ctx, tt.job, tt.limit)
+
+			assert.NoError(t, err)
+			if assert.Len(t, got, tt.wantLen) {
+				assert.Equal(t, model.JobRunStatusSucceeded, got[0].Status)
+				assert.NotNil(t, got[0].FinishedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.Transactor = (*Transactor)(nil)
+
+// PoolLimits - limits of connections in *pgxpool.Pool, zero value of a limit keeps pgxpool default.
+type PoolLimits struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+}
+
+// querier - is a common interface of *pgxpool.Pool and pgx.Tx, which is used by storages to run queries.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// txCtx - is a key of pgx.Tx in context.
+type txCtx struct{}
+
+// NewPool - creates *pgxpool.Pool with provided limits and checks that database is reachable.
+func NewPool(ctx context.Context, dsn string, limits PoolLimits) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("db config parsing: %w", err)
+	}
+
+	if limits.MaxConns > 0 {
+		poolCfg.MaxConns = limits.MaxConns
+	}
+	if limits.MinConns > 0 {
+		poolCfg.MinConns = limits.MinConns
+	}
+	if limits.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = limits.MaxConnLifetime
+	}
+	if limits.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = limits.MaxConnIdleTime
+	}
+
+	pool, err := pgxpool.ConnectConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("db open: %w", err)
+	}
+
+	if err = pool.Ping(ctx); err != nil {
+		pool.Close()
+
+		return nil, fmt.Errorf("db ping: %w", err)
+	}
+
+	return pool, nil
+}
+
+// Transactor - runs functions in a transaction of *pgxpool.Pool.
+type Transactor struct {
+	pool *pgxpool.Pool
+}
+
+// NewTransactor - creates new Transactor.
+func NewTransactor(p *pgxpool.Pool) *Transactor {
+	return &Transactor{pool: p}
+}
+
+// WithinTransaction - runs fn in a transaction, which is committed if fn returns no error and rolled back otherwise.
+//
+// Postgres storages called with context passed to fn run their queries in the transaction. If context already
+// carries a transaction, then fn joins it.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, t.pool, fn)
+}
+
+// withinTransaction - runs fn in a transaction from context or in a new transaction of pool.
+func withinTransaction(ctx context.Context, pool *pgxpool.Pool, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txCtx{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("transaction begin err: %w", err)
+	}
+	// rollback of committed transaction is no-op
+	defer tx.Rollback(ctx)
+
+	if err = fn(context.WithValue(ctx, txCtx{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit err: %w", err)
+	}
+
+	return nil
+}
+
+// conn - returns transaction from context if there is one, otherwise returns pool.
+func conn(ctx context.Context, pool *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txCtx{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return pool
+}
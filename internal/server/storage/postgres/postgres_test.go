@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergalkin/gophkeeper/pkg/utils"
+)
+
+func TestTransactor_WithinTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
+
+	insert := func(ctx context.Context, login string) error {
+		_, err := conn(ctx, con).Exec(ctx, "insert into users (login, password) values ($1, 'test')", login)
+		return err
+	}
+
+	tests := []struct {
+		name      string
+		fn        func(ctx context.Context) error
+		wantErr   assert.ErrorAssertionFunc
+		wantUsers int
+	}{
+		{
+			name: "Changes are committed if function succeeds",
+			fn: func(ctx context.Context) error {
+				if err := insert(ctx, "first"); err != nil {
+					return err
+				}
+
+				return insert(ctx, "second")
+			},
+			wantErr:   assert.NoError,
+			wantUsers: 2,
+		},
+		{
+			name: "Changes are rolled back if function fails",
+			fn: func(ctx context.Context) error {
+				if err := insert(ctx, "first"); err != nil {
+					return err
+				}
+
+				return errors.New("test")
+			},
+			wantErr:   assert.Error,
+			wantUsers: 0,
+		},
+		{
+			name: "Nested transaction joins outer one",
+			fn: func(ctx context.Context) error {
+				errNested := NewTransactor(con).WithinTransaction(ctx, func(ctx context.Context) error {
+					return insert(ctx, "first")
+				})
+				if errNested != nil {
+					return errNested
+				}
+
+				return errors.New("test")
+			},
+			wantErr:   assert.Error,
+			wantUsers: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.RefreshTestDatabase()
+
+			tt.wantErr(t, NewTransactor(con).WithinTransaction(ctx, tt.fn))
+
+			var users int
+			assert.NoError(t, con.QueryRow(ctx, "select count(*) from users").Scan(&users))
+			assert.Equal(t, tt.wantUsers, users)
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/sergalkin/gophkeeper/internal/server/model"
 	"github.com/sergalkin/gophkeeper/internal/server/storage"
@@ -17,47 +19,164 @@ import (
 var _ storage.SecretServerStorage = (*SecretPostgresStorage)(nil)
 
 type SecretPostgresStorage struct {
-	conn *pgx.Conn
+	pool *pgxpool.Pool
 }
 
 const (
 	CreateSecrete = `
-					insert into secrets (user_id, type_id, title, content, created_at, updated_at) 
-					values ($1,$2,$3,$4,$5,$6) 
-					returning id
+					insert into secrets (public_id, user_id, type_id, title, content, created_at, updated_at, updated_by,
+						title_hash) 
+					values ($1,$2,$3,$4,$5,$6,$7,$2,$8) 
+					on conflict (public_id) do nothing
+					returning id, version
 `
-	GetSecret = `select id, user_id, type_id, title, content, created_at, updated_at, deleted_at 
+	GetSecret = `select id, public_id, user_id, type_id, title, content, created_at, updated_at, deleted_at, version, 
+					folder_id, array(select tag_id from secret_tags where secret_id = secrets.id order by tag_id), title_hash
 				 from secrets 
-				 where id = $1 and user_id = $2
+				 where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+				   and user_id = $3 and ($4 or deleted_at is null)
+`
+	DeleteSecret = `update secrets 
+					set deleted_at = $4 
+					where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+					  and user_id = $3 and deleted_at is null
+					returning id
 `
-	DeleteSecret = `delete from secrets where id = $1 and user_id = $2 returning id`
 	UpdateSecret = `update secrets 
-					set title = $1, content = $2, updated_at = $3
+					set title = coalesce($1, title), content = coalesce($2, content), updated_at = $3, updated_by = $5, 
+						version = version + 1, title_hash = case when $1::text is null then title_hash else $6 end
 					where id = $4 and user_id = $5
-					returning type_id, updated_at, deleted_at
+					returning id, public_id, type_id, title, created_at, updated_at, deleted_at, version, folder_id, 
+						array(select tag_id from secret_tags where secret_id = secrets.id order by tag_id)
+`
+	LockSecretVersion = `select id, public_id, updated_at, version 
+						 from secrets 
+						 where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+						   and user_id = $3 and deleted_at is null
+						 for update
+`
+	SecretVersions = `select v.secret_id, s.public_id, v.version, v.type_id, v.title, v.content, v.changed_by, 
+       					  v.changed_at
+					  from secret_versions v
+					  join secrets s on s.id = v.secret_id
+					  where (s.public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and s.id = $1)
+					    and s.user_id = $3 and s.deleted_at is null
+					  union all
+					  select id, public_id, version, type_id, title, content, coalesce(updated_by, user_id), updated_at
+					  from secrets
+					  where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+					    and user_id = $3 and deleted_at is null
+`
+	RestoreSecretVersion = `update secrets s
+							set type_id = v.type_id, title = v.title, title_hash = v.title_hash, content = v.content, 
+								updated_at = $4, updated_by = $3, version = s.version + 1
+							from secret_versions v
+							where v.secret_id = s.id and v.version = $5 
+							  and (s.public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and s.id = $1)
+							  and s.user_id = $3 and s.deleted_at is null
+							returning s.id, s.public_id, s.type_id, s.title, s.created_at, s.updated_at, 
+								s.deleted_at, s.version
+`
+	TrimSecretVersions = `delete from secret_versions v
+						  using secrets s
+						  where v.secret_id = s.id
+							and (s.public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and s.id = $1)
+							and s.user_id = $3 and v.version < s.version - $4
 `
-	SecretsByType = `select id, user_id, type_id, title, content, created_at, updated_at, deleted_at 
+	SecretsByType = `select id, public_id, user_id, type_id, title, content, created_at, updated_at, deleted_at, version, 
+						folder_id, array(select tag_id from secret_tags where secret_id = secrets.id order by tag_id), title_hash
 					 from secrets
-					 where type_id = $1 and user_id = $2 
+					 where type_id = $1 and user_id = $2 and ($3 or deleted_at is null)
+`
+	TrashedSecrets = `select id, public_id, user_id, type_id, title, content, created_at, updated_at, deleted_at, version, 
+						 folder_id, array(select tag_id from secret_tags where secret_id = secrets.id order by tag_id), title_hash
+					  from secrets
+					  where user_id = $1 and deleted_at is not null
+					  order by deleted_at desc
+`
+	// ListSecrets - is a template of a query, content column, sort key expression, comparison operator of the cursor
+	// and sort direction are formatted into it.
+	ListSecrets = `select id, public_id, user_id, type_id, title, %[1]s, created_at, updated_at, deleted_at, version, 
+					  folder_id, array(select tag_id from secret_tags where secret_id = secrets.id order by tag_id), title_hash
+				   from secrets
+				   where user_id = $1
+					 and ($2::bigint is null or coalesce(folder_id, 0) = $2)
+					 and ($3::bigint = 0 or exists(select 1 from secret_tags where secret_id = secrets.id and tag_id = $3))
+					 and ($4::bigint = 0 or type_id = $4)
+					 and ($5::timestamptz is null or updated_at >= $5)
+					 and ($11::bytea is null or title_hash = $11)
+					 and case $6::int when 0 then deleted_at is null when 1 then deleted_at is not null else true end
+					 and (not $7::boolean or %[2]s %[3]s $8 or (%[2]s = $8 and id %[3]s $9))
+				   order by %[2]s %[4]s, id %[4]s
+				   limit $10
+`
+	MoveSecret = `update secrets 
+				  set folder_id = $4
+				  where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+				    and user_id = $3 and deleted_at is null
+					and ($4::bigint is null or exists(select 1 from folders where id = $4 and user_id = $3))
+				  returning id
+`
+	LockSecret = `select id 
+				  from secrets 
+				  where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+				    and user_id = $3 and deleted_at is null
+				  for update
+`
+	CountUserTags    = `select count(*) from tags where id = any($1) and user_id = $2`
+	DeleteSecretTags = `delete from secret_tags where secret_id = $1`
+	InsertSecretTags = `insert into secret_tags (secret_id, tag_id) select $1, unnest($2::bigint[])`
+	RestoreSecret    = `update secrets 
+					 set deleted_at = null 
+					 where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+					   and user_id = $3 and deleted_at is not null
+					 returning id, public_id, type_id, title, created_at, updated_at, deleted_at, version
+`
+	PurgeSecret = `delete from secrets 
+				   where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+				     and user_id = $3 and deleted_at is not null
+				   returning id
 `
+	PurgeTrash = `delete from secrets where deleted_at < $1`
 )
 
-func NewSecretPostgresStorage(c *pgx.Conn) *SecretPostgresStorage {
-	return &SecretPostgresStorage{conn: c}
+func NewSecretPostgresStorage(p *pgxpool.Pool) *SecretPostgresStorage {
+	return &SecretPostgresStorage{pool: p}
 }
 
 // CreateSecret - stores provided model.Secret in database.
 //
 // Values of key Content of model.Secret is being hex encoded.
+//
+// Creation is idempotent by PublicID: if a secret with the same PublicID already exists for the user, then the stored
+// model.Secret is returned instead of creating a duplicate. If PublicID belongs to another user, then
+// apperr.ErrConflict is returned. Conflicts don't abort transaction, so creation could be a part of a batch.
 func (s *SecretPostgresStorage) CreateSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	err := s.conn.QueryRow(ctxWithTimeOut, CreateSecrete, secret.UserID, secret.TypeID, secret.Title,
-		hex.EncodeToString(secret.Content), secret.CreatedAt, secret.UpdatedAt,
-	).Scan(&secret.ID)
+	if secret.PublicID == uuid.Nil {
+		secret.PublicID = uuid.New()
+	}
+
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, CreateSecrete, secret.PublicID, secret.UserID, secret.TypeID, secret.Title,
+		hex.EncodeToString(secret.Content), secret.CreatedAt, secret.UpdatedAt, titleHash(secret.TitleHash),
+	).Scan(&secret.ID, &secret.Version)
 	if err != nil {
-		return secret, fmt.Errorf("error in storing secret in db: %w", err)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return secret, fmt.Errorf("error in storing secret in db: %w", err)
+		}
+
+		existing, errGet := s.GetSecret(ctx, model.Secret{PublicID: secret.PublicID, UserID: secret.UserID}, true)
+		if errGet != nil {
+			if errors.Is(errGet, pgx.ErrNoRows) {
+				return secret, apperr.ErrConflict
+			}
+
+			return secret, errGet
+		}
+
+		return existing, nil
 	}
 
 	return secret, nil
@@ -65,14 +184,18 @@ func (s *SecretPostgresStorage) CreateSecret(ctx context.Context, secret model.S
 
 // GetSecret - return rehydrated model.Secret from database.
 //
-// Searches by user_id and id from provided model.Secret
-func (s *SecretPostgresStorage) GetSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+// Searches by user_id and id or public_id from provided model.Secret, secrets in trash are found only if withTrashed
+// is true.
+func (s *SecretPostgresStorage) GetSecret(
+	ctx context.Context, secret model.Secret, withTrashed bool,
+) (model.Secret, error) {
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	err := s.conn.QueryRow(ctxWithTimeOut, GetSecret, secret.ID, secret.UserID).Scan(
-		&secret.ID, &secret.UserID, &secret.TypeID, &secret.Title, &secret.Content,
-		&secret.CreatedAt, &secret.UpdatedAt, &secret.DeletedAt,
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, GetSecret, secret.ID, secret.PublicID, secret.UserID, withTrashed).Scan(
+		&secret.ID, &secret.PublicID, &secret.UserID, &secret.TypeID, &secret.Title, &secret.Content,
+		&secret.CreatedAt, &secret.UpdatedAt, &secret.DeletedAt, &secret.Version, &secret.FolderID, &secret.TagIDs,
+		&secret.TitleHash,
 	)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
@@ -88,17 +211,19 @@ func (s *SecretPostgresStorage) GetSecret(ctx context.Context, secret model.Secr
 	}
 
 	secret.Content = decode
+	secret.TagIDs = tagIDs(secret.TagIDs)
 
 	return secret, nil
 }
 
-// DeleteSecret - deletes a model.Secret from database.
+// DeleteSecret - moves a model.Secret to trash by setting its deleted_at in database.
 func (s *SecretPostgresStorage) DeleteSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
 	var deletedSecretId *int
-	err := s.conn.QueryRow(ctxWithTimeOut, DeleteSecret, secret.ID, secret.UserID).Scan(&deletedSecretId)
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, DeleteSecret, secret.ID, secret.PublicID, secret.UserID, time.Now()).
+		Scan(&deletedSecretId)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
 			return secret, fmt.Errorf("secret deletion err: %w", err)
@@ -110,23 +235,106 @@ func (s *SecretPostgresStorage) DeleteSecret(ctx context.Context, secret model.S
 	return model.Secret{}, nil
 }
 
-// EditSecret - updates a model.Secret in database.
+// EditSecret - updates a model.Secret in database, empty Title and nil Content keep the stored ones. TitleHash is
+// replaced along with Title.
+//
+// Update is applied only if Version of provided model.Secret matches the stored one, unless isForce is true. On
+// successful update Version is incremented. On mismatch returns apperr.ErrVersionDoesntMatch and model.Secret with
+// current Version and UpdatedAt from database.
+//
+// Stored version is read and checked in the same transaction with update, row of a secret is locked till its end.
 func (s *SecretPostgresStorage) EditSecret(ctx context.Context, secret model.Secret, isForce bool) (model.Secret, error) {
+	err := withinTransaction(ctx, s.pool, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		current := model.Secret{}
+		errVersion := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, LockSecretVersion, secret.ID, secret.PublicID,
+			secret.UserID,
+		).Scan(&current.ID, &current.PublicID, &current.UpdatedAt, &current.Version)
+		if errVersion != nil {
+			if !errors.Is(errVersion, pgx.ErrNoRows) {
+				return fmt.Errorf("secret version getting error: %w", errVersion)
+			}
+
+			return errVersion
+		}
+
+		if !isForce && current.Version != secret.Version {
+			secret.ID, secret.PublicID = current.ID, current.PublicID
+			secret.UpdatedAt, secret.Version = current.UpdatedAt, current.Version
+
+			return apperr.ErrVersionDoesntMatch
+		}
+
+		var title, content interface{}
+		if secret.Title != "" {
+			title = secret.Title
+		}
+		if secret.Content != nil {
+			content = hex.EncodeToString(secret.Content)
+		}
+
+		err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, UpdateSecret, title, content, time.Now(), current.ID,
+			secret.UserID, titleHash(secret.TitleHash),
+		).Scan(&secret.ID, &secret.PublicID, &secret.TypeID, &secret.Title, &secret.CreatedAt, &secret.UpdatedAt,
+			&secret.DeletedAt, &secret.Version, &secret.FolderID, &secret.TagIDs)
+		if err != nil {
+			return fmt.Errorf("secret updating error: %w", err)
+		}
+		secret.TagIDs = tagIDs(secret.TagIDs)
+
+		return nil
+	})
+
+	return secret, err
+}
+
+// GetListOfSecretByType - returns a []model.Secret from database by provided type_id via model.SecretType and user_id
+// via model.User.
+//
+// Secrets in trash are returned only if withTrashed is true.
+func (s *SecretPostgresStorage) GetListOfSecretByType(
+	ctx context.Context, secretType model.SecretType, user model.User, withTrashed bool,
+) ([]model.Secret, error) {
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	ss, _ := s.GetSecret(ctx, secret)
+	rows, err := conn(ctx, s.pool).Query(ctxWithTimeOut, SecretsByType, secretType.ID, user.ID, withTrashed)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of secrets error: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSecrets(rows)
+}
+
+// ListTrash - returns a []model.Secret in trash of model.User from database, latest deleted go first.
+func (s *SecretPostgresStorage) ListTrash(ctx context.Context, user model.User) ([]model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
 
-	if ss.UpdatedAt.Unix() != secret.UpdatedAt.Unix() && !isForce {
-		return secret, apperr.ErrUpdatedAtDoesntMatch
+	rows, err := conn(ctx, s.pool).Query(ctxWithTimeOut, TrashedSecrets, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of trashed secrets error: %w", err)
 	}
+	defer rows.Close()
+
+	return scanSecrets(rows)
+}
+
+// RestoreSecret - moves a model.Secret out of trash by resetting its deleted_at in database.
+func (s *SecretPostgresStorage) RestoreSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
 
-	err := s.conn.QueryRow(ctxWithTimeOut, UpdateSecret, secret.Title, hex.EncodeToString(secret.Content),
-		time.Now(), secret.ID, secret.UserID,
-	).Scan(&secret.TypeID, &secret.UpdatedAt, &secret.DeletedAt)
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, RestoreSecret, secret.ID, secret.PublicID, secret.UserID).Scan(
+		&secret.ID, &secret.PublicID, &secret.TypeID, &secret.Title, &secret.CreatedAt, &secret.UpdatedAt,
+		&secret.DeletedAt, &secret.Version,
+	)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
-			return secret, fmt.Errorf("secret updating error: %w", err)
+			return secret, fmt.Errorf("secret restoring error: %w", err)
 		}
 
 		return secret, err
@@ -135,27 +343,260 @@ func (s *SecretPostgresStorage) EditSecret(ctx context.Context, secret model.Sec
 	return secret, nil
 }
 
-// GetListOfSecretByType - returns a []model.Secret from database by provided type_id via model.SecretType and user_id
-// via model.User.
-func (s *SecretPostgresStorage) GetListOfSecretByType(
-	ctx context.Context, secretType model.SecretType, user model.User,
-) ([]model.Secret, error) {
+// PurgeSecret - permanently deletes a model.Secret, which is in trash, from database.
+func (s *SecretPostgresStorage) PurgeSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	var secrets []model.Secret
+	var purgedSecretId *int
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, PurgeSecret, secret.ID, secret.PublicID, secret.UserID).
+		Scan(&purgedSecretId)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return secret, fmt.Errorf("secret purging err: %w", err)
+		}
+
+		return secret, err
+	}
+
+	return model.Secret{}, nil
+}
+
+// PurgeTrash - permanently deletes all secrets, which were moved to trash before provided time, from database.
+//
+// Returns number of deleted secrets.
+func (s *SecretPostgresStorage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
 
-	rows, err := s.conn.Query(ctxWithTimeOut, SecretsByType, secretType.ID, user.ID)
+	tag, err := conn(ctx, s.pool).Exec(ctxWithTimeOut, PurgeTrash, before)
 	if err != nil {
-		return secrets, fmt.Errorf("getting list of secrets error: %w", err)
+		return 0, fmt.Errorf("trash purging error: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ListSecretVersions - returns a []model.SecretVersion of a model.Secret from database, including current version.
+//
+// Versions are ordered from the latest to the oldest one.
+func (s *SecretPostgresStorage) ListSecretVersions(
+	ctx context.Context, secret model.Secret,
+) ([]model.SecretVersion, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var versions []model.SecretVersion
+
+	rows, err := conn(ctx, s.pool).Query(ctxWithTimeOut, SecretVersions+" order by version desc",
+		secret.ID, secret.PublicID, secret.UserID,
+	)
+	if err != nil {
+		return versions, fmt.Errorf("getting list of secret versions error: %w", err)
 	}
 	defer rows.Close()
 
+	for rows.Next() {
+		version, errScan := scanSecretVersion(rows)
+		if errScan != nil {
+			return versions, errScan
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// GetSecretVersion - returns a model.SecretVersion of a model.Secret from database by its version.
+func (s *SecretPostgresStorage) GetSecretVersion(
+	ctx context.Context, secret model.Secret, version int64,
+) (model.SecretVersion, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	row := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, "select * from ("+SecretVersions+") versions where version = $4",
+		secret.ID, secret.PublicID, secret.UserID, version,
+	)
+
+	return scanSecretVersion(row)
+}
+
+// RestoreSecretVersion - sets title, type and content of a model.Secret in database to the values of provided
+// version.
+//
+// Restoring is a change of a secret, so Version is incremented and replaced content is kept as a prior version.
+// TitleHash is restored along with title.
+func (s *SecretPostgresStorage) RestoreSecretVersion(
+	ctx context.Context, secret model.Secret, version int64,
+) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, RestoreSecretVersion, secret.ID, secret.PublicID, secret.UserID,
+		time.Now(), version,
+	).Scan(&secret.ID, &secret.PublicID, &secret.TypeID, &secret.Title, &secret.CreatedAt, &secret.UpdatedAt,
+		&secret.DeletedAt, &secret.Version)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return secret, fmt.Errorf("secret version restoring error: %w", err)
+		}
+
+		return secret, err
+	}
+
+	return secret, nil
+}
+
+// TrimSecretVersions - deletes prior versions of a model.Secret from database, except keep latest ones.
+func (s *SecretPostgresStorage) TrimSecretVersions(ctx context.Context, secret model.Secret, keep int) error {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	_, err := conn(ctx, s.pool).Exec(ctxWithTimeOut, TrimSecretVersions, secret.ID, secret.PublicID, secret.UserID, keep)
+	if err != nil {
+		return fmt.Errorf("secret versions trimming error: %w", err)
+	}
+
+	return nil
+}
+
+// ListSecrets - returns a page of []model.Secret of a user selected and sorted by model.SecretFilter from database.
+func (s *SecretPostgresStorage) ListSecrets(ctx context.Context, filter model.SecretFilter) ([]model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	content, key, cmp, dir := "content", "id", ">", "asc"
+	if filter.WithoutContent {
+		content = "null::bytea"
+	}
+
+	var cursor interface{} = 0
+	switch filter.OrderKey() {
+	case model.SecretOrderUpdatedAt:
+		key, cursor = "updated_at", time.Time{}
+	}
+
+	if filter.Desc {
+		cmp, dir = "<", "desc"
+	}
+
+	cursorID := 0
+	if filter.After != nil {
+		cursorID = filter.After.ID
+		switch filter.OrderKey() {
+		case model.SecretOrderUpdatedAt:
+			cursor = filter.After.UpdatedAt
+		default:
+			cursor = filter.After.ID
+		}
+	}
+
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	rows, err := conn(ctx, s.pool).Query(ctxWithTimeOut, fmt.Sprintf(ListSecrets, content, key, cmp, dir),
+		filter.UserID, filter.FolderID, filter.TagID, filter.TypeID, filter.UpdatedSince, int(filter.Trash),
+		filter.After != nil, cursor, cursorID, limit, titleHash(filter.TitleHash),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of secrets error: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSecrets(rows)
+}
+
+// MoveSecret - sets folder of a model.Secret in database, folder has to belong to the user of a secret.
+//
+// Moving is not a change of content, so Version of a secret is kept.
+func (s *SecretPostgresStorage) MoveSecret(
+	ctx context.Context, secret model.Secret, folderID *int,
+) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var movedSecretID int
+	err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, MoveSecret, secret.ID, secret.PublicID, secret.UserID, folderID).
+		Scan(&movedSecretID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return secret, fmt.Errorf("secret moving error: %w", err)
+		}
+
+		return secret, err
+	}
+
+	return s.GetSecret(ctx, model.Secret{ID: movedSecretID, UserID: secret.UserID}, false)
+}
+
+// SetSecretTags - replaces tags of a model.Secret in database, every tag has to belong to the user of a secret.
+//
+// Tags are not a content of a secret, so Version of a secret is kept.
+func (s *SecretPostgresStorage) SetSecretTags(
+	ctx context.Context, secret model.Secret, tagIDs []int,
+) (model.Secret, error) {
+	err := withinTransaction(ctx, s.pool, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		err := conn(ctx, s.pool).QueryRow(ctxWithTimeOut, LockSecret, secret.ID, secret.PublicID, secret.UserID).
+			Scan(&secret.ID)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("secret locking error: %w", err)
+			}
+
+			return err
+		}
+
+		var owned int
+		if err = conn(ctx, s.pool).QueryRow(ctxWithTimeOut, CountUserTags, tagIDs, secret.UserID).Scan(&owned); err != nil {
+			return fmt.Errorf("tags counting error: %w", err)
+		}
+
+		if owned != len(tagIDs) {
+			return fmt.Errorf("unknown tag: %w", pgx.ErrNoRows)
+		}
+
+		if _, err = conn(ctx, s.pool).Exec(ctxWithTimeOut, DeleteSecretTags, secret.ID); err != nil {
+			return fmt.Errorf("secret tags deleting error: %w", err)
+		}
+
+		if _, err = conn(ctx, s.pool).Exec(ctxWithTimeOut, InsertSecretTags, secret.ID, tagIDs); err != nil {
+			return fmt.Errorf("secret tags inserting error: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return secret, err
+	}
+
+	return s.GetSecret(ctx, model.Secret{ID: secret.ID, UserID: secret.UserID}, false)
+}
+
+// tagIDs - returns nil instead of empty list of tags, so secrets without tags are the same for every storage.
+func tagIDs(ids []int) []int {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return ids
+}
+
+// scanSecrets - scans []model.Secret from rows and decodes their content.
+func scanSecrets(rows pgx.Rows) ([]model.Secret, error) {
+	var secrets []model.Secret
+
 	for rows.Next() {
 		var secret model.Secret
 
 		if scanErr := rows.Scan(
 			&secret.ID,
+			&secret.PublicID,
 			&secret.UserID,
 			&secret.TypeID,
 			&secret.Title,
@@ -163,18 +604,58 @@ func (s *SecretPostgresStorage) GetListOfSecretByType(
 			&secret.CreatedAt,
 			&secret.UpdatedAt,
 			&secret.DeletedAt,
+			&secret.Version,
+			&secret.FolderID,
+			&secret.TagIDs,
+			&secret.TitleHash,
 		); scanErr != nil {
 			return secrets, fmt.Errorf("error in scanning gotten row: %w", scanErr)
 		}
 
-		secret.Content, err = hex.DecodeString(string(secret.Content))
+		if secret.Content != nil {
+			var err error
+			secret.Content, err = hex.DecodeString(string(secret.Content))
 
-		if err != nil {
-			return secrets, fmt.Errorf("error in decodeing content from row: %w", err)
+			if err != nil {
+				return secrets, fmt.Errorf("error in decodeing content from row: %w", err)
+			}
 		}
+		secret.TagIDs = tagIDs(secret.TagIDs)
 
 		secrets = append(secrets, secret)
 	}
 
 	return secrets, nil
 }
+
+// scanSecretVersion - scans model.SecretVersion from row and decodes its content.
+func scanSecretVersion(row pgx.Row) (model.SecretVersion, error) {
+	var version model.SecretVersion
+
+	err := row.Scan(&version.SecretID, &version.PublicID, &version.Version, &version.TypeID, &version.Title,
+		&version.Content, &version.ChangedBy, &version.ChangedAt,
+	)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return version, fmt.Errorf("error in scanning secret version: %w", err)
+		}
+
+		return version, err
+	}
+
+	version.Content, err = hex.DecodeString(string(version.Content))
+	if err != nil {
+		return version, fmt.Errorf("error in decoding content of secret version: %w", err)
+	}
+
+	return version, nil
+}
+
+// titleHash - returns blind index of a title as a query argument, empty one is null.
+func titleHash(hash []byte) interface{} {
+	if len(hash) == 0 {
+		return nil
+	}
+
+	return hash
+}
@@ -9,9 +9,11 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/pkg/apperr"
 	"github.com/sergalkin/gophkeeper/pkg/utils"
 )
 
@@ -22,12 +24,12 @@ func TestNewSecretPostgresStorage(t *testing.T) {
 	}{
 		{
 			name: "New Postgres Secret Storage could be created",
-			want: &SecretPostgresStorage{&pgx.Conn{}},
+			want: &SecretPostgresStorage{&pgxpool.Pool{}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewSecretPostgresStorage(&pgx.Conn{})
+			got := NewSecretPostgresStorage(&pgxpool.Pool{})
 
 			assert.Equalf(t, tt.want, got, "NewPostgresSecretStorage() = %v, want %v", got, tt.want)
 		})
@@ -37,10 +39,11 @@ func TestNewSecretPostgresStorage(t *testing.T) {
 func TestSecretPostgresStorage_CreateSecret(t *testing.T) {
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	uid := uuid.New()
+	pid := uuid.New()
 
 	type args struct {
 		ctx    context.Context
@@ -58,6 +61,7 @@ func TestSecretPostgresStorage_CreateSecret(t *testing.T) {
 			args: args{
 				ctx: ctx,
 				secret: model.Secret{
+					PublicID:  pid,
 					UserID:    uid,
 					TypeID:    1,
 					Title:     "Test",
@@ -69,6 +73,7 @@ func TestSecretPostgresStorage_CreateSecret(t *testing.T) {
 			},
 			want: model.Secret{
 				ID:        1,
+				PublicID:  pid,
 				UserID:    uid,
 				TypeID:    1,
 				Title:     "Test",
@@ -87,18 +92,41 @@ func TestSecretPostgresStorage_CreateSecret(t *testing.T) {
 				defer row.Close()
 			},
 		},
+		{
+			name: "Secret creation with already stored public id returns stored secret",
+			args: args{
+				ctx: ctx,
+				secret: model.Secret{
+					PublicID: pid,
+					UserID:   uid,
+					TypeID:   1,
+					Title:    "Test retry",
+				},
+			},
+			want: model.Secret{
+				ID:       1,
+				PublicID: pid,
+				UserID:   uid,
+				TypeID:   1,
+				Title:    "Test",
+				Content:  []byte{},
+			},
+			wantErr: assert.NoError,
+			do:      func() {},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.do()
 
 			s := &SecretPostgresStorage{
-				conn: con,
+				pool: con,
 			}
 
 			got, err := s.CreateSecret(tt.args.ctx, tt.args.secret)
 			tt.wantErr(t, err, fmt.Sprintf("CreateSecret(%v, %v)", tt.args.ctx, tt.args.secret))
 
+			got.CreatedAt, got.UpdatedAt = tt.want.CreatedAt, tt.want.UpdatedAt
 			assert.Equalf(t, tt.want, got, "CreateSecret(%v, %v)", tt.args.ctx, tt.args.secret)
 		})
 	}
@@ -107,14 +135,15 @@ func TestSecretPostgresStorage_CreateSecret(t *testing.T) {
 func TestSecretPostgresStorage_GetSecret(t *testing.T) {
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	uid := uuid.New()
 
 	type args struct {
-		ctx    context.Context
-		secret model.Secret
+		ctx         context.Context
+		secret      model.Secret
+		withTrashed bool
 	}
 	tests := []struct {
 		name    string
@@ -145,6 +174,51 @@ func TestSecretPostgresStorage_GetSecret(t *testing.T) {
 				r2.Close()
 			},
 		},
+		{
+			name: "Trashed secret can be gotten only with trashed",
+			args: args{
+				ctx:         ctx,
+				secret:      model.Secret{ID: 1, UserID: uid},
+				withTrashed: true,
+			},
+			wantErr: assert.NoError,
+			do: func() {
+				utils.RefreshTestDatabase()
+
+				row, _ := con.Query(
+					ctx, "insert into users (id, login, password) values ($1,$2,$3)", uid, "test", "test",
+				)
+				row.Close()
+
+				r2, _ := con.Query(
+					ctx, "insert into secrets (user_id, title, content, type_id, deleted_at) values ($1,$2,$3,$4,$5)",
+					uid, "test", hex.EncodeToString([]byte{10, 20}), 1, time.Now(),
+				)
+				r2.Close()
+			},
+		},
+		{
+			name: "Error will be returned when getting trashed secret",
+			args: args{
+				ctx:    ctx,
+				secret: model.Secret{ID: 1, UserID: uid},
+			},
+			wantErr: assert.Error,
+			do: func() {
+				utils.RefreshTestDatabase()
+
+				row, _ := con.Query(
+					ctx, "insert into users (id, login, password) values ($1,$2,$3)", uid, "test", "test",
+				)
+				row.Close()
+
+				r2, _ := con.Query(
+					ctx, "insert into secrets (user_id, title, content, type_id, deleted_at) values ($1,$2,$3,$4,$5)",
+					uid, "test", hex.EncodeToString([]byte{10, 20}), 1, time.Now(),
+				)
+				r2.Close()
+			},
+		},
 		{
 			name: "Error will be returned when getting non existent secret",
 			args: args{
@@ -161,9 +235,9 @@ func TestSecretPostgresStorage_GetSecret(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.do()
 
-			s := &SecretPostgresStorage{conn: con}
+			s := &SecretPostgresStorage{pool: con}
 
-			got, err := s.GetSecret(tt.args.ctx, tt.args.secret)
+			got, err := s.GetSecret(tt.args.ctx, tt.args.secret, tt.args.withTrashed)
 
 			if !tt.wantErr(t, err, fmt.Sprintf("GetSecret(%v, %v)", tt.args.ctx, tt.args.secret)) {
 				assert.NotNil(t, got.Content)
@@ -175,8 +249,8 @@ func TestSecretPostgresStorage_GetSecret(t *testing.T) {
 func TestSecretPostgresStorage_DeleteSecret(t *testing.T) {
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	uid := uuid.New()
 
@@ -231,7 +305,7 @@ func TestSecretPostgresStorage_DeleteSecret(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.do()
 
-			s := &SecretPostgresStorage{conn: con}
+			s := &SecretPostgresStorage{pool: con}
 
 			got, err := s.DeleteSecret(tt.args.ctx, tt.args.secret)
 
@@ -245,8 +319,8 @@ func TestSecretPostgresStorage_DeleteSecret(t *testing.T) {
 func TestSecretPostgresStorage_EditSecret(t *testing.T) {
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	uid := uuid.New()
 
@@ -255,10 +329,12 @@ func TestSecretPostgresStorage_EditSecret(t *testing.T) {
 		secret model.Secret
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr assert.ErrorAssertionFunc
-		do      func()
+		name        string
+		args        args
+		isForce     bool
+		wantErr     assert.ErrorAssertionFunc
+		wantVersion int64
+		do          func()
 	}{
 		{
 			name: "Secret can be edited",
@@ -275,7 +351,9 @@ func TestSecretPostgresStorage_EditSecret(t *testing.T) {
 					DeletedAt: nil,
 				},
 			},
-			wantErr: assert.NoError,
+			isForce:     true,
+			wantErr:     assert.NoError,
+			wantVersion: 2,
 			do: func() {
 				utils.RefreshTestDatabase()
 
@@ -306,23 +384,76 @@ func TestSecretPostgresStorage_EditSecret(t *testing.T) {
 					DeletedAt: nil,
 				},
 			},
+			isForce: true,
 			wantErr: assert.Error,
 			do: func() {
 				utils.RefreshTestDatabase()
 			},
 		},
+		{
+			name: "Secret can be edited when provided version matches stored one",
+			args: args{
+				ctx: ctx,
+				secret: model.Secret{
+					ID:      1,
+					UserID:  uid,
+					Title:   "Test new",
+					Content: []byte{1, 2},
+					Version: 1,
+				},
+			},
+			wantErr:     assert.NoError,
+			wantVersion: 2,
+			do: func() {
+				utils.RefreshTestDatabase()
+
+				row, _ := con.Query(
+					ctx, "insert into users (id, login, password) values ($1,$2,$3)", uid, "test", "test",
+				)
+				row.Close()
+
+				r2, _ := con.Query(
+					ctx, "insert into secrets (user_id, title, content, type_id) values ($1,$2,$3,$4)",
+					uid, "test", hex.EncodeToString([]byte{10, 20}), 1,
+				)
+				r2.Close()
+			},
+		},
+		{
+			name: "Version mismatch error with current version will be returned on outdated version",
+			args: args{
+				ctx: ctx,
+				secret: model.Secret{
+					ID:      1,
+					UserID:  uid,
+					Title:   "Test new",
+					Content: []byte{1, 2},
+					Version: 1,
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, apperr.ErrVersionDoesntMatch, i...)
+			},
+			wantVersion: 2,
+			do: func() {
+				s := &SecretPostgresStorage{pool: con}
+				s.EditSecret(ctx, model.Secret{ID: 1, UserID: uid, Content: []byte{1}}, true)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.do()
-			s := &SecretPostgresStorage{conn: con}
+			s := &SecretPostgresStorage{pool: con}
 
-			got, err := s.EditSecret(tt.args.ctx, tt.args.secret, true)
-			if !tt.wantErr(t, err, fmt.Sprintf("EditSecret(%v, %v)", tt.args.ctx, tt.args.secret)) {
+			got, err := s.EditSecret(tt.args.ctx, tt.args.secret, tt.isForce)
+			if tt.wantErr(t, err, fmt.Sprintf("EditSecret(%v, %v)", tt.args.ctx, tt.args.secret)) && err == nil {
 				assert.Equal(t, []byte{1, 2}, got.Content)
 				assert.Equal(t, "Test new", got.Title)
 				assert.NotEqual(t, time.Time{}, got.UpdatedAt)
 			}
+
+			assert.Equal(t, tt.wantVersion, got.Version)
 		})
 	}
 }
@@ -330,15 +461,16 @@ func TestSecretPostgresStorage_EditSecret(t *testing.T) {
 func TestSecretPostgresStorage_GetListOfSecretByType(t *testing.T) {
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	uid := uuid.New()
 
 	type args struct {
-		ctx        context.Context
-		secretType model.SecretType
-		user       model.User
+		ctx         context.Context
+		secretType  model.SecretType
+		user        model.User
+		withTrashed bool
 	}
 	tests := []struct {
 		name    string
@@ -375,6 +507,43 @@ func TestSecretPostgresStorage_GetListOfSecretByType(t *testing.T) {
 					uid, "test2", hex.EncodeToString([]byte{1, 1}), 1,
 				)
 				r3.Close()
+
+				r4, _ := con.Query(
+					ctx, "insert into secrets (user_id, title, content, type_id, deleted_at) values ($1,$2,$3,$4,$5)",
+					uid, "test3", hex.EncodeToString([]byte{1, 2}), 1, time.Now(),
+				)
+				r4.Close()
+			},
+		},
+		{
+			name: "List of secrets includes trashed secrets only if asked",
+			args: args{
+				ctx:         ctx,
+				secretType:  model.SecretType{ID: 1},
+				user:        model.User{ID: &uid},
+				withTrashed: true,
+			},
+			wantErr: assert.NoError,
+			wantLen: 2,
+			do: func() {
+				utils.RefreshTestDatabase()
+
+				row, _ := con.Query(
+					ctx, "insert into users (id, login, password) values ($1,$2,$3)", uid, "test", "test",
+				)
+				row.Close()
+
+				r2, _ := con.Query(
+					ctx, "insert into secrets (user_id, title, content, type_id) values ($1,$2,$3,$4)",
+					uid, "test", hex.EncodeToString([]byte{10, 20}), 1,
+				)
+				r2.Close()
+
+				r3, _ := con.Query(
+					ctx, "insert into secrets (user_id, title, content, type_id, deleted_at) values ($1,$2,$3,$4,$5)",
+					uid, "test2", hex.EncodeToString([]byte{1, 1}), 1, time.Now(),
+				)
+				r3.Close()
 			},
 		},
 		{
@@ -395,8 +564,8 @@ func TestSecretPostgresStorage_GetListOfSecretByType(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.do()
 
-			s := &SecretPostgresStorage{conn: con}
-			got, err := s.GetListOfSecretByType(tt.args.ctx, tt.args.secretType, tt.args.user)
+			s := &SecretPostgresStorage{pool: con}
+			got, err := s.GetListOfSecretByType(tt.args.ctx, tt.args.secretType, tt.args.user, tt.args.withTrashed)
 
 			tt.wantErr(t, err, fmt.Sprintf("GetListOfSecretByType(%v, %v, %v)", tt.args.ctx, tt.args.secretType, tt.args.user))
 
@@ -404,3 +573,217 @@ func TestSecretPostgresStorage_GetListOfSecretByType(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretPostgresStorage_SecretVersions(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
+
+	uid := uuid.New()
+	secret := model.Secret{ID: 1, UserID: uid}
+
+	tests := []struct {
+		name         string
+		keep         int
+		wantVersions []int64
+		wantTitle    string
+		do           func(s *SecretPostgresStorage)
+	}{
+		{
+			name:         "Secret without changes has only current version",
+			wantVersions: []int64{1},
+			wantTitle:    "v1",
+			do:           func(s *SecretPostgresStorage) {},
+		},
+		{
+			name:         "Edited secret keeps prior versions",
+			wantVersions: []int64{3, 2, 1},
+			wantTitle:    "v3",
+			do: func(s *SecretPostgresStorage) {
+				s.EditSecret(ctx, model.Secret{ID: 1, UserID: uid, TypeID: 1, Title: "v2", Content: []byte{2}}, true)
+				s.EditSecret(ctx, model.Secret{ID: 1, UserID: uid, TypeID: 1, Title: "v3", Content: []byte{3}}, true)
+			},
+		},
+		{
+			name:         "Restored version becomes a new version",
+			wantVersions: []int64{3, 2, 1},
+			wantTitle:    "v1",
+			do: func(s *SecretPostgresStorage) {
+				s.EditSecret(ctx, model.Secret{ID: 1, UserID: uid, TypeID: 1, Title: "v2", Content: []byte{2}}, true)
+				s.RestoreSecretVersion(ctx, secret, 1)
+			},
+		},
+		{
+			name:         "Versions out of retention are trimmed",
+			keep:         1,
+			wantVersions: []int64{3, 2},
+			wantTitle:    "v3",
+			do: func(s *SecretPostgresStorage) {
+				s.EditSecret(ctx, model.Secret{ID: 1, UserID: uid, TypeID: 1, Title: "v2", Content: []byte{2}}, true)
+				s.EditSecret(ctx, model.Secret{ID: 1, UserID: uid, TypeID: 1, Title: "v3", Content: []byte{3}}, true)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.RefreshTestDatabase()
+
+			row, _ := con.Query(
+				ctx, "insert into users (id, login, password) values ($1,$2,$3)", uid, "test", "test",
+			)
+			row.Close()
+
+			r2, _ := con.Query(
+				ctx, "insert into secrets (user_id, title, content, type_id) values ($1,$2,$3,$4)",
+				uid, "v1", hex.EncodeToString([]byte{1}), 1,
+			)
+			r2.Close()
+
+			s := &SecretPostgresStorage{pool: con}
+			tt.do(s)
+
+			if tt.keep > 0 {
+				assert.NoError(t, s.TrimSecretVersions(ctx, secret, tt.keep))
+			}
+
+			versions, err := s.ListSecretVersions(ctx, secret)
+			assert.NoError(t, err)
+
+			var got []int64
+			for _, v := range versions {
+				got = append(got, v.Version)
+			}
+			assert.Equal(t, tt.wantVersions, got)
+
+			current, err := s.GetSecretVersion(ctx, secret, tt.wantVersions[0])
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTitle, current.Title)
+
+			_, err = s.GetSecretVersion(ctx, secret, 100)
+			assert.ErrorIs(t, err, pgx.ErrNoRows)
+		})
+	}
+}
+
+func TestSecretPostgresStorage_Trash(t *testing.T) {
+	ctx := context.Background()
+
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
+
+	uid := uuid.New()
+	secret := model.Secret{ID: 1, UserID: uid}
+
+	tests := []struct {
+		name        string
+		do          func(s *SecretPostgresStorage) error
+		wantErr     assert.ErrorAssertionFunc
+		wantTrash   int
+		wantPresent bool
+	}{
+		{
+			name: "Deleted secret is moved to trash",
+			do: func(s *SecretPostgresStorage) error {
+				_, err := s.DeleteSecret(ctx, secret)
+				return err
+			},
+			wantErr:     assert.NoError,
+			wantTrash:   1,
+			wantPresent: true,
+		},
+		{
+			name: "Trashed secret can be restored",
+			do: func(s *SecretPostgresStorage) error {
+				s.DeleteSecret(ctx, secret)
+				_, err := s.RestoreSecret(ctx, secret)
+				return err
+			},
+			wantErr:     assert.NoError,
+			wantTrash:   0,
+			wantPresent: true,
+		},
+		{
+			name: "Secret out of trash can't be restored",
+			do: func(s *SecretPostgresStorage) error {
+				_, err := s.RestoreSecret(ctx, secret)
+				return err
+			},
+			wantErr:     assert.Error,
+			wantTrash:   0,
+			wantPresent: true,
+		},
+		{
+			name: "Trashed secret can be purged",
+			do: func(s *SecretPostgresStorage) error {
+				s.DeleteSecret(ctx, secret)
+				_, err := s.PurgeSecret(ctx, secret)
+				return err
+			},
+			wantErr:     assert.NoError,
+			wantTrash:   0,
+			wantPresent: false,
+		},
+		{
+			name: "Secret out of trash can't be purged",
+			do: func(s *SecretPostgresStorage) error {
+				_, err := s.PurgeSecret(ctx, secret)
+				return err
+			},
+			wantErr:     assert.Error,
+			wantTrash:   0,
+			wantPresent: true,
+		},
+		{
+			name: "Secrets trashed before provided time are purged",
+			do: func(s *SecretPostgresStorage) error {
+				s.DeleteSecret(ctx, secret)
+				purged, err := s.PurgeTrash(ctx, time.Now().Add(time.Minute))
+				assert.Equal(t, int64(1), purged)
+				return err
+			},
+			wantErr:     assert.NoError,
+			wantTrash:   0,
+			wantPresent: false,
+		},
+		{
+			name: "Secrets trashed after provided time are kept",
+			do: func(s *SecretPostgresStorage) error {
+				s.DeleteSecret(ctx, secret)
+				purged, err := s.PurgeTrash(ctx, time.Now().Add(-time.Minute))
+				assert.Equal(t, int64(0), purged)
+				return err
+			},
+			wantErr:     assert.NoError,
+			wantTrash:   1,
+			wantPresent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.RefreshTestDatabase()
+
+			row, _ := con.Query(
+				ctx, "insert into users (id, login, password) values ($1,$2,$3)", uid, "test", "test",
+			)
+			row.Close()
+
+			r2, _ := con.Query(
+				ctx, "insert into secrets (user_id, title, content, type_id) values ($1,$2,$3,$4)",
+				uid, "test", hex.EncodeToString([]byte{10, 20}), 1,
+			)
+			r2.Close()
+
+			s := &SecretPostgresStorage{pool: con}
+
+			tt.wantErr(t, tt.do(s))
+
+			trash, err := s.ListTrash(ctx, model.User{ID: &uid})
+			assert.NoError(t, err)
+			assert.Len(t, trash, tt.wantTrash)
+
+			_, err = s.GetSecret(ctx, secret, true)
+			assert.Equal(t, tt.wantPresent, err == nil)
+		})
+	}
+}
@@ -2,9 +2,10 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/sergalkin/gophkeeper/internal/server/model"
 	"github.com/sergalkin/gophkeeper/internal/server/storage"
@@ -13,33 +14,38 @@ import (
 var _ storage.SecretTypeServerStorage = (*SecretTypePostgresStorage)(nil)
 
 type SecretTypePostgresStorage struct {
-	conn *pgx.Conn
+	pool *pgxpool.Pool
 }
 
 const (
-	GetSecretTypeList = `SELECT id, title FROM secret_types`
+	GetSecretTypeList = `SELECT id, title, fields FROM secret_types ORDER BY id`
 )
 
 // NewPostgresSecretTypeStorage - creates a postgres storage for secret types.
-func NewPostgresSecretTypeStorage(c *pgx.Conn) *SecretTypePostgresStorage {
-	return &SecretTypePostgresStorage{conn: c}
+func NewPostgresSecretTypeStorage(p *pgxpool.Pool) *SecretTypePostgresStorage {
+	return &SecretTypePostgresStorage{pool: p}
 }
 
 // GetSecretTypes - returns list of all available secret types.
 func (s *SecretTypePostgresStorage) GetSecretTypes(ctx context.Context) ([]model.SecretType, error) {
 	var list []model.SecretType
 
-	rows, err := s.conn.Query(ctx, GetSecretTypeList)
+	rows, err := conn(ctx, s.pool).Query(ctx, GetSecretTypeList)
 	if err != nil {
 		return list, fmt.Errorf("error in getting secret types list: %w", err)
 	}
 
 	for rows.Next() {
 		m := model.SecretType{}
-		if err = rows.Scan(&m.ID, &m.Title); err != nil {
+		var fields []byte
+		if err = rows.Scan(&m.ID, &m.Title, &fields); err != nil {
 			return list, fmt.Errorf("error scanning secret types: %w", err)
 		}
 
+		if err = json.Unmarshal(fields, &m.Fields); err != nil {
+			return list, fmt.Errorf("error decoding fields of secret type %d: %w", m.ID, err)
+		}
+
 		list = append(list, m)
 	}
 
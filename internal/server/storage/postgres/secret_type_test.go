@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/sergalkin/gophkeeper/pkg/utils"
@@ -17,12 +17,12 @@ func TestNewPostgresSecretTypeStorage(t *testing.T) {
 	}{
 		{
 			name: "New Postgres Secret Type Storage could be created",
-			want: &SecretTypePostgresStorage{&pgx.Conn{}},
+			want: &SecretTypePostgresStorage{&pgxpool.Pool{}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewPostgresSecretTypeStorage(&pgx.Conn{})
+			got := NewPostgresSecretTypeStorage(&pgxpool.Pool{})
 			assert.Equalf(t, tt.want, got, "NewPostgresSecretTypeStorage() = %v, want %v", got, tt.want)
 		})
 	}
@@ -31,8 +31,8 @@ func TestNewPostgresSecretTypeStorage(t *testing.T) {
 func Test_secretTypePostgresStorage_GetSecretTypes(t *testing.T) {
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	tests := []struct {
 		name  string
@@ -60,7 +60,7 @@ func Test_secretTypePostgresStorage_GetSecretTypes(t *testing.T) {
 			tt.do()
 
 			s := &SecretTypePostgresStorage{
-				conn: con,
+				pool: con,
 			}
 
 			got, err := s.GetSecretTypes(ctx)
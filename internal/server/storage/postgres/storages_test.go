@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/sergalkin/gophkeeper/internal/server/storage/storagetest"
+	"github.com/sergalkin/gophkeeper/pkg/utils"
+)
+
+// newTestStorages - creates storages backed by refreshed testing database.
+func newTestStorages(t *testing.T) storagetest.Storages {
+	utils.RefreshTestDatabase()
+
+	pool := utils.CreatePostgresTestPool()
+	t.Cleanup(pool.Close)
+
+	return storagetest.Storages{
+		Users:       NewPostgresUserStorage(pool),
+		SecretTypes: NewPostgresSecretTypeStorage(pool),
+		Secrets:     NewSecretPostgresStorage(pool),
+		Folders:     NewFolderPostgresStorage(pool),
+		Audit:       NewAuditPostgresStorage(pool),
+		Transactor:  NewTransactor(pool),
+	}
+}
+
+func TestPostgresStorages(t *testing.T) {
+	storagetest.Run(t, newTestStorages)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/sergalkin/gophkeeper/internal/server/model"
 	"github.com/sergalkin/gophkeeper/internal/server/storage"
@@ -18,18 +19,54 @@ import (
 var _ storage.UserServerStorage = (*UserPostgresStorage)(nil)
 
 type UserPostgresStorage struct {
-	conn *pgx.Conn
+	pool *pgxpool.Pool
 }
 
 const (
-	CreateUser     = `INSERT INTO users (login, password) VALUES ($1, crypt($2, gen_salt('bf'))) returning id`
-	GetUserId      = `SELECT id FROM users WHERE login = $1 AND password = crypt($2, password)`
-	DeleteUserById = `DELETE from users where id = $1 returning login`
+	CreateUser = `INSERT INTO users (login, password, vault_key) VALUES ($1, crypt($2, gen_salt('bf')), $3)
+				  returning id, role`
+	GetUserId = `SELECT id, role, disabled_at, vault_key FROM users
+				 WHERE login = $1 AND password = crypt($2, password)`
+	DeleteUserById      = `DELETE from users where id = $1 returning login`
+	DeleteSecretsByUser = `DELETE from secrets where user_id = $1`
+	DeleteFoldersByUser = `DELETE from folders where user_id = $1`
+	DeleteTagsByUser    = `DELETE from tags where user_id = $1`
+	GetUser             = `SELECT id, login, role, created_at, disabled_at, tokens_revoked_at FROM users
+						   WHERE ($1::uuid is not null and id = $1) or ($1::uuid is null and login = $2)`
+	ListUsers = `SELECT u.id, u.login, u.role, u.created_at, u.disabled_at, u.tokens_revoked_at,
+					    count(s.id) filter (where s.deleted_at is null),
+					    count(s.id) filter (where s.deleted_at is not null),
+					    coalesce(sum(octet_length(s.content)), 0) +
+					    coalesce((SELECT sum(octet_length(v.content)) FROM secret_versions v
+								  JOIN secrets vs on vs.id = v.secret_id WHERE vs.user_id = u.id), 0)
+				 FROM users u
+						  LEFT JOIN secrets s on s.user_id = u.id
+				 GROUP BY u.id
+				 ORDER BY u.login`
+	DisableUser = `UPDATE users SET disabled_at = coalesce(disabled_at, now()), tokens_revoked_at = now() WHERE id = $1
+				   returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	EnableUser = `UPDATE users SET disabled_at = null WHERE id = $1
+				  returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	SetUserRole = `UPDATE users SET role = $2, tokens_revoked_at = now() WHERE id = $1
+				   returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	ResetPassword = `UPDATE users SET password = crypt($2, gen_salt('bf')), tokens_revoked_at = now(), vault_key = null
+					 WHERE id = $1
+					 returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	SetVaultKey    = `UPDATE users SET vault_key = coalesce(vault_key, $2) WHERE id = $1 returning vault_key`
+	ChangePassword = `UPDATE users SET password = crypt($2, gen_salt('bf')), vault_key = $3 WHERE id = $1
+					  returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	GetUsersStats = `SELECT (SELECT count(*) FROM users),
+						    (SELECT count(*) FROM users WHERE disabled_at is not null),
+						    (SELECT count(*) FROM secrets WHERE deleted_at is null),
+						    (SELECT count(*) FROM secrets WHERE deleted_at is not null),
+						    (SELECT count(*) FROM secret_versions),
+						    (SELECT coalesce(sum(octet_length(content)), 0) FROM secrets) +
+						    (SELECT coalesce(sum(octet_length(content)), 0) FROM secret_versions)`
 )
 
 // NewPostgresUserStorage - Creates UserPostgresStorage instance.
-func NewPostgresUserStorage(c *pgx.Conn) *UserPostgresStorage {
-	return &UserPostgresStorage{conn: c}
+func NewPostgresUserStorage(p *pgxpool.Pool) *UserPostgresStorage {
+	return &UserPostgresStorage{pool: p}
 }
 
 // Create - creates a user record in DB with data provided from model.User, then returns model.User populated with
@@ -38,7 +75,8 @@ func (u UserPostgresStorage) Create(ctx context.Context, user model.User) (model
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	err := u.conn.QueryRow(ctxWithTimeOut, CreateUser, user.Login, user.Password).Scan(&user.ID)
+	err := conn(ctx, u.pool).QueryRow(ctxWithTimeOut, CreateUser, user.Login, user.Password, user.VaultKey).
+		Scan(&user.ID, &user.Role)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			if pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
@@ -58,7 +96,8 @@ func (u UserPostgresStorage) GetByLoginAndPassword(ctx context.Context, user mod
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	err := u.conn.QueryRow(ctxWithTimeOut, GetUserId, user.Login, user.Password).Scan(&user.ID)
+	err := conn(ctx, u.pool).QueryRow(ctxWithTimeOut, GetUserId, user.Login, user.Password).
+		Scan(&user.ID, &user.Role, &user.DisabledAt, &user.VaultKey)
 	if err != nil {
 		return user, fmt.Errorf("user login err: %w", err)
 	}
@@ -66,20 +105,197 @@ func (u UserPostgresStorage) GetByLoginAndPassword(ctx context.Context, user mod
 	return user, nil
 }
 
-// DeleteUser - deletes a user from DB, then sets ID to nil to model.User.
+// DeleteUser - deletes a user and its secrets from DB in one transaction, then sets ID to nil to model.User.
 func (u UserPostgresStorage) DeleteUser(ctx context.Context, user model.User) (model.User, error) {
+	err := withinTransaction(ctx, u.pool, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		if _, err := conn(ctx, u.pool).Exec(ctxWithTimeOut, DeleteSecretsByUser, user.ID); err != nil {
+			return fmt.Errorf("user secrets deletion err: %w", err)
+		}
+
+		var deletedLogin *string
+		err := conn(ctx, u.pool).QueryRow(ctxWithTimeOut, DeleteUserById, user.ID).Scan(&deletedLogin)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+
+			return fmt.Errorf("user deletion err: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return model.User{}, nil
+}
+
+// GetUser - searches DB for a user by ID from provided model.User, or by its login if ID is nil.
+func (u UserPostgresStorage) GetUser(ctx context.Context, user model.User) (model.User, error) {
 	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	var deletedLogin *string
-	err := u.conn.QueryRow(ctxWithTimeOut, DeleteUserById, user.ID).Scan(&deletedLogin)
+	found, err := scanUser(conn(ctx, u.pool).QueryRow(ctxWithTimeOut, GetUser, user.ID, user.Login))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return user, err
 		}
 
-		return user, fmt.Errorf("user deletion err: %w", err)
+		return user, fmt.Errorf("user get err: %w", err)
 	}
 
-	return model.User{}, nil
+	return found, nil
+}
+
+// ListUsers - returns all users from DB with number and size of their secrets, ordered by login.
+func (u UserPostgresStorage) ListUsers(ctx context.Context) ([]model.UserUsage, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, u.pool).Query(ctxWithTimeOut, ListUsers)
+	if err != nil {
+		return nil, fmt.Errorf("users list err: %w", err)
+	}
+	defer rows.Close()
+
+	var users []model.UserUsage
+	for rows.Next() {
+		var usage model.UserUsage
+		if err = rows.Scan(
+			&usage.User.ID, &usage.User.Login, &usage.User.Role, &usage.User.CreatedAt, &usage.User.DisabledAt,
+			&usage.User.TokensRevokedAt, &usage.Secrets, &usage.TrashedSecrets, &usage.Size,
+		); err != nil {
+			return nil, fmt.Errorf("users list err: %w", err)
+		}
+
+		users = append(users, usage)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("users list err: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetUserDisabled - disables or enables a user by ID from provided model.User in DB, then returns updated model.User.
+//
+// Disabling moves revocation time of user tokens to now, so tokens issued before it stay invalid after the user is
+// enabled again.
+func (u UserPostgresStorage) SetUserDisabled(ctx context.Context, user model.User, disabled bool) (model.User, error) {
+	query := EnableUser
+	if disabled {
+		query = DisableUser
+	}
+
+	return u.update(ctx, query, user.ID)
+}
+
+// SetUserRole - changes role of a user by ID from provided model.User in DB, then returns updated model.User.
+//
+// Tokens carry role of the user, so tokens issued before the change are revoked.
+func (u UserPostgresStorage) SetUserRole(ctx context.Context, user model.User, role string) (model.User, error) {
+	return u.update(ctx, SetUserRole, user.ID, role)
+}
+
+// ResetPassword - replaces password of a user by ID from provided model.User in DB, revokes its tokens and deletes
+// its vault in one transaction, then returns updated model.User.
+//
+// Data of the user can't be decrypted without key of the vault, which is wrapped by the previous password, so its
+// secrets, folders and tags are deleted along with wrapped key, and the user starts with an empty vault.
+func (u UserPostgresStorage) ResetPassword(ctx context.Context, user model.User, password string) (model.User, error) {
+	var updated model.User
+
+	err := withinTransaction(ctx, u.pool, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		for _, query := range []string{DeleteSecretsByUser, DeleteFoldersByUser, DeleteTagsByUser} {
+			if _, err := conn(ctx, u.pool).Exec(ctxWithTimeOut, query, user.ID); err != nil {
+				return fmt.Errorf("user vault deletion err: %w", err)
+			}
+		}
+
+		var err error
+		updated, err = u.update(ctx, ResetPassword, user.ID, password)
+
+		return err
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return updated, nil
+}
+
+// SetVaultKey - sets wrapped key of a vault of a user by ID from provided model.User in DB, if it isn't set yet, then
+// returns stored key, so concurrent clients of a user registered before vault keys end up with the same key.
+func (u UserPostgresStorage) SetVaultKey(ctx context.Context, user model.User, vaultKey []byte) ([]byte, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var stored []byte
+	err := conn(ctx, u.pool).QueryRow(ctxWithTimeOut, SetVaultKey, user.ID, vaultKey).Scan(&stored)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("user vault key err: %w", err)
+	}
+
+	return stored, nil
+}
+
+// ChangePassword - replaces password and wrapped key of a vault of a user by ID from provided model.User in DB, then
+// returns updated model.User.
+func (u UserPostgresStorage) ChangePassword(
+	ctx context.Context, user model.User, password string, vaultKey []byte,
+) (model.User, error) {
+	return u.update(ctx, ChangePassword, user.ID, password, vaultKey)
+}
+
+// update - runs query, which updates a user and returns its columns, then returns updated model.User.
+func (u UserPostgresStorage) update(ctx context.Context, query string, args ...interface{}) (model.User, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	updated, err := scanUser(conn(ctx, u.pool).QueryRow(ctxWithTimeOut, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return updated, err
+		}
+
+		return updated, fmt.Errorf("user update err: %w", err)
+	}
+
+	return updated, nil
+}
+
+// scanUser - scans model.User from row with id, login, role, created_at, disabled_at and tokens_revoked_at columns.
+func scanUser(row pgx.Row) (model.User, error) {
+	var user model.User
+	err := row.Scan(&user.ID, &user.Login, &user.Role, &user.CreatedAt, &user.DisabledAt, &user.TokensRevokedAt)
+
+	return user, err
+}
+
+// GetUsersStats - returns number of users and number and size of secrets stored in DB.
+func (u UserPostgresStorage) GetUsersStats(ctx context.Context) (model.UsersStats, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var stats model.UsersStats
+	err := conn(ctx, u.pool).QueryRow(ctxWithTimeOut, GetUsersStats).Scan(
+		&stats.Users, &stats.DisabledUsers, &stats.Secrets, &stats.TrashedSecrets, &stats.SecretVersions, &stats.Size,
+	)
+	if err != nil {
+		return stats, fmt.Errorf("users stats err: %w", err)
+	}
+
+	return stats, nil
 }
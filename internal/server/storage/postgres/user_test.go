@@ -6,7 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/sergalkin/gophkeeper/internal/server/model"
@@ -20,12 +20,12 @@ func TestNewPostgresUserStorage(t *testing.T) {
 	}{
 		{
 			name: "New Postgres User Storage can be created",
-			want: &UserPostgresStorage{conn: &pgx.Conn{}},
+			want: &UserPostgresStorage{pool: &pgxpool.Pool{}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, NewPostgresUserStorage(&pgx.Conn{}), "NewPostgresUserStorage()")
+			assert.Equalf(t, tt.want, NewPostgresUserStorage(&pgxpool.Pool{}), "NewPostgresUserStorage()")
 		})
 	}
 }
@@ -35,8 +35,8 @@ func TestUserPostgresStorage_Create(t *testing.T) {
 
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	type args struct {
 		ctx  context.Context
@@ -44,7 +44,7 @@ func TestUserPostgresStorage_Create(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		con     *pgx.Conn
+		con     *pgxpool.Pool
 		args    args
 		wantErr bool
 		do      func(user model.User, storage UserPostgresStorage)
@@ -75,7 +75,7 @@ func TestUserPostgresStorage_Create(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			u := UserPostgresStorage{conn: tt.con}
+			u := UserPostgresStorage{pool: tt.con}
 			tt.do(tt.args.user, u)
 
 			got, err := u.Create(tt.args.ctx, tt.args.user)
@@ -94,8 +94,8 @@ func TestUserPostgresStorage_GetByLoginAndPassword(t *testing.T) {
 
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	type args struct {
 		ctx  context.Context
@@ -103,7 +103,7 @@ func TestUserPostgresStorage_GetByLoginAndPassword(t *testing.T) {
 	}
 	tests := []struct {
 		name      string
-		con       *pgx.Conn
+		con       *pgxpool.Pool
 		args      args
 		want      model.User
 		wantEmpty bool
@@ -137,7 +137,7 @@ func TestUserPostgresStorage_GetByLoginAndPassword(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			u := UserPostgresStorage{conn: tt.con}
+			u := UserPostgresStorage{pool: tt.con}
 			tt.do(tt.args.user, u)
 
 			got, err := u.GetByLoginAndPassword(tt.args.ctx, tt.args.user)
@@ -157,12 +157,12 @@ func TestUserPostgresStorage_DeleteUser(t *testing.T) {
 
 	ctx := context.Background()
 
-	con := utils.CreatePostgresTestConn()
-	defer con.Close(ctx)
+	con := utils.CreatePostgresTestPool()
+	defer con.Close()
 
 	tests := []struct {
 		name      string
-		con       *pgx.Conn
+		con       *pgxpool.Pool
 		want      model.User
 		errAssert assert.ErrorAssertionFunc
 		do        func(ctx context.Context, user model.User) model.User
@@ -180,6 +180,25 @@ func TestUserPostgresStorage_DeleteUser(t *testing.T) {
 			},
 			errAssert: assert.NoError,
 		},
+		{
+			name: "User can be deleted along with its secrets",
+			con:  con,
+			want: model.User{},
+			do: func(ctx context.Context, user model.User) model.User {
+				con.QueryRow(
+					ctx, "insert into users (login, password) values ('test2', 'test') returning id",
+				).Scan(&user.ID)
+
+				r, _ := con.Query(
+					ctx, "insert into secrets (user_id, title, content, type_id) values ($1,$2,$3,$4)",
+					user.ID, "test", "0a14", 1,
+				)
+				r.Close()
+
+				return user
+			},
+			errAssert: assert.NoError,
+		},
 		{
 			name: "User deletion will return no rows error if non existing user_id is provided",
 			con:  con,
@@ -196,7 +215,7 @@ func TestUserPostgresStorage_DeleteUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			user := model.User{Login: "test", Password: "test"}
-			u := UserPostgresStorage{conn: con}
+			u := UserPostgresStorage{pool: con}
 
 			user = tt.do(ctx, user)
 
@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.AuditServerStorage = (*AuditSQLiteStorage)(nil)
+
+// AuditSQLiteStorage - is an append-only storage of audit events, changes of stored events are rejected by triggers.
+type AuditSQLiteStorage struct {
+	db *sql.DB
+}
+
+const (
+	GetLastAuditHash = `select hash from audit_events order by id desc limit 1`
+	CreateAuditEvent = `insert into audit_events (user_id, event, success, target, ip, user_agent, request_id, created_at,
+						prev_hash, hash)
+						values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+						returning id`
+	ListAuditEvents = `select id, user_id, event, success, target, ip, user_agent, request_id, created_at, prev_hash, hash
+					   from audit_events
+					   where ($1 is null or user_id = $1) and ($2 = 0 or id < $2)
+					   order by id desc
+					   limit $3`
+	ListAuditChain = `select id, user_id, event, success, target, ip, user_agent, request_id, created_at, prev_hash, hash
+					  from audit_events
+					  where id > $1
+					  order by id
+					  limit $2`
+	CreateAuditCheckpoint = `insert into audit_checkpoints (event_id, hash, signature, created_at)
+							 values ($1, $2, $3, $4)
+							 returning id`
+	GetLastAuditCheckpoint = `select id, event_id, hash, signature, created_at
+							  from audit_checkpoints
+							  order by id desc
+							  limit 1`
+	ListAuditCheckpoints = `select id, event_id, hash, signature, created_at from audit_checkpoints order by id`
+)
+
+// NewAuditSQLiteStorage - creates AuditSQLiteStorage instance.
+func NewAuditSQLiteStorage(db *sql.DB) *AuditSQLiteStorage {
+	return &AuditSQLiteStorage{db: db}
+}
+
+// CreateAuditEvent - inserts provided model.AuditEvent into DB chaining it to the last stored event, then returns it
+// populated with id and hashes.
+//
+// Transaction takes write lock on begin, so concurrent events are chained one after another in order of their ids.
+func (a *AuditSQLiteStorage) CreateAuditEvent(ctx context.Context, event model.AuditEvent) (model.AuditEvent, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	event.CreatedAt = event.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	err := withinTransaction(ctxWithTimeOut, a.db, func(ctx context.Context) error {
+		err := conn(ctx, a.db).QueryRowContext(ctx, GetLastAuditHash).Scan(&event.PrevHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		event.Hash = event.ChainHash(event.PrevHash)
+
+		return conn(ctx, a.db).QueryRowContext(ctx, CreateAuditEvent,
+			event.UserID, event.Event, event.Success, event.Target, event.IP, event.UserAgent, event.RequestID,
+			event.CreatedAt, event.PrevHash, event.Hash,
+		).Scan(&event.ID)
+	})
+	if err != nil {
+		return event, fmt.Errorf("audit event insertion err: %w", err)
+	}
+
+	return event, nil
+}
+
+// ListAuditEvents - returns audit events selected by model.AuditEventFilter from DB, latest go first.
+func (a *AuditSQLiteStorage) ListAuditEvents(
+	ctx context.Context, filter model.AuditEventFilter,
+) ([]model.AuditEvent, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, a.db).QueryContext(
+		ctxWithTimeOut, ListAuditEvents, filter.UserID, filter.BeforeID, filter.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("audit events list err: %w", err)
+	}
+
+	return scanAuditEvents(rows)
+}
+
+// ListAuditChain - returns up to limit audit events with id greater than afterID from DB in order of the chain.
+func (a *AuditSQLiteStorage) ListAuditChain(ctx context.Context, afterID int64, limit int) ([]model.AuditEvent, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, a.db).QueryContext(ctxWithTimeOut, ListAuditChain, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("audit chain list err: %w", err)
+	}
+
+	return scanAuditEvents(rows)
+}
+
+// CreateAuditCheckpoint - inserts provided model.AuditCheckpoint into DB, then returns it populated with id.
+func (a *AuditSQLiteStorage) CreateAuditCheckpoint(
+	ctx context.Context, checkpoint model.AuditCheckpoint,
+) (model.AuditCheckpoint, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := conn(ctx, a.db).QueryRowContext(ctxWithTimeOut, CreateAuditCheckpoint,
+		checkpoint.EventID, checkpoint.Hash, checkpoint.Signature, checkpoint.CreatedAt.UTC(),
+	).Scan(&checkpoint.ID)
+	if err != nil {
+		return checkpoint, fmt.Errorf("audit checkpoint insertion err: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// GetLastAuditCheckpoint - returns the latest audit checkpoint from DB, pgx.ErrNoRows is returned if there is none.
+func (a *AuditSQLiteStorage) GetLastAuditCheckpoint(ctx context.Context) (model.AuditCheckpoint, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var checkpoint model.AuditCheckpoint
+	err := conn(ctx, a.db).QueryRowContext(ctxWithTimeOut, GetLastAuditCheckpoint).Scan(
+		&checkpoint.ID, &checkpoint.EventID, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt,
+	)
+	if err != nil {
+		return checkpoint, fmt.Errorf("audit checkpoint get err: %w", noRows(err))
+	}
+
+	return checkpoint, nil
+}
+
+// ListAuditCheckpoints - returns all audit checkpoints from DB in order of creation.
+func (a *AuditSQLiteStorage) ListAuditCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, a.db).QueryContext(ctxWithTimeOut, ListAuditCheckpoints)
+	if err != nil {
+		return nil, fmt.Errorf("audit checkpoints list err: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []model.AuditCheckpoint
+	for rows.Next() {
+		var checkpoint model.AuditCheckpoint
+		if err = rows.Scan(
+			&checkpoint.ID, &checkpoint.EventID, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("audit checkpoints list err: %w", err)
+		}
+
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("audit checkpoints list err: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+// scanAuditEvents - scans audit events from rows and closes them.
+func scanAuditEvents(rows *sql.Rows) ([]model.AuditEvent, error) {
+	defer rows.Close()
+
+	var events []model.AuditEvent
+	for rows.Next() {
+		var event model.AuditEvent
+		if err := rows.Scan(
+			&event.ID, &event.UserID, &event.Event, &event.Success, &event.Target, &event.IP, &event.UserAgent,
+			&event.RequestID, &event.CreatedAt, &event.PrevHash, &event.Hash,
+		); err != nil {
+			return nil, fmt.Errorf("audit events list err: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("audit events list err: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.IdempotencyServerStorage = (*IdempotencySQLiteStorage)(nil)
+
+type IdempotencySQLiteStorage struct {
+	db *sql.DB
+}
+
+const (
+	DeleteExpiredIdempotencyKey = `delete from idempotency_keys
+								   where key = $1 and user_id = $2 and method = $3 and created_at < $4
+`
+	ReserveIdempotencyKey = `insert into idempotency_keys (key, user_id, method, request_hash, created_at)
+							 values ($1,$2,$3,$4,$5)
+							 on conflict do nothing
+							 returning created_at
+`
+	GetIdempotencyKey = `select request_hash, response, created_at
+						 from idempotency_keys
+						 where key = $1 and user_id = $2 and method = $3
+`
+	SaveIdempotencyResponse = `update idempotency_keys
+							   set response = $1
+							   where key = $2 and user_id = $3 and method = $4
+`
+	DeleteIdempotencyKey = `delete from idempotency_keys where key = $1 and user_id = $2 and method = $3`
+	PurgeIdempotencyKeys = `delete from idempotency_keys where created_at < $1`
+)
+
+// NewIdempotencySQLiteStorage - creates a sqlite storage for idempotency keys.
+func NewIdempotencySQLiteStorage(db *sql.DB) *IdempotencySQLiteStorage {
+	return &IdempotencySQLiteStorage{db: db}
+}
+
+// ReserveKey - stores provided model.IdempotencyKey without response in database.
+//
+// Expired record with the same key is removed before reservation. If record with the same key already exists, then
+// returns stored model.IdempotencyKey and false as representation of already reserved key.
+func (i *IdempotencySQLiteStorage) ReserveKey(
+	ctx context.Context, key model.IdempotencyKey, ttl time.Duration,
+) (model.IdempotencyKey, bool, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	now := time.Now().UTC()
+
+	_, err := conn(ctx, i.db).ExecContext(ctxWithTimeOut, DeleteExpiredIdempotencyKey, key.Key, key.UserID, key.Method,
+		now.Add(-ttl),
+	)
+	if err != nil {
+		return key, false, fmt.Errorf("expired idempotency key deletion err: %w", err)
+	}
+
+	err = conn(ctx, i.db).QueryRowContext(ctxWithTimeOut, ReserveIdempotencyKey, key.Key, key.UserID, key.Method,
+		blob(key.RequestHash), now,
+	).Scan(&key.CreatedAt)
+	if err == nil {
+		return key, true, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return key, false, fmt.Errorf("idempotency key reservation err: %w", err)
+	}
+
+	err = conn(ctx, i.db).QueryRowContext(ctxWithTimeOut, GetIdempotencyKey, key.Key, key.UserID, key.Method).
+		Scan(&key.RequestHash, &key.Response, &key.CreatedAt)
+	if err != nil {
+		return key, false, fmt.Errorf("error in getting idempotency key from db: %w", err)
+	}
+
+	return key, false, nil
+}
+
+// SaveResponse - stores response of reserved model.IdempotencyKey in database.
+func (i *IdempotencySQLiteStorage) SaveResponse(ctx context.Context, key model.IdempotencyKey) error {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := conn(ctx, i.db).ExecContext(ctxWithTimeOut, SaveIdempotencyResponse, blob(key.Response), key.Key,
+		key.UserID, key.Method,
+	)
+	if err != nil {
+		return fmt.Errorf("idempotency response saving err: %w", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReleaseKey - deletes model.IdempotencyKey from database, so request with the same key could be executed again.
+func (i *IdempotencySQLiteStorage) ReleaseKey(ctx context.Context, key model.IdempotencyKey) error {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	_, err := conn(ctx, i.db).ExecContext(ctxWithTimeOut, DeleteIdempotencyKey, key.Key, key.UserID, key.Method)
+	if err != nil {
+		return fmt.Errorf("idempotency key deletion err: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeKeys - removes records of keys created before provided time from database.
+//
+// Returns number of removed keys.
+func (i *IdempotencySQLiteStorage) PurgeKeys(ctx context.Context, before time.Time) (int64, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := conn(ctx, i.db).ExecContext(ctxWithTimeOut, PurgeIdempotencyKeys, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("idempotency keys purging err: %w", err)
+	}
+
+	return res.RowsAffected()
+}
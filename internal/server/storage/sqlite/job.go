@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.JobServerStorage = (*JobSQLiteStorage)(nil)
+
+// JobSQLiteStorage - is a storage of job runs.
+//
+// SQLite database belongs to a single server, so there is no leadership to take and every lock is granted.
+type JobSQLiteStorage struct {
+	db *sql.DB
+}
+
+const (
+	CreateJobRun = `insert into job_runs (name, instance, status, started_at)
+					values ($1,$2,$3,$4)
+					returning id
+`
+	FinishJobRun = `update job_runs set status = $1, message = $2, finished_at = $3 where id = $4`
+	ListJobRuns  = `select id, name, instance, status, message, started_at, finished_at
+					from job_runs
+					where ($1 = '' or name = $1)
+					order by started_at desc
+					limit $2
+`
+)
+
+// NewJobSQLiteStorage - creates a sqlite storage for job runs.
+func NewJobSQLiteStorage(db *sql.DB) *JobSQLiteStorage {
+	return &JobSQLiteStorage{db: db}
+}
+
+// TryLock - always takes the lock, because only one server works with SQLite database.
+func (j *JobSQLiteStorage) TryLock(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+
+// Ping - checks that database is reachable.
+func (j *JobSQLiteStorage) Ping(ctx context.Context) error {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	if err := j.db.PingContext(ctxWithTimeOut); err != nil {
+		return fmt.Errorf("job db err: %w", err)
+	}
+
+	return nil
+}
+
+// CreateJobRun - stores provided model.JobRun in database.
+func (j *JobSQLiteStorage) CreateJobRun(ctx context.Context, run model.JobRun) (model.JobRun, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := conn(ctx, j.db).QueryRowContext(ctxWithTimeOut, CreateJobRun, run.Name, run.Instance, run.Status,
+		run.StartedAt.UTC(),
+	).Scan(&run.ID)
+	if err != nil {
+		return run, fmt.Errorf("job run storing err: %w", err)
+	}
+
+	return run, nil
+}
+
+// FinishJobRun - updates status, message and finished_at of provided model.JobRun in database.
+func (j *JobSQLiteStorage) FinishJobRun(ctx context.Context, run model.JobRun) error {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var finishedAt *time.Time
+	if run.FinishedAt != nil {
+		utc := run.FinishedAt.UTC()
+		finishedAt = &utc
+	}
+
+	_, err := conn(ctx, j.db).ExecContext(ctxWithTimeOut, FinishJobRun, run.Status, run.Message, finishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("job run updating err: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobRuns - returns a []model.JobRun from database, latest started go first.
+func (j *JobSQLiteStorage) ListJobRuns(ctx context.Context, name string, limit int) ([]model.JobRun, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var runs []model.JobRun
+
+	rows, err := conn(ctx, j.db).QueryContext(ctxWithTimeOut, ListJobRuns, name, limit)
+	if err != nil {
+		return runs, fmt.Errorf("getting list of job runs error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run model.JobRun
+
+		if scanErr := rows.Scan(
+			&run.ID, &run.Name, &run.Instance, &run.Status, &run.Message, &run.StartedAt, &run.FinishedAt,
+		); scanErr != nil {
+			return runs, fmt.Errorf("error in scanning gotten row: %w", scanErr)
+		}
+
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
@@ -0,0 +1,708 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/pkg/apperr"
+)
+
+var _ storage.SecretServerStorage = (*SecretSQLiteStorage)(nil)
+
+type SecretSQLiteStorage struct {
+	db *sql.DB
+}
+
+const (
+	CreateSecrete = `
+					insert into secrets (public_id, user_id, type_id, title, content, created_at, updated_at, updated_by,
+						title_hash)
+					values ($1,$2,$3,$4,$5,$6,$7,$2,$8)
+					on conflict (public_id) do nothing
+					returning id, version
+`
+	GetSecret = `select id, public_id, user_id, type_id, title, content, created_at, updated_at, deleted_at, version,
+					folder_id, (select group_concat(tag_id) from (
+						select tag_id from secret_tags where secret_id = secrets.id order by tag_id)), title_hash
+				 from secrets
+				 where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+				   and user_id = $3 and ($4 or deleted_at is null)
+`
+	DeleteSecret = `update secrets
+					set deleted_at = $4
+					where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+					  and user_id = $3 and deleted_at is null
+					returning id
+`
+	UpdateSecret = `update secrets
+					set title = coalesce($1, title), content = coalesce($2, content), updated_at = $3, updated_by = $5,
+						version = version + 1, title_hash = case when $1 is null then title_hash else $6 end
+					where id = $4 and user_id = $5
+					returning id, public_id, type_id, title, created_at, updated_at, deleted_at, version, folder_id,
+						(select group_concat(tag_id) from (
+							select tag_id from secret_tags where secret_id = secrets.id order by tag_id))
+`
+	GetSecretVersionForUpdate = `select id, public_id, updated_at, version
+								 from secrets
+								 where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+								   and user_id = $3 and deleted_at is null
+`
+	SecretVersions = `select v.secret_id, s.public_id, v.version, v.type_id, v.title, v.content, v.changed_by,
+       					  v.changed_at
+					  from secret_versions v
+					  join secrets s on s.id = v.secret_id
+					  where (s.public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and s.id = $1)
+					    and s.user_id = $3 and s.deleted_at is null
+					  union all
+					  select id, public_id, version, type_id, title, content, coalesce(updated_by, user_id), updated_at
+					  from secrets
+					  where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+					    and user_id = $3 and deleted_at is null
+`
+	GetPriorSecretVersion = `select v.type_id, v.title, v.title_hash, v.content
+							 from secret_versions v
+							 join secrets s on s.id = v.secret_id
+							 where (s.public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and s.id = $1)
+							   and s.user_id = $3 and s.deleted_at is null
+							   and v.version = $4
+`
+	RestoreSecretVersion = `update secrets
+							set type_id = $5, title = $6, content = $7, updated_at = $4, updated_by = $3,
+								version = version + 1, title_hash = $8
+							where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+							  and user_id = $3 and deleted_at is null
+							returning id, public_id, type_id, title, created_at, updated_at, deleted_at, version
+`
+	TrimSecretVersions = `delete from secret_versions
+						  where secret_id in (
+							  select id from secrets
+							  where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+								and user_id = $3
+						  )
+						  and version < (select version from secrets where id = secret_id) - $4
+`
+	SecretsByType = `select id, public_id, user_id, type_id, title, content, created_at, updated_at, deleted_at, version,
+						folder_id, (select group_concat(tag_id) from (
+							select tag_id from secret_tags where secret_id = secrets.id order by tag_id)), title_hash
+					 from secrets
+					 where type_id = $1 and user_id = $2 and ($3 or deleted_at is null)
+`
+	TrashedSecrets = `select id, public_id, user_id, type_id, title, content, created_at, updated_at, deleted_at, version,
+						 folder_id, (select group_concat(tag_id) from (
+							 select tag_id from secret_tags where secret_id = secrets.id order by tag_id)), title_hash
+					  from secrets
+					  where user_id = $1 and deleted_at is not null
+					  order by deleted_at desc
+`
+	// ListSecrets - is a template of a query, content column, sort key, comparison operator of the cursor and sort
+	// direction are formatted into it.
+	ListSecrets = `select id, public_id, user_id, type_id, title, %[1]s, created_at, updated_at, deleted_at, version,
+					  folder_id, (select group_concat(tag_id) from (
+						  select tag_id from secret_tags where secret_id = secrets.id order by tag_id)), title_hash
+				   from secrets
+				   where user_id = $1
+					 and ($2 is null or coalesce(folder_id, 0) = $2)
+					 and ($3 = 0 or exists(select 1 from secret_tags where secret_id = secrets.id and tag_id = $3))
+					 and ($4 = 0 or type_id = $4)
+					 and ($5 is null or updated_at >= $5)
+					 and ($11 is null or title_hash = $11)
+					 and case $6 when 0 then deleted_at is null when 1 then deleted_at is not null else 1 end
+					 and (not $7 or %[2]s %[3]s $8 or (%[2]s = $8 and id %[3]s $9))
+				   order by %[2]s %[4]s, id %[4]s
+				   limit $10
+`
+	MoveSecret = `update secrets
+				  set folder_id = $4
+				  where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+				    and user_id = $3 and deleted_at is null
+					and ($4 is null or exists(select 1 from folders where id = $4 and user_id = $3))
+				  returning id
+`
+	GetActiveSecretID = `select id
+						 from secrets
+						 where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+						   and user_id = $3 and deleted_at is null
+`
+	DeleteSecretTags = `delete from secret_tags where secret_id = $1`
+	InsertSecretTag  = `insert into secret_tags (secret_id, tag_id)
+						select $1, id from tags where id = $2 and user_id = $3
+`
+	RestoreSecret = `update secrets
+					 set deleted_at = null
+					 where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+					   and user_id = $3 and deleted_at is not null
+					 returning id, public_id, type_id, title, created_at, updated_at, deleted_at, version
+`
+	PurgeSecret = `delete from secrets
+				   where (public_id = $2 or $2 = '00000000-0000-0000-0000-000000000000' and id = $1)
+				     and user_id = $3 and deleted_at is not null
+				   returning id
+`
+	PurgeTrash = `delete from secrets where deleted_at < $1`
+)
+
+// NewSecretSQLiteStorage - creates a sqlite storage for secrets.
+func NewSecretSQLiteStorage(db *sql.DB) *SecretSQLiteStorage {
+	return &SecretSQLiteStorage{db: db}
+}
+
+// CreateSecret - stores provided model.Secret in database.
+//
+// Creation is idempotent by PublicID: if a secret with the same PublicID already exists for the user, then the stored
+// model.Secret is returned instead of creating a duplicate. If PublicID belongs to another user, then
+// apperr.ErrConflict is returned.
+func (s *SecretSQLiteStorage) CreateSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	if secret.PublicID == uuid.Nil {
+		secret.PublicID = uuid.New()
+	}
+
+	err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, CreateSecrete, secret.PublicID, secret.UserID,
+		secret.TypeID, secret.Title, blob(secret.Content), secret.CreatedAt.UTC(), secret.UpdatedAt.UTC(),
+		titleHash(secret.TitleHash),
+	).Scan(&secret.ID, &secret.Version)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return secret, fmt.Errorf("error in storing secret in db: %w", err)
+		}
+
+		existing, errGet := s.GetSecret(ctx, model.Secret{PublicID: secret.PublicID, UserID: secret.UserID}, true)
+		if errGet != nil {
+			if errors.Is(errGet, pgx.ErrNoRows) {
+				return secret, apperr.ErrConflict
+			}
+
+			return secret, errGet
+		}
+
+		return existing, nil
+	}
+
+	return secret, nil
+}
+
+// GetSecret - return rehydrated model.Secret from database.
+//
+// Searches by user_id and id or public_id from provided model.Secret, secrets in trash are found only if withTrashed
+// is true.
+func (s *SecretSQLiteStorage) GetSecret(
+	ctx context.Context, secret model.Secret, withTrashed bool,
+) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	row := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, GetSecret, secret.ID, secret.PublicID, secret.UserID,
+		withTrashed,
+	)
+
+	got, err := scanSecret(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return secret, fmt.Errorf("secret getting error: %w", err)
+		}
+
+		return secret, fmt.Errorf("error in getting secret from db: %w", noRows(err))
+	}
+
+	return got, nil
+}
+
+// DeleteSecret - moves a model.Secret to trash by setting its deleted_at in database.
+func (s *SecretSQLiteStorage) DeleteSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var deletedSecretId *int
+	err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, DeleteSecret, secret.ID, secret.PublicID, secret.UserID,
+		time.Now().UTC(),
+	).Scan(&deletedSecretId)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return secret, fmt.Errorf("secret deletion err: %w", err)
+		}
+
+		return secret, noRows(err)
+	}
+
+	return model.Secret{}, nil
+}
+
+// EditSecret - updates a model.Secret in database, empty Title and nil Content keep the stored ones. TitleHash is
+// replaced along with Title.
+//
+// Update is applied only if Version of provided model.Secret matches the stored one, unless isForce is true. On
+// successful update Version is incremented. On mismatch returns apperr.ErrVersionDoesntMatch and model.Secret with
+// current Version and UpdatedAt from database.
+//
+// Stored version is read and checked in the same transaction with update, which holds write lock of database.
+func (s *SecretSQLiteStorage) EditSecret(ctx context.Context, secret model.Secret, isForce bool) (model.Secret, error) {
+	err := withinTransaction(ctx, s.db, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		current := model.Secret{}
+		errVersion := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, GetSecretVersionForUpdate, secret.ID,
+			secret.PublicID, secret.UserID,
+		).Scan(&current.ID, &current.PublicID, &current.UpdatedAt, &current.Version)
+		if errVersion != nil {
+			if !errors.Is(errVersion, sql.ErrNoRows) {
+				return fmt.Errorf("secret version getting error: %w", errVersion)
+			}
+
+			return noRows(errVersion)
+		}
+
+		if !isForce && current.Version != secret.Version {
+			secret.ID, secret.PublicID = current.ID, current.PublicID
+			secret.UpdatedAt, secret.Version = current.UpdatedAt, current.Version
+
+			return apperr.ErrVersionDoesntMatch
+		}
+
+		var title, content interface{}
+		if secret.Title != "" {
+			title = secret.Title
+		}
+		if secret.Content != nil {
+			content = secret.Content
+		}
+
+		var tagIDs sql.NullString
+		err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, UpdateSecret, title, content, time.Now().UTC(),
+			current.ID, secret.UserID, titleHash(secret.TitleHash),
+		).Scan(&secret.ID, &secret.PublicID, &secret.TypeID, &secret.Title, &secret.CreatedAt, &secret.UpdatedAt,
+			&secret.DeletedAt, &secret.Version, &secret.FolderID, &tagIDs)
+		if err != nil {
+			return fmt.Errorf("secret updating error: %w", err)
+		}
+
+		secret.TagIDs, err = parseTagIDs(tagIDs)
+
+		return err
+	})
+
+	return secret, err
+}
+
+// GetListOfSecretByType - returns a []model.Secret from database by provided type_id via model.SecretType and user_id
+// via model.User.
+//
+// Secrets in trash are returned only if withTrashed is true.
+func (s *SecretSQLiteStorage) GetListOfSecretByType(
+	ctx context.Context, secretType model.SecretType, user model.User, withTrashed bool,
+) ([]model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, s.db).QueryContext(ctxWithTimeOut, SecretsByType, secretType.ID, user.ID, withTrashed)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of secrets error: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSecrets(rows)
+}
+
+// ListTrash - returns a []model.Secret in trash of model.User from database, latest deleted go first.
+func (s *SecretSQLiteStorage) ListTrash(ctx context.Context, user model.User) ([]model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, s.db).QueryContext(ctxWithTimeOut, TrashedSecrets, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of trashed secrets error: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSecrets(rows)
+}
+
+// RestoreSecret - moves a model.Secret out of trash by resetting its deleted_at in database.
+func (s *SecretSQLiteStorage) RestoreSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, RestoreSecret, secret.ID, secret.PublicID, secret.UserID).
+		Scan(&secret.ID, &secret.PublicID, &secret.TypeID, &secret.Title, &secret.CreatedAt, &secret.UpdatedAt,
+			&secret.DeletedAt, &secret.Version,
+		)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return secret, fmt.Errorf("secret restoring error: %w", err)
+		}
+
+		return secret, noRows(err)
+	}
+
+	return secret, nil
+}
+
+// PurgeSecret - permanently deletes a model.Secret, which is in trash, from database.
+func (s *SecretSQLiteStorage) PurgeSecret(ctx context.Context, secret model.Secret) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var purgedSecretId *int
+	err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, PurgeSecret, secret.ID, secret.PublicID, secret.UserID).
+		Scan(&purgedSecretId)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return secret, fmt.Errorf("secret purging err: %w", err)
+		}
+
+		return secret, noRows(err)
+	}
+
+	return model.Secret{}, nil
+}
+
+// PurgeTrash - permanently deletes all secrets, which were moved to trash before provided time, from database.
+//
+// Returns number of deleted secrets.
+func (s *SecretSQLiteStorage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := conn(ctx, s.db).ExecContext(ctxWithTimeOut, PurgeTrash, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("trash purging error: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// ListSecretVersions - returns a []model.SecretVersion of a model.Secret from database, including current version.
+//
+// Versions are ordered from the latest to the oldest one.
+func (s *SecretSQLiteStorage) ListSecretVersions(
+	ctx context.Context, secret model.Secret,
+) ([]model.SecretVersion, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var versions []model.SecretVersion
+
+	rows, err := conn(ctx, s.db).QueryContext(ctxWithTimeOut, SecretVersions+" order by version desc",
+		secret.ID, secret.PublicID, secret.UserID,
+	)
+	if err != nil {
+		return versions, fmt.Errorf("getting list of secret versions error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		version, errScan := scanSecretVersion(rows)
+		if errScan != nil {
+			return versions, errScan
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// GetSecretVersion - returns a model.SecretVersion of a model.Secret from database by its version.
+func (s *SecretSQLiteStorage) GetSecretVersion(
+	ctx context.Context, secret model.Secret, version int64,
+) (model.SecretVersion, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	row := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut,
+		"select * from ("+SecretVersions+") versions where version = $4",
+		secret.ID, secret.PublicID, secret.UserID, version,
+	)
+
+	return scanSecretVersion(row)
+}
+
+// RestoreSecretVersion - sets title, type and content of a model.Secret in database to the values of provided prior
+// version.
+//
+// Restoring is a change of a secret, so Version is incremented and replaced content is kept as a prior version.
+// TitleHash is restored along with title.
+func (s *SecretSQLiteStorage) RestoreSecretVersion(
+	ctx context.Context, secret model.Secret, version int64,
+) (model.Secret, error) {
+	err := withinTransaction(ctx, s.db, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		var prior model.SecretVersion
+		err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, GetPriorSecretVersion, secret.ID, secret.PublicID,
+			secret.UserID, version,
+		).Scan(&prior.TypeID, &prior.Title, &prior.TitleHash, &prior.Content)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("secret version getting error: %w", err)
+			}
+
+			return noRows(err)
+		}
+
+		err = conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, RestoreSecretVersion, secret.ID, secret.PublicID,
+			secret.UserID, time.Now().UTC(), prior.TypeID, prior.Title, blob(prior.Content), titleHash(prior.TitleHash),
+		).Scan(&secret.ID, &secret.PublicID, &secret.TypeID, &secret.Title, &secret.CreatedAt, &secret.UpdatedAt,
+			&secret.DeletedAt, &secret.Version)
+		if err != nil {
+			return fmt.Errorf("secret version restoring error: %w", err)
+		}
+
+		return nil
+	})
+
+	return secret, err
+}
+
+// TrimSecretVersions - deletes prior versions of a model.Secret from database, except keep latest ones.
+func (s *SecretSQLiteStorage) TrimSecretVersions(ctx context.Context, secret model.Secret, keep int) error {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	_, err := conn(ctx, s.db).ExecContext(ctxWithTimeOut, TrimSecretVersions, secret.ID, secret.PublicID,
+		secret.UserID, keep,
+	)
+	if err != nil {
+		return fmt.Errorf("secret versions trimming error: %w", err)
+	}
+
+	return nil
+}
+
+// scanner - is a common interface of *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ListSecrets - returns a page of []model.Secret of a user selected and sorted by model.SecretFilter from database.
+func (s *SecretSQLiteStorage) ListSecrets(ctx context.Context, filter model.SecretFilter) ([]model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	content, key, cmp, dir := "content", "id", ">", "asc"
+	if filter.WithoutContent {
+		content = "null"
+	}
+
+	if filter.Desc {
+		cmp, dir = "<", "desc"
+	}
+
+	var cursor interface{} = 0
+	cursorID := 0
+	if filter.After != nil {
+		cursor, cursorID = filter.After.ID, filter.After.ID
+	}
+
+	if filter.OrderKey() == model.SecretOrderUpdatedAt {
+		key = "updated_at"
+		if filter.After != nil {
+			cursor = filter.After.UpdatedAt.UTC()
+		}
+	}
+
+	var updatedSince interface{}
+	if filter.UpdatedSince != nil {
+		updatedSince = filter.UpdatedSince.UTC()
+	}
+
+	limit := -1
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	rows, err := conn(ctx, s.db).QueryContext(ctxWithTimeOut, fmt.Sprintf(ListSecrets, content, key, cmp, dir),
+		filter.UserID, filter.FolderID, filter.TagID, filter.TypeID, updatedSince, int(filter.Trash),
+		filter.After != nil, cursor, cursorID, limit, titleHash(filter.TitleHash),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting list of secrets error: %w", err)
+	}
+	defer rows.Close()
+
+	secrets, err := scanSecrets(rows)
+	if filter.WithoutContent {
+		for i := range secrets {
+			secrets[i].Content = nil
+		}
+	}
+
+	return secrets, err
+}
+
+// MoveSecret - sets folder of a model.Secret in database, folder has to belong to the user of a secret.
+//
+// Moving is not a change of content, so Version of a secret is kept.
+func (s *SecretSQLiteStorage) MoveSecret(
+	ctx context.Context, secret model.Secret, folderID *int,
+) (model.Secret, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var movedSecretID int
+	err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, MoveSecret, secret.ID, secret.PublicID, secret.UserID,
+		folderID,
+	).Scan(&movedSecretID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return secret, fmt.Errorf("secret moving error: %w", err)
+		}
+
+		return secret, noRows(err)
+	}
+
+	return s.GetSecret(ctx, model.Secret{ID: movedSecretID, UserID: secret.UserID}, false)
+}
+
+// SetSecretTags - replaces tags of a model.Secret in database, every tag has to belong to the user of a secret.
+//
+// Tags are not a content of a secret, so Version of a secret is kept.
+func (s *SecretSQLiteStorage) SetSecretTags(
+	ctx context.Context, secret model.Secret, tagIDs []int,
+) (model.Secret, error) {
+	err := withinTransaction(ctx, s.db, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		err := conn(ctx, s.db).QueryRowContext(ctxWithTimeOut, GetActiveSecretID, secret.ID, secret.PublicID,
+			secret.UserID,
+		).Scan(&secret.ID)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("secret getting error: %w", err)
+			}
+
+			return noRows(err)
+		}
+
+		if _, err = conn(ctx, s.db).ExecContext(ctxWithTimeOut, DeleteSecretTags, secret.ID); err != nil {
+			return fmt.Errorf("secret tags deleting error: %w", err)
+		}
+
+		for _, tagID := range tagIDs {
+			res, errInsert := conn(ctx, s.db).ExecContext(ctxWithTimeOut, InsertSecretTag, secret.ID, tagID,
+				secret.UserID,
+			)
+			if errInsert != nil {
+				return fmt.Errorf("secret tag inserting error: %w", errInsert)
+			}
+
+			if inserted, _ := res.RowsAffected(); inserted == 0 {
+				return fmt.Errorf("unknown tag %d: %w", tagID, pgx.ErrNoRows)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return secret, err
+	}
+
+	return s.GetSecret(ctx, model.Secret{ID: secret.ID, UserID: secret.UserID}, false)
+}
+
+// scanSecret - scans model.Secret from row.
+func scanSecret(row scanner) (model.Secret, error) {
+	var secret model.Secret
+	var tagIDs sql.NullString
+
+	err := row.Scan(&secret.ID, &secret.PublicID, &secret.UserID, &secret.TypeID, &secret.Title, &secret.Content,
+		&secret.CreatedAt, &secret.UpdatedAt, &secret.DeletedAt, &secret.Version, &secret.FolderID, &tagIDs,
+		&secret.TitleHash,
+	)
+	if err != nil {
+		return secret, err
+	}
+
+	secret.Content = blob(secret.Content)
+	secret.TagIDs, err = parseTagIDs(tagIDs)
+
+	return secret, err
+}
+
+// parseTagIDs - parses comma separated ids of tags of a secret, NULL stands for a secret without tags.
+func parseTagIDs(s sql.NullString) ([]int, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+
+	var ids []int
+	for _, id := range strings.Split(s.String, ",") {
+		tagID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("error in parsing tags of secret: %w", err)
+		}
+
+		ids = append(ids, tagID)
+	}
+
+	return ids, nil
+}
+
+// scanSecrets - scans []model.Secret from rows.
+func scanSecrets(rows *sql.Rows) ([]model.Secret, error) {
+	var secrets []model.Secret
+
+	for rows.Next() {
+		secret, err := scanSecret(rows)
+		if err != nil {
+			return secrets, fmt.Errorf("error in scanning gotten row: %w", err)
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, rows.Err()
+}
+
+// scanSecretVersion - scans model.SecretVersion from row.
+func scanSecretVersion(row scanner) (model.SecretVersion, error) {
+	var version model.SecretVersion
+
+	err := row.Scan(&version.SecretID, &version.PublicID, &version.Version, &version.TypeID, &version.Title,
+		&version.Content, &version.ChangedBy, &version.ChangedAt,
+	)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return version, fmt.Errorf("error in scanning secret version: %w", err)
+		}
+
+		return version, noRows(err)
+	}
+
+	version.Content = blob(version.Content)
+
+	return version, nil
+}
+
+// blob - returns empty slice instead of nil one, because content columns are not nullable and SQLite returns nil for
+// empty blob.
+func blob(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+
+	return b
+}
+
+// titleHash - returns blind index of a title as a query argument, empty one is null.
+func titleHash(hash []byte) interface{} {
+	if len(hash) == 0 {
+		return nil
+	}
+
+	return hash
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.SecretTypeServerStorage = (*SecretTypeSQLiteStorage)(nil)
+
+type SecretTypeSQLiteStorage struct {
+	db *sql.DB
+}
+
+const (
+	GetSecretTypeList = `SELECT id, title, fields FROM secret_types ORDER BY id`
+)
+
+// NewSecretTypeSQLiteStorage - creates a sqlite storage for secret types.
+func NewSecretTypeSQLiteStorage(db *sql.DB) *SecretTypeSQLiteStorage {
+	return &SecretTypeSQLiteStorage{db: db}
+}
+
+// GetSecretTypes - returns list of all available secret types.
+func (s *SecretTypeSQLiteStorage) GetSecretTypes(ctx context.Context) ([]model.SecretType, error) {
+	var list []model.SecretType
+
+	rows, err := conn(ctx, s.db).QueryContext(ctx, GetSecretTypeList)
+	if err != nil {
+		return list, fmt.Errorf("error in getting secret types list: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		m := model.SecretType{}
+		var fields []byte
+		if err = rows.Scan(&m.ID, &m.Title, &fields); err != nil {
+			return list, fmt.Errorf("error scanning secret types: %w", err)
+		}
+
+		if err = json.Unmarshal(fields, &m.Fields); err != nil {
+			return list, fmt.Errorf("error decoding fields of secret type %d: %w", m.ID, err)
+		}
+
+		list = append(list, m)
+	}
+
+	return list, rows.Err()
+}
@@ -0,0 +1,130 @@
+// Package sqlite is an embedded storage of the server, which keeps all data in a single SQLite file.
+//
+// It is intended for single-user deployments and tests, where running Postgres is not worth it.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+)
+
+var _ storage.Transactor = (*Transactor)(nil)
+
+// querier - is a common interface of *sql.DB and *sql.Tx, which is used by storages to run queries.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txCtx - is a key of *sql.Tx in context.
+type txCtx struct{}
+
+// NewDB - opens SQLite database in file by provided path, creating it if needed.
+//
+// Migrations are not applied, they are run by migration manager of the server.
+//
+// SQLite allows only one writer at a time, so *sql.DB is limited to one connection: queries are queued by
+// database/sql instead of failing with busy error. Transactions take write lock on begin, so rows read in a
+// transaction can't be changed by another process till its end.
+func NewDB(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"+
+			"&_txlock=immediate",
+		path,
+	)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("db open: %w", err)
+	}
+
+	db.SetMaxOpenConns(1)
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("db ping: %w", err)
+	}
+
+	return db, nil
+}
+
+// Transactor - runs functions in a transaction of *sql.DB.
+type Transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor - creates new Transactor.
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction - runs fn in a transaction, which is committed if fn returns no error and rolled back otherwise.
+//
+// SQLite storages called with context passed to fn run their queries in the transaction. If context already
+// carries a transaction, then fn joins it.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, t.db, fn)
+}
+
+// withinTransaction - runs fn in a transaction from context or in a new transaction of db.
+func withinTransaction(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txCtx{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("transaction begin err: %w", err)
+	}
+	// rollback of committed transaction is no-op
+	defer tx.Rollback()
+
+	if err = fn(context.WithValue(ctx, txCtx{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("transaction commit err: %w", err)
+	}
+
+	return nil
+}
+
+// conn - returns transaction from context if there is one, otherwise returns db.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txCtx{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// noRows - replaces sql.ErrNoRows with pgx.ErrNoRows, which services treat as not found error of any storage, other
+// errors are returned as is.
+func noRows(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return pgx.ErrNoRows
+	}
+
+	return err
+}
+
+// isConstraintViolation - reports whether err is a violation of unique, foreign key or other constraint.
+func isConstraintViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergalkin/gophkeeper/internal/server/config"
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage/storagetest"
+	"github.com/sergalkin/gophkeeper/pkg/migrations"
+)
+
+// newMigratedDB - creates SQLite database in temporary directory of the test and applies migrations to it.
+func newMigratedDB(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	cfg := config.Config{StorageDriver: config.StorageDriverSQLite, SQLitePath: path}
+	require.NoError(t, migrations.NewMigrationManager(cfg).Up())
+
+	return path
+}
+
+// newTestStorages - creates storages backed by SQLite file in temporary directory of the test.
+func newTestStorages(t *testing.T) storagetest.Storages {
+	db, err := NewDB(context.Background(), newMigratedDB(t))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	return storagetest.Storages{
+		Users:       NewUserSQLiteStorage(db),
+		SecretTypes: NewSecretTypeSQLiteStorage(db),
+		Secrets:     NewSecretSQLiteStorage(db),
+		Folders:     NewFolderSQLiteStorage(db),
+		Audit:       NewAuditSQLiteStorage(db),
+		Transactor:  NewTransactor(db),
+	}
+}
+
+func TestSQLiteStorages(t *testing.T) {
+	storagetest.Run(t, newTestStorages)
+}
+
+func TestMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	manager := migrations.NewMigrationManager(config.Config{
+		StorageDriver: config.StorageDriverSQLite,
+		SQLitePath:    path,
+	})
+
+	require.NoError(t, manager.Up())
+	require.NoError(t, manager.Up(), "migrations can be applied to already migrated database")
+
+	status, err := manager.Status()
+	require.NoError(t, err)
+	require.False(t, status.Dirty)
+	require.Equal(t, status.Latest, status.Version)
+
+	latest := status.Latest
+
+	require.NoError(t, manager.Down(2))
+	status, err = manager.Status()
+	require.NoError(t, err)
+	require.Equal(t, latest-2, status.Version)
+
+	db, err := NewDB(context.Background(), path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Run("dirty database is refused", func(t *testing.T) {
+		_, err = db.Exec("update schema_migrations set dirty = true")
+		require.NoError(t, err)
+
+		require.ErrorIs(t, manager.Up(), migrations.ErrDirty)
+
+		require.NoError(t, manager.Force(int(latest-2)))
+		require.NoError(t, manager.Up())
+	})
+
+	t.Run("newer database is refused", func(t *testing.T) {
+		_, err = db.Exec("update schema_migrations set version = $1", latest+1)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, manager.Up(), migrations.ErrUnknownVersion)
+	})
+
+	t.Run("all migrations are rolled back", func(t *testing.T) {
+		require.NoError(t, manager.Force(int(latest)))
+		require.NoError(t, manager.Down(0))
+
+		status, err = manager.Status()
+		require.NoError(t, err)
+		require.Equal(t, migrations.Status{Latest: latest}, status)
+	})
+}
+
+func TestAuditSQLiteStorage_EventsAreAppendOnly(t *testing.T) {
+	db, err := NewDB(context.Background(), newMigratedDB(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewAuditSQLiteStorage(db).CreateAuditEvent(context.Background(), model.AuditEvent{
+		Event: model.AuditEventLogin, CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	_, err = db.Exec("update audit_events set success = true")
+	require.Error(t, err)
+
+	_, err = db.Exec("delete from audit_events")
+	require.Error(t, err)
+}
@@ -0,0 +1,332 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/pkg/apperr"
+)
+
+var _ storage.UserServerStorage = (*UserSQLiteStorage)(nil)
+
+type UserSQLiteStorage struct {
+	db *sql.DB
+}
+
+const (
+	CreateUser = `INSERT INTO users (id, login, password, role, created_at, vault_key)
+				  VALUES ($1, $2, $3, $4, $5, $6)`
+	GetUserPassword     = `SELECT id, password, role, disabled_at, vault_key FROM users WHERE login = $1`
+	DeleteUserById      = `DELETE from users where id = $1 returning login`
+	DeleteSecretsByUser = `DELETE from secrets where user_id = $1`
+	DeleteFoldersByUser = `DELETE from folders where user_id = $1`
+	DeleteTagsByUser    = `DELETE from tags where user_id = $1`
+	GetUser             = `SELECT id, login, role, created_at, disabled_at, tokens_revoked_at FROM users
+						   WHERE ($1 is not null and id = $1) or ($1 is null and login = $2)`
+	ListUsers = `SELECT u.id, u.login, u.role, u.created_at, u.disabled_at, u.tokens_revoked_at,
+					    count(s.id) filter (where s.deleted_at is null),
+					    count(s.id) filter (where s.deleted_at is not null),
+					    coalesce(sum(length(s.content)), 0) +
+					    coalesce((SELECT sum(length(v.content)) FROM secret_versions v
+								  JOIN secrets vs on vs.id = v.secret_id WHERE vs.user_id = u.id), 0)
+				 FROM users u
+						  LEFT JOIN secrets s on s.user_id = u.id
+				 GROUP BY u.id
+				 ORDER BY u.login`
+	DisableUser = `UPDATE users SET disabled_at = coalesce(disabled_at, $2), tokens_revoked_at = $2 WHERE id = $1
+				   returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	EnableUser = `UPDATE users SET disabled_at = null WHERE id = $1
+				  returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	SetUserRole = `UPDATE users SET role = $2, tokens_revoked_at = $3 WHERE id = $1
+				   returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	ResetPassword = `UPDATE users SET password = $2, tokens_revoked_at = $3, vault_key = null WHERE id = $1
+					 returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	SetVaultKey    = `UPDATE users SET vault_key = coalesce(vault_key, $2) WHERE id = $1 returning vault_key`
+	ChangePassword = `UPDATE users SET password = $2, vault_key = $3 WHERE id = $1
+					  returning id, login, role, created_at, disabled_at, tokens_revoked_at`
+	GetUsersStats = `SELECT (SELECT count(*) FROM users),
+						    (SELECT count(*) FROM users WHERE disabled_at is not null),
+						    (SELECT count(*) FROM secrets WHERE deleted_at is null),
+						    (SELECT count(*) FROM secrets WHERE deleted_at is not null),
+						    (SELECT count(*) FROM secret_versions),
+						    (SELECT coalesce(sum(length(content)), 0) FROM secrets) +
+						    (SELECT coalesce(sum(length(content)), 0) FROM secret_versions)`
+)
+
+// NewUserSQLiteStorage - Creates UserSQLiteStorage instance.
+func NewUserSQLiteStorage(db *sql.DB) *UserSQLiteStorage {
+	return &UserSQLiteStorage{db: db}
+}
+
+// Create - creates a user record in DB with data provided from model.User, then returns model.User populated with
+// generated user id.
+//
+// SQLite has no pgcrypto, so password is hashed with bcrypt before insertion.
+func (u UserSQLiteStorage) Create(ctx context.Context, user model.User) (model.User, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return user, fmt.Errorf("user password hashing err: %w", err)
+	}
+
+	id := uuid.New()
+
+	_, err = conn(ctx, u.db).ExecContext(
+		ctxWithTimeOut, CreateUser, id, user.Login, string(hash), model.RoleUser, time.Now().UTC(), user.VaultKey,
+	)
+	if err != nil {
+		if isConstraintViolation(err) {
+			return user, apperr.ErrConflict
+		}
+
+		return user, fmt.Errorf("user insertion err: %w", err)
+	}
+
+	user.ID, user.Role = &id, model.RoleUser
+
+	return user, nil
+}
+
+// GetByLoginAndPassword - searches DB with provided model.User, if record is found and password matches, then
+// populates model.User with user id from database.
+func (u UserSQLiteStorage) GetByLoginAndPassword(ctx context.Context, user model.User) (model.User, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var id uuid.UUID
+	var hash, role string
+	var disabledAt *time.Time
+	var vaultKey []byte
+
+	err := conn(ctx, u.db).QueryRowContext(ctxWithTimeOut, GetUserPassword, user.Login).
+		Scan(&id, &hash, &role, &disabledAt, &vaultKey)
+	if err != nil {
+		return user, fmt.Errorf("user login err: %w", noRows(err))
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(user.Password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			err = sql.ErrNoRows
+		}
+
+		return user, fmt.Errorf("user login err: %w", noRows(err))
+	}
+
+	user.ID, user.Role, user.DisabledAt, user.VaultKey = &id, role, disabledAt, vaultKey
+
+	return user, nil
+}
+
+// DeleteUser - deletes a user and its secrets from DB in one transaction, then sets ID to nil to model.User.
+func (u UserSQLiteStorage) DeleteUser(ctx context.Context, user model.User) (model.User, error) {
+	err := withinTransaction(ctx, u.db, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		if _, err := conn(ctx, u.db).ExecContext(ctxWithTimeOut, DeleteSecretsByUser, user.ID); err != nil {
+			return fmt.Errorf("user secrets deletion err: %w", err)
+		}
+
+		var deletedLogin *string
+		err := conn(ctx, u.db).QueryRowContext(ctxWithTimeOut, DeleteUserById, user.ID).Scan(&deletedLogin)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return noRows(err)
+			}
+
+			return fmt.Errorf("user deletion err: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return model.User{}, nil
+}
+
+// GetUser - searches DB for a user by ID from provided model.User, or by its login if ID is nil.
+func (u UserSQLiteStorage) GetUser(ctx context.Context, user model.User) (model.User, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	found, err := scanUser(conn(ctx, u.db).QueryRowContext(ctxWithTimeOut, GetUser, user.ID, user.Login))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, noRows(err)
+		}
+
+		return user, fmt.Errorf("user get err: %w", err)
+	}
+
+	return found, nil
+}
+
+// ListUsers - returns all users from DB with number and size of their secrets, ordered by login.
+func (u UserSQLiteStorage) ListUsers(ctx context.Context) ([]model.UserUsage, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := conn(ctx, u.db).QueryContext(ctxWithTimeOut, ListUsers)
+	if err != nil {
+		return nil, fmt.Errorf("users list err: %w", err)
+	}
+	defer rows.Close()
+
+	var users []model.UserUsage
+	for rows.Next() {
+		var usage model.UserUsage
+		if err = rows.Scan(
+			&usage.User.ID, &usage.User.Login, &usage.User.Role, &usage.User.CreatedAt, &usage.User.DisabledAt,
+			&usage.User.TokensRevokedAt, &usage.Secrets, &usage.TrashedSecrets, &usage.Size,
+		); err != nil {
+			return nil, fmt.Errorf("users list err: %w", err)
+		}
+
+		users = append(users, usage)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("users list err: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetUserDisabled - disables or enables a user by ID from provided model.User in DB, then returns updated model.User.
+//
+// Disabling moves revocation time of user tokens to now, so tokens issued before it stay invalid after the user is
+// enabled again.
+func (u UserSQLiteStorage) SetUserDisabled(ctx context.Context, user model.User, disabled bool) (model.User, error) {
+	if disabled {
+		return u.update(ctx, DisableUser, user.ID, time.Now().UTC())
+	}
+
+	return u.update(ctx, EnableUser, user.ID)
+}
+
+// SetUserRole - changes role of a user by ID from provided model.User in DB, then returns updated model.User.
+//
+// Tokens carry role of the user, so tokens issued before the change are revoked.
+func (u UserSQLiteStorage) SetUserRole(ctx context.Context, user model.User, role string) (model.User, error) {
+	return u.update(ctx, SetUserRole, user.ID, role, time.Now().UTC())
+}
+
+// ResetPassword - replaces password of a user by ID from provided model.User in DB, revokes its tokens and deletes
+// its vault in one transaction, then returns updated model.User.
+//
+// Data of the user can't be decrypted without key of the vault, which is wrapped by the previous password, so its
+// secrets, folders and tags are deleted along with wrapped key, and the user starts with an empty vault.
+func (u UserSQLiteStorage) ResetPassword(ctx context.Context, user model.User, password string) (model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return user, fmt.Errorf("user password hashing err: %w", err)
+	}
+
+	var updated model.User
+
+	err = withinTransaction(ctx, u.db, func(ctx context.Context) error {
+		ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+		defer cancel()
+
+		for _, query := range []string{DeleteSecretsByUser, DeleteFoldersByUser, DeleteTagsByUser} {
+			if _, errDelete := conn(ctx, u.db).ExecContext(ctxWithTimeOut, query, user.ID); errDelete != nil {
+				return fmt.Errorf("user vault deletion err: %w", errDelete)
+			}
+		}
+
+		var errUpdate error
+		updated, errUpdate = u.update(ctx, ResetPassword, user.ID, string(hash), time.Now().UTC())
+
+		return errUpdate
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return updated, nil
+}
+
+// SetVaultKey - sets wrapped key of a vault of a user by ID from provided model.User in DB, if it isn't set yet, then
+// returns stored key, so concurrent clients of a user registered before vault keys end up with the same key.
+func (u UserSQLiteStorage) SetVaultKey(ctx context.Context, user model.User, vaultKey []byte) ([]byte, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var stored []byte
+	err := conn(ctx, u.db).QueryRowContext(ctxWithTimeOut, SetVaultKey, user.ID, vaultKey).Scan(&stored)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, noRows(err)
+		}
+
+		return nil, fmt.Errorf("user vault key err: %w", err)
+	}
+
+	return stored, nil
+}
+
+// ChangePassword - replaces password and wrapped key of a vault of a user by ID from provided model.User in DB, then
+// returns updated model.User.
+func (u UserSQLiteStorage) ChangePassword(
+	ctx context.Context, user model.User, password string, vaultKey []byte,
+) (model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return user, fmt.Errorf("user password hashing err: %w", err)
+	}
+
+	return u.update(ctx, ChangePassword, user.ID, string(hash), vaultKey)
+}
+
+// update - runs query, which updates a user and returns its columns, then returns updated model.User.
+func (u UserSQLiteStorage) update(ctx context.Context, query string, args ...interface{}) (model.User, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	updated, err := scanUser(conn(ctx, u.db).QueryRowContext(ctxWithTimeOut, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return updated, noRows(err)
+		}
+
+		return updated, fmt.Errorf("user update err: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetUsersStats - returns number of users and number and size of secrets stored in DB.
+func (u UserSQLiteStorage) GetUsersStats(ctx context.Context) (model.UsersStats, error) {
+	ctxWithTimeOut, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var stats model.UsersStats
+	err := conn(ctx, u.db).QueryRowContext(ctxWithTimeOut, GetUsersStats).Scan(
+		&stats.Users, &stats.DisabledUsers, &stats.Secrets, &stats.TrashedSecrets, &stats.SecretVersions, &stats.Size,
+	)
+	if err != nil {
+		return stats, fmt.Errorf("users stats err: %w", err)
+	}
+
+	return stats, nil
+}
+
+// scanUser - scans model.User from row with id, login, role, created_at, disabled_at and tokens_revoked_at columns.
+func scanUser(row *sql.Row) (model.User, error) {
+	var user model.User
+	err := row.Scan(&user.ID, &user.Login, &user.Role, &user.CreatedAt, &user.DisabledAt, &user.TokensRevokedAt)
+
+	return user, err
+}
@@ -0,0 +1,1414 @@
+// Package storagetest is a backend-agnostic contract test suite of server storages.
+//
+// Every implementation of storage interfaces is expected to pass it, so behaviour of backends could not drift apart.
+// Suite works with storages through their interfaces only, so it needs nothing but a fresh database per test.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergalkin/gophkeeper/internal/server/model"
+	"github.com/sergalkin/gophkeeper/internal/server/storage"
+	"github.com/sergalkin/gophkeeper/pkg/apperr"
+)
+
+// Storages - is a set of storages of a backend under test.
+type Storages struct {
+	Users       storage.UserServerStorage
+	SecretTypes storage.SecretTypeServerStorage
+	Secrets     storage.SecretServerStorage
+	Folders     storage.FolderServerStorage
+	Audit       storage.AuditServerStorage
+	Transactor  storage.Transactor
+}
+
+// NewStorages - returns Storages backed by empty migrated database, which is used by one test only.
+type NewStorages func(t *testing.T) Storages
+
+// Run - runs the whole suite against storages created by newStorages.
+func Run(t *testing.T, newStorages NewStorages) {
+	t.Run("Users", func(t *testing.T) { RunUserStorageTests(t, newStorages) })
+	t.Run("SecretTypes", func(t *testing.T) { RunSecretTypeStorageTests(t, newStorages) })
+	t.Run("Secrets", func(t *testing.T) { RunSecretStorageTests(t, newStorages) })
+	t.Run("Folders", func(t *testing.T) { RunFolderStorageTests(t, newStorages) })
+	t.Run("Audit", func(t *testing.T) { RunAuditStorageTests(t, newStorages) })
+	t.Run("Transactor", func(t *testing.T) { RunTransactorTests(t, newStorages) })
+}
+
+// RunUserStorageTests - tests storage.UserServerStorage.
+func RunUserStorageTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	t.Run("User can be created", func(t *testing.T) {
+		st := newStorages(t)
+
+		got, err := st.Users.Create(ctx, model.User{Login: "test", Password: "test"})
+		assert.NoError(t, err)
+		assert.NotNil(t, got.ID)
+	})
+
+	t.Run("Conflict error will be returned on attempt to create a user with same login", func(t *testing.T) {
+		st := newStorages(t)
+		createUser(t, st, "test")
+
+		_, err := st.Users.Create(ctx, model.User{Login: "test", Password: "other"})
+		assert.ErrorIs(t, err, apperr.ErrConflict)
+	})
+
+	tests := []struct {
+		name     string
+		login    string
+		password string
+		wantErr  bool
+	}{
+		{name: "User can be gotten by login and password", login: "test", password: "test"},
+		{name: "User can't be gotten with wrong password", login: "test", password: "wrong", wantErr: true},
+		{name: "User can't be gotten with unknown login", login: "unknown", password: "test", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newStorages(t)
+			user := createUser(t, st, "test")
+
+			got, err := st.Users.GetByLoginAndPassword(ctx, model.User{Login: tt.login, Password: tt.password})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, pgx.ErrNoRows)
+				assert.Nil(t, got.ID)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, user.ID, got.ID)
+		})
+	}
+
+	t.Run("User and its secrets can be deleted", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		secret := createSecret(t, st, user, "test")
+
+		got, err := st.Users.DeleteUser(ctx, user)
+		assert.NoError(t, err)
+		assert.Nil(t, got.ID)
+
+		_, err = st.Users.GetByLoginAndPassword(ctx, user)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		_, err = st.Secrets.GetSecret(ctx, secret, true)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Not found error will be returned on deletion of unknown user", func(t *testing.T) {
+		st := newStorages(t)
+		id := uuid.New()
+
+		_, err := st.Users.DeleteUser(ctx, model.User{ID: &id})
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+	t.Run("User can be gotten by id or login", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		got, err := st.Users.GetUser(ctx, model.User{ID: user.ID})
+		require.NoError(t, err)
+		assert.Equal(t, "test", got.Login)
+		assert.Nil(t, got.DisabledAt)
+
+		got, err = st.Users.GetUser(ctx, model.User{Login: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, got.ID)
+
+		_, err = st.Users.GetUser(ctx, model.User{Login: "unknown"})
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("User can be disabled and enabled", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		got, err := st.Users.SetUserDisabled(ctx, user, true)
+		require.NoError(t, err)
+		require.NotNil(t, got.DisabledAt)
+		require.NotNil(t, got.TokensRevokedAt)
+
+		logged, err := st.Users.GetByLoginAndPassword(ctx, user)
+		require.NoError(t, err)
+		assert.NotNil(t, logged.DisabledAt)
+
+		got, err = st.Users.SetUserDisabled(ctx, user, false)
+		require.NoError(t, err)
+		assert.Nil(t, got.DisabledAt)
+		assert.NotNil(t, got.TokensRevokedAt, "tokens issued before disabling stay revoked")
+
+		id := uuid.New()
+		_, err = st.Users.SetUserDisabled(ctx, model.User{ID: &id}, true)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Role of user can be changed, which revokes its tokens", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		assert.Equal(t, model.RoleUser, user.Role)
+
+		got, err := st.Users.SetUserRole(ctx, user, model.RoleAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleAdmin, got.Role)
+		assert.NotNil(t, got.TokensRevokedAt)
+
+		logged, err := st.Users.GetByLoginAndPassword(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleAdmin, logged.Role)
+	})
+
+	t.Run("Password of user can be reset, which revokes its tokens", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		_, err := st.Users.SetVaultKey(ctx, user, []byte("wrapped"))
+		require.NoError(t, err)
+		createSecret(t, st, user, "lost")
+		_, err = st.Folders.CreateFolder(ctx, model.Folder{UserID: *user.ID, Name: []byte("lost")})
+		require.NoError(t, err)
+		_, err = st.Folders.CreateTag(ctx, model.Tag{UserID: *user.ID, Name: []byte("lost")})
+		require.NoError(t, err)
+
+		other := createUser(t, st, "other")
+		kept := createSecret(t, st, other, "kept")
+
+		got, err := st.Users.ResetPassword(ctx, user, "temporary")
+		require.NoError(t, err)
+		assert.NotNil(t, got.TokensRevokedAt)
+
+		secrets, err := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *user.ID})
+		require.NoError(t, err)
+		assert.Empty(t, secrets, "secrets can't be decrypted without the previous password, so they are deleted")
+
+		folders, err := st.Folders.ListFolders(ctx, user)
+		require.NoError(t, err)
+		assert.Empty(t, folders)
+
+		tags, err := st.Folders.ListTags(ctx, user)
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+
+		_, err = st.Secrets.GetSecret(ctx, kept, false)
+		assert.NoError(t, err, "secrets of other users are kept")
+
+		_, err = st.Users.GetByLoginAndPassword(ctx, user)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		logged, err := st.Users.GetByLoginAndPassword(ctx, model.User{Login: "test", Password: "temporary"})
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, logged.ID)
+		assert.Empty(t, logged.VaultKey, "new vault is set on the next login")
+
+		id := uuid.New()
+		_, err = st.Users.ResetPassword(ctx, model.User{ID: &id}, "temporary")
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Vault key is set once and returned on login", func(t *testing.T) {
+		st := newStorages(t)
+		user, err := st.Users.Create(ctx, model.User{Login: "test", Password: "test", VaultKey: []byte("wrapped")})
+		require.NoError(t, err)
+		legacy := createUser(t, st, "legacy")
+
+		logged, err := st.Users.GetByLoginAndPassword(ctx, model.User{Login: "test", Password: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("wrapped"), logged.VaultKey)
+
+		logged, err = st.Users.GetByLoginAndPassword(ctx, model.User{Login: "legacy", Password: "test"})
+		require.NoError(t, err)
+		assert.Empty(t, logged.VaultKey, "user registered before vault keys has none")
+
+		stored, err := st.Users.SetVaultKey(ctx, legacy, []byte("first"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("first"), stored)
+
+		stored, err = st.Users.SetVaultKey(ctx, legacy, []byte("second"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("first"), stored, "key set before is kept")
+
+		stored, err = st.Users.SetVaultKey(ctx, user, []byte("other"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("wrapped"), stored)
+
+		id := uuid.New()
+		_, err = st.Users.SetVaultKey(ctx, model.User{ID: &id}, []byte("first"))
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Password is changed along with wrapped vault key", func(t *testing.T) {
+		st := newStorages(t)
+		user, err := st.Users.Create(ctx, model.User{Login: "test", Password: "test", VaultKey: []byte("wrapped")})
+		require.NoError(t, err)
+
+		got, err := st.Users.ChangePassword(ctx, user, "changed", []byte("rewrapped"))
+		require.NoError(t, err)
+		assert.Nil(t, got.TokensRevokedAt, "tokens stay valid, key of the vault is the same")
+
+		_, err = st.Users.GetByLoginAndPassword(ctx, model.User{Login: "test", Password: "test"})
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		logged, err := st.Users.GetByLoginAndPassword(ctx, model.User{Login: "test", Password: "changed"})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("rewrapped"), logged.VaultKey)
+
+		id := uuid.New()
+		_, err = st.Users.ChangePassword(ctx, model.User{ID: &id}, "changed", []byte("rewrapped"))
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Users are listed with their usage and counted in stats", func(t *testing.T) {
+		st := newStorages(t)
+		bob := createUser(t, st, "bob")
+		alice := createUser(t, st, "alice")
+		createSecret(t, st, bob, "first")
+		trashed := createSecret(t, st, bob, "second")
+
+		_, err := st.Secrets.DeleteSecret(ctx, trashed)
+		require.NoError(t, err)
+		_, err = st.Users.SetUserDisabled(ctx, alice, true)
+		require.NoError(t, err)
+
+		users, err := st.Users.ListUsers(ctx)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+
+		assert.Equal(t, "alice", users[0].User.Login)
+		assert.NotNil(t, users[0].User.DisabledAt)
+		assert.Equal(t, model.UserUsage{User: users[0].User}, users[0])
+
+		assert.Equal(t, "bob", users[1].User.Login)
+		assert.Equal(t, int64(1), users[1].Secrets)
+		assert.Equal(t, int64(1), users[1].TrashedSecrets)
+		assert.Positive(t, users[1].Size)
+
+		stats, err := st.Users.GetUsersStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, model.UsersStats{
+			Users: 2, DisabledUsers: 1, Secrets: 1, TrashedSecrets: 1, Size: users[1].Size,
+		}, stats)
+	})
+}
+
+// RunAuditStorageTests - tests storage.AuditServerStorage.
+func RunAuditStorageTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	t.Run("Audit event can be created and listed", func(t *testing.T) {
+		st := newStorages(t)
+		uid := uuid.New()
+		createdAt := time.Now().Truncate(time.Second)
+
+		created, err := st.Audit.CreateAuditEvent(ctx, model.AuditEvent{
+			UserID: &uid, Event: model.AuditEventSecretRead, Success: true, Target: "42", IP: "127.0.0.1",
+			UserAgent: "test", RequestID: "request", CreatedAt: createdAt,
+		})
+		require.NoError(t, err)
+		assert.NotZero(t, created.ID)
+
+		events, err := st.Audit.ListAuditEvents(ctx, model.AuditEventFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, created.ID, events[0].ID)
+		assert.Equal(t, &uid, events[0].UserID)
+		assert.Equal(t, model.AuditEventSecretRead, events[0].Event)
+		assert.True(t, events[0].Success)
+		assert.Equal(t, "42", events[0].Target)
+		assert.Equal(t, "127.0.0.1", events[0].IP)
+		assert.Equal(t, "test", events[0].UserAgent)
+		assert.Equal(t, "request", events[0].RequestID)
+		assert.True(t, createdAt.Equal(events[0].CreatedAt))
+	})
+
+	t.Run("Audit events are paged and filtered by user", func(t *testing.T) {
+		st := newStorages(t)
+		alice, bob := uuid.New(), uuid.New()
+
+		for i, user := range []*uuid.UUID{&alice, &bob, &alice, nil, &alice} {
+			_, err := st.Audit.CreateAuditEvent(ctx, model.AuditEvent{
+				UserID: user, Event: model.AuditEventLogin, Target: fmt.Sprint(i), CreatedAt: time.Now(),
+			})
+			require.NoError(t, err)
+		}
+
+		page, err := st.Audit.ListAuditEvents(ctx, model.AuditEventFilter{UserID: &alice, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"4", "2"}, targets(page))
+
+		page, err = st.Audit.ListAuditEvents(ctx, model.AuditEventFilter{UserID: &alice, BeforeID: page[1].ID, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0"}, targets(page))
+
+		page, err = st.Audit.ListAuditEvents(ctx, model.AuditEventFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"4", "3", "2", "1", "0"}, targets(page))
+	})
+
+	t.Run("Audit events are chained by hashes", func(t *testing.T) {
+		st := newStorages(t)
+		uid := uuid.New()
+
+		for i := 0; i < 3; i++ {
+			_, err := st.Audit.CreateAuditEvent(ctx, model.AuditEvent{
+				UserID: &uid, Event: model.AuditEventLogin, Target: fmt.Sprint(i), CreatedAt: time.Now(),
+			})
+			require.NoError(t, err)
+		}
+
+		first, err := st.Audit.ListAuditChain(ctx, 0, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"0", "1"}, targets(first))
+
+		rest, err := st.Audit.ListAuditChain(ctx, first[1].ID, 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2"}, targets(rest))
+
+		prevHash := ""
+		for _, event := range append(first, rest...) {
+			assert.Equal(t, prevHash, event.PrevHash)
+			assert.Equal(t, event.ChainHash(prevHash), event.Hash, "hash of stored event is reproducible")
+
+			prevHash = event.Hash
+		}
+	})
+
+	t.Run("Audit checkpoints can be created and listed", func(t *testing.T) {
+		st := newStorages(t)
+
+		_, err := st.Audit.GetLastAuditCheckpoint(ctx)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		for i := int64(1); i <= 2; i++ {
+			created, errCreate := st.Audit.CreateAuditCheckpoint(ctx, model.AuditCheckpoint{
+				EventID: i, Hash: fmt.Sprint("hash", i), Signature: []byte{1, 2, byte(i)}, CreatedAt: time.Now(),
+			})
+			require.NoError(t, errCreate)
+			assert.NotZero(t, created.ID)
+		}
+
+		last, err := st.Audit.GetLastAuditCheckpoint(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), last.EventID)
+		assert.Equal(t, "hash2", last.Hash)
+		assert.Equal(t, []byte{1, 2, 2}, last.Signature)
+
+		list, err := st.Audit.ListAuditCheckpoints(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, int64(1), list[0].EventID)
+	})
+}
+
+// RunSecretTypeStorageTests - tests storage.SecretTypeServerStorage.
+func RunSecretTypeStorageTests(t *testing.T, newStorages NewStorages) {
+	t.Run("Secret types list can be gotten", func(t *testing.T) {
+		st := newStorages(t)
+
+		got, err := st.SecretTypes.GetSecretTypes(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []model.SecretType{
+			{ID: 1, Title: "login/pass", Fields: []model.SecretTypeField{
+				{Name: "Login", Label: "Login", Kind: model.SecretFieldKindText, Required: true},
+				{Name: "Password", Label: "Password", Kind: model.SecretFieldKindText, Required: true, Sensitive: true},
+			}},
+			{ID: 2, Title: "text", Fields: []model.SecretTypeField{
+				{Name: "Text", Label: "Text", Kind: model.SecretFieldKindMultiline, Required: true},
+			}},
+			{ID: 3, Title: "binary", Fields: []model.SecretTypeField{
+				{Name: "File", Label: "File", Kind: model.SecretFieldKindFile, Required: true},
+			}},
+			{ID: 4, Title: "card", Fields: []model.SecretTypeField{
+				{
+					Name: "CardNumber", Label: "Card number", Kind: model.SecretFieldKindText, Required: true,
+					Sensitive: true, Pattern: "^[0-9]{12,19}$",
+				},
+				{
+					Name: "CVV", Label: "CVV", Kind: model.SecretFieldKindText, Required: true, Sensitive: true,
+					Pattern: "^[0-9]{3,4}$",
+				},
+				{
+					Name: "Due", Label: "Due date", Kind: model.SecretFieldKindText, Required: true,
+					Pattern: "^(0[1-9]|1[0-2])/([0-9]{2}|[0-9]{4})$",
+				},
+			}},
+		}, got)
+	})
+}
+
+// RunFolderStorageTests - tests storage.FolderServerStorage and organising of secrets by folders and tags.
+func RunFolderStorageTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	t.Run("Folders and tags can be created and listed by their owner only", func(t *testing.T) {
+		st := newStorages(t)
+		alice, bob := createUser(t, st, "alice"), createUser(t, st, "bob")
+
+		parent := createFolder(t, st, alice, nil, "work")
+		child := createFolder(t, st, alice, &parent.ID, "projects")
+		createFolder(t, st, bob, nil, "home")
+
+		_, err := st.Folders.CreateFolder(ctx, model.Folder{
+			UserID: *bob.ID, ParentID: &parent.ID, Name: []byte("stolen"), CreatedAt: time.Now(),
+		})
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		folders, err := st.Folders.ListFolders(ctx, alice)
+		require.NoError(t, err)
+		require.Len(t, folders, 2)
+		assert.Equal(t, parent.ID, folders[0].ID)
+		assert.Nil(t, folders[0].ParentID)
+		assert.Equal(t, []byte("work"), folders[0].Name)
+		assert.Equal(t, child.ID, folders[1].ID)
+		assert.Equal(t, &parent.ID, folders[1].ParentID)
+
+		createTag(t, st, alice, "personal")
+		createTag(t, st, bob, "shared")
+
+		tags, err := st.Folders.ListTags(ctx, alice)
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		assert.Equal(t, []byte("personal"), tags[0].Name)
+	})
+
+	t.Run("Secrets can be moved and listed by folder", func(t *testing.T) {
+		st := newStorages(t)
+		alice, bob := createUser(t, st, "alice"), createUser(t, st, "bob")
+		folder := createFolder(t, st, alice, nil, "work")
+		bobsFolder := createFolder(t, st, bob, nil, "home")
+		moved, kept := createSecret(t, st, alice, "moved"), createSecret(t, st, alice, "kept")
+
+		got, err := st.Secrets.MoveSecret(ctx, moved, &folder.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &folder.ID, got.FolderID)
+		assert.Equal(t, moved.Version, got.Version)
+
+		_, err = st.Secrets.MoveSecret(ctx, kept, &bobsFolder.ID)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		inFolder, err := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *alice.ID, FolderID: &folder.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"moved"}, titles(inFolder))
+
+		root := 0
+		inRoot, err := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *alice.ID, FolderID: &root})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kept"}, titles(inRoot))
+
+		all, err := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *alice.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"moved", "kept"}, titles(all))
+
+		got, err = st.Secrets.MoveSecret(ctx, moved, nil)
+		require.NoError(t, err)
+		assert.Nil(t, got.FolderID)
+	})
+
+	t.Run("Secrets can be tagged and listed by tag", func(t *testing.T) {
+		st := newStorages(t)
+		alice, bob := createUser(t, st, "alice"), createUser(t, st, "bob")
+		personal, work := createTag(t, st, alice, "personal"), createTag(t, st, alice, "work")
+		bobsTag := createTag(t, st, bob, "shared")
+		tagged, untagged := createSecret(t, st, alice, "tagged"), createSecret(t, st, alice, "untagged")
+
+		got, err := st.Secrets.SetSecretTags(ctx, tagged, []int{work.ID, personal.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []int{personal.ID, work.ID}, got.TagIDs)
+		assert.Equal(t, tagged.Version, got.Version)
+
+		_, err = st.Secrets.SetSecretTags(ctx, untagged, []int{bobsTag.ID})
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		got, err = st.Secrets.GetSecret(ctx, untagged, false)
+		require.NoError(t, err)
+		assert.Empty(t, got.TagIDs)
+
+		list, err := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *alice.ID, TagID: work.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tagged"}, titles(list))
+
+		got, err = st.Secrets.SetSecretTags(ctx, tagged, nil)
+		require.NoError(t, err)
+		assert.Empty(t, got.TagIDs)
+	})
+}
+
+// RunSecretStorageTests - tests storage.SecretServerStorage.
+func RunSecretStorageTests(t *testing.T, newStorages NewStorages) {
+	t.Run("Create", func(t *testing.T) { runCreateSecretTests(t, newStorages) })
+	t.Run("Get", func(t *testing.T) { runGetSecretTests(t, newStorages) })
+	t.Run("Edit", func(t *testing.T) { runEditSecretTests(t, newStorages) })
+	t.Run("Trash", func(t *testing.T) { runTrashTests(t, newStorages) })
+	t.Run("Versions", func(t *testing.T) { runSecretVersionsTests(t, newStorages) })
+	t.Run("List", func(t *testing.T) { runListSecretsTests(t, newStorages) })
+	t.Run("TitleHash", func(t *testing.T) { runTitleHashTests(t, newStorages) })
+	t.Run("Ownership", func(t *testing.T) { runOwnershipTests(t, newStorages) })
+}
+
+func runCreateSecretTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	t.Run("Secret can be created", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		pid := uuid.New()
+
+		got, err := st.Secrets.CreateSecret(ctx, model.Secret{
+			PublicID: pid, UserID: *user.ID, TypeID: 1, Title: "Test", Content: []byte{1, 2},
+			CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		})
+		assert.NoError(t, err)
+		assert.NotZero(t, got.ID)
+		assert.Equal(t, pid, got.PublicID)
+		assert.Equal(t, int64(1), got.Version)
+
+		stored, err := st.Secrets.GetSecret(ctx, model.Secret{ID: got.ID, UserID: *user.ID}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, "Test", stored.Title)
+		assert.Equal(t, []byte{1, 2}, stored.Content)
+	})
+
+	t.Run("Public id is generated when it is not provided", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		got, err := st.Secrets.CreateSecret(ctx, model.Secret{UserID: *user.ID, TypeID: 1, Title: "Test"})
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, got.PublicID)
+	})
+
+	t.Run("Secret creation with already stored public id returns stored secret", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		secret := createSecret(t, st, user, "Test")
+
+		got, err := st.Secrets.CreateSecret(ctx, model.Secret{
+			PublicID: secret.PublicID, UserID: *user.ID, TypeID: 1, Title: "Test retry",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, secret.ID, got.ID)
+		assert.Equal(t, "Test", got.Title)
+	})
+
+	t.Run("Conflict error will be returned when public id belongs to another user", func(t *testing.T) {
+		st := newStorages(t)
+		secret := createSecret(t, st, createUser(t, st, "test"), "Test")
+		other := createUser(t, st, "other")
+
+		_, err := st.Secrets.CreateSecret(ctx, model.Secret{
+			PublicID: secret.PublicID, UserID: *other.ID, TypeID: 1, Title: "Test",
+		})
+		assert.ErrorIs(t, err, apperr.ErrConflict)
+	})
+}
+
+func runTitleHashTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	create := func(st Storages, user model.User, title string, hash []byte) (model.Secret, error) {
+		return st.Secrets.CreateSecret(ctx, model.Secret{
+			UserID: *user.ID, TypeID: 1, Title: title, TitleHash: hash, Content: []byte(title),
+			CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		})
+	}
+	find := func(st Storages, user model.User, hash []byte) []int {
+		secrets, err := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *user.ID, TitleHash: hash})
+		require.NoError(t, err)
+
+		var ids []int
+		for _, secret := range secrets {
+			ids = append(ids, secret.ID)
+		}
+
+		return ids
+	}
+
+	t.Run("Secrets are found by title hash, which may be shared by several of them", func(t *testing.T) {
+		st := newStorages(t)
+		user, other := createUser(t, st, "test"), createUser(t, st, "other")
+
+		first, err := create(st, user, "a", []byte("hash"))
+		require.NoError(t, err)
+
+		second, err := create(st, user, "b", []byte("hash"))
+		require.NoError(t, err, "titles aren't unique, e.g. secrets of different folders can have the same title")
+
+		_, err = create(st, other, "c", []byte("hash"))
+		require.NoError(t, err)
+
+		for _, title := range []string{"d", "e"} {
+			_, err = create(st, user, title, nil)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, []int{first.ID, second.ID}, find(st, user, []byte("hash")))
+		assert.Empty(t, find(st, user, []byte("unknown")))
+	})
+
+	t.Run("Title hash is returned along with secrets, secrets without it have none", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		hashed, err := create(st, user, "a", []byte("hash"))
+		require.NoError(t, err)
+		plain, err := create(st, user, "b", nil)
+		require.NoError(t, err)
+
+		secrets, err := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *user.ID})
+		require.NoError(t, err)
+		require.Len(t, secrets, 2)
+		assert.Equal(t, []byte("hash"), secrets[0].TitleHash)
+		assert.Empty(t, secrets[1].TitleHash)
+
+		got, err := st.Secrets.GetSecret(ctx, hashed, false)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hash"), got.TitleHash)
+
+		got, err = st.Secrets.GetSecret(ctx, plain, false)
+		require.NoError(t, err)
+		assert.Empty(t, got.TitleHash)
+	})
+
+	t.Run("Title hash is replaced along with title", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		secret, err := create(st, user, "a", []byte("a"))
+		require.NoError(t, err)
+		_, err = create(st, user, "b", []byte("b"))
+		require.NoError(t, err)
+
+		_, err = st.Secrets.EditSecret(ctx, model.Secret{
+			ID: secret.ID, UserID: *user.ID, Content: []byte("content"), Version: 1,
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []int{secret.ID}, find(st, user, []byte("a")), "title hash is kept along with title")
+
+		_, err = st.Secrets.EditSecret(ctx, model.Secret{
+			ID: secret.ID, UserID: *user.ID, Title: "c", TitleHash: []byte("c"), Version: 2,
+		}, false)
+		require.NoError(t, err)
+		assert.Empty(t, find(st, user, []byte("a")))
+		assert.Equal(t, []int{secret.ID}, find(st, user, []byte("c")))
+
+		_, err = st.Secrets.RestoreSecretVersion(ctx, model.Secret{ID: secret.ID, UserID: *user.ID}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{secret.ID}, find(st, user, []byte("a")), "title hash is restored along with title")
+	})
+
+	t.Run("Trashed secret is restored regardless of its title hash", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		trashed, err := create(st, user, "a", []byte("hash"))
+		require.NoError(t, err)
+		_, err = st.Secrets.DeleteSecret(ctx, trashed)
+		require.NoError(t, err)
+
+		secret, err := create(st, user, "a", []byte("hash"))
+		require.NoError(t, err)
+
+		_, err = st.Secrets.RestoreSecret(ctx, trashed)
+		require.NoError(t, err)
+		assert.Equal(t, []int{trashed.ID, secret.ID}, find(st, user, []byte("hash")))
+	})
+}
+
+func runGetSecretTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	st := newStorages(t)
+	user := createUser(t, st, "test")
+	other := createUser(t, st, "other")
+	secret := createSecret(t, st, user, "Test")
+	trashed := createSecret(t, st, user, "Trashed")
+	card := createSecretOfType(t, st, user, "Card", 4)
+
+	_, err := st.Secrets.DeleteSecret(ctx, trashed)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		secret      model.Secret
+		withTrashed bool
+		wantTitle   string
+	}{
+		{name: "Secret can be gotten by id", secret: model.Secret{ID: secret.ID, UserID: *user.ID}, wantTitle: "Test"},
+		{
+			name:      "Secret can be gotten by public id",
+			secret:    model.Secret{PublicID: secret.PublicID, UserID: *user.ID},
+			wantTitle: "Test",
+		},
+		{
+			name:      "Public id is preferred to id of another secret",
+			secret:    model.Secret{ID: secret.ID, PublicID: card.PublicID, UserID: *user.ID},
+			wantTitle: "Card",
+		},
+		{name: "Secret of another user can't be gotten", secret: model.Secret{ID: secret.ID, UserID: *other.ID}},
+		{name: "Secret in trash can't be gotten", secret: model.Secret{ID: trashed.ID, UserID: *user.ID}},
+		{
+			name:        "Secret in trash can be gotten with trashed ones",
+			secret:      model.Secret{ID: trashed.ID, UserID: *user.ID},
+			withTrashed: true,
+			wantTitle:   "Trashed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errGet := st.Secrets.GetSecret(ctx, tt.secret, tt.withTrashed)
+			if tt.wantTitle == "" {
+				assert.ErrorIs(t, errGet, pgx.ErrNoRows)
+				return
+			}
+
+			assert.NoError(t, errGet)
+			assert.Equal(t, tt.wantTitle, got.Title)
+			assert.Equal(t, *user.ID, got.UserID)
+		})
+	}
+
+	t.Run("Secrets can be listed by type", func(t *testing.T) {
+		list, errList := st.Secrets.GetListOfSecretByType(ctx, model.SecretType{ID: 1}, user, false)
+		assert.NoError(t, errList)
+		assert.Equal(t, []string{"Test"}, titles(list))
+
+		list, errList = st.Secrets.GetListOfSecretByType(ctx, model.SecretType{ID: 1}, user, true)
+		assert.NoError(t, errList)
+		assert.ElementsMatch(t, []string{"Test", "Trashed"}, titles(list))
+
+		list, errList = st.Secrets.GetListOfSecretByType(ctx, model.SecretType{ID: 4}, user, false)
+		assert.NoError(t, errList)
+		assert.Equal(t, []string{"Card"}, titles(list))
+
+		list, errList = st.Secrets.GetListOfSecretByType(ctx, model.SecretType{ID: 1}, other, false)
+		assert.NoError(t, errList)
+		assert.Empty(t, list)
+	})
+}
+
+func runEditSecretTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		version     int64
+		isForce     bool
+		wantErr     error
+		wantVersion int64
+	}{
+		{name: "Secret can be edited when provided version matches stored one", version: 1, wantVersion: 2},
+		{name: "Secret can be edited regardless of version with force", version: 5, isForce: true, wantVersion: 2},
+		{
+			name:        "Version mismatch error with current version will be returned on outdated version",
+			version:     5,
+			wantErr:     apperr.ErrVersionDoesntMatch,
+			wantVersion: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newStorages(t)
+			user := createUser(t, st, "test")
+			secret := createSecret(t, st, user, "Test")
+
+			got, err := st.Secrets.EditSecret(ctx, model.Secret{
+				ID: secret.ID, UserID: *user.ID, Title: "Test new", Content: []byte{1, 2}, Version: tt.version,
+			}, tt.isForce)
+			assert.Equal(t, tt.wantVersion, got.Version)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "Test new", got.Title)
+			assert.Equal(t, []byte{1, 2}, got.Content)
+			assert.Equal(t, secret.PublicID, got.PublicID)
+			assert.NotEqual(t, time.Time{}, got.UpdatedAt)
+		})
+	}
+
+	t.Run("Title and content are kept if they are not provided", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		secret := createSecret(t, st, user, "Test")
+
+		got, err := st.Secrets.EditSecret(ctx, model.Secret{ID: secret.ID, UserID: *user.ID, Title: "Renamed"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", got.Title)
+
+		_, err = st.Secrets.EditSecret(ctx, model.Secret{ID: secret.ID, UserID: *user.ID, Content: []byte("new")}, true)
+		require.NoError(t, err)
+
+		stored, err := st.Secrets.GetSecret(ctx, model.Secret{ID: secret.ID, UserID: *user.ID}, false)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", stored.Title)
+		assert.Equal(t, []byte("new"), stored.Content)
+		assert.Equal(t, int64(3), stored.Version)
+	})
+
+	t.Run("Not found error will be returned on edit of unknown secret", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		_, err := st.Secrets.EditSecret(ctx, model.Secret{ID: 1, UserID: *user.ID, Title: "Test"}, true)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Concurrent edits of the same version are applied only once", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		secret := createSecret(t, st, user, "Test")
+
+		const editors = 5
+
+		var wg sync.WaitGroup
+		errs := make(chan error, editors)
+
+		for i := 0; i < editors; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := st.Secrets.EditSecret(ctx, model.Secret{
+					ID: secret.ID, UserID: *user.ID, Title: "Test new", Version: 1,
+				}, false)
+				errs <- err
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+
+		var applied int
+		for err := range errs {
+			if err == nil {
+				applied++
+				continue
+			}
+
+			assert.ErrorIs(t, err, apperr.ErrVersionDoesntMatch)
+		}
+		assert.Equal(t, 1, applied)
+
+		stored, err := st.Secrets.GetSecret(ctx, secret, false)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), stored.Version)
+	})
+}
+
+func runOwnershipTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	st := newStorages(t)
+	owner := createUser(t, st, "test")
+	secret := createSecret(t, st, owner, "Test")
+	trashed := createSecret(t, st, owner, "Trashed")
+
+	_, err := st.Secrets.EditSecret(ctx, model.Secret{ID: secret.ID, UserID: *owner.ID, Title: "v2"}, true)
+	require.NoError(t, err)
+
+	_, err = st.Secrets.DeleteSecret(ctx, trashed)
+	require.NoError(t, err)
+
+	other := createUser(t, st, "other")
+	foreign := model.Secret{ID: secret.ID, PublicID: secret.PublicID, UserID: *other.ID}
+	foreignTrashed := model.Secret{ID: trashed.ID, PublicID: trashed.PublicID, UserID: *other.ID}
+
+	tests := []struct {
+		name string
+		do   func() error
+	}{
+		{
+			name: "Secret of another user can't be edited",
+			do: func() error {
+				_, errDo := st.Secrets.EditSecret(ctx, foreign, true)
+				return errDo
+			},
+		},
+		{
+			name: "Secret of another user can't be moved to trash",
+			do: func() error {
+				_, errDo := st.Secrets.DeleteSecret(ctx, foreign)
+				return errDo
+			},
+		},
+		{
+			name: "Secret of another user can't be restored from trash",
+			do: func() error {
+				_, errDo := st.Secrets.RestoreSecret(ctx, foreignTrashed)
+				return errDo
+			},
+		},
+		{
+			name: "Secret of another user can't be purged",
+			do: func() error {
+				_, errDo := st.Secrets.PurgeSecret(ctx, foreignTrashed)
+				return errDo
+			},
+		},
+		{
+			name: "Version of secret of another user can't be gotten",
+			do: func() error {
+				_, errDo := st.Secrets.GetSecretVersion(ctx, foreign, 1)
+				return errDo
+			},
+		},
+		{
+			name: "Secret of another user can't be restored to prior version",
+			do: func() error {
+				_, errDo := st.Secrets.RestoreSecretVersion(ctx, foreign, 1)
+				return errDo
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.do(), pgx.ErrNoRows)
+		})
+	}
+
+	t.Run("Versions of secret of another user are not listed", func(t *testing.T) {
+		versions, errList := st.Secrets.ListSecretVersions(ctx, foreign)
+		assert.NoError(t, errList)
+		assert.Empty(t, versions)
+	})
+
+	t.Run("Trash of another user is not listed", func(t *testing.T) {
+		trash, errList := st.Secrets.ListTrash(ctx, other)
+		assert.NoError(t, errList)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("Secrets of owner are left intact", func(t *testing.T) {
+		stored, errGet := st.Secrets.GetSecret(ctx, secret, false)
+		assert.NoError(t, errGet)
+		assert.Equal(t, int64(2), stored.Version)
+
+		_, errGet = st.Secrets.GetSecret(ctx, trashed, true)
+		assert.NoError(t, errGet)
+	})
+}
+
+func runTrashTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	t.Run("Secret can be moved to trash and restored", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		secret := createSecret(t, st, user, "Test")
+
+		_, err := st.Secrets.DeleteSecret(ctx, secret)
+		assert.NoError(t, err)
+
+		_, err = st.Secrets.DeleteSecret(ctx, secret)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		trash, err := st.Secrets.ListTrash(ctx, user)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Test"}, titles(trash))
+		assert.NotNil(t, trash[0].DeletedAt)
+
+		restored, err := st.Secrets.RestoreSecret(ctx, secret)
+		assert.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+
+		_, err = st.Secrets.RestoreSecret(ctx, secret)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		trash, err = st.Secrets.ListTrash(ctx, user)
+		assert.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("Only secret with public id is trashed and purged if id of another one is provided", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		kept := createSecret(t, st, user, "Kept")
+		trashed := createSecret(t, st, user, "Trashed")
+		ref := model.Secret{ID: kept.ID, PublicID: trashed.PublicID, UserID: *user.ID}
+
+		_, err := st.Secrets.DeleteSecret(ctx, ref)
+		require.NoError(t, err)
+
+		trash, err := st.Secrets.ListTrash(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Trashed"}, titles(trash))
+
+		_, err = st.Secrets.DeleteSecret(ctx, ref)
+		assert.ErrorIs(t, err, pgx.ErrNoRows, "kept secret is not trashed by id")
+
+		_, err = st.Secrets.PurgeSecret(ctx, ref)
+		require.NoError(t, err)
+
+		_, err = st.Secrets.GetSecret(ctx, kept, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Latest deleted secrets go first in trash", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+
+		for _, title := range []string{"First", "Second"} {
+			_, err := st.Secrets.DeleteSecret(ctx, createSecret(t, st, user, title))
+			require.NoError(t, err)
+			time.Sleep(time.Millisecond * 10)
+		}
+
+		trash, err := st.Secrets.ListTrash(ctx, user)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Second", "First"}, titles(trash))
+	})
+
+	t.Run("Only secret in trash can be purged", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		secret := createSecret(t, st, user, "Test")
+
+		_, err := st.Secrets.PurgeSecret(ctx, secret)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		_, err = st.Secrets.DeleteSecret(ctx, secret)
+		require.NoError(t, err)
+
+		_, err = st.Secrets.PurgeSecret(ctx, secret)
+		assert.NoError(t, err)
+
+		_, err = st.Secrets.GetSecret(ctx, secret, true)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Trash is purged of secrets deleted before provided time", func(t *testing.T) {
+		st := newStorages(t)
+		user := createUser(t, st, "test")
+		kept := createSecret(t, st, user, "Kept")
+		purged := createSecret(t, st, user, "Purged")
+
+		_, err := st.Secrets.DeleteSecret(ctx, purged)
+		require.NoError(t, err)
+
+		count, err := st.Secrets.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		count, err = st.Secrets.PurgeTrash(ctx, time.Now().Add(time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		_, err = st.Secrets.GetSecret(ctx, purged, true)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+		_, err = st.Secrets.GetSecret(ctx, kept, false)
+		assert.NoError(t, err)
+	})
+}
+
+func runSecretVersionsTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	st := newStorages(t)
+	user := createUser(t, st, "test")
+	secret := createSecret(t, st, user, "v1")
+
+	for _, title := range []string{"v2", "v3", "v4"} {
+		_, err := st.Secrets.EditSecret(ctx, model.Secret{
+			ID: secret.ID, UserID: *user.ID, Title: title, Content: []byte(title),
+		}, true)
+		require.NoError(t, err)
+	}
+
+	t.Run("Versions are listed from the latest to the oldest one", func(t *testing.T) {
+		versions, err := st.Secrets.ListSecretVersions(ctx, secret)
+		assert.NoError(t, err)
+
+		var got []int64
+		for _, v := range versions {
+			got = append(got, v.Version)
+			assert.Equal(t, secret.PublicID, v.PublicID)
+			assert.Equal(t, *user.ID, v.ChangedBy)
+		}
+		assert.Equal(t, []int64{4, 3, 2, 1}, got)
+	})
+
+	t.Run("Prior version can be gotten", func(t *testing.T) {
+		version, err := st.Secrets.GetSecretVersion(ctx, secret, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", version.Title)
+		assert.Equal(t, []byte("v2"), version.Content)
+	})
+
+	t.Run("Not found error will be returned for unknown version", func(t *testing.T) {
+		_, err := st.Secrets.GetSecretVersion(ctx, secret, 10)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Secret can be restored to prior version as a new one", func(t *testing.T) {
+		got, err := st.Secrets.RestoreSecretVersion(ctx, secret, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), got.Version)
+		assert.Equal(t, "v2", got.Title)
+
+		stored, err := st.Secrets.GetSecret(ctx, secret, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("v2"), stored.Content)
+
+		replaced, err := st.Secrets.GetSecretVersion(ctx, secret, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, "v4", replaced.Title)
+	})
+
+	t.Run("Secret can't be restored to its current version", func(t *testing.T) {
+		_, err := st.Secrets.RestoreSecretVersion(ctx, secret, 5)
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Only latest prior versions are kept on trim", func(t *testing.T) {
+		assert.NoError(t, st.Secrets.TrimSecretVersions(ctx, secret, 2))
+
+		versions, err := st.Secrets.ListSecretVersions(ctx, secret)
+		assert.NoError(t, err)
+		assert.Len(t, versions, 3)
+		assert.Equal(t, int64(3), versions[2].Version)
+	})
+}
+
+// RunTransactorTests - tests storage.Transactor together with storages, which must run their queries in its
+// transaction.
+func runListSecretsTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	st := newStorages(t)
+	user := createUser(t, st, "test")
+	other := createUser(t, st, "other")
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for i, title := range []string{"c", "a", "d", "b"} {
+		_, err := st.Secrets.CreateSecret(ctx, model.Secret{
+			UserID: *user.ID, TypeID: 1 + i%2, Title: title, Content: []byte(title),
+			CreatedAt: now, UpdatedAt: now.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+	trashed := createSecretOfType(t, st, user, "e", 1)
+	_, err := st.Secrets.DeleteSecret(ctx, trashed)
+	require.NoError(t, err)
+	createSecret(t, st, other, "f")
+
+	since := now.Add(2 * time.Minute)
+
+	tests := []struct {
+		name   string
+		filter model.SecretFilter
+		want   []string
+	}{
+		{name: "Active secrets are sorted by id", want: []string{"c", "a", "d", "b"}},
+		{name: "Secrets can be sorted in descending order",
+			filter: model.SecretFilter{Order: model.SecretOrderUpdatedAt, Desc: true}, want: []string{"b", "d", "a", "c"}},
+		{name: "Secrets can be filtered by type", filter: model.SecretFilter{TypeID: 2}, want: []string{"a", "b"}},
+		{name: "Secrets can be filtered by time of update", filter: model.SecretFilter{UpdatedSince: &since},
+			want: []string{"d", "b"}},
+		{name: "Secrets in trash can be listed", filter: model.SecretFilter{Trash: model.SecretsTrashed},
+			want: []string{"e"}},
+		{name: "Secrets can be listed regardless of trash", filter: model.SecretFilter{Trash: model.SecretsAll},
+			want: []string{"c", "a", "d", "b", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.UserID = *user.ID
+
+			list, errList := st.Secrets.ListSecrets(ctx, tt.filter)
+			assert.NoError(t, errList)
+			assert.Equal(t, tt.want, titles(list))
+		})
+	}
+
+	for _, order := range []model.SecretOrder{model.SecretOrderID, model.SecretOrderUpdatedAt} {
+		for _, desc := range []bool{false, true} {
+			t.Run(fmt.Sprintf("Secrets are paged by %s desc=%t", order, desc), func(t *testing.T) {
+				filter := model.SecretFilter{UserID: *user.ID, Order: order, Desc: desc, Trash: model.SecretsAll}
+
+				all, errList := st.Secrets.ListSecrets(ctx, filter)
+				require.NoError(t, errList)
+
+				var paged []model.Secret
+				filter.Limit = 2
+				for pages := 1; ; pages++ {
+					require.LessOrEqual(t, pages, 3)
+
+					page, errPage := st.Secrets.ListSecrets(ctx, filter)
+					require.NoError(t, errPage)
+
+					paged = append(paged, page...)
+					if len(page) < filter.Limit {
+						break
+					}
+
+					cursor := model.NewSecretCursor(page[len(page)-1])
+					filter.After = &cursor
+				}
+
+				assert.Equal(t, titles(all), titles(paged))
+			})
+		}
+	}
+
+	t.Run("Content of secrets can be omitted", func(t *testing.T) {
+		list, errList := st.Secrets.ListSecrets(ctx, model.SecretFilter{UserID: *user.ID, WithoutContent: true})
+		require.NoError(t, errList)
+		require.Len(t, list, 4)
+
+		for _, secret := range list {
+			assert.Nil(t, secret.Content)
+			assert.NotZero(t, secret.Version)
+		}
+	})
+}
+
+func RunTransactorTests(t *testing.T, newStorages NewStorages) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		fn       func(st Storages) func(ctx context.Context) error
+		wantErr  bool
+		wantUser bool
+	}{
+		{
+			name: "Changes are committed if function succeeds",
+			fn: func(st Storages) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					_, err := st.Users.Create(ctx, model.User{Login: "test", Password: "test"})
+					return err
+				}
+			},
+			wantUser: true,
+		},
+		{
+			name: "Changes are rolled back if function fails",
+			fn: func(st Storages) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					if _, err := st.Users.Create(ctx, model.User{Login: "test", Password: "test"}); err != nil {
+						return err
+					}
+
+					return errors.New("test")
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Nested transaction joins outer one",
+			fn: func(st Storages) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					errNested := st.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+						_, err := st.Users.Create(ctx, model.User{Login: "test", Password: "test"})
+						return err
+					})
+					if errNested != nil {
+						return errNested
+					}
+
+					return errors.New("test")
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newStorages(t)
+
+			err := st.Transactor.WithinTransaction(ctx, tt.fn(st))
+			assert.Equal(t, tt.wantErr, err != nil)
+
+			_, err = st.Users.GetByLoginAndPassword(ctx, model.User{Login: "test", Password: "test"})
+			assert.Equal(t, tt.wantUser, err == nil)
+		})
+	}
+}
+
+// createUser - creates a user with provided login and "test" password.
+func createUser(t *testing.T, st Storages, login string) model.User {
+	t.Helper()
+
+	user, err := st.Users.Create(context.Background(), model.User{Login: login, Password: "test"})
+	require.NoError(t, err)
+
+	return user
+}
+
+// createSecret - creates a login/pass secret of user with provided title.
+func createSecret(t *testing.T, st Storages, user model.User, title string) model.Secret {
+	t.Helper()
+
+	return createSecretOfType(t, st, user, title, 1)
+}
+
+// createSecretOfType - creates a secret of user with provided title and type.
+func createSecretOfType(t *testing.T, st Storages, user model.User, title string, typeID int) model.Secret {
+	t.Helper()
+
+	secret, err := st.Secrets.CreateSecret(context.Background(), model.Secret{
+		UserID: *user.ID, TypeID: typeID, Title: title, Content: []byte(title),
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	return secret
+}
+
+// createFolder - creates a folder of user with provided parent and name.
+func createFolder(t *testing.T, st Storages, user model.User, parentID *int, name string) model.Folder {
+	t.Helper()
+
+	folder, err := st.Folders.CreateFolder(context.Background(), model.Folder{
+		UserID: *user.ID, ParentID: parentID, Name: []byte(name), CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	return folder
+}
+
+// createTag - creates a tag of user with provided name.
+func createTag(t *testing.T, st Storages, user model.User, name string) model.Tag {
+	t.Helper()
+
+	tag, err := st.Folders.CreateTag(context.Background(), model.Tag{
+		UserID: *user.ID, Name: []byte(name), CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	return tag
+}
+
+// targets - returns targets of audit events.
+func targets(events []model.AuditEvent) []string {
+	var list []string
+	for _, e := range events {
+		list = append(list, e.Target)
+	}
+
+	return list
+}
+
+// titles - returns titles of secrets.
+func titles(secrets []model.Secret) []string {
+	var list []string
+	for _, s := range secrets {
+		list = append(list, s.Title)
+	}
+
+	return list
+}
@@ -6,7 +6,7 @@ import (
 )
 
 var (
-	ErrInvalidInput         = errors.New("invalid input")
-	ErrConflict             = fmt.Errorf("conflict: %w", ErrInvalidInput)
-	ErrUpdatedAtDoesntMatch = fmt.Errorf("could not update secrete. Local data doesn't match with server")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrConflict           = fmt.Errorf("conflict: %w", ErrInvalidInput)
+	ErrVersionDoesntMatch = fmt.Errorf("could not update secrete. Local version doesn't match with server")
 )
@@ -4,29 +4,152 @@ package crypt
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 )
 
 type Crypter interface {
 	Encode(payload string) string
 	Decode(sha string) (string, error)
+	BlindIndex(payload string) []byte
+	// Legacy - reports whether sha is sealed by legacy key of the binary with legacy nonce, so its payload has to be
+	// encoded again. Sha, which can't be opened by any key, isn't legacy.
+	Legacy(sha string) bool
 }
 
+var (
+	// ErrLocked - is returned on decoding by UserCrypt, which isn't unlocked by password of a user.
+	ErrLocked = errors.New("crypt is locked, login is required")
+	// ErrVaultKey - is returned on unlocking by key of a vault, which can't be unwrapped by provided password, e.g. it
+	// was wrapped by password, which was reset since.
+	ErrVaultKey = errors.New("key of the vault can't be unwrapped by the password")
+)
+
 var (
 	key = []byte{4, 51, 71, 14, 63, 8, 95, 100, 44, 4, 19, 85, 57, 54, 23, 54, 26, 59, 24, 44, 47, 52, 63, 1, 84, 24,
 		23, 51, 3, 88, 72, 73}
-	nonce = []byte{4, 51, 71, 14, 63, 8, 95, 100, 44, 4, 19, 85}
+	// legacyNonce - is a nonce, which every payload was sealed with before nonces became random. It's used to open
+	// such payloads only.
+	legacyNonce = []byte{4, 51, 71, 14, 63, 8, 95, 100, 44, 4, 19, 85}
+	// blindIndexLabel - separates key of blind indexes from key of encryption.
+	blindIndexLabel = []byte("blind index")
+	// encryptionLabel - separates key of encryption from key of blind indexes.
+	encryptionLabel = []byte("encryption")
+	// saltLabel - separates salts of users of this application from salts of other ones with the same login.
+	saltLabel = []byte("gophkeeper user salt")
+	// wrapLabel - separates key, which wraps key of a vault, from keys derived before keys of vaults became random.
+	wrapLabel = []byte("vault key wrapping")
 )
 
+// Parameters of argon2id, by which master key of a user is derived from its password.
+const (
+	kdfTime    = 1
+	kdfMemory  = 64 * 1024
+	kdfThreads = 4
+	kdfKeySize = 32
+)
+
+// vaultKeySize - is a size of key of a vault: key of encryption followed by key of blind indexes.
+const vaultKeySize = 2 * kdfKeySize
+
 type crypt struct {
 	aesGCM   cipher.AEAD
-	aesBlock cipher.Block
-	nonce    []byte
+	indexKey []byte
+	// legacyGCM - opens payloads sealed by key of the binary with legacyNonce, it's nil if there are none.
+	legacyGCM cipher.AEAD
 }
 
-// NewCrypt - creates new Crypter instance.
+// NewCrypt - creates new Crypter instance with a key of the binary. It's used for data, which is encoded and decoded
+// by server itself, such as tokens, data of users is encoded by UserCrypt.
 func NewCrypt() (*crypt, error) {
+	aesGCM, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crypt{aesGCM: aesGCM, indexKey: mac(key, blindIndexLabel), legacyGCM: aesGCM}, nil
+}
+
+// newVaultCrypt - creates crypt with keys of encryption and blind indexes of a vault, payloads sealed by key of the
+// binary before keys became per-user are opened as well.
+func newVaultCrypt(vaultKey []byte) (*crypt, error) {
+	if len(vaultKey) != vaultKeySize {
+		return nil, fmt.Errorf("key of the vault has size %d instead of %d", len(vaultKey), vaultKeySize)
+	}
+
+	aesGCM, err := newGCM(vaultKey[:kdfKeySize])
+	if err != nil {
+		return nil, err
+	}
+
+	legacyGCM, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crypt{aesGCM: aesGCM, indexKey: vaultKey[kdfKeySize:], legacyGCM: legacyGCM}, nil
+}
+
+// masterKey - derives master key of a user from its login and password by argon2id.
+func masterKey(login, password string) []byte {
+	salt := mac(saltLabel, []byte(login))
+
+	return argon2.IDKey([]byte(password), salt, kdfTime, kdfMemory, kdfThreads, kdfKeySize)
+}
+
+// NewVaultKey - returns random key of a vault of a new user.
+func NewVaultKey() ([]byte, error) {
+	vaultKey := make([]byte, vaultKeySize)
+	if _, err := rand.Read(vaultKey); err != nil {
+		return nil, fmt.Errorf("vault key generating error: %w", err)
+	}
+
+	return vaultKey, nil
+}
+
+// DerivedVaultKey - returns key of a vault derived from login and password of a user. Data of users registered before
+// keys of vaults became random is encrypted by it, so it becomes their key of a vault.
+func DerivedVaultKey(login, password string) []byte {
+	master := masterKey(login, password)
+
+	return append(mac(master, encryptionLabel), mac(master, blindIndexLabel)...)
+}
+
+// WrapVaultKey - seals key of a vault by key derived from login and password of a user, so the server keeps it
+// without being able to open it. Changing password wraps the same key again, data stays encrypted by it.
+func WrapVaultKey(login, password string, vaultKey []byte) ([]byte, error) {
+	kek, err := newGCM(mac(masterKey(login, password), wrapLabel))
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(kek, vaultKey)
+}
+
+// unwrapVaultKey - opens key of a vault wrapped by WrapVaultKey, or returns ErrVaultKey if password doesn't open it.
+func unwrapVaultKey(login, password string, wrapped []byte) ([]byte, error) {
+	kek, err := newGCM(mac(masterKey(login, password), wrapLabel))
+	if err != nil {
+		return nil, err
+	}
+
+	vaultKey, err := open(kek, wrapped)
+	if err != nil {
+		return nil, ErrVaultKey
+	}
+
+	return vaultKey, nil
+}
+
+// newGCM - creates AES-GCM cipher with provided key.
+func newGCM(key []byte) (cipher.AEAD, error) {
 	aesBlock, errBlock := aes.NewCipher(key)
 	if errBlock != nil {
 		return nil, fmt.Errorf("error in creating new cipher: %w", errBlock)
@@ -37,36 +160,195 @@ func NewCrypt() (*crypt, error) {
 		return nil, fmt.Errorf("error in creating GCM: %w", errGCM)
 	}
 
-	return &crypt{
-		aesGCM:   aesGCM,
-		aesBlock: aesBlock,
-		nonce:    nonce,
-	}, nil
-
+	return aesGCM, nil
 }
 
-// Encode - returns sha of sealed payload by aesGCM.
+// Encode - returns sha of payload sealed by aesGCM with random nonce, which prefixes sealed payload.
 func (c *crypt) Encode(payload string) string {
-	src := []byte(payload)
-
-	dst := c.aesGCM.Seal(nil, c.nonce, src, nil)
-
-	sha := hex.EncodeToString(dst)
+	dst, err := seal(c.aesGCM, []byte(payload))
+	if err != nil {
+		// payload can't be sealed safely without random nonce
+		panic(err.Error())
+	}
 
-	return sha
+	return hex.EncodeToString(dst)
 }
 
-// Decode - returns decoded string by aesGCM from sha.
+// Decode - returns decoded string by aesGCM from sha, sha sealed by legacy key with legacy nonce is decoded as well.
 func (c *crypt) Decode(sha string) (string, error) {
 	dst, errDecode := hex.DecodeString(sha)
 	if errDecode != nil {
 		return "", fmt.Errorf("hex decode error: %w", errDecode)
 	}
 
-	src, errGCM := c.aesGCM.Open(nil, c.nonce, dst, nil)
+	src, errGCM := open(c.aesGCM, dst)
+	if errGCM != nil && c.legacyGCM != nil {
+		src, errGCM = c.legacyGCM.Open(nil, legacyNonce, dst, nil)
+	}
 	if errGCM != nil {
-		return "", errDecode
+		return "", fmt.Errorf("gcm open error: %w", errGCM)
 	}
 
 	return string(src), nil
 }
+
+// Legacy - reports whether sha is opened by legacyGCM with legacy nonce only, see Crypter.
+func (c *crypt) Legacy(sha string) bool {
+	dst, err := hex.DecodeString(sha)
+	if err != nil || c.legacyGCM == nil {
+		return false
+	}
+
+	if _, err = open(c.aesGCM, dst); err == nil {
+		return false
+	}
+
+	_, err = c.legacyGCM.Open(nil, legacyNonce, dst, nil)
+
+	return err == nil
+}
+
+// seal - seals payload by aead with random nonce, which prefixes sealed payload.
+func seal(aead cipher.AEAD, payload []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce generating error: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, payload, nil), nil
+}
+
+// open - opens payload sealed by aead, which is prefixed by its nonce.
+func open(aead cipher.AEAD, dst []byte) ([]byte, error) {
+	size := aead.NonceSize()
+	if len(dst) < size {
+		return nil, errors.New("sealed payload is shorter than nonce")
+	}
+
+	return aead.Open(nil, dst[:size], dst[size:], nil)
+}
+
+// BlindIndex - returns keyed hash of payload, which is equal for equal payloads and doesn't reveal them.
+func (c *crypt) BlindIndex(payload string) []byte {
+	return mac(c.indexKey, []byte(payload))
+}
+
+// UserCrypt - is a Crypter of data of a user, which is encrypted by key of a vault of the user. Server keeps the key
+// wrapped by key derived from login and password of the user by argon2id, so neither the database nor the binary is
+// enough to decrypt the data or to confirm guessed titles by their blind indexes.
+//
+// UserCrypt is locked until it's unlocked on login: it encodes nothing, so payload is encoded to an empty string,
+// and decoding fails with ErrLocked.
+type UserCrypt struct {
+	mu       sync.RWMutex
+	crypt    *crypt
+	vaultKey []byte
+}
+
+var _ Crypter = (*UserCrypt)(nil)
+
+// NewUserCrypt - creates locked UserCrypt.
+func NewUserCrypt() *UserCrypt {
+	return &UserCrypt{}
+}
+
+// Unlock - unwraps key of a vault of a user by its login and password, see WrapVaultKey.
+//
+// Key of the vault can be unwrapped by the password, which wrapped it, only, so data becomes unreadable after password
+// is reset by admin, who doesn't know the previous one.
+func (u *UserCrypt) Unlock(login, password string, wrappedKey []byte) error {
+	vaultKey, err := unwrapVaultKey(login, password, wrappedKey)
+	if err != nil {
+		return err
+	}
+
+	c, err := newVaultCrypt(vaultKey)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.crypt, u.vaultKey = c, vaultKey
+
+	return nil
+}
+
+// Rewrap - returns key of a vault of unlocked user wrapped by its new password, see WrapVaultKey, or ErrLocked if
+// UserCrypt is locked.
+func (u *UserCrypt) Rewrap(login, password string) ([]byte, error) {
+	u.mu.RLock()
+	vaultKey := u.vaultKey
+	u.mu.RUnlock()
+
+	if vaultKey == nil {
+		return nil, ErrLocked
+	}
+
+	return WrapVaultKey(login, password, vaultKey)
+}
+
+// Lock - forgets key of a vault of a user.
+func (u *UserCrypt) Lock() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.crypt, u.vaultKey = nil, nil
+}
+
+// Encode - returns sha of sealed payload, see crypt.Encode, or an empty string if UserCrypt is locked.
+func (u *UserCrypt) Encode(payload string) string {
+	c := u.current()
+	if c == nil {
+		return ""
+	}
+
+	return c.Encode(payload)
+}
+
+// Decode - returns decoded string from sha, see crypt.Decode, or ErrLocked if UserCrypt is locked.
+func (u *UserCrypt) Decode(sha string) (string, error) {
+	c := u.current()
+	if c == nil {
+		return "", ErrLocked
+	}
+
+	return c.Decode(sha)
+}
+
+// BlindIndex - returns keyed hash of payload, see crypt.BlindIndex, or nil if UserCrypt is locked.
+func (u *UserCrypt) BlindIndex(payload string) []byte {
+	c := u.current()
+	if c == nil {
+		return nil
+	}
+
+	return c.BlindIndex(payload)
+}
+
+// Legacy - reports whether sha has to be encoded again, see Crypter. Nothing is legacy while UserCrypt is locked.
+func (u *UserCrypt) Legacy(sha string) bool {
+	c := u.current()
+	if c == nil {
+		return false
+	}
+
+	return c.Legacy(sha)
+}
+
+// current - returns crypt of unlocked user, or nil if UserCrypt is locked.
+func (u *UserCrypt) current() *crypt {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	return u.crypt
+}
+
+// mac - returns HMAC-SHA256 of data with key.
+func mac(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+
+	return h.Sum(nil)
+}
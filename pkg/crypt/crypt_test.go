@@ -0,0 +1,92 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserCrypt(t *testing.T) {
+	alice := NewUserCrypt()
+
+	assert.Empty(t, alice.Encode("payload"), "locked crypt encodes nothing")
+	_, err := alice.Decode("00")
+	assert.ErrorIs(t, err, ErrLocked)
+
+	vaultKey, err := NewVaultKey()
+	require.NoError(t, err)
+	wrapped, err := WrapVaultKey("alice", "secret", vaultKey)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, alice.Unlock("alice", "guess", wrapped), ErrVaultKey)
+	require.NoError(t, alice.Unlock("alice", "secret", wrapped))
+
+	sealed := alice.Encode("payload")
+	assert.NotEqual(t, sealed, alice.Encode("payload"), "nonce is random")
+	assert.False(t, alice.Legacy(sealed))
+
+	decoded, err := alice.Decode(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", decoded)
+
+	rewrapped, err := alice.Rewrap("alice", "changed")
+	require.NoError(t, err)
+
+	changed := NewUserCrypt()
+	require.NoError(t, changed.Unlock("alice", "changed", rewrapped))
+
+	decoded, err = changed.Decode(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", decoded, "password is changed by wrapping the same key again")
+	assert.Equal(t, alice.BlindIndex("title"), changed.BlindIndex("title"))
+
+	bobKey, err := NewVaultKey()
+	require.NoError(t, err)
+	bobWrapped, err := WrapVaultKey("bob", "secret", bobKey)
+	require.NoError(t, err)
+
+	bob := NewUserCrypt()
+	require.NoError(t, bob.Unlock("bob", "secret", bobWrapped))
+
+	_, err = bob.Decode(sealed)
+	assert.Error(t, err, "payload of another user can't be decoded")
+	assert.NotEqual(t, alice.BlindIndex("title"), bob.BlindIndex("title"))
+
+	binary, err := NewCrypt()
+	require.NoError(t, err)
+	assert.NotEqual(t, binary.BlindIndex("title"), alice.BlindIndex("title"), "index key isn't the key of the binary")
+
+	legacy := hex.EncodeToString(binary.aesGCM.Seal(nil, legacyNonce, []byte("legacy"), nil))
+
+	decoded, err = alice.Decode(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", decoded, "payload sealed before keys became per-user is decoded")
+	assert.True(t, alice.Legacy(legacy))
+	assert.False(t, alice.Legacy("plain title"), "payload, which isn't sealed, isn't legacy")
+	assert.False(t, alice.Legacy(bob.Encode("payload")), "payload, which can't be opened, isn't legacy")
+
+	derived, err := WrapVaultKey("alice", "secret", DerivedVaultKey("alice", "secret"))
+	require.NoError(t, err)
+
+	registered := NewUserCrypt()
+	require.NoError(t, registered.Unlock("alice", "secret", derived))
+
+	master := masterKey("alice", "secret")
+	aesGCM, err := newGCM(mac(master, encryptionLabel))
+	require.NoError(t, err)
+	before, err := seal(aesGCM, []byte("before vault keys"))
+	require.NoError(t, err)
+
+	decoded, err = registered.Decode(hex.EncodeToString(before))
+	require.NoError(t, err)
+	assert.Equal(t, "before vault keys", decoded, "data of users registered before vault keys is readable")
+	assert.Equal(t, mac(mac(master, blindIndexLabel), []byte("title")), registered.BlindIndex("title"))
+
+	alice.Lock()
+	_, err = alice.Decode(sealed)
+	assert.ErrorIs(t, err, ErrLocked)
+	_, err = alice.Rewrap("alice", "changed")
+	assert.ErrorIs(t, err, ErrLocked)
+}
@@ -33,6 +33,20 @@ func (m *MockCrypter) EXPECT() *MockCrypterMockRecorder {
 	return m.recorder
 }
 
+// BlindIndex mocks base method.
+func (m *MockCrypter) BlindIndex(payload string) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlindIndex", payload)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// BlindIndex indicates an expected call of BlindIndex.
+func (mr *MockCrypterMockRecorder) BlindIndex(payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlindIndex", reflect.TypeOf((*MockCrypter)(nil).BlindIndex), payload)
+}
+
 // Decode mocks base method.
 func (m *MockCrypter) Decode(sha string) (string, error) {
 	m.ctrl.T.Helper()
@@ -61,3 +75,17 @@ func (mr *MockCrypterMockRecorder) Encode(payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encode", reflect.TypeOf((*MockCrypter)(nil).Encode), payload)
 }
+
+// Legacy mocks base method.
+func (m *MockCrypter) Legacy(sha string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Legacy", sha)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Legacy indicates an expected call of Legacy.
+func (mr *MockCrypterMockRecorder) Legacy(sha interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Legacy", reflect.TypeOf((*MockCrypter)(nil).Legacy), sha)
+}
@@ -16,13 +16,16 @@ type JWT struct {
 
 type Claims struct {
 	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
 }
 
 type Manager interface {
-	// Issue a new token for a given id with exp time from env
-	Issue(id string) (string, error)
+	// Issue a new token for a given id and role with exp time from env
+	Issue(id, role string) (string, error)
 	// Decode provided token to the uuid
 	Decode(token string) (string, error)
+	// DecodeClaims decodes provided token to its claims
+	DecodeClaims(token string) (Claims, error)
 }
 
 // NewJWT - creates new instance of JWT.
@@ -39,7 +42,7 @@ func NewJWT(secret, validDays string) (*JWT, error) {
 }
 
 // Issue - implementation of Manager.Issue.
-func (j *JWT) Issue(id string) (string, error) {
+func (j *JWT) Issue(id, role string) (string, error) {
 	now := time.Now()
 	exp := now.Add(j.exp)
 
@@ -50,6 +53,7 @@ func (j *JWT) Issue(id string) (string, error) {
 			NotBefore: jwt.NewNumericDate(now),
 			ID:        id,
 		},
+		Role: role,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, data)
@@ -59,17 +63,27 @@ func (j *JWT) Issue(id string) (string, error) {
 
 // Decode - implementation of Manager.Decode.
 func (j *JWT) Decode(decode string) (string, error) {
+	payload, err := j.DecodeClaims(decode)
+	if err != nil {
+		return "", err
+	}
+
+	return payload.ID, nil
+}
+
+// DecodeClaims - implementation of Manager.DecodeClaims.
+func (j *JWT) DecodeClaims(decode string) (Claims, error) {
 	payload := &Claims{}
 	_, err := jwt.ParseWithClaims(decode, payload, j.parseKey)
 	if err != nil {
-		return "", fmt.Errorf("token parse: %w", err)
+		return Claims{}, fmt.Errorf("token parse: %w", err)
 	}
 
 	if payload.Valid() != nil {
-		return "", jwt.ErrTokenInvalidClaims
+		return Claims{}, jwt.ErrTokenInvalidClaims
 	}
 
-	return payload.ID, nil
+	return *payload, nil
 }
 
 // parseKey - validates token signing method and alg, then return JWT.key.
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	jwt "github.com/sergalkin/gophkeeper/pkg/jwt"
 )
 
 // MockManager is a mock of Manager interface.
@@ -48,17 +49,32 @@ func (mr *MockManagerMockRecorder) Decode(token interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decode", reflect.TypeOf((*MockManager)(nil).Decode), token)
 }
 
+// DecodeClaims mocks base method.
+func (m *MockManager) DecodeClaims(token string) (jwt.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeClaims", token)
+	ret0, _ := ret[0].(jwt.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecodeClaims indicates an expected call of DecodeClaims.
+func (mr *MockManagerMockRecorder) DecodeClaims(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeClaims", reflect.TypeOf((*MockManager)(nil).DecodeClaims), token)
+}
+
 // Issue mocks base method.
-func (m *MockManager) Issue(id string) (string, error) {
+func (m *MockManager) Issue(id, role string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Issue", id)
+	ret := m.ctrl.Call(m, "Issue", id, role)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Issue indicates an expected call of Issue.
-func (mr *MockManagerMockRecorder) Issue(id interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) Issue(id, role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockManager)(nil).Issue), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockManager)(nil).Issue), id, role)
 }
@@ -1,16 +1,42 @@
 package migrations
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/golang-migrate/migrate/v4/source/github"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 
 	"github.com/sergalkin/gophkeeper/internal/server/config"
+	"github.com/sergalkin/gophkeeper/internal/server/migrations"
 )
 
+var (
+	// ErrDirty - is returned when last migration of database failed, so its schema has to be fixed manually and then
+	// its version set via Force.
+	ErrDirty = errors.New("database schema is dirty")
+	// ErrUnknownVersion - is returned when database is migrated to a version, which is unknown to the server, most
+	// likely by a newer server.
+	ErrUnknownVersion = errors.New("database schema version is newer than known one")
+	// ErrNoMigrations - is returned for storage driver, which has no schema.
+	ErrNoMigrations = errors.New("storage driver has no migrations")
+)
+
+// Status - is a state of database schema.
+type Status struct {
+	// Version - is a version of last applied migration, 0 if no migrations are applied.
+	Version uint
+	// Dirty - is true if last migration failed.
+	Dirty bool
+	// Latest - is a version of the latest migration known to the server.
+	Latest uint
+}
+
 type migrationManager struct {
 	cfg config.Config
 }
@@ -20,15 +46,82 @@ func NewMigrationManager(c config.Config) *migrationManager {
 	return &migrationManager{cfg: c}
 }
 
-// Up - applies migrations to database via DSN from config.
+// Up - applies migrations, which are not applied yet, to database of storage driver from config.
+//
+// Refuses to migrate dirty database or database with version newer than the latest known migration.
 func (m *migrationManager) Up() error {
-	migration, err := migrate.New("file://internal/server/migrations", m.cfg.DSN)
+	migration, latest, err := m.open(m.cfg.DSN)
 	if err != nil {
-		return fmt.Errorf("mingrate.New error: %w", err)
+		return err
 	}
 	defer migration.Close()
 
-	migration.Up()
+	if err = check(migration, latest); err != nil {
+		return err
+	}
+
+	if err = migration.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("error in migrating db: %w", err)
+	}
+
+	return nil
+}
+
+// Down - rolls back provided number of applied migrations, all of them if steps is 0.
+func (m *migrationManager) Down(steps int) error {
+	migration, latest, err := m.open(m.cfg.DSN)
+	if err != nil {
+		return err
+	}
+	defer migration.Close()
+
+	if err = check(migration, latest); err != nil {
+		return err
+	}
+
+	if steps == 0 {
+		err = migration.Down()
+	} else {
+		err = migration.Steps(-steps)
+	}
+
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("error in rolling back db migrations: %w", err)
+	}
+
+	return nil
+}
+
+// Status - returns state of database schema.
+func (m *migrationManager) Status() (Status, error) {
+	migration, latest, err := m.open(m.cfg.DSN)
+	if err != nil {
+		return Status{}, err
+	}
+	defer migration.Close()
+
+	version, dirty, err := migration.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, fmt.Errorf("error in getting db version: %w", err)
+	}
+
+	return Status{Version: version, Dirty: dirty, Latest: latest}, nil
+}
+
+// Force - sets version of database schema without running migrations and resets its dirty state, -1 means that no
+// migrations are applied.
+//
+// Should be used only after schema of dirty database is fixed manually.
+func (m *migrationManager) Force(version int) error {
+	migration, _, err := m.open(m.cfg.DSN)
+	if err != nil {
+		return err
+	}
+	defer migration.Close()
+
+	if err = migration.Force(version); err != nil {
+		return fmt.Errorf("error in forcing db version: %w", err)
+	}
 
 	return nil
 }
@@ -46,9 +139,9 @@ func (m *migrationManager) RefreshTest() error {
 
 // DropTest - drops all tables in testing database.
 func (m *migrationManager) DropTest() error {
-	migration, err := migrate.New("file://../../../../internal/server/migrations", m.cfg.DSNTest)
+	migration, _, err := m.open(m.cfg.DSNTest)
 	if err != nil {
-		return fmt.Errorf("mingrate.New error: %w", err)
+		return err
 	}
 	defer migration.Close()
 
@@ -61,9 +154,9 @@ func (m *migrationManager) DropTest() error {
 
 // UpTest - runs migrations against testing database.
 func (m *migrationManager) UpTest() error {
-	migration, err := migrate.New("file://../../../../internal/server/migrations", m.cfg.DSNTest)
+	migration, _, err := m.open(m.cfg.DSNTest)
 	if err != nil {
-		return fmt.Errorf("mingrate.New error: %w", err)
+		return err
 	}
 	defer migration.Close()
 
@@ -73,3 +166,85 @@ func (m *migrationManager) UpTest() error {
 
 	return nil
 }
+
+// open - creates migrate.Migrate with embedded migrations of storage driver from config and returns it with version
+// of the latest of them.
+//
+// Postgres database is reached via provided DSN, SQLite one via path from config.
+func (m *migrationManager) open(dsn string) (*migrate.Migrate, uint, error) {
+	var fsys fs.FS
+	var dir string
+
+	switch m.cfg.StorageDriver {
+	case config.StorageDriverPostgres:
+		fsys, dir = migrations.Postgres, "."
+	case config.StorageDriverSQLite:
+		fsys, dir, dsn = migrations.SQLite, "sqlite", "sqlite://"+m.cfg.SQLitePath
+	default:
+		return nil, 0, fmt.Errorf("%w: %q", ErrNoMigrations, m.cfg.StorageDriver)
+	}
+
+	src, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("migrations source error: %w", err)
+	}
+
+	latest, err := latestVersion(src)
+	if err != nil {
+		src.Close()
+
+		return nil, 0, err
+	}
+
+	migration, err := migrate.NewWithSourceInstance("iofs", src, dsn)
+	if err != nil {
+		src.Close()
+
+		return nil, 0, fmt.Errorf("mingrate.New error: %w", err)
+	}
+
+	return migration, latest, nil
+}
+
+// latestVersion - returns version of the latest migration of source.
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("error in reading migrations: %w", err)
+	}
+
+	for {
+		next, errNext := src.Next(version)
+		if errors.Is(errNext, os.ErrNotExist) {
+			return version, nil
+		}
+
+		if errNext != nil {
+			return 0, fmt.Errorf("error in reading migrations: %w", errNext)
+		}
+
+		version = next
+	}
+}
+
+// check - returns error if database is dirty or its version is newer than the latest known migration.
+func check(migration *migrate.Migrate, latest uint) error {
+	version, dirty, err := migration.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error in getting db version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("%w: migration %d failed, fix schema and then force its version", ErrDirty, version)
+	}
+
+	if version > latest {
+		return fmt.Errorf("%w: %d, latest known is %d", ErrUnknownVersion, version, latest)
+	}
+
+	return nil
+}
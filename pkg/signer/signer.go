@@ -0,0 +1,89 @@
+//go:generate mockgen -source=./signer.go -destination=./mock/signer.go -package=signermock
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSignature - is returned when signature doesn't match signed data.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+type Signer interface {
+	// Sign - returns signature of data.
+	Sign(data []byte) ([]byte, error)
+	// Verify - returns ErrInvalidSignature if signature is not a signature of data.
+	Verify(data, signature []byte) error
+}
+
+var _ Signer = (*keyPairSigner)(nil)
+
+type keyPairSigner struct {
+	key       crypto.Signer
+	publicKey crypto.PublicKey
+}
+
+// NewKeyPairSigner - creates new Signer, which signs data with private key and verifies signatures with public key of
+// certificate, both are read from PEM files by provided paths.
+//
+// RSA, ECDSA and Ed25519 keys are supported.
+func NewKeyPairSigner(certPath, keyPath string) (*keyPairSigner, error) {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error in loading key pair: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("error in parsing certificate: %w", err)
+	}
+
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T can't sign", pair.PrivateKey)
+	}
+
+	return &keyPairSigner{key: key, publicKey: cert.PublicKey}, nil
+}
+
+// Sign - returns signature of sha256 hash of data, Ed25519 key signs data itself.
+func (s *keyPairSigner) Sign(data []byte) ([]byte, error) {
+	if _, ok := s.key.Public().(ed25519.PublicKey); ok {
+		return s.key.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(data)
+
+	return s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// Verify - returns ErrInvalidSignature if signature is not a signature of data made by Sign.
+func (s *keyPairSigner) Verify(data, signature []byte) error {
+	digest := sha256.Sum256(data)
+
+	var valid bool
+	switch key := s.publicKey.(type) {
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, data, signature)
+	default:
+		return fmt.Errorf("public key of type %T is not supported", s.publicKey)
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/sergalkin/gophkeeper/internal/server/config"
 	"github.com/sergalkin/gophkeeper/pkg/migrations"
@@ -30,9 +31,32 @@ func CreatePostgresTestConn() *pgx.Conn {
 	return con
 }
 
+// CreatePostgresTestPool - creates *pgxpool.Pool with testing database.
+//
+// DSN to testing database is gotten from config.
+func CreatePostgresTestPool() *pgxpool.Pool {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+
+	pool, err := pgxpool.Connect(ctx, cfg.DSNTest)
+	if err != nil {
+		panic(err)
+	}
+
+	errPing := pool.Ping(ctx)
+	if errPing != nil {
+		panic(errPing)
+	}
+
+	return pool
+}
+
 // RefreshTestDatabase - drops all tables in testing db and then runs all migrations again.
 func RefreshTestDatabase() {
-	migrator := migrations.NewMigrationManager(config.NewConfig())
+	cfg := config.NewConfig()
+	cfg.StorageDriver = config.StorageDriverPostgres
+
+	migrator := migrations.NewMigrationManager(cfg)
 	if err := migrator.RefreshTest(); err != nil {
 		panic(err)
 	}